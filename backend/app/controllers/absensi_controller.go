@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"sim-sekolah/app/middlewares"
 	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
 	"sim-sekolah/config"
 	"sim-sekolah/utils"
 )
@@ -295,56 +296,45 @@ func GetRekapAbsensiSiswa(c *gin.Context) {
 		return
 	}
 
-	query := config.DB.Model(&models.Absensi{}).Where("siswa_id = ?", siswaID)
-
-	// Filter semester (via jadwal)
-	if semesterID := c.Query("semester_id"); semesterID != "" {
-		query = query.Joins("JOIN jadwals ON jadwals.id = absensis.jadwal_id").
-			Where("jadwals.semester_id = ?", semesterID)
-	}
-
-	// Filter tanggal range
-	if dari := c.Query("dari"); dari != "" {
-		query = query.Where("tanggal >= ?", dari)
+	filter, ok := filterRekapAbsensi(c)
+	if !ok {
+		return
 	}
-	if sampai := c.Query("sampai"); sampai != "" {
-		query = query.Where("tanggal <= ?", sampai)
+	rekap, err := services.RekapAbsensiSiswa(siswa.ID, filter)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghitung rekap absensi")
+		return
 	}
 
 	type RekapStatus struct {
 		Status string `json:"status"`
 		Jumlah int64  `json:"jumlah"`
 	}
-
-	var rekap []RekapStatus
-	config.DB.Model(&models.Absensi{}).
-		Select("status, COUNT(*) as jumlah").
-		Where(query.Statement.SQL.String(), query.Statement.Vars...).
-		Group("status").
-		Scan(&rekap)
-
-	total := int64(0)
-	counts := map[string]int64{"hadir": 0, "izin": 0, "sakit": 0, "alfa": 0}
-	for _, r := range rekap {
-		counts[r.Status] = r.Jumlah
-		total += r.Jumlah
+	detail := []RekapStatus{}
+	for _, r := range []RekapStatus{
+		{"hadir", rekap.Hadir}, {"izin", rekap.Izin}, {"sakit", rekap.Sakit}, {"alfa", rekap.Alfa},
+	} {
+		if r.Jumlah > 0 {
+			detail = append(detail, r)
+		}
 	}
 
-	// Hitung persentase kehadiran
-	persentaseHadir := float64(0)
-	if total > 0 {
-		persentaseHadir = (float64(counts["hadir"]) / float64(total)) * 100
+	// Kelas yang berlaku pada semester yang direkap, bukan kelas siswa saat ini
+	kelasSemester := siswa.Kelas
+	if filter.SemesterID != 0 {
+		kelasSemester = services.KelasSiswaDiSemester(siswa.ID, filter.SemesterID)
 	}
 
 	utils.ResponseOK(c, "Rekap absensi siswa", gin.H{
 		"siswa":            siswa,
-		"total_pertemuan":  total,
-		"hadir":            counts["hadir"],
-		"izin":             counts["izin"],
-		"sakit":            counts["sakit"],
-		"alfa":             counts["alfa"],
-		"persentase_hadir": persentaseHadir,
-		"detail_rekap":     rekap,
+		"kelas":            kelasSemester,
+		"total_pertemuan":  rekap.TotalPertemuan,
+		"hadir":            rekap.Hadir,
+		"izin":             rekap.Izin,
+		"sakit":            rekap.Sakit,
+		"alfa":             rekap.Alfa,
+		"persentase_hadir": rekap.PersentaseHadir,
+		"detail_rekap":     detail,
 	})
 }
 
@@ -354,73 +344,69 @@ func GetRekapAbsensiSiswa(c *gin.Context) {
 // @Security BearerAuth
 // @Param kelas_id path int true "Kelas ID"
 // @Param semester_id query int true "Semester ID"
+// @Param dari query string false "Tanggal mulai YYYY-MM-DD"
+// @Param sampai query string false "Tanggal akhir YYYY-MM-DD"
 // @Router /absensi/rekap/kelas/{kelas_id} [get]
 func GetRekapAbsensiKelas(c *gin.Context) {
 	kelasID := c.Param("kelas_id")
-	semesterID := c.Query("semester_id")
 
-	if semesterID == "" {
+	if c.Query("semester_id") == "" {
 		utils.ResponseBadRequest(c, "Parameter semester_id wajib diisi", nil)
 		return
 	}
+	filter, ok := filterRekapAbsensi(c)
+	if !ok {
+		return
+	}
 
 	var kelas models.Kelas
 	if err := config.DB.Preload("Jurusan").First(&kelas, kelasID).Error; err != nil {
 		utils.ResponseNotFound(c, "Kelas tidak ditemukan")
 		return
 	}
+	filter.KelasID = kelas.ID
 
-	// Ambil semua siswa di kelas
-	var siswaList []models.Siswa
-	config.DB.Where("kelas_id = ?", kelasID).Find(&siswaList)
-
-	type RekapSiswa struct {
-		SiswaID         uint    `json:"siswa_id"`
-		Nama            string  `json:"nama"`
-		TotalPertemuan  int64   `json:"total_pertemuan"`
-		Hadir           int64   `json:"hadir"`
-		Izin            int64   `json:"izin"`
-		Sakit           int64   `json:"sakit"`
-		Alfa            int64   `json:"alfa"`
-		PersentaseHadir float64 `json:"persentase_hadir"`
-	}
-
-	var rekapList []RekapSiswa
-	for _, s := range siswaList {
-		var absensiList []models.Absensi
-		config.DB.Joins("JOIN jadwals ON jadwals.id = absensis.jadwal_id").
-			Where("absensis.siswa_id = ? AND jadwals.semester_id = ?", s.ID, semesterID).
-			Find(&absensiList)
-
-		counts := map[string]int64{"hadir": 0, "izin": 0, "sakit": 0, "alfa": 0}
-		for _, a := range absensiList {
-			counts[a.Status]++
-		}
-		total := int64(len(absensiList))
-		persen := float64(0)
-		if total > 0 {
-			persen = (float64(counts["hadir"]) / float64(total)) * 100
-		}
-
-		rekapList = append(rekapList, RekapSiswa{
-			SiswaID:         s.ID,
-			Nama:            s.Nama,
-			TotalPertemuan:  total,
-			Hadir:           counts["hadir"],
-			Izin:            counts["izin"],
-			Sakit:           counts["sakit"],
-			Alfa:            counts["alfa"],
-			PersentaseHadir: persen,
-		})
+	// Siswa yang tercatat di kelas ini selama rentang rekap (termasuk yang pindah di tengahnya)
+	dari, sampai := filter.Rentang()
+	rekapList, err := services.RekapAbsensiPerSiswa(services.SiswaIDDiKelas(kelas.ID, dari, sampai), filter)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghitung rekap absensi kelas")
+		return
 	}
 
 	utils.ResponseOK(c, "Rekap absensi kelas "+kelas.Nama, gin.H{
 		"kelas":       kelas,
-		"total_siswa": len(siswaList),
+		"total_siswa": len(rekapList),
 		"rekap":       rekapList,
 	})
 }
 
+// filterRekapAbsensi membaca semester_id, dari dan sampai dari query string
+func filterRekapAbsensi(c *gin.Context) (services.FilterRekapAbsensi, bool) {
+	var f services.FilterRekapAbsensi
+	if v := c.Query("semester_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			utils.ResponseBadRequest(c, "Parameter semester_id tidak valid", nil)
+			return f, false
+		}
+		f.SemesterID = uint(id)
+	}
+	for nama, tujuan := range map[string]**time.Time{"dari": &f.Dari, "sampai": &f.Sampai} {
+		v := c.Query(nama)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			utils.ResponseBadRequest(c, "Format "+nama+" harus YYYY-MM-DD", nil)
+			return f, false
+		}
+		*tujuan = &t
+	}
+	return f, true
+}
+
 // GetAbsensiSaya godoc
 // @Summary Rekap absensi untuk siswa/orang tua yang sedang login
 // @Tags Absensi
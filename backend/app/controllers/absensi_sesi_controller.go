@@ -0,0 +1,357 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sim-sekolah/app/middlewares"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// ── DTOs ──────────────────────────────────────────────────────
+
+type BukaSesiAbsensiRequest struct {
+	JadwalID       uint `json:"jadwal_id" binding:"required"`
+	IntervalDetik  int  `json:"interval_detik" binding:"omitempty,min=10,max=300"` // default ABSENSI_QR_INTERVAL_DETIK
+	WajibPerangkat bool `json:"wajib_perangkat"`
+}
+
+type CheckinAbsensiRequest struct {
+	Token       string `json:"token" binding:"required"`
+	PerangkatID string `json:"perangkat_id" binding:"max=100"` // ID perangkat dari aplikasi siswa
+}
+
+type OverrideAbsensiSesiRequest struct {
+	Status     string `json:"status" binding:"required,oneof=hadir izin sakit alfa"`
+	Keterangan string `json:"keterangan"`
+}
+
+// ── Helper akses ──────────────────────────────────────────────
+
+// guruMengajarJadwal: guru pemilik jadwal atau pengampu mapel di kelasnya
+func guruMengajarJadwal(guru models.Guru, jadwal models.Jadwal) bool {
+	return jadwal.GuruID == guru.ID ||
+		services.GuruMengampu(guru.ID, jadwal.SemesterID, jadwal.KelasID, jadwal.MataPelajaranID)
+}
+
+// ambilSesiAbsensi memuat sesi yang boleh dikelola user yang sedang login:
+// admin untuk semua sesi, guru hanya untuk jadwal yang ia ajar
+func ambilSesiAbsensi(c *gin.Context) (models.SesiAbsensi, bool) {
+	var sesi models.SesiAbsensi
+	if err := config.DB.Preload("Jadwal.Kelas").Preload("Jadwal.MataPelajaran").
+		First(&sesi, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Sesi absensi tidak ditemukan")
+		return sesi, false
+	}
+	if middlewares.GetCurrentUser(c).Role == models.RoleAdmin {
+		return sesi, true
+	}
+	guru, ok := guruLogin(c)
+	if !ok {
+		return sesi, false
+	}
+	if sesi.GuruID != guru.ID && !guruMengajarJadwal(guru, sesi.Jadwal) {
+		utils.ResponseForbidden(c, "Anda tidak mengajar pada jadwal ini")
+		return sesi, false
+	}
+	return sesi, true
+}
+
+// ── Sesi (guru) ───────────────────────────────────────────────
+
+// BukaSesiAbsensi godoc
+// @Summary Buka sesi absensi QR untuk pertemuan jadwal hari ini
+// @Description Jika sesi hari ini sudah dibuka, sesi yang sama dikembalikan.
+// @Tags Absensi QR
+// @Security BearerAuth
+// @Param body body BukaSesiAbsensiRequest true "Jadwal dan pengaturan sesi"
+// @Router /absensi/sesi [post]
+func BukaSesiAbsensi(c *gin.Context) {
+	var req BukaSesiAbsensiRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	guru, ok := guruLogin(c)
+	if !ok {
+		return
+	}
+
+	var jadwal models.Jadwal
+	if err := config.DB.Preload("Kelas").Preload("MataPelajaran").First(&jadwal, req.JadwalID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Jadwal tidak ditemukan", nil)
+		return
+	}
+	if !guruMengajarJadwal(guru, jadwal) {
+		utils.ResponseForbidden(c, "Anda tidak mengajar pada jadwal ini")
+		return
+	}
+
+	now := time.Now()
+	hariKe := int(now.Weekday())
+	if hariKe == 0 {
+		hariKe = 7
+	}
+	if hariKe != jadwal.HariKe {
+		utils.ResponseBadRequest(c, "Sesi absensi hanya dapat dibuka pada hari jadwal ("+services.NamaHari(jadwal.HariKe)+")", nil)
+		return
+	}
+	tanggal, _ := time.Parse("2006-01-02", now.Format("2006-01-02"))
+
+	var sesi models.SesiAbsensi
+	if err := config.DB.Where("jadwal_id = ? AND tanggal = ?", jadwal.ID, tanggal.Format("2006-01-02")).
+		First(&sesi).Error; err == nil {
+		if sesi.Status == models.SesiAbsensiDitutup {
+			utils.ResponseBadRequest(c, "Sesi absensi pertemuan ini sudah ditutup, gunakan koreksi absensi per siswa", gin.H{"sesi_id": sesi.ID})
+			return
+		}
+		sesi.Jadwal = jadwal
+		utils.ResponseOK(c, "Sesi absensi sudah dibuka", sesi)
+		return
+	}
+
+	rahasia, err := services.BuatRahasiaSesi()
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal membuat kunci sesi")
+		return
+	}
+	interval := req.IntervalDetik
+	if interval == 0 {
+		interval = services.IntervalTokenSesi()
+	}
+	sesi = models.SesiAbsensi{
+		JadwalID:       jadwal.ID,
+		Tanggal:        tanggal,
+		GuruID:         guru.ID,
+		Rahasia:        rahasia,
+		IntervalDetik:  interval,
+		WajibPerangkat: req.WajibPerangkat,
+		Status:         models.SesiAbsensiDibuka,
+		DibukaPada:     now,
+	}
+	if err := config.DB.Create(&sesi).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal membuka sesi absensi")
+		return
+	}
+	sesi.Jadwal = jadwal
+	utils.ResponseCreated(c, "Sesi absensi dibuka", sesi)
+}
+
+// GetSesiAbsensi godoc
+// @Summary Detail sesi absensi QR beserta status kehadiran setiap siswa
+// @Tags Absensi QR
+// @Security BearerAuth
+// @Param id path int true "Sesi ID"
+// @Router /absensi/sesi/{id} [get]
+func GetSesiAbsensi(c *gin.Context) {
+	sesi, ok := ambilSesiAbsensi(c)
+	if !ok {
+		return
+	}
+	siswa, err := services.KehadiranSesi(sesi, sesi.Jadwal.KelasID)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal mengambil kehadiran sesi")
+		return
+	}
+
+	ringkasan := map[string]int{"hadir": 0, "izin": 0, "sakit": 0, "alfa": 0, "belum": 0, "checkin_qr": 0}
+	for _, s := range siswa {
+		if s.Status == nil {
+			ringkasan["belum"]++
+		} else {
+			ringkasan[*s.Status]++
+		}
+		if s.WaktuCheckin != nil {
+			ringkasan["checkin_qr"]++
+		}
+	}
+	utils.ResponseOK(c, "Detail sesi absensi", gin.H{
+		"sesi":      sesi,
+		"ringkasan": ringkasan,
+		"siswa":     siswa,
+	})
+}
+
+// GetQRSesiAbsensi godoc
+// @Summary QR sesi absensi yang berlaku saat ini (berganti setiap interval_detik)
+// @Description Layar guru memanggil ulang endpoint ini sebelum berlaku_sampai.
+// @Tags Absensi QR
+// @Security BearerAuth
+// @Param id path int true "Sesi ID"
+// @Param format query string false "json (default) atau png"
+// @Router /absensi/sesi/{id}/qr [get]
+func GetQRSesiAbsensi(c *gin.Context) {
+	sesi, ok := ambilSesiAbsensi(c)
+	if !ok {
+		return
+	}
+	if sesi.Status != models.SesiAbsensiDibuka {
+		utils.ResponseBadRequest(c, "Sesi absensi sudah ditutup", nil)
+		return
+	}
+
+	token, berlakuSampai := services.TokenSesiAbsensi(sesi, time.Now())
+	c.Header("Cache-Control", "no-store")
+	if c.Query("format") == "png" {
+		png, err := services.QRCodePNG(token, 400)
+		if err != nil {
+			utils.ResponseInternalError(c, "Gagal membuat QR")
+			return
+		}
+		c.Header("X-QR-Berlaku-Sampai", berlakuSampai.Format(time.RFC3339))
+		c.Data(200, "image/png", png)
+		return
+	}
+	utils.ResponseOK(c, "QR sesi absensi", gin.H{
+		"token":          token,
+		"berlaku_sampai": berlakuSampai,
+		"interval_detik": sesi.IntervalDetik,
+	})
+}
+
+// TutupSesiAbsensi godoc
+// @Summary Tutup sesi absensi QR; siswa yang belum check-in dicatat alfa
+// @Tags Absensi QR
+// @Security BearerAuth
+// @Param id path int true "Sesi ID"
+// @Router /absensi/sesi/{id}/tutup [post]
+func TutupSesiAbsensi(c *gin.Context) {
+	sesi, ok := ambilSesiAbsensi(c)
+	if !ok {
+		return
+	}
+	jumlahAlfa, err := services.TutupSesi(&sesi, sesi.Jadwal.KelasID)
+	if errors.Is(err, services.ErrSesiAbsensiDitutup) {
+		utils.ResponseBadRequest(c, "Sesi absensi sudah ditutup", nil)
+		return
+	}
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menutup sesi absensi")
+		return
+	}
+	utils.ResponseOK(c, "Sesi absensi ditutup", gin.H{
+		"sesi":         sesi,
+		"dicatat_alfa": jumlahAlfa,
+	})
+}
+
+// OverrideAbsensiSesi godoc
+// @Summary Koreksi status absensi seorang siswa pada sesi QR (juga setelah sesi ditutup)
+// @Tags Absensi QR
+// @Security BearerAuth
+// @Param id path int true "Sesi ID"
+// @Param siswa_id path int true "Siswa ID"
+// @Param body body OverrideAbsensiSesiRequest true "Status baru"
+// @Router /absensi/sesi/{id}/siswa/{siswa_id} [put]
+func OverrideAbsensiSesi(c *gin.Context) {
+	sesi, ok := ambilSesiAbsensi(c)
+	if !ok {
+		return
+	}
+	var req OverrideAbsensiSesiRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	siswaID, err := strconv.ParseUint(c.Param("siswa_id"), 10, 64)
+	if err != nil || !services.SiswaDiKelasJadwal(uint(siswaID), sesi.Jadwal) {
+		utils.ResponseBadRequest(c, "Siswa tidak terdaftar di kelas pada jadwal ini", nil)
+		return
+	}
+
+	var abs models.Absensi
+	err = config.DB.Where("jadwal_id = ? AND siswa_id = ? AND DATE(tanggal) = ?",
+		sesi.JadwalID, siswaID, sesi.Tanggal.Format("2006-01-02")).First(&abs).Error
+	if err != nil {
+		abs = models.Absensi{JadwalID: sesi.JadwalID, SiswaID: uint(siswaID), Tanggal: sesi.Tanggal}
+	}
+	abs.Status = req.Status
+	abs.Keterangan = req.Keterangan
+	if err := config.DB.Omit("Siswa", "Jadwal").Save(&abs).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan absensi")
+		return
+	}
+	utils.ResponseOK(c, "Absensi siswa dikoreksi", abs)
+}
+
+// ResetPerangkatSiswa godoc
+// @Summary Hapus perangkat terdaftar siswa agar bisa check-in dari perangkat baru
+// @Tags Absensi QR
+// @Security BearerAuth
+// @Param siswa_id path int true "Siswa ID"
+// @Router /absensi/perangkat/{siswa_id} [delete]
+func ResetPerangkatSiswa(c *gin.Context) {
+	res := config.DB.Where("siswa_id = ?", c.Param("siswa_id")).Delete(&models.PerangkatSiswa{})
+	if res.Error != nil {
+		utils.ResponseInternalError(c, "Gagal menghapus perangkat siswa")
+		return
+	}
+	if res.RowsAffected == 0 {
+		utils.ResponseNotFound(c, "Siswa belum memiliki perangkat terdaftar")
+		return
+	}
+	utils.ResponseOK(c, "Perangkat siswa dihapus, perangkat berikutnya yang dipakai check-in akan didaftarkan", nil)
+}
+
+// ── Check-in (siswa) ──────────────────────────────────────────
+
+// CheckinAbsensiQR godoc
+// @Summary Siswa check-in hadir dengan memindai QR sesi absensi
+// @Tags Absensi QR
+// @Security BearerAuth
+// @Param body body CheckinAbsensiRequest true "Isi QR dan ID perangkat"
+// @Router /absensi/checkin [post]
+func CheckinAbsensiQR(c *gin.Context) {
+	var req CheckinAbsensiRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	claims := middlewares.GetCurrentUser(c)
+	var siswa models.Siswa
+	if err := config.DB.Where("user_id = ?", claims.UserID).First(&siswa).Error; err != nil {
+		utils.ResponseForbidden(c, "Akun ini tidak terdaftar sebagai siswa")
+		return
+	}
+
+	sesiID, err := services.SesiDariToken(req.Token)
+	if err != nil {
+		utils.ResponseBadRequest(c, "Check-in ditolak: "+err.Error(), nil)
+		return
+	}
+	var sesi models.SesiAbsensi
+	if err := config.DB.Preload("Jadwal.MataPelajaran").First(&sesi, sesiID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Check-in ditolak: "+services.ErrTokenSesiTidakValid.Error(), nil)
+		return
+	}
+	jendela, err := services.ValidasiTokenSesi(sesi, req.Token, time.Now())
+	if err != nil {
+		utils.ResponseBadRequest(c, "Check-in ditolak: "+err.Error(), nil)
+		return
+	}
+	if !services.SiswaDiKelasJadwal(siswa.ID, sesi.Jadwal) {
+		utils.ResponseForbidden(c, "Check-in ditolak: "+services.ErrSiswaBukanAnggotaKelas.Error())
+		return
+	}
+
+	abs, err := services.CheckinSesi(sesi, siswa.ID, jendela, req.PerangkatID, c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrSudahCheckin),
+			errors.Is(err, services.ErrPerangkatWajib),
+			errors.Is(err, services.ErrPerangkatTidakTerdaftar),
+			errors.Is(err, services.ErrPerangkatMilikSiswaLain),
+			errors.Is(err, services.ErrPerangkatSudahDipakai):
+			utils.ResponseBadRequest(c, "Check-in ditolak: "+err.Error(), nil)
+		default:
+			utils.ResponseInternalError(c, "Gagal menyimpan check-in")
+		}
+		return
+	}
+	utils.ResponseCreated(c, "Check-in berhasil, Anda tercatat hadir di "+sesi.Jadwal.MataPelajaran.Nama, abs)
+}
@@ -0,0 +1,227 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// filterAnalitik membaca filter umum dari query string. Jika semester_id dan
+// tahun_ajaran_id sama-sama kosong dan defaultSemesterAktif bernilai true,
+// data dibatasi ke semester aktif.
+func filterAnalitik(c *gin.Context, defaultSemesterAktif bool) (services.FilterAnalitik, bool) {
+	var f services.FilterAnalitik
+	ids := map[string]*uint{
+		"tahun_ajaran_id":   &f.TahunAjaranID,
+		"semester_id":       &f.SemesterID,
+		"kelas_id":          &f.KelasID,
+		"jurusan_id":        &f.JurusanID,
+		"mata_pelajaran_id": &f.MataPelajaranID,
+		"guru_id":           &f.GuruID,
+	}
+	for nama, tujuan := range ids {
+		v := c.Query(nama)
+		if v == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			utils.ResponseBadRequest(c, "Parameter "+nama+" tidak valid", nil)
+			return f, false
+		}
+		*tujuan = uint(id)
+	}
+	tanggal := map[string]**time.Time{"dari": &f.Dari, "sampai": &f.Sampai}
+	for nama, tujuan := range tanggal {
+		v := c.Query(nama)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			utils.ResponseBadRequest(c, "Format "+nama+" harus YYYY-MM-DD", nil)
+			return f, false
+		}
+		*tujuan = &t
+	}
+	f.NamaSemester = c.Query("semester")
+	switch f.Komponen = c.Query("komponen"); f.Komponen {
+	case "", models.KomponenHarian, models.KomponenUTS, models.KomponenUAS:
+	default:
+		utils.ResponseBadRequest(c, "Parameter komponen harus harian, uts atau uas", nil)
+		return f, false
+	}
+
+	if defaultSemesterAktif && f.SemesterID == 0 && f.TahunAjaranID == 0 {
+		var aktif models.Semester
+		if err := config.DB.Where("is_aktif = ?", true).First(&aktif).Error; err == nil {
+			f.SemesterID = aktif.ID
+		}
+	}
+	return f, true
+}
+
+// GetTrenKehadiran godoc
+// @Summary Tren persentase kehadiran per minggu/bulan
+// @Tags Analytics
+// @Security BearerAuth
+// @Param periode query string false "minggu (default) / bulan"
+// @Param kelompok query string false "sekolah (default) / kelas / jurusan"
+// @Param semester_id query int false "Filter semester (default: semester aktif)"
+// @Param tahun_ajaran_id query int false "Filter tahun ajaran"
+// @Param kelas_id query int false "Filter kelas"
+// @Param jurusan_id query int false "Filter jurusan"
+// @Param dari query string false "Tanggal mulai YYYY-MM-DD"
+// @Param sampai query string false "Tanggal akhir YYYY-MM-DD"
+// @Router /analytics/kehadiran/tren [get]
+func GetTrenKehadiran(c *gin.Context) {
+	f, ok := filterAnalitik(c, true)
+	if !ok {
+		return
+	}
+	periode := c.DefaultQuery("periode", "minggu")
+	if periode != "minggu" && periode != "bulan" {
+		utils.ResponseBadRequest(c, "Periode harus minggu atau bulan", nil)
+		return
+	}
+	kelompok := c.DefaultQuery("kelompok", "sekolah")
+	if kelompok != "sekolah" && kelompok != "kelas" && kelompok != "jurusan" {
+		utils.ResponseBadRequest(c, "Kelompok harus sekolah, kelas atau jurusan", nil)
+		return
+	}
+
+	tren, err := services.TrenKehadiran(f, periode, kelompok)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghitung tren kehadiran")
+		return
+	}
+	utils.ResponseOK(c, "Tren kehadiran", gin.H{
+		"periode":  periode,
+		"kelompok": kelompok,
+		"filter":   f,
+		"data":     tren,
+	})
+}
+
+// GetDistribusiNilai godoc
+// @Summary Sebaran nilai akhir per mata pelajaran
+// @Tags Analytics
+// @Security BearerAuth
+// @Param semester_id query int false "Filter semester (default: semester aktif)"
+// @Param tahun_ajaran_id query int false "Filter tahun ajaran"
+// @Param kelas_id query int false "Filter kelas"
+// @Param jurusan_id query int false "Filter jurusan"
+// @Param mata_pelajaran_id query int false "Filter mata pelajaran"
+// @Router /analytics/nilai/distribusi [get]
+func GetDistribusiNilai(c *gin.Context) {
+	f, ok := filterAnalitik(c, true)
+	if !ok {
+		return
+	}
+	distribusi, err := services.DistribusiNilai(f)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghitung distribusi nilai")
+		return
+	}
+	utils.ResponseOK(c, "Distribusi nilai per mata pelajaran", gin.H{
+		"filter": f,
+		"data":   distribusi,
+	})
+}
+
+// GetNilaiDibawahKKM godoc
+// @Summary Jumlah nilai dan siswa di bawah KKM
+// @Tags Analytics
+// @Security BearerAuth
+// @Param kelompok query string false "mapel (default) / kelas / jurusan"
+// @Param semester_id query int false "Filter semester (default: semester aktif)"
+// @Param tahun_ajaran_id query int false "Filter tahun ajaran"
+// @Param kelas_id query int false "Filter kelas"
+// @Param jurusan_id query int false "Filter jurusan"
+// @Param mata_pelajaran_id query int false "Filter mata pelajaran"
+// @Router /analytics/nilai/dibawah-kkm [get]
+func GetNilaiDibawahKKM(c *gin.Context) {
+	f, ok := filterAnalitik(c, true)
+	if !ok {
+		return
+	}
+	kelompok := c.DefaultQuery("kelompok", "mapel")
+	if kelompok != "mapel" && kelompok != "kelas" && kelompok != "jurusan" {
+		utils.ResponseBadRequest(c, "Kelompok harus mapel, kelas atau jurusan", nil)
+		return
+	}
+	rekap, err := services.RekapDibawahKKM(f, kelompok)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghitung nilai di bawah KKM")
+		return
+	}
+	utils.ResponseOK(c, "Nilai di bawah KKM", gin.H{
+		"kelompok": kelompok,
+		"filter":   f,
+		"data":     rekap,
+	})
+}
+
+// GetKelengkapanInputGuru godoc
+// @Summary Jadwal yang pertemuannya sudah lewat tetapi belum diisi absensi
+// @Tags Analytics
+// @Security BearerAuth
+// @Param semester_id query int false "Filter semester (default: semester aktif)"
+// @Param tahun_ajaran_id query int false "Filter tahun ajaran"
+// @Param kelas_id query int false "Filter kelas"
+// @Param jurusan_id query int false "Filter jurusan"
+// @Param guru_id query int false "Filter guru"
+// @Param dari query string false "Tanggal mulai YYYY-MM-DD"
+// @Param sampai query string false "Tanggal akhir YYYY-MM-DD"
+// @Param semua query bool false "true = sertakan jadwal yang sudah lengkap"
+// @Router /analytics/kelengkapan-input [get]
+func GetKelengkapanInputGuru(c *gin.Context) {
+	f, ok := filterAnalitik(c, true)
+	if !ok {
+		return
+	}
+	jadwal, err := services.KelengkapanAbsensi(f, c.Query("semua") != "true")
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghitung kelengkapan input")
+		return
+	}
+
+	var pertemuan, kosong int64
+	for _, j := range jadwal {
+		pertemuan += j.JumlahPertemuan
+		kosong += j.Kosong
+	}
+	utils.ResponseOK(c, "Kelengkapan input absensi guru", gin.H{
+		"filter":          f,
+		"jumlah_jadwal":   len(jadwal),
+		"total_pertemuan": pertemuan,
+		"total_kosong":    kosong,
+		"data":            jadwal,
+	})
+}
+
+// GetPerbandinganTahunan godoc
+// @Summary Perbandingan kehadiran dan nilai antar tahun ajaran
+// @Tags Analytics
+// @Security BearerAuth
+// @Param semester query string false "Bandingkan semester yang sama saja (Ganjil / Genap)"
+// @Param jurusan_id query int false "Filter jurusan"
+// @Param mata_pelajaran_id query int false "Filter mata pelajaran"
+// @Router /analytics/perbandingan-tahunan [get]
+func GetPerbandinganTahunan(c *gin.Context) {
+	f, ok := filterAnalitik(c, false)
+	if !ok {
+		return
+	}
+	data, err := services.PerbandinganTahunan(f)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghitung perbandingan antar tahun ajaran")
+		return
+	}
+	utils.ResponseOK(c, "Perbandingan antar tahun ajaran", data)
+}
@@ -1,10 +1,14 @@
 package controllers
 
 import (
+	"fmt"
+	"log"
+	"math"
 	"time"
 
 	"sim-sekolah/app/middlewares"
 	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
 	"sim-sekolah/config"
 	"sim-sekolah/utils"
 
@@ -51,16 +55,25 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	ip := c.ClientIP()
+
+	// Tolak lebih awal jika akun / IP sedang dikunci
+	if sisa := services.CekLockout(req.Email, ip); sisa > 0 {
+		utils.ResponseTooManyRequests(c, fmt.Sprintf(
+			"Terlalu banyak percobaan login gagal. Coba lagi dalam %d menit", int(math.Ceil(sisa.Minutes()))))
+		return
+	}
+
 	// Cari user berdasarkan email, eager load Role
 	var user models.User
 	if err := config.DB.Preload("Role").Where("email = ? AND is_active = true", req.Email).First(&user).Error; err != nil {
-		utils.ResponseUnauthorized(c, "Email atau password salah")
+		loginGagal(c, req.Email, nil, "Email atau password salah")
 		return
 	}
 
 	// Verifikasi password
 	if !user.CheckPassword(req.Password) {
-		utils.ResponseUnauthorized(c, "Email atau password salah")
+		loginGagal(c, req.Email, &user, "Email atau password salah")
 		return
 	}
 
@@ -70,16 +83,37 @@ func Login(c *gin.Context) {
         return
     }
 
-	// Generate token
-	token, err := utils.GenerateToken(user.ID, user.RoleID, user.Nama, user.Email, user.Role.Nama)
+	// Langkah kedua: akun dengan 2FA aktif harus memverifikasi kode TOTP dulu
+	if user.TOTPAktif {
+		partial, err := utils.GeneratePartialToken(user.ID, user.Email)
+		if err != nil {
+			utils.ResponseInternalError(c, "Gagal membuat token")
+			return
+		}
+		utils.ResponseOK(c, "Masukkan kode autentikasi dua faktor", gin.H{
+			"two_fa_required": true,
+			"partial_token":   partial,
+			"expires_in":      300,
+		})
+		return
+	}
+
+	kirimLoginBerhasil(c, user)
+}
+
+// kirimLoginBerhasil membuat access token lalu mengirim response login.
+// Dipakai oleh Login (tanpa 2FA) dan Verify2FA.
+func kirimLoginBerhasil(c *gin.Context, user models.User) {
+	token, flags, err := buatTokenUser(user)
 	if err != nil {
 		utils.ResponseInternalError(c, "Gagal membuat token")
 		return
 	}
 
-	// Update last login
+	// Update last login & bersihkan hitungan gagal
 	now := time.Now()
 	config.DB.Model(&user).Update("last_login", now)
+	services.ResetLoginGagal(user.Email)
 
     c.JSON(200, gin.H{
         "success": true,
@@ -91,6 +125,8 @@ func Login(c *gin.Context) {
                 "nama":      user.Nama,
                 "email":     user.Email,
                 "is_active": user.IsActive,
+                "wajib_ganti_password": flags.WajibGantiPassword,
+                "wajib_2fa":            flags.Wajib2FA,
                 "role": gin.H{
                     "id":        user.Role.ID,
                     "nama_role": user.Role.Nama,  // ← sekarang object, bukan string
@@ -100,6 +136,40 @@ func Login(c *gin.Context) {
     })
 }
 
+// buatTokenUser membuat access token beserta flag pembatasan akses user.
+// User harus sudah di-preload Role-nya.
+func buatTokenUser(user models.User) (string, utils.TokenFlags, error) {
+	flags := utils.TokenFlags{
+		WajibGantiPassword: user.WajibGantiPassword,
+		Wajib2FA:           user.Role.Wajib2FA && !user.TOTPAktif,
+	}
+	token, err := utils.GenerateToken(user.ID, user.RoleID, user.Nama, user.Email, user.Role.Nama, flags)
+	return token, flags, err
+}
+
+// loginGagal mencatat percobaan gagal, menerapkan jeda progresif,
+// dan menulis activity log jika percobaan ini memicu lockout
+func loginGagal(c *gin.Context, email string, user *models.User, pesan string) {
+	hasil := services.CatatLoginGagal(email, c.ClientIP())
+
+	if hasil.Terkunci {
+		if user != nil {
+			services.CatatAktivitas(models.ActivityLog{
+				UserID:    user.ID,
+				Action:    "LOCKOUT",
+				Entity:    "auth",
+				IPAddress: c.ClientIP(),
+				UserAgent: c.Request.UserAgent(),
+			})
+		}
+		log.Printf("🔒 Login dikunci (%v) untuk email=%s ip=%s sampai %s",
+			hasil.TipeTerkunci, email, c.ClientIP(), hasil.TerkunciSampai.Format(time.RFC3339))
+	}
+
+	time.Sleep(hasil.Jeda)
+	utils.ResponseUnauthorized(c, pesan)
+}
+
 // Me godoc
 // @Summary Ambil data pengguna yang sedang login
 // @Tags Auth
@@ -130,6 +200,8 @@ func Me(c *gin.Context) {
 		},
 		"is_active":  user.IsActive,
 		"last_login": user.LastLogin,
+		"wajib_ganti_password": user.WajibGantiPassword,
+		"totp_aktif":           user.TOTPAktif,
 	})
 }
 
@@ -153,7 +225,7 @@ func ChangePassword(c *gin.Context) {
 	}
 
 	var user models.User
-	if err := config.DB.First(&user, claims.UserID).Error; err != nil {
+	if err := config.DB.Preload("Role").First(&user, claims.UserID).Error; err != nil {
 		utils.ResponseNotFound(c, "User tidak ditemukan")
 		return
 	}
@@ -169,10 +241,93 @@ func ChangePassword(c *gin.Context) {
 	}
 
 	// Simpan langsung (bypass BeforeCreate hook)
-	if err := config.DB.Model(&user).Update("password", user.Password).Error; err != nil {
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"password":             user.Password,
+		"wajib_ganti_password": false,
+	}).Error; err != nil {
 		utils.ResponseInternalError(c, "Gagal menyimpan password baru")
 		return
 	}
 
-	utils.ResponseOK(c, "Password berhasil diubah", nil)
+	// Token baru tanpa flag wajib ganti password. wajib_2fa dikirim agar
+	// frontend tahu apakah user masih harus mengaktifkan 2FA.
+	user.WajibGantiPassword = false
+	token, flags, err := buatTokenUser(user)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal membuat token")
+		return
+	}
+
+	utils.ResponseOK(c, "Password berhasil diubah", gin.H{
+		"token":     token,
+		"wajib_2fa": flags.Wajib2FA,
+	})
+}
+
+// ForgotPassword godoc
+// @Summary Minta tautan reset password lewat email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Router /auth/forgot-password [post]
+func ForgotPassword(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	// Response selalu sama agar tidak bisa dipakai menebak email terdaftar
+	pesan := "Jika email terdaftar, tautan reset password telah dikirim"
+
+	var user models.User
+	if err := config.DB.Where("email = ? AND is_active = true", req.Email).First(&user).Error; err != nil {
+		utils.ResponseOK(c, pesan, nil)
+		return
+	}
+
+	if err := services.KirimLinkResetPassword(user, c.ClientIP()); err != nil {
+		log.Printf("❌ Gagal mengirim email reset password ke %s: %v", user.Email, err)
+	}
+
+	utils.ResponseOK(c, pesan, nil)
+}
+
+// ResetPassword godoc
+// @Summary Atur password baru menggunakan token reset dari email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Router /auth/reset-password [post]
+func ResetPassword(c *gin.Context) {
+	var req struct {
+		Token        string `json:"token" binding:"required"`
+		PasswordBaru string `json:"password_baru" binding:"required,min=8"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	user, err := services.ResetPasswordDenganToken(req.Token, req.PasswordBaru)
+	if err == services.ErrTokenResetTidakValid {
+		utils.ResponseBadRequest(c, "Token reset tidak valid atau sudah kadaluarsa", nil)
+		return
+	}
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal mengatur ulang password")
+		return
+	}
+
+	services.CatatAktivitas(models.ActivityLog{
+		UserID:    user.ID,
+		Action:    "RESET_PASSWORD",
+		Entity:    "auth",
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	utils.ResponseOK(c, "Password berhasil diatur ulang, silakan login", nil)
 }
\ No newline at end of file
@@ -0,0 +1,529 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// ── Tujuan Pembelajaran ───────────────────────────────────────
+
+type TujuanPembelajaranRequest struct {
+	MataPelajaranID uint   `json:"mata_pelajaran_id" binding:"required"`
+	Tingkat         string `json:"tingkat" binding:"required,oneof=X XI XII"`
+	Kode            string `json:"kode" binding:"max=20"`
+	Deskripsi       string `json:"deskripsi" binding:"required"`
+	Urutan          int    `json:"urutan"`
+}
+
+// GetTujuanPembelajaran godoc
+// @Summary Daftar tujuan pembelajaran (TP)
+// @Tags Capaian
+// @Security BearerAuth
+// @Param mata_pelajaran_id query int false "Filter mapel"
+// @Param tingkat query string false "Filter tingkat"
+// @Router /tujuan-pembelajaran [get]
+func GetTujuanPembelajaran(c *gin.Context) {
+	query := config.DB.Model(&models.TujuanPembelajaran{}).Preload("MataPelajaran")
+	if v := c.Query("mata_pelajaran_id"); v != "" {
+		query = query.Where("mata_pelajaran_id = ?", v)
+	}
+	if v := c.Query("tingkat"); v != "" {
+		query = query.Where("tingkat = ?", v)
+	}
+
+	var list []models.TujuanPembelajaran
+	query.Order("mata_pelajaran_id ASC, tingkat ASC, urutan ASC, id ASC").Find(&list)
+	utils.ResponseOK(c, "Daftar tujuan pembelajaran", list)
+}
+
+// CreateTujuanPembelajaran godoc
+// @Summary Tambah tujuan pembelajaran
+// @Tags Capaian
+// @Security BearerAuth
+// @Router /tujuan-pembelajaran [post]
+func CreateTujuanPembelajaran(c *gin.Context) {
+	var req TujuanPembelajaranRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	var mapel models.MataPelajaran
+	if err := config.DB.First(&mapel, req.MataPelajaranID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Mata pelajaran tidak ditemukan", nil)
+		return
+	}
+
+	tp := models.TujuanPembelajaran{
+		MataPelajaranID: req.MataPelajaranID,
+		Tingkat:         req.Tingkat,
+		Kode:            req.Kode,
+		Deskripsi:       req.Deskripsi,
+		Urutan:          req.Urutan,
+	}
+	if err := config.DB.Create(&tp).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan tujuan pembelajaran")
+		return
+	}
+
+	config.DB.Preload("MataPelajaran").First(&tp, tp.ID)
+	utils.ResponseCreated(c, "Tujuan pembelajaran berhasil ditambahkan", tp)
+}
+
+// UpdateTujuanPembelajaran godoc
+// @Summary Update tujuan pembelajaran
+// @Tags Capaian
+// @Security BearerAuth
+// @Param id path int true "ID"
+// @Router /tujuan-pembelajaran/{id} [put]
+func UpdateTujuanPembelajaran(c *gin.Context) {
+	var tp models.TujuanPembelajaran
+	if err := config.DB.First(&tp, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Tujuan pembelajaran tidak ditemukan")
+		return
+	}
+
+	var req struct {
+		Kode      *string `json:"kode" binding:"omitempty,max=20"`
+		Deskripsi string  `json:"deskripsi"`
+		Urutan    *int    `json:"urutan"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	if req.Kode != nil {
+		tp.Kode = *req.Kode
+	}
+	if req.Deskripsi != "" {
+		tp.Deskripsi = req.Deskripsi
+	}
+	if req.Urutan != nil {
+		tp.Urutan = *req.Urutan
+	}
+
+	config.DB.Save(&tp)
+	config.DB.Preload("MataPelajaran").First(&tp, tp.ID)
+	utils.ResponseOK(c, "Tujuan pembelajaran berhasil diupdate", tp)
+}
+
+// DeleteTujuanPembelajaran godoc
+// @Summary Hapus tujuan pembelajaran
+// @Tags Capaian
+// @Security BearerAuth
+// @Param id path int true "ID"
+// @Router /tujuan-pembelajaran/{id} [delete]
+func DeleteTujuanPembelajaran(c *gin.Context) {
+	var tp models.TujuanPembelajaran
+	if err := config.DB.First(&tp, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Tujuan pembelajaran tidak ditemukan")
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.CapaianTujuanPembelajaran{}).Where("tujuan_pembelajaran_id = ?", tp.ID).Count(&count)
+	if count > 0 {
+		utils.ResponseBadRequest(c, "Tujuan pembelajaran sudah dipakai pada capaian siswa, tidak bisa dihapus", nil)
+		return
+	}
+
+	config.DB.Delete(&tp)
+	utils.ResponseOK(c, "Tujuan pembelajaran berhasil dihapus", nil)
+}
+
+// ── Capaian Kompetensi ────────────────────────────────────────
+
+// SimpanCapaian godoc
+// @Summary Simpan TP yang tercapai / perlu bimbingan untuk seorang siswa
+// @Tags Capaian
+// @Security BearerAuth
+// @Router /capaian [post]
+func SimpanCapaian(c *gin.Context) {
+	var req struct {
+		SiswaID         uint   `json:"siswa_id" binding:"required"`
+		SemesterID      uint   `json:"semester_id" binding:"required"`
+		MataPelajaranID uint   `json:"mata_pelajaran_id" binding:"required"`
+		Tercapai        []uint `json:"tercapai"`
+		PerluBimbingan  []uint `json:"perlu_bimbingan"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	var siswa models.Siswa
+	if err := config.DB.First(&siswa, req.SiswaID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Siswa tidak ditemukan", nil)
+		return
+	}
+	var semester models.Semester
+	if err := config.DB.First(&semester, req.SemesterID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Semester tidak ditemukan", nil)
+		return
+	}
+
+	// Hanya guru pengampu mapel di kelas siswa yang boleh mengisi capaian
+	if !pastikanPengampu(c, siswa.ID, semester.ID, req.MataPelajaranID) {
+		return
+	}
+	guru, _ := guruLogin(c)
+	kelas := services.KelasSiswaDiSemester(siswa.ID, semester.ID)
+
+	// Semua TP harus milik mapel & tingkat kelas siswa, dan tidak boleh dipilih dua kali
+	status := map[uint]string{}
+	for _, id := range req.Tercapai {
+		status[id] = models.CapaianTercapai
+	}
+	for _, id := range req.PerluBimbingan {
+		if _, ok := status[id]; ok {
+			utils.ResponseBadRequest(c, "TP yang sama tidak boleh tercapai sekaligus perlu bimbingan", gin.H{"tujuan_pembelajaran_id": id})
+			return
+		}
+		status[id] = models.CapaianPerluBimbingan
+	}
+
+	var tpMapel []models.TujuanPembelajaran
+	config.DB.Where("mata_pelajaran_id = ? AND tingkat = ?", req.MataPelajaranID, kelas.Tingkat).Find(&tpMapel)
+	valid := map[uint]bool{}
+	var semuaTP []uint
+	for _, tp := range tpMapel {
+		valid[tp.ID] = true
+		semuaTP = append(semuaTP, tp.ID)
+	}
+	for id := range status {
+		if !valid[id] {
+			utils.ResponseBadRequest(c, "Tujuan pembelajaran tidak sesuai dengan mapel dan tingkat kelas siswa", gin.H{"tujuan_pembelajaran_id": id})
+			return
+		}
+	}
+
+	// Pilihan baru menggantikan seluruh pilihan lama untuk mapel ini
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if len(semuaTP) > 0 {
+			if err := tx.Where("siswa_id = ? AND semester_id = ? AND tujuan_pembelajaran_id IN ?", siswa.ID, semester.ID, semuaTP).
+				Delete(&models.CapaianTujuanPembelajaran{}).Error; err != nil {
+				return err
+			}
+		}
+		for id, s := range status {
+			capaian := models.CapaianTujuanPembelajaran{
+				SiswaID:              siswa.ID,
+				SemesterID:           semester.ID,
+				TujuanPembelajaranID: id,
+				Status:               s,
+				GuruID:               &guru.ID,
+			}
+			if err := tx.Create(&capaian).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan capaian")
+		return
+	}
+
+	utils.ResponseOK(c, "Capaian kompetensi berhasil disimpan", gin.H{
+		"siswa_id":          siswa.ID,
+		"semester_id":       semester.ID,
+		"mata_pelajaran_id": req.MataPelajaranID,
+		"deskripsi":         services.DeskripsiCapaianSiswa(siswa, semester.ID)[req.MataPelajaranID],
+	})
+}
+
+// GetCapaianSiswa godoc
+// @Summary Capaian kompetensi seorang siswa per mapel beserta teks deskripsinya
+// @Tags Capaian
+// @Security BearerAuth
+// @Param siswa_id path int true "Siswa ID"
+// @Param semester_id query int true "Semester ID"
+// @Router /capaian/siswa/{siswa_id} [get]
+func GetCapaianSiswa(c *gin.Context) {
+	semesterID := c.Query("semester_id")
+	if semesterID == "" {
+		utils.ResponseBadRequest(c, "Parameter semester_id wajib diisi", nil)
+		return
+	}
+
+	var siswa models.Siswa
+	if err := config.DB.First(&siswa, c.Param("siswa_id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Siswa tidak ditemukan")
+		return
+	}
+	var semester models.Semester
+	if err := config.DB.First(&semester, semesterID).Error; err != nil {
+		utils.ResponseNotFound(c, "Semester tidak ditemukan")
+		return
+	}
+
+	var capaian []models.CapaianTujuanPembelajaran
+	config.DB.Preload("TujuanPembelajaran.MataPelajaran").
+		Where("siswa_id = ? AND semester_id = ?", siswa.ID, semester.ID).
+		Find(&capaian)
+
+	type capaianMapel struct {
+		MataPelajaran  models.MataPelajaran        `json:"mata_pelajaran"`
+		Tercapai       []models.TujuanPembelajaran `json:"tercapai"`
+		PerluBimbingan []models.TujuanPembelajaran `json:"perlu_bimbingan"`
+		Deskripsi      string                      `json:"deskripsi"`
+	}
+	deskripsi := services.DeskripsiCapaianSiswa(siswa, semester.ID)
+	perMapel := map[uint]*capaianMapel{}
+	var urutan []uint
+	for _, cp := range capaian {
+		tp := cp.TujuanPembelajaran
+		m, ok := perMapel[tp.MataPelajaranID]
+		if !ok {
+			m = &capaianMapel{
+				MataPelajaran:  tp.MataPelajaran,
+				Tercapai:       []models.TujuanPembelajaran{},
+				PerluBimbingan: []models.TujuanPembelajaran{},
+				Deskripsi:      deskripsi[tp.MataPelajaranID],
+			}
+			perMapel[tp.MataPelajaranID] = m
+			urutan = append(urutan, tp.MataPelajaranID)
+		}
+		tp.MataPelajaran = models.MataPelajaran{}
+		if cp.Status == models.CapaianTercapai {
+			m.Tercapai = append(m.Tercapai, tp)
+		} else {
+			m.PerluBimbingan = append(m.PerluBimbingan, tp)
+		}
+	}
+
+	list := []capaianMapel{}
+	for _, id := range urutan {
+		list = append(list, *perMapel[id])
+	}
+	utils.ResponseOK(c, "Capaian kompetensi siswa", gin.H{
+		"siswa":   siswa,
+		"capaian": list,
+	})
+}
+
+// ── Projek P5 ─────────────────────────────────────────────────
+
+type ProjekP5Request struct {
+	SemesterID uint   `json:"semester_id" binding:"required"`
+	KelasID    uint   `json:"kelas_id" binding:"required"`
+	Tema       string `json:"tema" binding:"required,max=100"`
+	Judul      string `json:"judul" binding:"required,max=150"`
+	Deskripsi  string `json:"deskripsi"`
+}
+
+// GetProjekP5 godoc
+// @Summary Daftar projek P5
+// @Tags Capaian
+// @Security BearerAuth
+// @Param semester_id query int false "Filter semester"
+// @Param kelas_id query int false "Filter kelas"
+// @Router /projek-p5 [get]
+func GetProjekP5(c *gin.Context) {
+	query := config.DB.Model(&models.ProjekP5{}).Preload("Kelas").Preload("Semester")
+	if v := c.Query("semester_id"); v != "" {
+		query = query.Where("semester_id = ?", v)
+	}
+	if v := c.Query("kelas_id"); v != "" {
+		query = query.Where("kelas_id = ?", v)
+	}
+
+	var list []models.ProjekP5
+	query.Order("semester_id DESC, kelas_id ASC, id ASC").Find(&list)
+	utils.ResponseOK(c, "Daftar projek P5", gin.H{
+		"projek":  list,
+		"dimensi": models.DimensiP5,
+	})
+}
+
+// CreateProjekP5 godoc
+// @Summary Tambah projek P5 untuk sebuah kelas
+// @Tags Capaian
+// @Security BearerAuth
+// @Router /projek-p5 [post]
+func CreateProjekP5(c *gin.Context) {
+	var req ProjekP5Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	var kelas models.Kelas
+	if err := config.DB.First(&kelas, req.KelasID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Kelas tidak ditemukan", nil)
+		return
+	}
+	var semester models.Semester
+	if err := config.DB.First(&semester, req.SemesterID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Semester tidak ditemukan", nil)
+		return
+	}
+
+	p := models.ProjekP5{
+		SemesterID: req.SemesterID,
+		KelasID:    req.KelasID,
+		Tema:       req.Tema,
+		Judul:      req.Judul,
+		Deskripsi:  req.Deskripsi,
+	}
+	if err := config.DB.Create(&p).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan projek P5")
+		return
+	}
+
+	config.DB.Preload("Kelas").Preload("Semester").First(&p, p.ID)
+	utils.ResponseCreated(c, "Projek P5 berhasil ditambahkan", p)
+}
+
+// UpdateProjekP5 godoc
+// @Summary Update projek P5
+// @Tags Capaian
+// @Security BearerAuth
+// @Param id path int true "ID"
+// @Router /projek-p5/{id} [put]
+func UpdateProjekP5(c *gin.Context) {
+	var p models.ProjekP5
+	if err := config.DB.First(&p, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Projek P5 tidak ditemukan")
+		return
+	}
+
+	var req struct {
+		Tema      string  `json:"tema" binding:"max=100"`
+		Judul     string  `json:"judul" binding:"max=150"`
+		Deskripsi *string `json:"deskripsi"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	if req.Tema != "" {
+		p.Tema = req.Tema
+	}
+	if req.Judul != "" {
+		p.Judul = req.Judul
+	}
+	if req.Deskripsi != nil {
+		p.Deskripsi = *req.Deskripsi
+	}
+
+	config.DB.Save(&p)
+	config.DB.Preload("Kelas").Preload("Semester").First(&p, p.ID)
+	utils.ResponseOK(c, "Projek P5 berhasil diupdate", p)
+}
+
+// DeleteProjekP5 godoc
+// @Summary Hapus projek P5 beserta nilainya
+// @Tags Capaian
+// @Security BearerAuth
+// @Param id path int true "ID"
+// @Router /projek-p5/{id} [delete]
+func DeleteProjekP5(c *gin.Context) {
+	var p models.ProjekP5
+	if err := config.DB.First(&p, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Projek P5 tidak ditemukan")
+		return
+	}
+
+	config.DB.Transaction(func(tx *gorm.DB) error {
+		tx.Where("projek_p5_id = ?", p.ID).Delete(&models.NilaiP5{})
+		return tx.Delete(&p).Error
+	})
+	utils.ResponseOK(c, "Projek P5 berhasil dihapus", nil)
+}
+
+// GetNilaiP5 godoc
+// @Summary Nilai dimensi P5 seluruh siswa pada sebuah projek
+// @Tags Capaian
+// @Security BearerAuth
+// @Param id path int true "Projek ID"
+// @Router /projek-p5/{id}/nilai [get]
+func GetNilaiP5(c *gin.Context) {
+	var p models.ProjekP5
+	if err := config.DB.Preload("Kelas").First(&p, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Projek P5 tidak ditemukan")
+		return
+	}
+
+	var nilai []models.NilaiP5
+	config.DB.Where("projek_p5_id = ?", p.ID).Order("siswa_id, id").Find(&nilai)
+	utils.ResponseOK(c, "Nilai projek P5", gin.H{
+		"projek": p,
+		"nilai":  nilai,
+	})
+}
+
+// SimpanNilaiP5 godoc
+// @Summary Simpan predikat dimensi P5 seorang siswa
+// @Tags Capaian
+// @Security BearerAuth
+// @Param id path int true "Projek ID"
+// @Router /projek-p5/{id}/nilai [post]
+func SimpanNilaiP5(c *gin.Context) {
+	var p models.ProjekP5
+	if err := config.DB.First(&p, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Projek P5 tidak ditemukan")
+		return
+	}
+
+	var req struct {
+		SiswaID uint `json:"siswa_id" binding:"required"`
+		Nilai   []struct {
+			Dimensi  string `json:"dimensi" binding:"required"`
+			Predikat string `json:"predikat" binding:"required,oneof=MB SB BSH SAB"`
+			Catatan  string `json:"catatan"`
+		} `json:"nilai" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	// Siswa harus anggota kelas projek pada semester tersebut
+	kelas := services.KelasSiswaDiSemester(req.SiswaID, p.SemesterID)
+	if kelas == nil || kelas.ID != p.KelasID {
+		utils.ResponseBadRequest(c, "Siswa bukan anggota kelas projek ini", nil)
+		return
+	}
+
+	dimensiValid := map[string]bool{}
+	for _, d := range models.DimensiP5 {
+		dimensiValid[d] = true
+	}
+	for _, n := range req.Nilai {
+		if !dimensiValid[n.Dimensi] {
+			utils.ResponseBadRequest(c, "Dimensi tidak dikenal: "+n.Dimensi, gin.H{"dimensi_valid": models.DimensiP5})
+			return
+		}
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, n := range req.Nilai {
+			var nilai models.NilaiP5
+			tx.Where("projek_p5_id = ? AND siswa_id = ? AND dimensi = ?", p.ID, req.SiswaID, n.Dimensi).First(&nilai)
+			nilai.ProjekP5ID = p.ID
+			nilai.SiswaID = req.SiswaID
+			nilai.Dimensi = n.Dimensi
+			nilai.Predikat = n.Predikat
+			nilai.Catatan = n.Catatan
+			if err := tx.Save(&nilai).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan nilai P5")
+		return
+	}
+
+	var nilai []models.NilaiP5
+	config.DB.Where("projek_p5_id = ? AND siswa_id = ?", p.ID, req.SiswaID).Order("id").Find(&nilai)
+	utils.ResponseOK(c, "Nilai P5 berhasil disimpan", nilai)
+}
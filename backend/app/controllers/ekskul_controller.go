@@ -0,0 +1,657 @@
+package controllers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"sim-sekolah/app/middlewares"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// ── DTOs ──────────────────────────────────────────────────────
+
+type JadwalEkskulRequest struct {
+	HariKe     int    `json:"hari_ke" binding:"required,min=1,max=7"`
+	JamMulai   string `json:"jam_mulai" binding:"required"`   // "15:00"
+	JamSelesai string `json:"jam_selesai" binding:"required"` // "17:00"
+}
+
+type EkskulRequest struct {
+	Nama          string                `json:"nama" binding:"required,max=100"`
+	Deskripsi     string                `json:"deskripsi"`
+	PembinaGuruID uint                  `json:"pembina_guru_id" binding:"required"`
+	Tempat        string                `json:"tempat" binding:"max=100"`
+	IsWajib       bool                  `json:"is_wajib"`
+	IsAktif       *bool                 `json:"is_aktif"`
+	Jadwal        []JadwalEkskulRequest `json:"jadwal" binding:"dive"`
+}
+
+type AnggotaEkskulRequest struct {
+	SemesterID uint   `json:"semester_id" binding:"required"`
+	SiswaIDs   []uint `json:"siswa_ids" binding:"required,min=1"`
+}
+
+type PertemuanEkskulRequest struct {
+	SemesterID uint                          `json:"semester_id"`                // kosong = semester aktif
+	Tanggal    string                        `json:"tanggal" binding:"required"` // "2025-02-12"
+	Materi     string                        `json:"materi" binding:"max=255"`
+	Absensi    []services.AbsensiEkskulMasuk `json:"absensi" binding:"dive"`
+}
+
+type NilaiEkskulRequest struct {
+	SemesterID uint `json:"semester_id" binding:"required"`
+	Nilai      []struct {
+		SiswaID    uint   `json:"siswa_id" binding:"required"`
+		Predikat   string `json:"predikat" binding:"required,oneof='Sangat Baik' Baik Cukup Kurang"`
+		Keterangan string `json:"keterangan"`
+	} `json:"nilai" binding:"required,min=1,dive"`
+}
+
+// ── Helper akses ──────────────────────────────────────────────
+
+// ambilEkskulDikelola memuat ekstrakurikuler dan memastikan user yang login
+// adalah admin atau pembinanya
+func ambilEkskulDikelola(c *gin.Context) (models.Ekstrakurikuler, bool) {
+	var ekskul models.Ekstrakurikuler
+	if err := config.DB.First(&ekskul, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Ekstrakurikuler tidak ditemukan")
+		return ekskul, false
+	}
+	if middlewares.GetCurrentUser(c).Role == models.RoleAdmin {
+		return ekskul, true
+	}
+	guru, ok := guruLogin(c)
+	if !ok {
+		return ekskul, false
+	}
+	if ekskul.PembinaGuruID != guru.ID {
+		utils.ResponseForbidden(c, "Anda bukan pembina ekstrakurikuler "+ekskul.Nama)
+		return ekskul, false
+	}
+	return ekskul, true
+}
+
+func validasiJadwalEkskul(c *gin.Context, jadwal []JadwalEkskulRequest) bool {
+	for _, j := range jadwal {
+		if _, err := time.Parse("15:04", j.JamMulai); err != nil {
+			utils.ResponseBadRequest(c, "Format jam tidak valid (gunakan HH:MM)", nil)
+			return false
+		}
+		if _, err := time.Parse("15:04", j.JamSelesai); err != nil {
+			utils.ResponseBadRequest(c, "Format jam tidak valid (gunakan HH:MM)", nil)
+			return false
+		}
+		if j.JamMulai >= j.JamSelesai {
+			utils.ResponseBadRequest(c, "Jam mulai harus lebih awal dari jam selesai", nil)
+			return false
+		}
+	}
+	return true
+}
+
+// simpanJadwalEkskul mengganti seluruh jadwal rutin ekstrakurikuler
+func simpanJadwalEkskul(tx *gorm.DB, ekskulID uint, jadwal []JadwalEkskulRequest) error {
+	if err := tx.Where("ekskul_id = ?", ekskulID).Delete(&models.JadwalEkskul{}).Error; err != nil {
+		return err
+	}
+	for _, j := range jadwal {
+		if err := tx.Create(&models.JadwalEkskul{
+			EkskulID:   ekskulID,
+			HariKe:     j.HariKe,
+			JamMulai:   j.JamMulai,
+			JamSelesai: j.JamSelesai,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ── CRUD Ekstrakurikuler ──────────────────────────────────────
+
+// GetEkskul godoc
+// @Summary List ekstrakurikuler beserta pembina dan jadwalnya
+// @Tags Ekstrakurikuler
+// @Security BearerAuth
+// @Param aktif query bool false "Hanya yang aktif"
+// @Param pembina_guru_id query int false "Filter pembina"
+// @Router /ekskul [get]
+func GetEkskul(c *gin.Context) {
+	query := config.DB.Model(&models.Ekstrakurikuler{})
+	if c.Query("aktif") == "true" {
+		query = query.Where("is_aktif = ?", true)
+	}
+	if pembina := c.Query("pembina_guru_id"); pembina != "" {
+		query = query.Where("pembina_guru_id = ?", pembina)
+	}
+	var list []models.Ekstrakurikuler
+	query.Preload("Pembina").
+		Preload("Jadwal", func(db *gorm.DB) *gorm.DB { return db.Order("hari_ke ASC, jam_mulai ASC") }).
+		Order("nama ASC").
+		Find(&list)
+	utils.ResponseOK(c, "Daftar ekstrakurikuler", list)
+}
+
+// GetEkskulByID godoc
+// @Summary Detail ekstrakurikuler
+// @Tags Ekstrakurikuler
+// @Security BearerAuth
+// @Param id path int true "Ekskul ID"
+// @Router /ekskul/{id} [get]
+func GetEkskulByID(c *gin.Context) {
+	var ekskul models.Ekstrakurikuler
+	if err := config.DB.Preload("Pembina").
+		Preload("Jadwal", func(db *gorm.DB) *gorm.DB { return db.Order("hari_ke ASC, jam_mulai ASC") }).
+		First(&ekskul, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Ekstrakurikuler tidak ditemukan")
+		return
+	}
+	utils.ResponseOK(c, "Detail ekstrakurikuler", ekskul)
+}
+
+// CreateEkskul godoc
+// @Summary Tambah ekstrakurikuler
+// @Tags Ekstrakurikuler
+// @Security BearerAuth
+// @Param body body EkskulRequest true "Data ekstrakurikuler"
+// @Router /ekskul [post]
+func CreateEkskul(c *gin.Context) {
+	var req EkskulRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if !validasiJadwalEkskul(c, req.Jadwal) {
+		return
+	}
+	var count int64
+	config.DB.Model(&models.Guru{}).Where("id = ?", req.PembinaGuruID).Count(&count)
+	if count == 0 {
+		utils.ResponseBadRequest(c, "Guru pembina tidak ditemukan", nil)
+		return
+	}
+	config.DB.Model(&models.Ekstrakurikuler{}).Where("nama = ?", req.Nama).Count(&count)
+	if count > 0 {
+		utils.ResponseBadRequest(c, "Nama ekstrakurikuler sudah digunakan", nil)
+		return
+	}
+
+	ekskul := models.Ekstrakurikuler{
+		Nama:          req.Nama,
+		Deskripsi:     req.Deskripsi,
+		PembinaGuruID: req.PembinaGuruID,
+		Tempat:        req.Tempat,
+		IsWajib:       req.IsWajib,
+		IsAktif:       true,
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&ekskul).Error; err != nil {
+			return err
+		}
+		if req.IsAktif != nil && !*req.IsAktif {
+			if err := tx.Model(&ekskul).Update("is_aktif", false).Error; err != nil {
+				return err
+			}
+			ekskul.IsAktif = false
+		}
+		return simpanJadwalEkskul(tx, ekskul.ID, req.Jadwal)
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan ekstrakurikuler")
+		return
+	}
+
+	config.DB.Preload("Pembina").Preload("Jadwal").First(&ekskul, ekskul.ID)
+	utils.ResponseCreated(c, "Ekstrakurikuler berhasil ditambahkan", ekskul)
+}
+
+// UpdateEkskul godoc
+// @Summary Ubah ekstrakurikuler; jadwal yang dikirim menggantikan jadwal lama
+// @Tags Ekstrakurikuler
+// @Security BearerAuth
+// @Param id path int true "Ekskul ID"
+// @Param body body EkskulRequest true "Data ekstrakurikuler"
+// @Router /ekskul/{id} [put]
+func UpdateEkskul(c *gin.Context) {
+	var ekskul models.Ekstrakurikuler
+	if err := config.DB.First(&ekskul, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Ekstrakurikuler tidak ditemukan")
+		return
+	}
+	var req EkskulRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if !validasiJadwalEkskul(c, req.Jadwal) {
+		return
+	}
+	var count int64
+	config.DB.Model(&models.Guru{}).Where("id = ?", req.PembinaGuruID).Count(&count)
+	if count == 0 {
+		utils.ResponseBadRequest(c, "Guru pembina tidak ditemukan", nil)
+		return
+	}
+	config.DB.Model(&models.Ekstrakurikuler{}).Where("nama = ? AND id <> ?", req.Nama, ekskul.ID).Count(&count)
+	if count > 0 {
+		utils.ResponseBadRequest(c, "Nama ekstrakurikuler sudah digunakan", nil)
+		return
+	}
+
+	ekskul.Nama = req.Nama
+	ekskul.Deskripsi = req.Deskripsi
+	ekskul.PembinaGuruID = req.PembinaGuruID
+	ekskul.Tempat = req.Tempat
+	ekskul.IsWajib = req.IsWajib
+	if req.IsAktif != nil {
+		ekskul.IsAktif = *req.IsAktif
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&ekskul).Error; err != nil {
+			return err
+		}
+		return simpanJadwalEkskul(tx, ekskul.ID, req.Jadwal)
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal memperbarui ekstrakurikuler")
+		return
+	}
+
+	config.DB.Preload("Pembina").Preload("Jadwal").First(&ekskul, ekskul.ID)
+	utils.ResponseOK(c, "Ekstrakurikuler berhasil diperbarui", ekskul)
+}
+
+// DeleteEkskul godoc
+// @Summary Hapus ekstrakurikuler yang belum memiliki anggota; jika sudah, nonaktifkan saja
+// @Tags Ekstrakurikuler
+// @Security BearerAuth
+// @Param id path int true "Ekskul ID"
+// @Router /ekskul/{id} [delete]
+func DeleteEkskul(c *gin.Context) {
+	var ekskul models.Ekstrakurikuler
+	if err := config.DB.First(&ekskul, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Ekstrakurikuler tidak ditemukan")
+		return
+	}
+	var anggota int64
+	config.DB.Model(&models.AnggotaEkskul{}).Where("ekskul_id = ?", ekskul.ID).Count(&anggota)
+	if anggota > 0 {
+		utils.ResponseBadRequest(c, "Ekstrakurikuler sudah memiliki anggota, nonaktifkan saja", nil)
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("ekskul_id = ?", ekskul.ID).Delete(&models.JadwalEkskul{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&ekskul).Error
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghapus ekstrakurikuler")
+		return
+	}
+	utils.ResponseOK(c, "Ekstrakurikuler berhasil dihapus", nil)
+}
+
+// ── Anggota ───────────────────────────────────────────────────
+
+// GetAnggotaEkskul godoc
+// @Summary Anggota ekstrakurikuler pada satu semester beserta rekap kehadiran dan nilai
+// @Tags Ekstrakurikuler
+// @Security BearerAuth
+// @Param id path int true "Ekskul ID"
+// @Param semester_id query int true "Semester ID"
+// @Router /ekskul/{id}/anggota [get]
+func GetAnggotaEkskul(c *gin.Context) {
+	ekskul, ok := ambilEkskulDikelola(c)
+	if !ok {
+		return
+	}
+	semesterID := c.Query("semester_id")
+	if semesterID == "" {
+		utils.ResponseBadRequest(c, "Parameter semester_id wajib diisi", nil)
+		return
+	}
+	var semester models.Semester
+	if err := config.DB.First(&semester, semesterID).Error; err != nil {
+		utils.ResponseNotFound(c, "Semester tidak ditemukan")
+		return
+	}
+
+	var anggota []models.AnggotaEkskul
+	config.DB.Preload("Siswa.Kelas").
+		Joins("JOIN siswas ON siswas.id = anggota_ekskuls.siswa_id").
+		Where("anggota_ekskuls.ekskul_id = ? AND anggota_ekskuls.semester_id = ?", ekskul.ID, semester.ID).
+		Order("siswas.nama ASC").
+		Find(&anggota)
+
+	kehadiran := services.RekapKehadiranEkskul(ekskul.ID, semester.ID)
+	list := make([]gin.H, 0, len(anggota))
+	for _, a := range anggota {
+		k := kehadiran[a.SiswaID]
+		k.SiswaID = a.SiswaID
+		list = append(list, gin.H{
+			"anggota":   a,
+			"kehadiran": k,
+		})
+	}
+	utils.ResponseOK(c, "Anggota ekstrakurikuler", gin.H{
+		"ekskul":   ekskul,
+		"semester": semester,
+		"anggota":  list,
+	})
+}
+
+// TambahAnggotaEkskul godoc
+// @Summary Daftarkan siswa sebagai anggota ekstrakurikuler pada satu semester
+// @Tags Ekstrakurikuler
+// @Security BearerAuth
+// @Param id path int true "Ekskul ID"
+// @Param body body AnggotaEkskulRequest true "Semester dan daftar siswa"
+// @Router /ekskul/{id}/anggota [post]
+func TambahAnggotaEkskul(c *gin.Context) {
+	ekskul, ok := ambilEkskulDikelola(c)
+	if !ok {
+		return
+	}
+	var req AnggotaEkskulRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if !ekskul.IsAktif {
+		utils.ResponseBadRequest(c, "Ekstrakurikuler sudah nonaktif", nil)
+		return
+	}
+	var count int64
+	config.DB.Model(&models.Semester{}).Where("id = ?", req.SemesterID).Count(&count)
+	if count == 0 {
+		utils.ResponseBadRequest(c, "Semester tidak ditemukan", nil)
+		return
+	}
+
+	var siswaValid []uint
+	config.DB.Model(&models.Siswa{}).
+		Where("id IN ? AND status = ?", req.SiswaIDs, "aktif").
+		Pluck("id", &siswaValid)
+	if len(siswaValid) == 0 {
+		utils.ResponseBadRequest(c, "Tidak ada siswa aktif yang valid", nil)
+		return
+	}
+
+	var sudahAda []uint
+	config.DB.Model(&models.AnggotaEkskul{}).
+		Where("ekskul_id = ? AND semester_id = ? AND siswa_id IN ?", ekskul.ID, req.SemesterID, siswaValid).
+		Pluck("siswa_id", &sudahAda)
+	ada := make(map[uint]bool, len(sudahAda))
+	for _, id := range sudahAda {
+		ada[id] = true
+	}
+
+	var baru []models.AnggotaEkskul
+	for _, id := range siswaValid {
+		if !ada[id] {
+			baru = append(baru, models.AnggotaEkskul{EkskulID: ekskul.ID, SiswaID: id, SemesterID: req.SemesterID})
+		}
+	}
+	if len(baru) > 0 {
+		if err := config.DB.Create(&baru).Error; err != nil {
+			utils.ResponseInternalError(c, "Gagal menambahkan anggota")
+			return
+		}
+	}
+	utils.ResponseCreated(c, "Anggota ekstrakurikuler berhasil ditambahkan", gin.H{
+		"ditambahkan":     len(baru),
+		"sudah_terdaftar": len(sudahAda),
+		"tidak_valid":     len(req.SiswaIDs) - len(siswaValid),
+	})
+}
+
+// HapusAnggotaEkskul godoc
+// @Summary Keluarkan siswa dari ekstrakurikuler beserta presensinya di semester itu
+// @Tags Ekstrakurikuler
+// @Security BearerAuth
+// @Param id path int true "Ekskul ID"
+// @Param anggota_id path int true "Anggota Ekskul ID"
+// @Router /ekskul/{id}/anggota/{anggota_id} [delete]
+func HapusAnggotaEkskul(c *gin.Context) {
+	ekskul, ok := ambilEkskulDikelola(c)
+	if !ok {
+		return
+	}
+	var anggota models.AnggotaEkskul
+	if err := config.DB.Where("id = ? AND ekskul_id = ?", c.Param("anggota_id"), ekskul.ID).First(&anggota).Error; err != nil {
+		utils.ResponseNotFound(c, "Anggota tidak ditemukan")
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("siswa_id = ? AND pertemuan_id IN (?)", anggota.SiswaID,
+			tx.Model(&models.PertemuanEkskul{}).Select("id").
+				Where("ekskul_id = ? AND semester_id = ?", ekskul.ID, anggota.SemesterID)).
+			Delete(&models.AbsensiEkskul{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&anggota).Error
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal mengeluarkan anggota")
+		return
+	}
+	utils.ResponseOK(c, "Anggota berhasil dikeluarkan", nil)
+}
+
+// ── Pertemuan & Presensi ──────────────────────────────────────
+
+// GetPertemuanEkskul godoc
+// @Summary Daftar pertemuan ekstrakurikuler beserta presensinya
+// @Tags Ekstrakurikuler
+// @Security BearerAuth
+// @Param id path int true "Ekskul ID"
+// @Param semester_id query int false "Filter semester"
+// @Router /ekskul/{id}/pertemuan [get]
+func GetPertemuanEkskul(c *gin.Context) {
+	ekskul, ok := ambilEkskulDikelola(c)
+	if !ok {
+		return
+	}
+	query := config.DB.Where("ekskul_id = ?", ekskul.ID)
+	if semesterID := c.Query("semester_id"); semesterID != "" {
+		query = query.Where("semester_id = ?", semesterID)
+	}
+	var list []models.PertemuanEkskul
+	query.Preload("Absensi.Siswa").Order("tanggal DESC").Find(&list)
+	utils.ResponseOK(c, "Daftar pertemuan ekstrakurikuler", list)
+}
+
+// SimpanPertemuanEkskul godoc
+// @Summary Catat pertemuan ekstrakurikuler dan presensi anggotanya. Pertemuan di tanggal yang sama diperbarui.
+// @Tags Ekstrakurikuler
+// @Security BearerAuth
+// @Param id path int true "Ekskul ID"
+// @Param body body PertemuanEkskulRequest true "Data pertemuan dan presensi"
+// @Router /ekskul/{id}/pertemuan [post]
+func SimpanPertemuanEkskul(c *gin.Context) {
+	ekskul, ok := ambilEkskulDikelola(c)
+	if !ok {
+		return
+	}
+	var req PertemuanEkskulRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	tanggal, err := time.ParseInLocation("2006-01-02", req.Tanggal, time.Local)
+	if err != nil {
+		utils.ResponseBadRequest(c, "Format tanggal tidak valid (gunakan YYYY-MM-DD)", nil)
+		return
+	}
+	if tanggal.After(time.Now()) {
+		utils.ResponseBadRequest(c, "Presensi tidak dapat diisi untuk tanggal yang belum terjadi", nil)
+		return
+	}
+	if req.SemesterID == 0 {
+		var aktif models.Semester
+		if err := config.DB.Where("is_aktif = ?", true).First(&aktif).Error; err != nil {
+			utils.ResponseBadRequest(c, "Tidak ada semester aktif, isi semester_id", nil)
+			return
+		}
+		req.SemesterID = aktif.ID
+	}
+
+	var pertemuan models.PertemuanEkskul
+	err = config.DB.Where("ekskul_id = ? AND tanggal = ?", ekskul.ID, req.Tanggal).First(&pertemuan).Error
+	switch {
+	case err == nil:
+		if pertemuan.SemesterID != req.SemesterID {
+			utils.ResponseBadRequest(c, "Pertemuan pada tanggal ini sudah tercatat di semester lain", nil)
+			return
+		}
+		pertemuan.Materi = req.Materi
+		err = config.DB.Model(&pertemuan).Update("materi", req.Materi).Error
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		pertemuan = models.PertemuanEkskul{
+			EkskulID:   ekskul.ID,
+			SemesterID: req.SemesterID,
+			Tanggal:    tanggal,
+			Materi:     req.Materi,
+		}
+		err = config.DB.Create(&pertemuan).Error
+	}
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan pertemuan")
+		return
+	}
+
+	if len(req.Absensi) > 0 {
+		if err := services.SimpanAbsensiEkskul(pertemuan, req.Absensi); err != nil {
+			if errors.Is(err, services.ErrBukanAnggotaEkskul) {
+				utils.ResponseBadRequest(c, err.Error(), nil)
+				return
+			}
+			utils.ResponseInternalError(c, "Gagal menyimpan presensi")
+			return
+		}
+	}
+
+	config.DB.Preload("Absensi.Siswa").First(&pertemuan, pertemuan.ID)
+	utils.ResponseOK(c, "Pertemuan dan presensi berhasil disimpan", pertemuan)
+}
+
+// DeletePertemuanEkskul godoc
+// @Summary Hapus pertemuan ekstrakurikuler beserta presensinya
+// @Tags Ekstrakurikuler
+// @Security BearerAuth
+// @Param id path int true "Ekskul ID"
+// @Param pertemuan_id path int true "Pertemuan ID"
+// @Router /ekskul/{id}/pertemuan/{pertemuan_id} [delete]
+func DeletePertemuanEkskul(c *gin.Context) {
+	ekskul, ok := ambilEkskulDikelola(c)
+	if !ok {
+		return
+	}
+	var pertemuan models.PertemuanEkskul
+	if err := config.DB.Where("id = ? AND ekskul_id = ?", c.Param("pertemuan_id"), ekskul.ID).First(&pertemuan).Error; err != nil {
+		utils.ResponseNotFound(c, "Pertemuan tidak ditemukan")
+		return
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("pertemuan_id = ?", pertemuan.ID).Delete(&models.AbsensiEkskul{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&pertemuan).Error
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghapus pertemuan")
+		return
+	}
+	utils.ResponseOK(c, "Pertemuan berhasil dihapus", nil)
+}
+
+// ── Nilai ─────────────────────────────────────────────────────
+
+// SimpanNilaiEkskul godoc
+// @Summary Isi predikat dan keterangan ekstrakurikuler anggota (oleh pembina) untuk rapor
+// @Tags Ekstrakurikuler
+// @Security BearerAuth
+// @Param id path int true "Ekskul ID"
+// @Param body body NilaiEkskulRequest true "Nilai anggota"
+// @Router /ekskul/{id}/nilai [put]
+func SimpanNilaiEkskul(c *gin.Context) {
+	ekskul, ok := ambilEkskulDikelola(c)
+	if !ok {
+		return
+	}
+	var req NilaiEkskulRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	now := time.Now()
+	var tidakTerdaftar []uint
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, n := range req.Nilai {
+			res := tx.Model(&models.AnggotaEkskul{}).
+				Where("ekskul_id = ? AND semester_id = ? AND siswa_id = ?", ekskul.ID, req.SemesterID, n.SiswaID).
+				Updates(map[string]interface{}{
+					"predikat":     n.Predikat,
+					"keterangan":   n.Keterangan,
+					"dinilai_pada": now,
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				tidakTerdaftar = append(tidakTerdaftar, n.SiswaID)
+			}
+		}
+		if len(tidakTerdaftar) > 0 {
+			return services.ErrBukanAnggotaEkskul
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrBukanAnggotaEkskul) {
+			utils.ResponseBadRequest(c, err.Error(), gin.H{"siswa_id": tidakTerdaftar})
+			return
+		}
+		utils.ResponseInternalError(c, "Gagal menyimpan nilai ekstrakurikuler")
+		return
+	}
+	utils.ResponseOK(c, "Nilai ekstrakurikuler berhasil disimpan", gin.H{"jumlah": len(req.Nilai)})
+}
+
+// GetEkskulSaya godoc
+// @Summary Ekstrakurikuler yang diikuti siswa (atau anak) yang sedang login
+// @Tags Ekstrakurikuler
+// @Security BearerAuth
+// @Param semester_id query int false "Semester (default: semester aktif)"
+// @Param siswa_id query int false "Anak yang dipilih (khusus orang tua)"
+// @Router /ekskul/saya [get]
+func GetEkskulSaya(c *gin.Context) {
+	siswaID, ok := siswaMilikLogin(c)
+	if !ok {
+		return
+	}
+	var semester models.Semester
+	query := config.DB.Model(&models.Semester{})
+	if semesterID := c.Query("semester_id"); semesterID != "" {
+		query = query.Where("id = ?", semesterID)
+	} else {
+		query = query.Where("is_aktif = ?", true)
+	}
+	if err := query.First(&semester).Error; err != nil {
+		utils.ResponseNotFound(c, "Semester tidak ditemukan")
+		return
+	}
+	utils.ResponseOK(c, "Ekstrakurikuler saya", gin.H{
+		"semester": semester,
+		"ekskul":   services.EkskulSiswa(siswaID, semester.ID),
+	})
+}
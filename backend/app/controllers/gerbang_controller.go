@@ -0,0 +1,585 @@
+package controllers
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sim-sekolah/app/middlewares"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// Batas jumlah tap dalam satu upload batch offline
+const maksBatchTap = 1000
+
+var polaJam = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+
+// ── DTOs ──────────────────────────────────────────────────────
+
+type PerangkatGerbangRequest struct {
+	Nama    string `json:"nama" binding:"required,max=100"`
+	Lokasi  string `json:"lokasi" binding:"max=100"`
+	IsAktif *bool  `json:"is_aktif"`
+}
+
+type KartuAksesRequest struct {
+	UID        string `json:"uid" binding:"required,max=50"`
+	SiswaID    *uint  `json:"siswa_id"`
+	GuruID     *uint  `json:"guru_id"`
+	Keterangan string `json:"keterangan" binding:"max=255"`
+}
+
+type UpdateKartuAksesRequest struct {
+	IsAktif    *bool   `json:"is_aktif"`
+	Keterangan *string `json:"keterangan" binding:"omitempty,max=255"`
+}
+
+type JadwalBelRequest struct {
+	JamMasuk       string `json:"jam_masuk" binding:"required"`  // "07:00"
+	JamPulang      string `json:"jam_pulang" binding:"required"` // "14:00"
+	ToleransiMenit int    `json:"toleransi_menit" binding:"min=0,max=120"`
+}
+
+type BatchTapRequest struct {
+	Taps []services.TapMasuk `json:"taps" binding:"required,min=1,dive"`
+}
+
+type IsiAbsensiGerbangRequest struct {
+	Tanggal string `json:"tanggal"` // YYYY-MM-DD; kosong = hari ini
+}
+
+// ── API perangkat ─────────────────────────────────────────────
+
+// PingPerangkatGerbang godoc
+// @Summary Heartbeat perangkat gerbang; mengembalikan waktu server untuk sinkronisasi jam
+// @Tags Perangkat Gerbang
+// @Param X-API-Key header string true "API key perangkat"
+// @Router /perangkat-gerbang/ping [get]
+func PingPerangkatGerbang(c *gin.Context) {
+	perangkat := middlewares.GetPerangkatGerbang(c)
+	utils.ResponseOK(c, "Perangkat terhubung", gin.H{
+		"perangkat_id": perangkat.ID,
+		"nama":         perangkat.Nama,
+		"waktu_server": time.Now(),
+	})
+}
+
+// TapPerangkatGerbang godoc
+// @Summary Kirim satu tap kartu dari perangkat gerbang
+// @Tags Perangkat Gerbang
+// @Param X-API-Key header string true "API key perangkat"
+// @Param body body services.TapMasuk true "UID kartu, waktu tap, event ID"
+// @Router /perangkat-gerbang/tap [post]
+func TapPerangkatGerbang(c *gin.Context) {
+	var req services.TapMasuk
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if strings.TrimSpace(req.EventID) == "" {
+		utils.ResponseBadRequest(c, services.ErrEventIDKosong.Error(), nil)
+		return
+	}
+	hasil, err := services.ProsesTap(middlewares.GetPerangkatGerbang(c), req, false)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan tap")
+		return
+	}
+	if hasil.Status == models.TapKartuTidakDikenal {
+		utils.ResponseNotFound(c, hasil.Pesan)
+		return
+	}
+	utils.ResponseOK(c, "Tap diterima", hasil)
+}
+
+// BatchTapPerangkatGerbang godoc
+// @Summary Upload tap yang tersimpan selama perangkat offline (maks 1000 per request)
+// @Description Tap dengan event_id yang sudah pernah diterima dilewati, sehingga batch aman dikirim ulang.
+// @Tags Perangkat Gerbang
+// @Param X-API-Key header string true "API key perangkat"
+// @Param body body BatchTapRequest true "Daftar tap"
+// @Router /perangkat-gerbang/tap/batch [post]
+func BatchTapPerangkatGerbang(c *gin.Context) {
+	var req BatchTapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if len(req.Taps) > maksBatchTap {
+		utils.ResponseBadRequest(c, "Maksimal "+strconv.Itoa(maksBatchTap)+" tap per batch", nil)
+		return
+	}
+	for i, t := range req.Taps {
+		if strings.TrimSpace(t.EventID) == "" {
+			utils.ResponseBadRequest(c, "Tap ke-"+strconv.Itoa(i+1)+": "+services.ErrEventIDKosong.Error(), nil)
+			return
+		}
+	}
+
+	hasil := services.ProsesBatchTap(middlewares.GetPerangkatGerbang(c), req.Taps)
+	ringkasan := map[string]int{}
+	for _, h := range hasil {
+		ringkasan[h.Status]++
+	}
+	utils.ResponseOK(c, "Batch tap diproses", gin.H{
+		"jumlah":    len(hasil),
+		"ringkasan": ringkasan,
+		"hasil":     hasil,
+	})
+}
+
+// ── Perangkat (admin) ─────────────────────────────────────────
+
+// GetDaftarPerangkatGerbang godoc
+// @Summary Daftar perangkat pembaca kartu gerbang
+// @Tags Gerbang
+// @Security BearerAuth
+// @Router /gerbang/perangkat [get]
+func GetDaftarPerangkatGerbang(c *gin.Context) {
+	var list []models.PerangkatGerbang
+	config.DB.Order("nama ASC").Find(&list)
+	utils.ResponseOK(c, "Daftar perangkat gerbang", list)
+}
+
+// CreatePerangkatGerbang godoc
+// @Summary Daftarkan perangkat gerbang baru; API key hanya ditampilkan sekali
+// @Tags Gerbang
+// @Security BearerAuth
+// @Param body body PerangkatGerbangRequest true "Data perangkat"
+// @Router /gerbang/perangkat [post]
+func CreatePerangkatGerbang(c *gin.Context) {
+	var req PerangkatGerbangRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	key, prefix, hash, err := services.BuatAPIKeyPerangkat()
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal membuat API key")
+		return
+	}
+	perangkat := models.PerangkatGerbang{
+		Nama:      req.Nama,
+		Lokasi:    req.Lokasi,
+		KeyPrefix: prefix,
+		KeyHash:   hash,
+		IsAktif:   req.IsAktif == nil || *req.IsAktif,
+	}
+	if err := config.DB.Create(&perangkat).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan perangkat")
+		return
+	}
+	utils.ResponseCreated(c, "Perangkat gerbang terdaftar. Simpan API key ini, tidak akan ditampilkan lagi", gin.H{
+		"perangkat": perangkat,
+		"api_key":   key,
+	})
+}
+
+// UpdatePerangkatGerbang godoc
+// @Summary Ubah nama, lokasi, atau status aktif perangkat gerbang
+// @Tags Gerbang
+// @Security BearerAuth
+// @Param id path int true "Perangkat ID"
+// @Param body body PerangkatGerbangRequest true "Data perangkat"
+// @Router /gerbang/perangkat/{id} [put]
+func UpdatePerangkatGerbang(c *gin.Context) {
+	var perangkat models.PerangkatGerbang
+	if err := config.DB.First(&perangkat, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Perangkat tidak ditemukan")
+		return
+	}
+	var req PerangkatGerbangRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	perangkat.Nama = req.Nama
+	perangkat.Lokasi = req.Lokasi
+	if req.IsAktif != nil {
+		perangkat.IsAktif = *req.IsAktif
+	}
+	if err := config.DB.Save(&perangkat).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan perangkat")
+		return
+	}
+	utils.ResponseOK(c, "Perangkat gerbang berhasil diupdate", perangkat)
+}
+
+// RegenerateKeyPerangkatGerbang godoc
+// @Summary Buat ulang API key perangkat (key lama langsung tidak berlaku)
+// @Tags Gerbang
+// @Security BearerAuth
+// @Param id path int true "Perangkat ID"
+// @Router /gerbang/perangkat/{id}/regenerate-key [post]
+func RegenerateKeyPerangkatGerbang(c *gin.Context) {
+	var perangkat models.PerangkatGerbang
+	if err := config.DB.First(&perangkat, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Perangkat tidak ditemukan")
+		return
+	}
+	key, prefix, hash, err := services.BuatAPIKeyPerangkat()
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal membuat API key")
+		return
+	}
+	if err := config.DB.Model(&perangkat).Updates(map[string]interface{}{
+		"key_prefix": prefix,
+		"key_hash":   hash,
+	}).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan API key")
+		return
+	}
+	utils.ResponseOK(c, "API key baru dibuat. Simpan API key ini, tidak akan ditampilkan lagi", gin.H{
+		"perangkat": perangkat,
+		"api_key":   key,
+	})
+}
+
+// DeletePerangkatGerbang godoc
+// @Summary Hapus perangkat gerbang (log tap tetap disimpan)
+// @Tags Gerbang
+// @Security BearerAuth
+// @Param id path int true "Perangkat ID"
+// @Router /gerbang/perangkat/{id} [delete]
+func DeletePerangkatGerbang(c *gin.Context) {
+	res := config.DB.Delete(&models.PerangkatGerbang{}, c.Param("id"))
+	if res.Error != nil {
+		utils.ResponseInternalError(c, "Gagal menghapus perangkat")
+		return
+	}
+	if res.RowsAffected == 0 {
+		utils.ResponseNotFound(c, "Perangkat tidak ditemukan")
+		return
+	}
+	utils.ResponseOK(c, "Perangkat gerbang berhasil dihapus", nil)
+}
+
+// ── Kartu ─────────────────────────────────────────────────────
+
+// GetKartuAkses godoc
+// @Summary Daftar kartu akses beserta pemiliknya
+// @Tags Gerbang
+// @Security BearerAuth
+// @Param pemilik query string false "siswa / guru"
+// @Param uid query string false "Cari UID"
+// @Router /gerbang/kartu [get]
+func GetKartuAkses(c *gin.Context) {
+	query := config.DB.Model(&models.KartuAkses{}).Preload("Siswa").Preload("Guru")
+	switch c.Query("pemilik") {
+	case models.PemilikSiswa:
+		query = query.Where("siswa_id IS NOT NULL")
+	case models.PemilikGuru:
+		query = query.Where("guru_id IS NOT NULL")
+	}
+	if uid := c.Query("uid"); uid != "" {
+		query = query.Where("uid LIKE ?", "%"+services.NormalisasiUID(uid)+"%")
+	}
+	var list []models.KartuAkses
+	query.Order("uid ASC").Find(&list)
+	utils.ResponseOK(c, "Daftar kartu akses", list)
+}
+
+// CreateKartuAkses godoc
+// @Summary Daftarkan kartu ke satu siswa atau guru
+// @Tags Gerbang
+// @Security BearerAuth
+// @Param body body KartuAksesRequest true "UID kartu dan pemilik"
+// @Router /gerbang/kartu [post]
+func CreateKartuAkses(c *gin.Context) {
+	var req KartuAksesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if (req.SiswaID == nil) == (req.GuruID == nil) {
+		utils.ResponseBadRequest(c, "Isi salah satu: siswa_id atau guru_id", nil)
+		return
+	}
+	if req.SiswaID != nil {
+		if err := config.DB.First(&models.Siswa{}, *req.SiswaID).Error; err != nil {
+			utils.ResponseBadRequest(c, "Siswa tidak ditemukan", nil)
+			return
+		}
+	} else if err := config.DB.First(&models.Guru{}, *req.GuruID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Guru tidak ditemukan", nil)
+		return
+	}
+
+	uid := services.NormalisasiUID(req.UID)
+	var existing models.KartuAkses
+	if err := config.DB.Where("uid = ?", uid).First(&existing).Error; err == nil {
+		utils.ResponseBadRequest(c, "Kartu dengan UID ini sudah terdaftar", gin.H{"kartu_id": existing.ID})
+		return
+	}
+
+	kartu := models.KartuAkses{
+		UID:        uid,
+		SiswaID:    req.SiswaID,
+		GuruID:     req.GuruID,
+		IsAktif:    true,
+		Keterangan: req.Keterangan,
+	}
+	if err := config.DB.Create(&kartu).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan kartu")
+		return
+	}
+	config.DB.Preload("Siswa").Preload("Guru").First(&kartu, kartu.ID)
+	utils.ResponseCreated(c, "Kartu berhasil didaftarkan", kartu)
+}
+
+// UpdateKartuAkses godoc
+// @Summary Aktifkan/nonaktifkan kartu (misal kartu hilang) atau ubah keterangan
+// @Tags Gerbang
+// @Security BearerAuth
+// @Param id path int true "Kartu ID"
+// @Param body body UpdateKartuAksesRequest true "Data kartu"
+// @Router /gerbang/kartu/{id} [put]
+func UpdateKartuAkses(c *gin.Context) {
+	var kartu models.KartuAkses
+	if err := config.DB.First(&kartu, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Kartu tidak ditemukan")
+		return
+	}
+	var req UpdateKartuAksesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if req.IsAktif != nil {
+		kartu.IsAktif = *req.IsAktif
+	}
+	if req.Keterangan != nil {
+		kartu.Keterangan = *req.Keterangan
+	}
+	if err := config.DB.Save(&kartu).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan kartu")
+		return
+	}
+	utils.ResponseOK(c, "Kartu berhasil diupdate", kartu)
+}
+
+// DeleteKartuAkses godoc
+// @Summary Hapus kartu akses
+// @Tags Gerbang
+// @Security BearerAuth
+// @Param id path int true "Kartu ID"
+// @Router /gerbang/kartu/{id} [delete]
+func DeleteKartuAkses(c *gin.Context) {
+	res := config.DB.Delete(&models.KartuAkses{}, c.Param("id"))
+	if res.Error != nil {
+		utils.ResponseInternalError(c, "Gagal menghapus kartu")
+		return
+	}
+	if res.RowsAffected == 0 {
+		utils.ResponseNotFound(c, "Kartu tidak ditemukan")
+		return
+	}
+	utils.ResponseOK(c, "Kartu berhasil dihapus", nil)
+}
+
+// ── Log tap & kehadiran ───────────────────────────────────────
+
+// GetTapGerbang godoc
+// @Summary Log tap kartu dari semua perangkat
+// @Description Filter status=kartu_tidak_dikenal membantu mendaftarkan kartu baru.
+// @Tags Gerbang
+// @Security BearerAuth
+// @Param tanggal query string false "Filter tanggal YYYY-MM-DD"
+// @Param status query string false "diterima / duplikat / kartu_tidak_dikenal"
+// @Param perangkat_id query int false "Filter perangkat"
+// @Param uid query string false "Filter UID"
+// @Param page query int false "Halaman" default(1)
+// @Param limit query int false "Jumlah per halaman" default(50)
+// @Router /gerbang/tap [get]
+func GetTapGerbang(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	query := config.DB.Model(&models.TapGerbang{})
+	if tanggal := c.Query("tanggal"); tanggal != "" {
+		t, err := time.ParseInLocation("2006-01-02", tanggal, time.Local)
+		if err != nil {
+			utils.ResponseBadRequest(c, "Format tanggal salah, gunakan YYYY-MM-DD", nil)
+			return
+		}
+		query = query.Where("waktu >= ? AND waktu < ?", t, t.AddDate(0, 0, 1))
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if perangkatID := c.Query("perangkat_id"); perangkatID != "" {
+		query = query.Where("perangkat_id = ?", perangkatID)
+	}
+	if uid := c.Query("uid"); uid != "" {
+		query = query.Where("uid = ?", services.NormalisasiUID(uid))
+	}
+
+	var total int64
+	query.Count(&total)
+	var list []models.TapGerbang
+	query.Order("waktu DESC").Offset((page - 1) * limit).Limit(limit).Find(&list)
+	utils.ResponsePaginated(c, "Log tap gerbang", list, page, limit, total)
+}
+
+// GetKehadiranGerbang godoc
+// @Summary Jam datang/pulang dan keterlambatan per siswa atau guru pada satu tanggal
+// @Tags Gerbang
+// @Security BearerAuth
+// @Param tanggal query string false "Tanggal YYYY-MM-DD (default: hari ini)"
+// @Param pemilik query string false "siswa (default) / guru"
+// @Param kelas_id query int false "Filter kelas (siswa)"
+// @Param terlambat query bool false "Hanya yang terlambat"
+// @Param belum_tap query bool false "Hanya yang belum tap"
+// @Router /gerbang/kehadiran [get]
+func GetKehadiranGerbang(c *gin.Context) {
+	f := services.FilterKehadiranGerbang{
+		Tanggal:        time.Now(),
+		Pemilik:        c.DefaultQuery("pemilik", models.PemilikSiswa),
+		HanyaTerlambat: c.Query("terlambat") == "true",
+		HanyaBelumTap:  c.Query("belum_tap") == "true",
+	}
+	if tanggal := c.Query("tanggal"); tanggal != "" {
+		t, err := time.Parse("2006-01-02", tanggal)
+		if err != nil {
+			utils.ResponseBadRequest(c, "Format tanggal salah, gunakan YYYY-MM-DD", nil)
+			return
+		}
+		f.Tanggal = t
+	}
+	if kelasID, _ := strconv.ParseUint(c.Query("kelas_id"), 10, 64); kelasID != 0 {
+		f.KelasID = uint(kelasID)
+	}
+	// Wali kelas hanya melihat siswa kelas perwaliannya
+	if middlewares.GetCurrentUser(c).Role == models.RoleWaliKelas {
+		var kelas models.Kelas
+		if f.KelasID == 0 || f.Pemilik != models.PemilikSiswa || config.DB.First(&kelas, f.KelasID).Error != nil {
+			utils.ResponseBadRequest(c, "Wali kelas wajib memilih kelas_id kelas perwaliannya", nil)
+			return
+		}
+		if !pastikanWaliKelas(c, kelas) {
+			return
+		}
+	}
+
+	list, err := services.LaporanKehadiranGerbang(f)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal mengambil kehadiran gerbang")
+		return
+	}
+	hadir, terlambat := 0, 0
+	for _, k := range list {
+		if k.JamDatang != nil {
+			hadir++
+		}
+		if k.Terlambat {
+			terlambat++
+		}
+	}
+	utils.ResponseOK(c, "Kehadiran gerbang", gin.H{
+		"tanggal":   f.Tanggal.Format("2006-01-02"),
+		"pemilik":   f.Pemilik,
+		"jumlah":    len(list),
+		"hadir":     hadir,
+		"terlambat": terlambat,
+		"belum_tap": len(list) - hadir,
+		"data":      list,
+	})
+}
+
+// IsiAbsensiGerbang godoc
+// @Summary Isi absensi per jadwal dari tap gerbang (dijalankan juga otomatis setiap sore)
+// @Tags Gerbang
+// @Security BearerAuth
+// @Param body body IsiAbsensiGerbangRequest false "Tanggal"
+// @Router /gerbang/isi-absensi [post]
+func IsiAbsensiGerbang(c *gin.Context) {
+	var req IsiAbsensiGerbangRequest
+	c.ShouldBindJSON(&req)
+	if req.Tanggal != "" {
+		if _, err := time.Parse("2006-01-02", req.Tanggal); err != nil {
+			utils.ResponseBadRequest(c, "Format tanggal salah, gunakan YYYY-MM-DD", nil)
+			return
+		}
+	}
+	job, err := services.AntrikanAbsensiGerbang(req.Tanggal)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal mengantrikan pengisian absensi")
+		return
+	}
+	utils.ResponseOK(c, "Pengisian absensi dari tap gerbang diantrikan", gin.H{"job_id": job.ID})
+}
+
+// ── Jadwal bel ────────────────────────────────────────────────
+
+// GetJadwalBel godoc
+// @Summary Jam masuk dan pulang sekolah per hari
+// @Tags Gerbang
+// @Security BearerAuth
+// @Router /gerbang/jadwal-bel [get]
+func GetJadwalBel(c *gin.Context) {
+	var list []models.JadwalBel
+	config.DB.Order("hari_ke ASC").Find(&list)
+	utils.ResponseOK(c, "Jadwal bel", list)
+}
+
+// SetJadwalBel godoc
+// @Summary Atur jam masuk dan pulang untuk satu hari
+// @Tags Gerbang
+// @Security BearerAuth
+// @Param hari_ke path int true "1=Senin … 7=Minggu"
+// @Param body body JadwalBelRequest true "Jam bel"
+// @Router /gerbang/jadwal-bel/{hari_ke} [put]
+func SetJadwalBel(c *gin.Context) {
+	hariKe, err := strconv.Atoi(c.Param("hari_ke"))
+	if err != nil || hariKe < 1 || hariKe > 7 {
+		utils.ResponseBadRequest(c, "hari_ke harus 1 (Senin) sampai 7 (Minggu)", nil)
+		return
+	}
+	var req JadwalBelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if !polaJam.MatchString(req.JamMasuk) || !polaJam.MatchString(req.JamPulang) {
+		utils.ResponseBadRequest(c, "Format jam harus HH:MM", nil)
+		return
+	}
+	if req.JamPulang <= req.JamMasuk {
+		utils.ResponseBadRequest(c, "Jam pulang harus setelah jam masuk", nil)
+		return
+	}
+
+	var bel models.JadwalBel
+	config.DB.Where("hari_ke = ?", hariKe).First(&bel)
+	bel.HariKe = hariKe
+	bel.JamMasuk = req.JamMasuk
+	bel.JamPulang = req.JamPulang
+	bel.ToleransiMenit = req.ToleransiMenit
+	if err := config.DB.Save(&bel).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan jadwal bel")
+		return
+	}
+	utils.ResponseOK(c, "Jadwal bel "+services.NamaHari(hariKe)+" disimpan", bel)
+}
+
+// DeleteJadwalBel godoc
+// @Summary Hapus jadwal bel satu hari (hari libur, keterlambatan tidak dihitung)
+// @Tags Gerbang
+// @Security BearerAuth
+// @Param hari_ke path int true "1=Senin … 7=Minggu"
+// @Router /gerbang/jadwal-bel/{hari_ke} [delete]
+func DeleteJadwalBel(c *gin.Context) {
+	config.DB.Where("hari_ke = ?", c.Param("hari_ke")).Delete(&models.JadwalBel{})
+	utils.ResponseOK(c, "Jadwal bel dihapus", nil)
+}
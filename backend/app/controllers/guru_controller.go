@@ -124,11 +124,12 @@ func CreateGuru(c *gin.Context) {
 	var guru models.Guru
 	err := config.DB.Transaction(func(tx *gorm.DB) error {
 		user := models.User{
-			RoleID:   roleGuru.ID,
-			Nama:     req.Nama,
-			Email:    req.Email,
-			Password: req.Password,
-			IsActive: true,
+			RoleID:             roleGuru.ID,
+			Nama:               req.Nama,
+			Email:              req.Email,
+			Password:           req.Password,
+			IsActive:           true,
+			WajibGantiPassword: true,
 		}
 		if err := tx.Create(&user).Error; err != nil {
 			return err
@@ -347,6 +347,16 @@ func UpdateJadwal(c *gin.Context) {
 		return
 	}
 
+	// Mapel baru harus termasuk kurikulum kelas
+	if mapelID != jadwal.MataPelajaranID {
+		var kelas models.Kelas
+		config.DB.First(&kelas, jadwal.KelasID)
+		if err := services.CekMapelKurikulum(kelas, mapelID); err != nil {
+			utils.ResponseBadRequest(c, "Mata pelajaran tidak termasuk kurikulum kelas "+kelas.Nama, nil)
+			return
+		}
+	}
+
 	// Re-validasi dengan excludeID = jadwal.ID (kecualikan diri sendiri)
 	hasil := services.ValidasiKonflikJadwal(
 		jadwal.SemesterID, jadwal.KelasID, guruID,
@@ -442,6 +452,21 @@ func BulkCreateJadwal(c *gin.Context) {
 			continue
 		}
 
+		// Mapel harus termasuk kurikulum kelas
+		var kelas models.Kelas
+		if err := config.DB.First(&kelas, item.KelasID).Error; err != nil {
+			res.Berhasil = false
+			res.Pesan = "Kelas tidak ditemukan"
+			results = append(results, res)
+			continue
+		}
+		if err := services.CekMapelKurikulum(kelas, item.MataPelajaranID); err != nil {
+			res.Berhasil = false
+			res.Pesan = "Mata pelajaran tidak termasuk kurikulum kelas " + kelas.Nama
+			results = append(results, res)
+			continue
+		}
+
 		// Cek bentrok
 		hasil := services.ValidasiKonflikJadwal(
 			item.SemesterID, item.KelasID, item.GuruID,
@@ -508,5 +533,9 @@ func validateJadwalFK(c *gin.Context, kelasID, guruID, mapelID, semesterID uint)
 		utils.ResponseBadRequest(c, "Semester tidak ditemukan", nil)
 		return err
 	}
+	if err := services.CekMapelKurikulum(kelas, mapelID); err != nil {
+		utils.ResponseBadRequest(c, "Mata pelajaran "+mp.Nama+" tidak termasuk kurikulum kelas "+kelas.Nama, nil)
+		return err
+	}
 	return nil
 }
\ No newline at end of file
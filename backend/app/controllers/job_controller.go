@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// DaftarkanJobHandler mendaftarkan handler job antrian milik package controllers
+func DaftarkanJobHandler() {
+	services.DaftarkanHandlerJob(JobRaporBatch, jobRaporBatch)
+	services.DaftarkanHandlerJob(JobNotifikasiRole, jobNotifikasiRole)
+}
+
+// GetJobs godoc
+// @Summary List job antrian latar belakang
+// @Tags Job
+// @Security BearerAuth
+// @Param status query string false "antri / proses / selesai / gagal / batal"
+// @Param tipe query string false "Tipe job"
+// @Param page query int false "Halaman" default(1)
+// @Param limit query int false "Jumlah per halaman" default(20)
+// @Router /jobs [get]
+func GetJobs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	if page < 1 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	query := config.DB.Model(&models.Job{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if tipe := c.Query("tipe"); tipe != "" {
+		query = query.Where("tipe = ?", tipe)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var jobs []models.Job
+	if err := query.Offset(offset).Limit(limit).Order("id DESC").Find(&jobs).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal mengambil data job")
+		return
+	}
+	utils.ResponsePaginated(c, "Daftar job", jobs, page, limit, total)
+}
+
+// GetRingkasanJob godoc
+// @Summary Jumlah job per tipe dan status
+// @Tags Job
+// @Security BearerAuth
+// @Router /jobs/ringkasan [get]
+func GetRingkasanJob(c *gin.Context) {
+	var rows []struct {
+		Tipe   string `json:"tipe"`
+		Status string `json:"status"`
+		Jumlah int64  `json:"jumlah"`
+	}
+	if err := config.DB.Model(&models.Job{}).
+		Select("tipe, status, COUNT(*) AS jumlah").
+		Group("tipe, status").Order("tipe, status").
+		Scan(&rows).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal mengambil ringkasan job")
+		return
+	}
+
+	perStatus := map[string]int64{
+		models.JobAntri: 0, models.JobProses: 0, models.JobSelesai: 0,
+		models.JobGagal: 0, models.JobBatal: 0,
+	}
+	for _, r := range rows {
+		perStatus[r.Status] += r.Jumlah
+	}
+	utils.ResponseOK(c, "Ringkasan job", gin.H{
+		"per_status": perStatus,
+		"per_tipe":   rows,
+	})
+}
+
+// GetJobByID godoc
+// @Summary Detail job
+// @Tags Job
+// @Security BearerAuth
+// @Param id path int true "Job ID"
+// @Router /jobs/{id} [get]
+func GetJobByID(c *gin.Context) {
+	var job models.Job
+	if err := config.DB.First(&job, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Job tidak ditemukan")
+		return
+	}
+	utils.ResponseOK(c, "Detail job", job)
+}
+
+// UlangiJob godoc
+// @Summary Antrikan ulang job yang gagal atau dibatalkan
+// @Tags Job
+// @Security BearerAuth
+// @Param id path int true "Job ID"
+// @Router /jobs/{id}/ulangi [post]
+func UlangiJob(c *gin.Context) {
+	var job models.Job
+	if err := config.DB.First(&job, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Job tidak ditemukan")
+		return
+	}
+	if job.Status != models.JobGagal && job.Status != models.JobBatal {
+		utils.ResponseBadRequest(c, "Hanya job berstatus gagal atau batal yang bisa diulang", nil)
+		return
+	}
+
+	res := config.DB.Model(&models.Job{}).
+		Where("id = ? AND status = ?", job.ID, job.Status).
+		Updates(map[string]interface{}{
+			"status":        models.JobAntri,
+			"percobaan":     0,
+			"jalankan_pada": time.Now(),
+			"selesai_pada":  nil,
+		})
+	if res.Error != nil || res.RowsAffected == 0 {
+		utils.ResponseInternalError(c, "Gagal mengantrikan ulang job")
+		return
+	}
+	config.DB.First(&job, job.ID)
+	utils.ResponseOK(c, "Job diantrikan ulang", job)
+}
+
+// BatalkanJob godoc
+// @Summary Batalkan job yang masih antri
+// @Tags Job
+// @Security BearerAuth
+// @Param id path int true "Job ID"
+// @Router /jobs/{id}/batal [post]
+func BatalkanJob(c *gin.Context) {
+	var job models.Job
+	if err := config.DB.First(&job, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Job tidak ditemukan")
+		return
+	}
+
+	// Kondisi status di WHERE mencegah pembatalan job yang baru saja diambil worker
+	res := config.DB.Model(&models.Job{}).
+		Where("id = ? AND status = ?", job.ID, models.JobAntri).
+		Updates(map[string]interface{}{"status": models.JobBatal, "selesai_pada": time.Now()})
+	if res.Error != nil {
+		utils.ResponseInternalError(c, "Gagal membatalkan job")
+		return
+	}
+	if res.RowsAffected == 0 {
+		utils.ResponseBadRequest(c, "Hanya job berstatus antri yang bisa dibatalkan", nil)
+		return
+	}
+	config.DB.First(&job, job.ID)
+	utils.ResponseOK(c, "Job dibatalkan", job)
+}
+
+// GetJobTerjadwal godoc
+// @Summary List job berkala (cron)
+// @Tags Job
+// @Security BearerAuth
+// @Router /jobs/terjadwal [get]
+func GetJobTerjadwal(c *gin.Context) {
+	var list []models.JobTerjadwal
+	config.DB.Order("nama").Find(&list)
+	utils.ResponseOK(c, "Daftar job terjadwal", list)
+}
+
+// UpdateJobTerjadwal godoc
+// @Summary Aktifkan/nonaktifkan job berkala atau ubah jadwal cron-nya
+// @Tags Job
+// @Security BearerAuth
+// @Param id path int true "Job terjadwal ID"
+// @Router /jobs/terjadwal/{id} [put]
+func UpdateJobTerjadwal(c *gin.Context) {
+	var jt models.JobTerjadwal
+	if err := config.DB.First(&jt, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Job terjadwal tidak ditemukan")
+		return
+	}
+
+	var req struct {
+		Aktif *bool  `json:"aktif"`
+		Cron  string `json:"cron"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	update := map[string]interface{}{}
+	if req.Aktif != nil {
+		update["aktif"] = *req.Aktif
+	}
+	if req.Cron != "" {
+		berikutnya, err := services.ValidasiCron(req.Cron)
+		if err != nil {
+			utils.ResponseBadRequest(c, "Ekspresi cron tidak valid", err.Error())
+			return
+		}
+		update["cron"] = req.Cron
+		update["berikutnya_pada"] = berikutnya
+	}
+	if len(update) == 0 {
+		utils.ResponseBadRequest(c, "Tidak ada perubahan", nil)
+		return
+	}
+
+	if err := config.DB.Model(&jt).Updates(update).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal memperbarui job terjadwal")
+		return
+	}
+	config.DB.First(&jt, jt.ID)
+	utils.ResponseOK(c, "Job terjadwal diperbarui", jt)
+}
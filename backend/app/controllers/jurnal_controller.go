@@ -0,0 +1,685 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jung-kurt/gofpdf"
+	"gorm.io/gorm"
+	"sim-sekolah/app/middlewares"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// Batas lampiran per jurnal
+const (
+	maksLampiranJurnal  = 5
+	maksUkuranLampiran  = 5 * 1024 * 1024
+	formatTanggalJurnal = "2006-01-02"
+)
+
+// ── DTOs ──────────────────────────────────────────────────────
+
+type JurnalRequest struct {
+	JadwalID             uint   `json:"jadwal_id" binding:"required"`
+	Tanggal              string `json:"tanggal" binding:"required"` // "2025-02-12"
+	Topik                string `json:"topik" binding:"required,max=255"`
+	KegiatanPembelajaran string `json:"kegiatan_pembelajaran"`
+	Catatan              string `json:"catatan"`
+}
+
+type UpdateJurnalRequest struct {
+	Topik                string  `json:"topik" binding:"omitempty,max=255"`
+	KegiatanPembelajaran *string `json:"kegiatan_pembelajaran"`
+	Catatan              *string `json:"catatan"`
+}
+
+type ReviewJurnalRequest struct {
+	Status  string `json:"status" binding:"required,oneof=disetujui revisi"`
+	Catatan string `json:"catatan"`
+}
+
+// ── Helper akses ──────────────────────────────────────────────
+
+// queryJurnalTerlihat membatasi jurnal sesuai role: admin dan kepala sekolah
+// melihat semua, guru melihat jurnalnya sendiri, wali kelas juga melihat jurnal
+// di kelas perwaliannya.
+func queryJurnalTerlihat(c *gin.Context) (*gorm.DB, bool) {
+	query := config.DB.Model(&models.JurnalMengajar{}).
+		Joins("JOIN jadwals ON jadwals.id = jurnal_mengajars.jadwal_id")
+
+	claims := middlewares.GetCurrentUser(c)
+	switch claims.Role {
+	case models.RoleAdmin, models.RoleKepalaSekolah:
+		return query, true
+	case models.RoleGuru, models.RoleWaliKelas:
+		guru, ok := guruLogin(c)
+		if !ok {
+			return nil, false
+		}
+		if claims.Role == models.RoleWaliKelas {
+			return query.Where(`jurnal_mengajars.guru_id = ? OR jadwals.guru_id = ? OR jadwals.kelas_id IN (
+				SELECT id FROM kelas WHERE wali_kelas_id = ?)`, guru.ID, guru.ID, guru.ID), true
+		}
+		return query.Where("jurnal_mengajars.guru_id = ? OR jadwals.guru_id = ?", guru.ID, guru.ID), true
+	}
+	utils.ResponseForbidden(c, "Akses ditolak")
+	return nil, false
+}
+
+// ambilJurnal memuat jurnal yang boleh dilihat user yang sedang login
+func ambilJurnal(c *gin.Context) (models.JurnalMengajar, bool) {
+	var jurnal models.JurnalMengajar
+	query, ok := queryJurnalTerlihat(c)
+	if !ok {
+		return jurnal, false
+	}
+	if err := query.Where("jurnal_mengajars.id = ?", c.Param("id")).
+		Preload("Jadwal.Kelas").Preload("Jadwal.MataPelajaran").Preload("Guru").Preload("Lampiran").
+		First(&jurnal).Error; err != nil {
+		utils.ResponseNotFound(c, "Jurnal tidak ditemukan")
+		return jurnal, false
+	}
+	return jurnal, true
+}
+
+// ambilJurnalMilikSaya memuat jurnal yang diisi guru yang sedang login dan
+// masih boleh diubah (belum disetujui)
+func ambilJurnalMilikSaya(c *gin.Context) (models.JurnalMengajar, bool) {
+	var jurnal models.JurnalMengajar
+	guru, ok := guruLogin(c)
+	if !ok {
+		return jurnal, false
+	}
+	if err := config.DB.Preload("Lampiran").First(&jurnal, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Jurnal tidak ditemukan")
+		return jurnal, false
+	}
+	if jurnal.GuruID != guru.ID {
+		utils.ResponseForbidden(c, "Anda hanya dapat mengubah jurnal milik sendiri")
+		return jurnal, false
+	}
+	if jurnal.Status == models.JurnalDisetujui {
+		utils.ResponseBadRequest(c, "Jurnal yang sudah disetujui tidak dapat diubah", nil)
+		return jurnal, false
+	}
+	return jurnal, true
+}
+
+// absensiSesiJurnal mengambil rekap absensi pertemuan yang sama dengan jurnal
+func absensiSesiJurnal(jurnal models.JurnalMengajar) gin.H {
+	rekap, _ := services.RekapAbsensiSesi([]uint{jurnal.JadwalID}, jurnal.Tanggal, jurnal.Tanggal)
+	r, terisi := rekap[services.KunciSesi{JadwalID: jurnal.JadwalID, Tanggal: jurnal.Tanggal.Format(formatTanggalJurnal)}]
+
+	var tidakHadir []models.Absensi
+	if terisi {
+		config.DB.Preload("Siswa").
+			Where("jadwal_id = ? AND tanggal::date = ? AND status <> 'hadir'", jurnal.JadwalID, jurnal.Tanggal.Format(formatTanggalJurnal)).
+			Find(&tidakHadir)
+	}
+	return gin.H{
+		"terisi":           terisi,
+		"total":            r.TotalPertemuan,
+		"hadir":            r.Hadir,
+		"izin":             r.Izin,
+		"sakit":            r.Sakit,
+		"alfa":             r.Alfa,
+		"persentase_hadir": r.PersentaseHadir,
+		"tidak_hadir":      tidakHadir,
+	}
+}
+
+// ── CRUD ──────────────────────────────────────────────────────
+
+// GetJurnal godoc
+// @Summary List jurnal mengajar
+// @Tags Jurnal
+// @Security BearerAuth
+// @Param guru_id query int false "Filter guru"
+// @Param kelas_id query int false "Filter kelas"
+// @Param semester_id query int false "Filter semester"
+// @Param bulan query string false "Filter bulan YYYY-MM"
+// @Param status query string false "diajukan / disetujui / revisi"
+// @Param page query int false "Halaman" default(1)
+// @Param limit query int false "Jumlah per halaman" default(20)
+// @Router /jurnal [get]
+func GetJurnal(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	if page < 1 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	query, ok := queryJurnalTerlihat(c)
+	if !ok {
+		return
+	}
+	if guruID := c.Query("guru_id"); guruID != "" {
+		query = query.Where("jurnal_mengajars.guru_id = ?", guruID)
+	}
+	if kelasID := c.Query("kelas_id"); kelasID != "" {
+		query = query.Where("jadwals.kelas_id = ?", kelasID)
+	}
+	if semesterID := c.Query("semester_id"); semesterID != "" {
+		query = query.Where("jadwals.semester_id = ?", semesterID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("jurnal_mengajars.status = ?", status)
+	}
+	if bulan := c.Query("bulan"); bulan != "" {
+		awal, err := time.Parse("2006-01", bulan)
+		if err != nil {
+			utils.ResponseBadRequest(c, "Format bulan harus YYYY-MM", nil)
+			return
+		}
+		query = query.Where("jurnal_mengajars.tanggal >= ? AND jurnal_mengajars.tanggal < ?", awal, awal.AddDate(0, 1, 0))
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var list []models.JurnalMengajar
+	if err := query.Preload("Jadwal.Kelas").Preload("Jadwal.MataPelajaran").Preload("Guru").
+		Offset(offset).Limit(limit).
+		Order("jurnal_mengajars.tanggal DESC, jadwals.jam_mulai DESC").
+		Find(&list).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal mengambil data jurnal")
+		return
+	}
+	utils.ResponsePaginated(c, "Daftar jurnal mengajar", list, page, limit, total)
+}
+
+// GetJurnalByID godoc
+// @Summary Detail jurnal mengajar beserta lampiran dan absensi pertemuannya
+// @Tags Jurnal
+// @Security BearerAuth
+// @Param id path int true "Jurnal ID"
+// @Router /jurnal/{id} [get]
+func GetJurnalByID(c *gin.Context) {
+	jurnal, ok := ambilJurnal(c)
+	if !ok {
+		return
+	}
+	utils.ResponseOK(c, "Detail jurnal mengajar", gin.H{
+		"jurnal":  jurnal,
+		"absensi": absensiSesiJurnal(jurnal),
+	})
+}
+
+// CreateJurnal godoc
+// @Summary Isi jurnal mengajar untuk satu pertemuan jadwal
+// @Tags Jurnal
+// @Security BearerAuth
+// @Param body body JurnalRequest true "Data jurnal"
+// @Router /jurnal [post]
+func CreateJurnal(c *gin.Context) {
+	var req JurnalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	guru, ok := guruLogin(c)
+	if !ok {
+		return
+	}
+
+	var jadwal models.Jadwal
+	if err := config.DB.Preload("Semester").First(&jadwal, req.JadwalID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Jadwal tidak ditemukan", nil)
+		return
+	}
+	if jadwal.GuruID != guru.ID && !services.GuruMengampu(guru.ID, jadwal.SemesterID, jadwal.KelasID, jadwal.MataPelajaranID) {
+		utils.ResponseForbidden(c, "Anda tidak mengajar pada jadwal ini")
+		return
+	}
+
+	tanggal, err := time.Parse(formatTanggalJurnal, req.Tanggal)
+	if err != nil {
+		utils.ResponseBadRequest(c, "Format tanggal tidak valid (gunakan YYYY-MM-DD)", nil)
+		return
+	}
+	hariIni := time.Now().Format(formatTanggalJurnal)
+	if req.Tanggal > hariIni {
+		utils.ResponseBadRequest(c, "Jurnal tidak dapat diisi untuk tanggal yang belum terjadi", nil)
+		return
+	}
+	hariKe := int(tanggal.Weekday())
+	if hariKe == 0 {
+		hariKe = 7
+	}
+	if hariKe != jadwal.HariKe {
+		utils.ResponseBadRequest(c, "Tanggal tidak sesuai hari jadwal ("+services.NamaHari(jadwal.HariKe)+")", nil)
+		return
+	}
+	sm := jadwal.Semester
+	if (sm.TanggalMulai != nil && tanggal.Before(*sm.TanggalMulai)) ||
+		(sm.TanggalSelesai != nil && tanggal.After(*sm.TanggalSelesai)) {
+		utils.ResponseBadRequest(c, "Tanggal berada di luar rentang semester", nil)
+		return
+	}
+
+	var existing models.JurnalMengajar
+	if err := config.DB.Where("jadwal_id = ? AND tanggal = ?", jadwal.ID, req.Tanggal).First(&existing).Error; err == nil {
+		utils.ResponseBadRequest(c, "Jurnal untuk pertemuan ini sudah diisi", gin.H{"jurnal_id": existing.ID})
+		return
+	}
+
+	jurnal := models.JurnalMengajar{
+		JadwalID:             jadwal.ID,
+		Tanggal:              tanggal,
+		GuruID:               guru.ID,
+		Topik:                req.Topik,
+		KegiatanPembelajaran: req.KegiatanPembelajaran,
+		Catatan:              req.Catatan,
+		Status:               models.JurnalDiajukan,
+	}
+	if err := config.DB.Create(&jurnal).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan jurnal")
+		return
+	}
+	config.DB.Preload("Jadwal.Kelas").Preload("Jadwal.MataPelajaran").First(&jurnal, jurnal.ID)
+	utils.ResponseCreated(c, "Jurnal mengajar berhasil disimpan", gin.H{
+		"jurnal":  jurnal,
+		"absensi": absensiSesiJurnal(jurnal),
+	})
+}
+
+// UpdateJurnal godoc
+// @Summary Ubah jurnal mengajar (sebelum disetujui)
+// @Tags Jurnal
+// @Security BearerAuth
+// @Param id path int true "Jurnal ID"
+// @Param body body UpdateJurnalRequest true "Data jurnal"
+// @Router /jurnal/{id} [put]
+func UpdateJurnal(c *gin.Context) {
+	jurnal, ok := ambilJurnalMilikSaya(c)
+	if !ok {
+		return
+	}
+	var req UpdateJurnalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	if req.Topik != "" {
+		jurnal.Topik = req.Topik
+	}
+	if req.KegiatanPembelajaran != nil {
+		jurnal.KegiatanPembelajaran = *req.KegiatanPembelajaran
+	}
+	if req.Catatan != nil {
+		jurnal.Catatan = *req.Catatan
+	}
+	// Jurnal yang diminta revisi kembali diajukan setelah diperbaiki
+	jurnal.Status = models.JurnalDiajukan
+
+	if err := config.DB.Omit("Lampiran").Save(&jurnal).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan jurnal")
+		return
+	}
+	utils.ResponseOK(c, "Jurnal mengajar berhasil diupdate", jurnal)
+}
+
+// DeleteJurnal godoc
+// @Summary Hapus jurnal mengajar beserta lampirannya (sebelum disetujui)
+// @Tags Jurnal
+// @Security BearerAuth
+// @Param id path int true "Jurnal ID"
+// @Router /jurnal/{id} [delete]
+func DeleteJurnal(c *gin.Context) {
+	var jurnal models.JurnalMengajar
+	if middlewares.GetCurrentUser(c).Role == models.RoleAdmin {
+		if err := config.DB.Preload("Lampiran").First(&jurnal, c.Param("id")).Error; err != nil {
+			utils.ResponseNotFound(c, "Jurnal tidak ditemukan")
+			return
+		}
+	} else {
+		var ok bool
+		if jurnal, ok = ambilJurnalMilikSaya(c); !ok {
+			return
+		}
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("jurnal_id = ?", jurnal.ID).Delete(&models.LampiranJurnal{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&jurnal).Error
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghapus jurnal")
+		return
+	}
+	for _, l := range jurnal.Lampiran {
+		os.Remove(l.FilePath)
+	}
+	utils.ResponseOK(c, "Jurnal mengajar berhasil dihapus", nil)
+}
+
+// ── Lampiran ──────────────────────────────────────────────────
+
+// UploadLampiranJurnal godoc
+// @Summary Upload lampiran jurnal (PDF, gambar, dokumen Office; maks 5MB)
+// @Tags Jurnal
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Param id path int true "Jurnal ID"
+// @Param file formData file true "File lampiran"
+// @Router /jurnal/{id}/lampiran [post]
+func UploadLampiranJurnal(c *gin.Context) {
+	jurnal, ok := ambilJurnalMilikSaya(c)
+	if !ok {
+		return
+	}
+	if len(jurnal.Lampiran) >= maksLampiranJurnal {
+		utils.ResponseBadRequest(c, fmt.Sprintf("Maksimal %d lampiran per jurnal", maksLampiranJurnal), nil)
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		utils.ResponseBadRequest(c, "File tidak ditemukan", err.Error())
+		return
+	}
+	defer file.Close()
+
+	filePath, ok := utils.SimpanUpload(c, header, utils.AturanUpload{
+		Dir:        "storage/jurnal",
+		Prefix:     fmt.Sprintf("jurnal_%d", jurnal.ID),
+		Ekstensi:   []string{".pdf", ".jpg", ".jpeg", ".png", ".docx", ".pptx", ".xlsx"},
+		MaksByte:   maksUkuranLampiran,
+		TipeKonten: []string{"application/pdf", "image/jpeg", "image/png", "application/zip"},
+	})
+	if !ok {
+		return
+	}
+
+	lampiran := models.LampiranJurnal{
+		JurnalID: jurnal.ID,
+		NamaFile: header.Filename,
+		FilePath: filePath,
+		Ukuran:   header.Size,
+	}
+	if err := config.DB.Create(&lampiran).Error; err != nil {
+		os.Remove(filePath) // rollback: hapus file yang baru diupload
+		utils.ResponseInternalError(c, "Gagal menyimpan lampiran")
+		return
+	}
+	utils.ResponseCreated(c, "Lampiran berhasil diupload", gin.H{
+		"lampiran": lampiran,
+		"url":      fmt.Sprintf("/api/v1/jurnal/%d/lampiran/%d", jurnal.ID, lampiran.ID),
+	})
+}
+
+// DownloadLampiranJurnal godoc
+// @Summary Unduh lampiran jurnal (hanya untuk user yang dapat melihat jurnal tersebut)
+// @Tags Jurnal
+// @Security BearerAuth
+// @Param id path int true "Jurnal ID"
+// @Param lampiran_id path int true "Lampiran ID"
+// @Router /jurnal/{id}/lampiran/{lampiran_id} [get]
+func DownloadLampiranJurnal(c *gin.Context) {
+	jurnal, ok := ambilJurnal(c)
+	if !ok {
+		return
+	}
+	var lampiran models.LampiranJurnal
+	if err := config.DB.Where("id = ? AND jurnal_id = ?", c.Param("lampiran_id"), jurnal.ID).
+		First(&lampiran).Error; err != nil {
+		utils.ResponseNotFound(c, "Lampiran tidak ditemukan")
+		return
+	}
+	utils.KirimFile(c, lampiran.FilePath)
+}
+
+// DeleteLampiranJurnal godoc
+// @Summary Hapus lampiran jurnal
+// @Tags Jurnal
+// @Security BearerAuth
+// @Param id path int true "Jurnal ID"
+// @Param lampiran_id path int true "Lampiran ID"
+// @Router /jurnal/{id}/lampiran/{lampiran_id} [delete]
+func DeleteLampiranJurnal(c *gin.Context) {
+	jurnal, ok := ambilJurnalMilikSaya(c)
+	if !ok {
+		return
+	}
+	var lampiran models.LampiranJurnal
+	if err := config.DB.Where("id = ? AND jurnal_id = ?", c.Param("lampiran_id"), jurnal.ID).
+		First(&lampiran).Error; err != nil {
+		utils.ResponseNotFound(c, "Lampiran tidak ditemukan")
+		return
+	}
+	if err := config.DB.Delete(&lampiran).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menghapus lampiran")
+		return
+	}
+	os.Remove(lampiran.FilePath)
+	utils.ResponseOK(c, "Lampiran berhasil dihapus", nil)
+}
+
+// ── Review ────────────────────────────────────────────────────
+
+// ReviewJurnal godoc
+// @Summary Setujui jurnal atau kembalikan untuk revisi (wali kelas / kepala sekolah)
+// @Tags Jurnal
+// @Security BearerAuth
+// @Param id path int true "Jurnal ID"
+// @Param body body ReviewJurnalRequest true "Hasil review"
+// @Router /jurnal/{id}/review [post]
+func ReviewJurnal(c *gin.Context) {
+	var req ReviewJurnalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if req.Status == models.JurnalRevisi && req.Catatan == "" {
+		utils.ResponseBadRequest(c, "Catatan wajib diisi jika jurnal dikembalikan untuk revisi", nil)
+		return
+	}
+
+	jurnal, ok := ambilJurnal(c)
+	if !ok {
+		return
+	}
+	claims := middlewares.GetCurrentUser(c)
+	if claims.Role == models.RoleWaliKelas {
+		guru, ok := guruLogin(c)
+		if !ok {
+			return
+		}
+		kelas := jurnal.Jadwal.Kelas
+		if kelas.WaliKelasID == nil || *kelas.WaliKelasID != guru.ID {
+			utils.ResponseForbidden(c, "Anda bukan wali kelas "+kelas.Nama)
+			return
+		}
+		if jurnal.GuruID == guru.ID {
+			utils.ResponseForbidden(c, "Jurnal sendiri tidak dapat direview sendiri")
+			return
+		}
+	}
+
+	now := time.Now()
+	if err := config.DB.Model(&jurnal).Updates(map[string]interface{}{
+		"status":         req.Status,
+		"catatan_review": req.Catatan,
+		"direview_oleh":  claims.UserID,
+		"direview_pada":  now,
+	}).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan review")
+		return
+	}
+
+	if req.Status == models.JurnalRevisi {
+		SendNotification(jurnal.Guru.UserID, models.NotifJadwal, "📝", "Jurnal perlu direvisi",
+			fmt.Sprintf("Jurnal %s %s tanggal %s dikembalikan: %s", jurnal.Jadwal.MataPelajaran.Nama,
+				jurnal.Jadwal.Kelas.Nama, jurnal.Tanggal.Format("02-01-2006"), req.Catatan),
+			fmt.Sprintf("/jurnal/%d", jurnal.ID))
+	}
+	utils.ResponseOK(c, "Review jurnal disimpan", gin.H{"id": jurnal.ID, "status": req.Status})
+}
+
+// ── Export PDF bulanan ────────────────────────────────────────
+
+// ExportJurnalBulananPDF godoc
+// @Summary Export jurnal mengajar satu guru dalam satu bulan ke PDF
+// @Tags Jurnal
+// @Security BearerAuth
+// @Param guru_id path int true "Guru ID"
+// @Param bulan query string true "Bulan YYYY-MM"
+// @Router /jurnal/guru/{guru_id}/pdf [get]
+func ExportJurnalBulananPDF(c *gin.Context) {
+	var guru models.Guru
+	if err := config.DB.First(&guru, c.Param("guru_id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Guru tidak ditemukan")
+		return
+	}
+	claims := middlewares.GetCurrentUser(c)
+	if claims.Role == models.RoleGuru || claims.Role == models.RoleWaliKelas {
+		if guru.UserID != claims.UserID {
+			utils.ResponseForbidden(c, "Anda hanya dapat mengekspor jurnal milik sendiri")
+			return
+		}
+	}
+
+	awal, err := time.Parse("2006-01", c.Query("bulan"))
+	if err != nil {
+		utils.ResponseBadRequest(c, "Parameter bulan wajib diisi dengan format YYYY-MM", nil)
+		return
+	}
+	akhir := awal.AddDate(0, 1, -1)
+
+	var list []models.JurnalMengajar
+	config.DB.Joins("JOIN jadwals ON jadwals.id = jurnal_mengajars.jadwal_id").
+		Preload("Jadwal.Kelas").Preload("Jadwal.MataPelajaran").
+		Where("jurnal_mengajars.guru_id = ? AND jurnal_mengajars.tanggal BETWEEN ? AND ?", guru.ID, awal, akhir).
+		Order("jurnal_mengajars.tanggal ASC, jadwals.jam_mulai ASC").
+		Find(&list)
+
+	jadwalIDs := make([]uint, 0, len(list))
+	for _, j := range list {
+		jadwalIDs = append(jadwalIDs, j.JadwalID)
+	}
+	absensi, err := services.RekapAbsensiSesi(jadwalIDs, awal, akhir)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghitung absensi pertemuan")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := renderJurnalPDF(guru, awal, list, absensi, services.ProfilSekolah()).Output(&buf); err != nil {
+		utils.ResponseInternalError(c, "Gagal membuat PDF jurnal")
+		return
+	}
+	namaFile := fmt.Sprintf("jurnal_%s_%s.pdf", namaFileAman(guru.Nama), awal.Format("2006-01"))
+	c.Header("Content-Disposition", "attachment; filename="+namaFile)
+	c.Data(200, "application/pdf", buf.Bytes())
+}
+
+var namaBulan = [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+func renderJurnalPDF(guru models.Guru, bulan time.Time, list []models.JurnalMengajar,
+	absensi map[services.KunciSesi]services.RekapAbsensi, sekolah models.ProfilSekolah) *gofpdf.Fpdf {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 12)
+	pdf.AddPage()
+	tulisKopSekolah(pdf, sekolah, true)
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 7, "JURNAL MENGAJAR GURU", "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	pdf.CellFormat(0, 5, fmt.Sprintf("Bulan %s %d", namaBulan[bulan.Month()-1], bulan.Year()), "", 1, "C", false, 0, "")
+	pdf.Ln(2)
+	pdf.Cell(25, 5, "Nama Guru")
+	pdf.Cell(0, 5, ": "+guru.Nama)
+	pdf.Ln(5)
+	pdf.Cell(25, 5, "NIP")
+	pdf.Cell(0, 5, ": "+guru.NIP)
+	pdf.Ln(7)
+
+	lebar := []float64{8, 28, 24, 32, 18, 50, 75, 22, 20}
+	judul := []string{"No", "Hari/Tanggal", "Kelas", "Mata Pelajaran", "Jam", "Topik", "Kegiatan Pembelajaran", "Kehadiran", "Status"}
+	pdf.SetFont("Arial", "B", 8)
+	pdf.SetFillColor(220, 220, 220)
+	for i, j := range judul {
+		pdf.CellFormat(lebar[i], 7, j, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Arial", "", 8)
+	if len(list) == 0 {
+		pdf.CellFormat(0, 7, "Belum ada jurnal pada bulan ini", "1", 1, "C", false, 0, "")
+	}
+	for i, j := range list {
+		hari := int(j.Tanggal.Weekday())
+		if hari == 0 {
+			hari = 7
+		}
+		kehadiran := "-"
+		if r, ok := absensi[services.KunciSesi{JadwalID: j.JadwalID, Tanggal: j.Tanggal.Format(formatTanggalJurnal)}]; ok {
+			kehadiran = fmt.Sprintf("%d/%d hadir", r.Hadir, r.TotalPertemuan)
+		}
+		kegiatan := j.KegiatanPembelajaran
+		if j.Catatan != "" {
+			kegiatan += "\nCatatan: " + j.Catatan
+		}
+		barisTabel(pdf, lebar, []string{
+			strconv.Itoa(i + 1),
+			services.NamaHari(hari) + ", " + j.Tanggal.Format("02-01-2006"),
+			j.Jadwal.Kelas.Nama,
+			j.Jadwal.MataPelajaran.Nama,
+			j.Jadwal.JamMulai + "-" + j.Jadwal.JamSelesai,
+			j.Topik,
+			kegiatan,
+			kehadiran,
+			j.Status,
+		}, []string{"C", "L", "L", "L", "C", "L", "L", "C", "C"})
+	}
+
+	// Tanda tangan guru dan kepala sekolah
+	_, tinggiHalaman := pdf.GetPageSize()
+	if pdf.GetY()+45 > tinggiHalaman-12 {
+		pdf.AddPage()
+	}
+	pdf.Ln(8)
+	tanggal := time.Now().Format("02-01-2006")
+	if sekolah.Kota != "" {
+		tanggal = sekolah.Kota + ", " + tanggal
+	}
+	kepsek := sekolah.KepalaSekolahNama
+	if kepsek == "" {
+		kepsek = "___________________"
+	}
+	pdf.Cell(190, 5, "")
+	pdf.Cell(0, 5, tanggal)
+	pdf.Ln(5)
+	pdf.Cell(190, 5, "Mengetahui, Kepala Sekolah")
+	pdf.Cell(0, 5, "Guru Mata Pelajaran")
+	pdf.Ln(20)
+	pdf.SetFont("Arial", "B", 8)
+	pdf.Cell(190, 5, kepsek)
+	pdf.Cell(0, 5, guru.Nama)
+	pdf.Ln(5)
+	pdf.SetFont("Arial", "", 8)
+	if sekolah.KepalaSekolahNIP != "" {
+		pdf.Cell(190, 5, "NIP. "+sekolah.KepalaSekolahNIP)
+	} else {
+		pdf.Cell(190, 5, "")
+	}
+	if guru.NIP != "" {
+		pdf.Cell(0, 5, "NIP. "+guru.NIP)
+	}
+	return pdf
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"github.com/gin-gonic/gin"
 	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
 	"sim-sekolah/config"
 	"sim-sekolah/utils"
 )
@@ -233,7 +234,177 @@ func DeleteMataPelajaran(c *gin.Context) {
 		utils.ResponseBadRequest(c, "Mata pelajaran masih digunakan di jadwal", nil)
 		return
 	}
+	config.DB.Model(&models.KurikulumMapel{}).Where("mata_pelajaran_id = ?", mp.ID).Count(&count)
+	if count > 0 {
+		utils.ResponseBadRequest(c, "Mata pelajaran masih terdaftar di kurikulum", nil)
+		return
+	}
 
 	config.DB.Delete(&mp)
 	utils.ResponseOK(c, "Mata pelajaran berhasil dihapus", nil)
-}
\ No newline at end of file
+}
+
+// ── Kurikulum ─────────────────────────────────────────────────
+
+type KurikulumRequest struct {
+	JurusanID       *uint  `json:"jurusan_id"` // kosong = berlaku untuk semua jurusan
+	Tingkat         string `json:"tingkat" binding:"required,oneof=X XI XII"`
+	MataPelajaranID uint   `json:"mata_pelajaran_id" binding:"required"`
+	Kelompok        string `json:"kelompok" binding:"required,oneof=umum kejuruan muatan_lokal"`
+	JamPerMinggu    int    `json:"jam_per_minggu" binding:"min=0,max=20"`
+}
+
+// GetKurikulum godoc
+// @Summary Daftar struktur kurikulum (mapel per jurusan & tingkat)
+// @Tags Kurikulum
+// @Security BearerAuth
+// @Param jurusan_id query int false "Filter jurusan"
+// @Param tingkat query string false "Filter tingkat"
+// @Param kelas_id query int false "Kurikulum yang berlaku untuk kelas"
+// @Router /kurikulum [get]
+func GetKurikulum(c *gin.Context) {
+	// Kurikulum efektif untuk satu kelas (termasuk mapel umum lintas jurusan)
+	if kelasID := c.Query("kelas_id"); kelasID != "" {
+		var kelas models.Kelas
+		if err := config.DB.First(&kelas, kelasID).Error; err != nil {
+			utils.ResponseNotFound(c, "Kelas tidak ditemukan")
+			return
+		}
+		list := services.KurikulumKelas(kelas)
+		totalJP := 0
+		for _, k := range list {
+			totalJP += k.JamPerMinggu
+		}
+		utils.ResponseOK(c, "Kurikulum kelas "+kelas.Nama, gin.H{
+			"kelas":     kelas,
+			"kurikulum": list,
+			"total_jp":  totalJP,
+		})
+		return
+	}
+
+	query := config.DB.Model(&models.KurikulumMapel{}).Preload("Jurusan").Preload("MataPelajaran")
+	if v := c.Query("jurusan_id"); v != "" {
+		query = query.Where("jurusan_id = ?", v)
+	}
+	if v := c.Query("tingkat"); v != "" {
+		query = query.Where("tingkat = ?", v)
+	}
+
+	var list []models.KurikulumMapel
+	query.Order("tingkat ASC, jurusan_id ASC, kelompok ASC").Find(&list)
+	utils.ResponseOK(c, "Daftar kurikulum", list)
+}
+
+// CreateKurikulum godoc
+// @Summary Tambah mapel ke kurikulum jurusan & tingkat
+// @Tags Kurikulum
+// @Security BearerAuth
+// @Router /kurikulum [post]
+func CreateKurikulum(c *gin.Context) {
+	var req KurikulumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if !validasiKurikulumFK(c, req) {
+		return
+	}
+
+	k := models.KurikulumMapel{
+		JurusanID:       req.JurusanID,
+		Tingkat:         req.Tingkat,
+		MataPelajaranID: req.MataPelajaranID,
+		Kelompok:        req.Kelompok,
+		JamPerMinggu:    req.JamPerMinggu,
+	}
+	if err := config.DB.Create(&k).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan kurikulum")
+		return
+	}
+
+	config.DB.Preload("Jurusan").Preload("MataPelajaran").First(&k, k.ID)
+	utils.ResponseCreated(c, "Mapel berhasil ditambahkan ke kurikulum", k)
+}
+
+// UpdateKurikulum godoc
+// @Summary Update kelompok / jam per minggu mapel kurikulum
+// @Tags Kurikulum
+// @Security BearerAuth
+// @Param id path int true "ID"
+// @Router /kurikulum/{id} [put]
+func UpdateKurikulum(c *gin.Context) {
+	var k models.KurikulumMapel
+	if err := config.DB.First(&k, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Data kurikulum tidak ditemukan")
+		return
+	}
+
+	var req struct {
+		Kelompok     string `json:"kelompok" binding:"omitempty,oneof=umum kejuruan muatan_lokal"`
+		JamPerMinggu *int   `json:"jam_per_minggu" binding:"omitempty,min=0,max=20"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	if req.Kelompok != "" {
+		k.Kelompok = req.Kelompok
+	}
+	if req.JamPerMinggu != nil {
+		k.JamPerMinggu = *req.JamPerMinggu
+	}
+
+	config.DB.Save(&k)
+	config.DB.Preload("Jurusan").Preload("MataPelajaran").First(&k, k.ID)
+	utils.ResponseOK(c, "Kurikulum berhasil diupdate", k)
+}
+
+// DeleteKurikulum godoc
+// @Summary Hapus mapel dari kurikulum
+// @Tags Kurikulum
+// @Security BearerAuth
+// @Param id path int true "ID"
+// @Router /kurikulum/{id} [delete]
+func DeleteKurikulum(c *gin.Context) {
+	var k models.KurikulumMapel
+	if err := config.DB.First(&k, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Data kurikulum tidak ditemukan")
+		return
+	}
+
+	config.DB.Delete(&k)
+	utils.ResponseOK(c, "Mapel berhasil dihapus dari kurikulum", nil)
+}
+
+func validasiKurikulumFK(c *gin.Context, req KurikulumRequest) bool {
+	if req.JurusanID != nil {
+		var j models.Jurusan
+		if err := config.DB.First(&j, *req.JurusanID).Error; err != nil {
+			utils.ResponseBadRequest(c, "Jurusan tidak ditemukan", nil)
+			return false
+		}
+	}
+	var mp models.MataPelajaran
+	if err := config.DB.First(&mp, req.MataPelajaranID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Mata pelajaran tidak ditemukan", nil)
+		return false
+	}
+
+	// Cegah duplikasi mapel di jurusan & tingkat yang sama
+	query := config.DB.Model(&models.KurikulumMapel{}).
+		Where("tingkat = ? AND mata_pelajaran_id = ?", req.Tingkat, req.MataPelajaranID)
+	if req.JurusanID != nil {
+		query = query.Where("jurusan_id = ?", *req.JurusanID)
+	} else {
+		query = query.Where("jurusan_id IS NULL")
+	}
+	var count int64
+	query.Count(&count)
+	if count > 0 {
+		utils.ResponseBadRequest(c, "Mapel sudah terdaftar di kurikulum jurusan & tingkat ini", nil)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,984 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"sim-sekolah/app/middlewares"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+const maksUkuranBuktiPelanggaran = 5 * 1024 * 1024
+
+// ── DTOs ──────────────────────────────────────────────────────
+
+type JenisPelanggaranRequest struct {
+	Kode      string `json:"kode" binding:"required,max=20"`
+	Nama      string `json:"nama" binding:"required,max=150"`
+	Kategori  string `json:"kategori" binding:"required,oneof=ringan sedang berat"`
+	Poin      int    `json:"poin" binding:"required,min=1,max=1000"`
+	Deskripsi string `json:"deskripsi"`
+	IsAktif   *bool  `json:"is_aktif"`
+}
+
+type AmbangPoinRequest struct {
+	Poin          int    `json:"poin" binding:"required,min=1"`
+	Nama          string `json:"nama" binding:"required,max=100"`
+	Sanksi        string `json:"sanksi"`
+	KirimOrangTua *bool  `json:"kirim_orang_tua"`
+}
+
+type PelanggaranRequest struct {
+	SiswaID            uint   `json:"siswa_id" binding:"required"`
+	JenisPelanggaranID uint   `json:"jenis_pelanggaran_id" binding:"required"`
+	SemesterID         uint   `json:"semester_id"`                // kosong = semester aktif
+	Tanggal            string `json:"tanggal" binding:"required"` // "2025-02-12"
+	Kronologi          string `json:"kronologi"`
+}
+
+type UpdateSanksiRequest struct {
+	Status  string `json:"status" binding:"required,oneof=aktif selesai batal"`
+	Catatan string `json:"catatan"`
+}
+
+type KonselingRequest struct {
+	SiswaID       uint   `json:"siswa_id" binding:"required"`
+	Tanggal       string `json:"tanggal" binding:"required"` // "2025-02-12"
+	Bidang        string `json:"bidang" binding:"omitempty,oneof=pribadi sosial belajar karir"`
+	Permasalahan  string `json:"permasalahan" binding:"required"`
+	Penanganan    string `json:"penanganan"`
+	TindakLanjut  string `json:"tindak_lanjut"`
+	PelanggaranID *uint  `json:"pelanggaran_id"`
+}
+
+// ── Helper akses ──────────────────────────────────────────────
+
+// queryPelanggaranTerlihat membatasi data pelanggaran sesuai role: admin,
+// kepala sekolah dan guru BK melihat semua, wali kelas melihat siswa di kelas
+// perwaliannya, guru melihat pelanggaran yang ia laporkan.
+func queryPelanggaranTerlihat(c *gin.Context) (*gorm.DB, bool) {
+	query := config.DB.Model(&models.PelanggaranSiswa{}).
+		Joins("JOIN siswas ON siswas.id = pelanggaran_siswas.siswa_id")
+
+	claims := middlewares.GetCurrentUser(c)
+	switch claims.Role {
+	case models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuruBK:
+		return query, true
+	case models.RoleGuru, models.RoleWaliKelas:
+		guru, ok := guruLogin(c)
+		if !ok {
+			return nil, false
+		}
+		if claims.Role == models.RoleWaliKelas {
+			return query.Where(`pelanggaran_siswas.pelapor_guru_id = ? OR siswas.kelas_id IN (
+				SELECT id FROM kelas WHERE wali_kelas_id = ?)`, guru.ID, guru.ID), true
+		}
+		return query.Where("pelanggaran_siswas.pelapor_guru_id = ?", guru.ID), true
+	}
+	utils.ResponseForbidden(c, "Akses ditolak")
+	return nil, false
+}
+
+// siswaMilikLogin mengembalikan siswa yang terkait akun siswa/orang tua yang login
+func siswaMilikLogin(c *gin.Context) (uint, bool) {
+	claims := middlewares.GetCurrentUser(c)
+	switch claims.Role {
+	case models.RoleSiswa:
+		var siswa models.Siswa
+		if err := config.DB.Where("user_id = ?", claims.UserID).First(&siswa).Error; err != nil {
+			utils.ResponseNotFound(c, "Data siswa tidak ditemukan")
+			return 0, false
+		}
+		return siswa.ID, true
+	case models.RoleOrangTua:
+		var ot models.OrangTua
+		if err := config.DB.Where("user_id = ?", claims.UserID).First(&ot).Error; err != nil {
+			utils.ResponseNotFound(c, "Data orang tua tidak ditemukan")
+			return 0, false
+		}
+		query := config.DB.Where("orang_tua_id = ?", ot.ID)
+		if siswaID := c.Query("siswa_id"); siswaID != "" {
+			query = query.Where("siswa_id = ?", siswaID)
+		}
+		var link models.OrangTuaSiswa
+		if err := query.First(&link).Error; err != nil {
+			utils.ResponseBadRequest(c, "Belum ada data anak terdaftar", nil)
+			return 0, false
+		}
+		return link.SiswaID, true
+	}
+	utils.ResponseForbidden(c, "Role ini tidak memiliki data kedisiplinan personal")
+	return 0, false
+}
+
+// pastikanAksesKonseling memastikan catatan konseling siswa hanya dibuka oleh
+// guru BK atau wali kelas siswa tersebut
+func pastikanAksesKonseling(c *gin.Context, siswaID uint) bool {
+	claims := middlewares.GetCurrentUser(c)
+	switch claims.Role {
+	case models.RoleGuruBK:
+		return true
+	case models.RoleWaliKelas:
+		var siswa models.Siswa
+		if err := config.DB.Preload("Kelas").First(&siswa, siswaID).Error; err != nil {
+			utils.ResponseNotFound(c, "Siswa tidak ditemukan")
+			return false
+		}
+		if siswa.Kelas == nil {
+			utils.ResponseForbidden(c, "Siswa belum memiliki kelas")
+			return false
+		}
+		return pastikanWaliKelas(c, *siswa.Kelas)
+	}
+	utils.ResponseForbidden(c, "Catatan konseling hanya dapat diakses guru BK dan wali kelas")
+	return false
+}
+
+func parseTanggalKedisiplinan(c *gin.Context, s string) (time.Time, bool) {
+	tanggal, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		utils.ResponseBadRequest(c, "Format tanggal tidak valid (gunakan YYYY-MM-DD)", nil)
+		return tanggal, false
+	}
+	if tanggal.After(time.Now()) {
+		utils.ResponseBadRequest(c, "Tanggal tidak boleh di masa depan", nil)
+		return tanggal, false
+	}
+	return tanggal, true
+}
+
+// ── Katalog Pelanggaran ───────────────────────────────────────
+
+// GetJenisPelanggaran godoc
+// @Summary Katalog jenis pelanggaran beserta poinnya
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param kategori query string false "ringan / sedang / berat"
+// @Param aktif query bool false "Hanya yang aktif"
+// @Router /kedisiplinan/jenis [get]
+func GetJenisPelanggaran(c *gin.Context) {
+	query := config.DB.Model(&models.JenisPelanggaran{})
+	if kategori := c.Query("kategori"); kategori != "" {
+		query = query.Where("kategori = ?", kategori)
+	}
+	if c.Query("aktif") == "true" {
+		query = query.Where("is_aktif = ?", true)
+	}
+	var list []models.JenisPelanggaran
+	query.Order("kategori ASC, kode ASC").Find(&list)
+	utils.ResponseOK(c, "Katalog jenis pelanggaran", list)
+}
+
+// CreateJenisPelanggaran godoc
+// @Summary Tambah jenis pelanggaran
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param body body JenisPelanggaranRequest true "Data jenis pelanggaran"
+// @Router /kedisiplinan/jenis [post]
+func CreateJenisPelanggaran(c *gin.Context) {
+	var req JenisPelanggaranRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	var count int64
+	config.DB.Model(&models.JenisPelanggaran{}).Where("kode = ?", req.Kode).Count(&count)
+	if count > 0 {
+		utils.ResponseBadRequest(c, "Kode pelanggaran sudah digunakan", nil)
+		return
+	}
+
+	jenis := models.JenisPelanggaran{
+		Kode:      req.Kode,
+		Nama:      req.Nama,
+		Kategori:  req.Kategori,
+		Poin:      req.Poin,
+		Deskripsi: req.Deskripsi,
+		IsAktif:   true,
+	}
+	if err := config.DB.Create(&jenis).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan jenis pelanggaran")
+		return
+	}
+	if req.IsAktif != nil && !*req.IsAktif {
+		config.DB.Model(&jenis).Update("is_aktif", false)
+		jenis.IsAktif = false
+	}
+	utils.ResponseCreated(c, "Jenis pelanggaran berhasil ditambahkan", jenis)
+}
+
+// UpdateJenisPelanggaran godoc
+// @Summary Ubah jenis pelanggaran. Perubahan poin tidak mengubah pelanggaran yang sudah tercatat.
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param id path int true "Jenis Pelanggaran ID"
+// @Param body body JenisPelanggaranRequest true "Data jenis pelanggaran"
+// @Router /kedisiplinan/jenis/{id} [put]
+func UpdateJenisPelanggaran(c *gin.Context) {
+	var jenis models.JenisPelanggaran
+	if err := config.DB.First(&jenis, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Jenis pelanggaran tidak ditemukan")
+		return
+	}
+	var req JenisPelanggaranRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	var count int64
+	config.DB.Model(&models.JenisPelanggaran{}).Where("kode = ? AND id <> ?", req.Kode, jenis.ID).Count(&count)
+	if count > 0 {
+		utils.ResponseBadRequest(c, "Kode pelanggaran sudah digunakan", nil)
+		return
+	}
+
+	jenis.Kode = req.Kode
+	jenis.Nama = req.Nama
+	jenis.Kategori = req.Kategori
+	jenis.Poin = req.Poin
+	jenis.Deskripsi = req.Deskripsi
+	if req.IsAktif != nil {
+		jenis.IsAktif = *req.IsAktif
+	}
+	if err := config.DB.Save(&jenis).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal memperbarui jenis pelanggaran")
+		return
+	}
+	utils.ResponseOK(c, "Jenis pelanggaran berhasil diperbarui", jenis)
+}
+
+// DeleteJenisPelanggaran godoc
+// @Summary Hapus jenis pelanggaran. Jenis yang sudah dipakai hanya dinonaktifkan.
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param id path int true "Jenis Pelanggaran ID"
+// @Router /kedisiplinan/jenis/{id} [delete]
+func DeleteJenisPelanggaran(c *gin.Context) {
+	var jenis models.JenisPelanggaran
+	if err := config.DB.First(&jenis, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Jenis pelanggaran tidak ditemukan")
+		return
+	}
+	var dipakai int64
+	config.DB.Model(&models.PelanggaranSiswa{}).Where("jenis_pelanggaran_id = ?", jenis.ID).Count(&dipakai)
+	if dipakai > 0 {
+		config.DB.Model(&jenis).Update("is_aktif", false)
+		jenis.IsAktif = false
+		utils.ResponseOK(c, "Jenis pelanggaran sudah dipakai sehingga hanya dinonaktifkan", jenis)
+		return
+	}
+	if err := config.DB.Delete(&jenis).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menghapus jenis pelanggaran")
+		return
+	}
+	utils.ResponseOK(c, "Jenis pelanggaran berhasil dihapus", nil)
+}
+
+// ── Ambang Poin ───────────────────────────────────────────────
+
+// GetAmbangPoin godoc
+// @Summary Daftar ambang poin dan sanksinya
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Router /kedisiplinan/ambang [get]
+func GetAmbangPoin(c *gin.Context) {
+	var list []models.AmbangPoin
+	config.DB.Order("poin ASC").Find(&list)
+	utils.ResponseOK(c, "Daftar ambang poin", list)
+}
+
+// CreateAmbangPoin godoc
+// @Summary Tambah ambang poin pemicu sanksi
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param body body AmbangPoinRequest true "Data ambang poin"
+// @Router /kedisiplinan/ambang [post]
+func CreateAmbangPoin(c *gin.Context) {
+	var req AmbangPoinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	var count int64
+	config.DB.Model(&models.AmbangPoin{}).Where("poin = ?", req.Poin).Count(&count)
+	if count > 0 {
+		utils.ResponseBadRequest(c, "Ambang dengan poin yang sama sudah ada", nil)
+		return
+	}
+
+	ambang := models.AmbangPoin{Poin: req.Poin, Nama: req.Nama, Sanksi: req.Sanksi, KirimOrangTua: true}
+	if err := config.DB.Create(&ambang).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan ambang poin")
+		return
+	}
+	if req.KirimOrangTua != nil && !*req.KirimOrangTua {
+		config.DB.Model(&ambang).Update("kirim_orang_tua", false)
+		ambang.KirimOrangTua = false
+	}
+	utils.ResponseCreated(c, "Ambang poin berhasil ditambahkan", ambang)
+}
+
+// UpdateAmbangPoin godoc
+// @Summary Ubah ambang poin. Sanksi yang sudah tercatat tidak dihitung ulang.
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param id path int true "Ambang Poin ID"
+// @Param body body AmbangPoinRequest true "Data ambang poin"
+// @Router /kedisiplinan/ambang/{id} [put]
+func UpdateAmbangPoin(c *gin.Context) {
+	var ambang models.AmbangPoin
+	if err := config.DB.First(&ambang, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Ambang poin tidak ditemukan")
+		return
+	}
+	var req AmbangPoinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	var count int64
+	config.DB.Model(&models.AmbangPoin{}).Where("poin = ? AND id <> ?", req.Poin, ambang.ID).Count(&count)
+	if count > 0 {
+		utils.ResponseBadRequest(c, "Ambang dengan poin yang sama sudah ada", nil)
+		return
+	}
+
+	ambang.Poin = req.Poin
+	ambang.Nama = req.Nama
+	ambang.Sanksi = req.Sanksi
+	if req.KirimOrangTua != nil {
+		ambang.KirimOrangTua = *req.KirimOrangTua
+	}
+	if err := config.DB.Save(&ambang).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal memperbarui ambang poin")
+		return
+	}
+	utils.ResponseOK(c, "Ambang poin berhasil diperbarui", ambang)
+}
+
+// DeleteAmbangPoin godoc
+// @Summary Hapus ambang poin yang belum pernah memicu sanksi
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param id path int true "Ambang Poin ID"
+// @Router /kedisiplinan/ambang/{id} [delete]
+func DeleteAmbangPoin(c *gin.Context) {
+	var ambang models.AmbangPoin
+	if err := config.DB.First(&ambang, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Ambang poin tidak ditemukan")
+		return
+	}
+	var dipakai int64
+	config.DB.Model(&models.SanksiSiswa{}).Where("ambang_poin_id = ?", ambang.ID).Count(&dipakai)
+	if dipakai > 0 {
+		utils.ResponseBadRequest(c, "Ambang poin sudah memicu sanksi dan tidak dapat dihapus", nil)
+		return
+	}
+	if err := config.DB.Delete(&ambang).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menghapus ambang poin")
+		return
+	}
+	utils.ResponseOK(c, "Ambang poin berhasil dihapus", nil)
+}
+
+// ── Pelanggaran Siswa ─────────────────────────────────────────
+
+// GetPelanggaran godoc
+// @Summary List pelanggaran siswa
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param siswa_id query int false "Filter siswa"
+// @Param kelas_id query int false "Filter kelas"
+// @Param semester_id query int false "Filter semester"
+// @Param jenis_pelanggaran_id query int false "Filter jenis pelanggaran"
+// @Param page query int false "Halaman" default(1)
+// @Param limit query int false "Jumlah per halaman" default(20)
+// @Router /kedisiplinan/pelanggaran [get]
+func GetPelanggaran(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	if page < 1 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	query, ok := queryPelanggaranTerlihat(c)
+	if !ok {
+		return
+	}
+	if siswaID := c.Query("siswa_id"); siswaID != "" {
+		query = query.Where("pelanggaran_siswas.siswa_id = ?", siswaID)
+	}
+	if kelasID := c.Query("kelas_id"); kelasID != "" {
+		query = query.Where("siswas.kelas_id = ?", kelasID)
+	}
+	if semesterID := c.Query("semester_id"); semesterID != "" {
+		query = query.Where("pelanggaran_siswas.semester_id = ?", semesterID)
+	}
+	if jenisID := c.Query("jenis_pelanggaran_id"); jenisID != "" {
+		query = query.Where("pelanggaran_siswas.jenis_pelanggaran_id = ?", jenisID)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var list []models.PelanggaranSiswa
+	if err := query.Preload("Siswa.Kelas").Preload("JenisPelanggaran").Preload("Pelapor").
+		Offset(offset).Limit(limit).
+		Order("pelanggaran_siswas.tanggal DESC, pelanggaran_siswas.id DESC").
+		Find(&list).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal mengambil data pelanggaran")
+		return
+	}
+	utils.ResponsePaginated(c, "Daftar pelanggaran siswa", list, page, limit, total)
+}
+
+// CreatePelanggaran godoc
+// @Summary Catat pelanggaran siswa. Jika akumulasi poin melewati ambang, sanksi dibuat dan orang tua diberi tahu.
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param body body PelanggaranRequest true "Data pelanggaran"
+// @Router /kedisiplinan/pelanggaran [post]
+func CreatePelanggaran(c *gin.Context) {
+	var req PelanggaranRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	guru, ok := guruLogin(c)
+	if !ok {
+		return
+	}
+	tanggal, ok := parseTanggalKedisiplinan(c, req.Tanggal)
+	if !ok {
+		return
+	}
+
+	var siswa models.Siswa
+	if err := config.DB.First(&siswa, req.SiswaID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Siswa tidak ditemukan", nil)
+		return
+	}
+	if req.SemesterID == 0 {
+		var aktif models.Semester
+		if err := config.DB.Where("is_aktif = ?", true).First(&aktif).Error; err != nil {
+			utils.ResponseBadRequest(c, "Tidak ada semester aktif, isi semester_id", nil)
+			return
+		}
+		req.SemesterID = aktif.ID
+	}
+
+	pelanggaran := models.PelanggaranSiswa{
+		SiswaID:            siswa.ID,
+		JenisPelanggaranID: req.JenisPelanggaranID,
+		SemesterID:         req.SemesterID,
+		Tanggal:            tanggal,
+		Kronologi:          req.Kronologi,
+		PelaporGuruID:      guru.ID,
+	}
+	sanksi, err := services.CatatPelanggaran(&pelanggaran)
+	if err != nil {
+		if errors.Is(err, services.ErrJenisPelanggaranNonaktif) || errors.Is(err, services.ErrTanggalDiluarSemester) {
+			utils.ResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.ResponseBadRequest(c, "Semester tidak ditemukan", nil)
+			return
+		}
+		utils.ResponseInternalError(c, "Gagal mencatat pelanggaran")
+		return
+	}
+
+	config.DB.Preload("JenisPelanggaran").First(&pelanggaran, pelanggaran.ID)
+	utils.ResponseCreated(c, "Pelanggaran berhasil dicatat", gin.H{
+		"pelanggaran": pelanggaran,
+		"sanksi_baru": sanksi,
+	})
+}
+
+// UploadBuktiPelanggaran godoc
+// @Summary Unggah bukti pelanggaran (foto atau dokumen)
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Param id path int true "Pelanggaran ID"
+// @Param file formData file true "File bukti (jpg/png/pdf, maks 5MB)"
+// @Router /kedisiplinan/pelanggaran/{id}/bukti [post]
+func UploadBuktiPelanggaran(c *gin.Context) {
+	var pelanggaran models.PelanggaranSiswa
+	if err := config.DB.First(&pelanggaran, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Pelanggaran tidak ditemukan")
+		return
+	}
+	claims := middlewares.GetCurrentUser(c)
+	if claims.Role != models.RoleGuruBK {
+		guru, ok := guruLogin(c)
+		if !ok {
+			return
+		}
+		if pelanggaran.PelaporGuruID != guru.ID {
+			utils.ResponseForbidden(c, "Hanya pelapor atau guru BK yang dapat mengunggah bukti")
+			return
+		}
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		utils.ResponseBadRequest(c, "File tidak ditemukan", err.Error())
+		return
+	}
+	defer file.Close()
+
+	filePath, ok := utils.SimpanUpload(c, header, utils.AturanUpload{
+		Dir:        "storage/pelanggaran",
+		Prefix:     fmt.Sprintf("pelanggaran_%d", pelanggaran.ID),
+		Ekstensi:   []string{".jpg", ".jpeg", ".png", ".pdf"},
+		MaksByte:   maksUkuranBuktiPelanggaran,
+		TipeKonten: []string{"image/jpeg", "image/png", "application/pdf"},
+	})
+	if !ok {
+		return
+	}
+
+	lama := pelanggaran.BuktiPath
+	if err := config.DB.Model(&pelanggaran).Update("bukti_path", filePath).Error; err != nil {
+		os.Remove(filePath)
+		utils.ResponseInternalError(c, "Gagal menyimpan bukti")
+		return
+	}
+	if lama != "" {
+		os.Remove(lama)
+	}
+	utils.ResponseOK(c, "Bukti pelanggaran berhasil diunggah", pelanggaran)
+}
+
+// DownloadBuktiPelanggaran godoc
+// @Summary Unduh bukti pelanggaran (staf yang dapat melihat pelanggaran, siswa bersangkutan, atau orang tuanya)
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param id path int true "Pelanggaran ID"
+// @Router /kedisiplinan/pelanggaran/{id}/bukti [get]
+func DownloadBuktiPelanggaran(c *gin.Context) {
+	var pelanggaran models.PelanggaranSiswa
+	claims := middlewares.GetCurrentUser(c)
+	switch claims.Role {
+	case models.RoleSiswa, models.RoleOrangTua:
+		if err := config.DB.First(&pelanggaran, c.Param("id")).Error; err != nil {
+			utils.ResponseNotFound(c, "Pelanggaran tidak ditemukan")
+			return
+		}
+		if !pelanggaranMilikLogin(claims, pelanggaran.SiswaID) {
+			utils.ResponseNotFound(c, "Pelanggaran tidak ditemukan")
+			return
+		}
+	default:
+		query, ok := queryPelanggaranTerlihat(c)
+		if !ok {
+			return
+		}
+		if err := query.Select("pelanggaran_siswas.*").
+			First(&pelanggaran, "pelanggaran_siswas.id = ?", c.Param("id")).Error; err != nil {
+			utils.ResponseNotFound(c, "Pelanggaran tidak ditemukan")
+			return
+		}
+	}
+	if pelanggaran.BuktiPath == "" {
+		utils.ResponseNotFound(c, "Pelanggaran ini tidak memiliki bukti")
+		return
+	}
+	utils.KirimFile(c, pelanggaran.BuktiPath)
+}
+
+// pelanggaranMilikLogin memeriksa apakah siswa adalah akun siswa yang login
+// atau anak dari orang tua yang login
+func pelanggaranMilikLogin(claims *utils.JWTClaims, siswaID uint) bool {
+	for _, id := range siswaTerkaitLogin(claims) {
+		if id == siswaID {
+			return true
+		}
+	}
+	return false
+}
+
+// DeletePelanggaran godoc
+// @Summary Hapus catatan pelanggaran. Sanksi yang sudah terpicu tidak dibatalkan otomatis.
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param id path int true "Pelanggaran ID"
+// @Router /kedisiplinan/pelanggaran/{id} [delete]
+func DeletePelanggaran(c *gin.Context) {
+	var pelanggaran models.PelanggaranSiswa
+	if err := config.DB.First(&pelanggaran, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Pelanggaran tidak ditemukan")
+		return
+	}
+	claims := middlewares.GetCurrentUser(c)
+	if claims.Role != models.RoleAdmin && claims.Role != models.RoleGuruBK {
+		guru, ok := guruLogin(c)
+		if !ok {
+			return
+		}
+		if pelanggaran.PelaporGuruID != guru.ID {
+			utils.ResponseForbidden(c, "Anda hanya dapat menghapus pelanggaran yang Anda laporkan")
+			return
+		}
+	}
+
+	if err := config.DB.Delete(&pelanggaran).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menghapus pelanggaran")
+		return
+	}
+	if pelanggaran.BuktiPath != "" {
+		os.Remove(pelanggaran.BuktiPath)
+	}
+	utils.ResponseOK(c, "Pelanggaran berhasil dihapus", nil)
+}
+
+// GetRingkasanKedisiplinanSiswa godoc
+// @Summary Ringkasan kedisiplinan seorang siswa: total poin tahun ajaran, predikat semester, dan sanksi
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param siswa_id path int true "Siswa ID"
+// @Param semester_id query int false "Semester (default: semester aktif)"
+// @Router /kedisiplinan/siswa/{siswa_id}/ringkasan [get]
+func GetRingkasanKedisiplinanSiswa(c *gin.Context) {
+	var siswa models.Siswa
+	if err := config.DB.Preload("Kelas").First(&siswa, c.Param("siswa_id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Siswa tidak ditemukan")
+		return
+	}
+	if siswa.Kelas != nil && !pastikanWaliKelas(c, *siswa.Kelas) {
+		return
+	}
+	kirimRingkasanKedisiplinan(c, siswa)
+}
+
+// GetKedisiplinanSaya godoc
+// @Summary Ringkasan kedisiplinan untuk siswa/orang tua yang sedang login
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param siswa_id query int false "Anak yang dipilih (khusus orang tua)"
+// @Param semester_id query int false "Semester (default: semester aktif)"
+// @Router /kedisiplinan/saya [get]
+func GetKedisiplinanSaya(c *gin.Context) {
+	siswaID, ok := siswaMilikLogin(c)
+	if !ok {
+		return
+	}
+	var siswa models.Siswa
+	if err := config.DB.Preload("Kelas").First(&siswa, siswaID).Error; err != nil {
+		utils.ResponseNotFound(c, "Data siswa tidak ditemukan")
+		return
+	}
+	kirimRingkasanKedisiplinan(c, siswa)
+}
+
+func kirimRingkasanKedisiplinan(c *gin.Context, siswa models.Siswa) {
+	var semester models.Semester
+	query := config.DB.Preload("TahunAjaran")
+	if semesterID := c.Query("semester_id"); semesterID != "" {
+		query = query.Where("id = ?", semesterID)
+	} else {
+		query = query.Where("is_aktif = ?", true)
+	}
+	if err := query.First(&semester).Error; err != nil {
+		utils.ResponseNotFound(c, "Semester tidak ditemukan")
+		return
+	}
+
+	var pelanggaran []models.PelanggaranSiswa
+	config.DB.Preload("JenisPelanggaran").Preload("Pelapor").
+		Where("siswa_id = ? AND semester_id = ?", siswa.ID, semester.ID).
+		Order("tanggal DESC").
+		Find(&pelanggaran)
+
+	var sanksi []models.SanksiSiswa
+	config.DB.Preload("AmbangPoin").
+		Where("siswa_id = ? AND tahun_ajaran_id = ?", siswa.ID, semester.TahunAjaranID).
+		Order("created_at ASC").
+		Find(&sanksi)
+
+	utils.ResponseOK(c, "Ringkasan kedisiplinan siswa", gin.H{
+		"siswa":                   siswa,
+		"semester":                semester,
+		"total_poin_tahun_ajaran": services.TotalPoinTahunAjaran(config.DB, siswa.ID, semester.TahunAjaranID),
+		"perilaku":                services.RingkasanPerilakuSiswa(siswa.ID, semester.ID),
+		"pelanggaran":             pelanggaran,
+		"sanksi":                  sanksi,
+	})
+}
+
+// ── Sanksi ────────────────────────────────────────────────────
+
+// GetSanksi godoc
+// @Summary List sanksi yang terpicu dari akumulasi poin
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param siswa_id query int false "Filter siswa"
+// @Param tahun_ajaran_id query int false "Filter tahun ajaran"
+// @Param status query string false "aktif / selesai / batal"
+// @Router /kedisiplinan/sanksi [get]
+func GetSanksi(c *gin.Context) {
+	query := config.DB.Model(&models.SanksiSiswa{}).
+		Joins("JOIN siswas ON siswas.id = sanksi_siswas.siswa_id")
+
+	if middlewares.GetCurrentUser(c).Role == models.RoleWaliKelas {
+		guru, ok := guruLogin(c)
+		if !ok {
+			return
+		}
+		query = query.Where("siswas.kelas_id IN (SELECT id FROM kelas WHERE wali_kelas_id = ?)", guru.ID)
+	}
+	if siswaID := c.Query("siswa_id"); siswaID != "" {
+		query = query.Where("sanksi_siswas.siswa_id = ?", siswaID)
+	}
+	if taID := c.Query("tahun_ajaran_id"); taID != "" {
+		query = query.Where("sanksi_siswas.tahun_ajaran_id = ?", taID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("sanksi_siswas.status = ?", status)
+	}
+
+	var list []models.SanksiSiswa
+	if err := query.Preload("Siswa.Kelas").Preload("AmbangPoin").
+		Order("sanksi_siswas.created_at DESC").
+		Find(&list).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal mengambil data sanksi")
+		return
+	}
+	utils.ResponseOK(c, "Daftar sanksi siswa", list)
+}
+
+// UpdateSanksi godoc
+// @Summary Perbarui status sanksi (selesai dijalani atau dibatalkan)
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param id path int true "Sanksi ID"
+// @Param body body UpdateSanksiRequest true "Status sanksi"
+// @Router /kedisiplinan/sanksi/{id} [put]
+func UpdateSanksi(c *gin.Context) {
+	var sanksi models.SanksiSiswa
+	if err := config.DB.Preload("Siswa.Kelas").Preload("AmbangPoin").First(&sanksi, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Sanksi tidak ditemukan")
+		return
+	}
+	if sanksi.Siswa.Kelas != nil && !pastikanWaliKelas(c, *sanksi.Siswa.Kelas) {
+		return
+	}
+	var req UpdateSanksiRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if err := services.UbahStatusSanksi(&sanksi, req.Status, req.Catatan); err != nil {
+		utils.ResponseInternalError(c, "Gagal memperbarui sanksi")
+		return
+	}
+	utils.ResponseOK(c, "Sanksi berhasil diperbarui", sanksi)
+}
+
+// ── Catatan Konseling (rahasia) ───────────────────────────────
+
+// GetKonseling godoc
+// @Summary List catatan konseling. Guru BK melihat semua, wali kelas hanya siswa di kelasnya.
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param siswa_id query int false "Filter siswa"
+// @Param page query int false "Halaman" default(1)
+// @Param limit query int false "Jumlah per halaman" default(20)
+// @Router /kedisiplinan/konseling [get]
+func GetKonseling(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	if page < 1 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	query := config.DB.Model(&models.CatatanKonseling{}).
+		Joins("JOIN siswas ON siswas.id = catatan_konselings.siswa_id")
+	if middlewares.GetCurrentUser(c).Role == models.RoleWaliKelas {
+		guru, ok := guruLogin(c)
+		if !ok {
+			return
+		}
+		query = query.Where("siswas.kelas_id IN (SELECT id FROM kelas WHERE wali_kelas_id = ?)", guru.ID)
+	}
+	if siswaID := c.Query("siswa_id"); siswaID != "" {
+		query = query.Where("catatan_konselings.siswa_id = ?", siswaID)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var list []models.CatatanKonseling
+	if err := query.Preload("Siswa.Kelas").Preload("Konselor").
+		Offset(offset).Limit(limit).
+		Order("catatan_konselings.tanggal DESC, catatan_konselings.id DESC").
+		Find(&list).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal mengambil catatan konseling")
+		return
+	}
+	utils.ResponsePaginated(c, "Daftar catatan konseling", list, page, limit, total)
+}
+
+// GetKonselingByID godoc
+// @Summary Detail catatan konseling
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param id path int true "Catatan Konseling ID"
+// @Router /kedisiplinan/konseling/{id} [get]
+func GetKonselingByID(c *gin.Context) {
+	var catatan models.CatatanKonseling
+	if err := config.DB.Preload("Siswa.Kelas").Preload("Konselor").First(&catatan, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Catatan konseling tidak ditemukan")
+		return
+	}
+	if !pastikanAksesKonseling(c, catatan.SiswaID) {
+		return
+	}
+	utils.ResponseOK(c, "Detail catatan konseling", catatan)
+}
+
+// CreateKonseling godoc
+// @Summary Tambah catatan konseling (khusus guru BK)
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param body body KonselingRequest true "Data konseling"
+// @Router /kedisiplinan/konseling [post]
+func CreateKonseling(c *gin.Context) {
+	var req KonselingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	guru, ok := guruLogin(c)
+	if !ok {
+		return
+	}
+	tanggal, ok := parseTanggalKedisiplinan(c, req.Tanggal)
+	if !ok {
+		return
+	}
+	var count int64
+	config.DB.Model(&models.Siswa{}).Where("id = ?", req.SiswaID).Count(&count)
+	if count == 0 {
+		utils.ResponseBadRequest(c, "Siswa tidak ditemukan", nil)
+		return
+	}
+	if req.PelanggaranID != nil {
+		config.DB.Model(&models.PelanggaranSiswa{}).
+			Where("id = ? AND siswa_id = ?", *req.PelanggaranID, req.SiswaID).Count(&count)
+		if count == 0 {
+			utils.ResponseBadRequest(c, "Pelanggaran tidak ditemukan untuk siswa ini", nil)
+			return
+		}
+	}
+
+	catatan := models.CatatanKonseling{
+		SiswaID:        req.SiswaID,
+		KonselorGuruID: guru.ID,
+		Tanggal:        tanggal,
+		Bidang:         req.Bidang,
+		Permasalahan:   req.Permasalahan,
+		Penanganan:     req.Penanganan,
+		TindakLanjut:   req.TindakLanjut,
+		PelanggaranID:  req.PelanggaranID,
+	}
+	if err := config.DB.Create(&catatan).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan catatan konseling")
+		return
+	}
+	utils.ResponseCreated(c, "Catatan konseling berhasil disimpan", catatan)
+}
+
+// UpdateKonseling godoc
+// @Summary Ubah catatan konseling (hanya konselor yang menulisnya)
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param id path int true "Catatan Konseling ID"
+// @Param body body KonselingRequest true "Data konseling"
+// @Router /kedisiplinan/konseling/{id} [put]
+func UpdateKonseling(c *gin.Context) {
+	catatan, ok := ambilKonselingMilikSaya(c)
+	if !ok {
+		return
+	}
+	var req KonselingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if req.SiswaID != catatan.SiswaID {
+		utils.ResponseBadRequest(c, "Siswa pada catatan konseling tidak dapat diubah", nil)
+		return
+	}
+	tanggal, ok := parseTanggalKedisiplinan(c, req.Tanggal)
+	if !ok {
+		return
+	}
+
+	catatan.Tanggal = tanggal
+	catatan.Bidang = req.Bidang
+	catatan.Permasalahan = req.Permasalahan
+	catatan.Penanganan = req.Penanganan
+	catatan.TindakLanjut = req.TindakLanjut
+	if err := config.DB.Model(&catatan).
+		Select("tanggal", "bidang", "permasalahan", "penanganan", "tindak_lanjut").
+		Updates(&catatan).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal memperbarui catatan konseling")
+		return
+	}
+	utils.ResponseOK(c, "Catatan konseling berhasil diperbarui", catatan)
+}
+
+// DeleteKonseling godoc
+// @Summary Hapus catatan konseling (hanya konselor yang menulisnya)
+// @Tags Kedisiplinan
+// @Security BearerAuth
+// @Param id path int true "Catatan Konseling ID"
+// @Router /kedisiplinan/konseling/{id} [delete]
+func DeleteKonseling(c *gin.Context) {
+	catatan, ok := ambilKonselingMilikSaya(c)
+	if !ok {
+		return
+	}
+	if err := config.DB.Delete(&catatan).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menghapus catatan konseling")
+		return
+	}
+	utils.ResponseOK(c, "Catatan konseling berhasil dihapus", nil)
+}
+
+// ambilKonselingMilikSaya memuat catatan konseling yang ditulis guru BK yang login
+func ambilKonselingMilikSaya(c *gin.Context) (models.CatatanKonseling, bool) {
+	var catatan models.CatatanKonseling
+	guru, ok := guruLogin(c)
+	if !ok {
+		return catatan, false
+	}
+	if err := config.DB.First(&catatan, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Catatan konseling tidak ditemukan")
+		return catatan, false
+	}
+	if catatan.KonselorGuruID != guru.ID {
+		utils.ResponseForbidden(c, "Anda hanya dapat mengubah catatan konseling milik sendiri")
+		return catatan, false
+	}
+	return catatan, true
+}
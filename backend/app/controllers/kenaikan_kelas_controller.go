@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sim-sekolah/app/middlewares"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// GetProsesKenaikan godoc
+// @Summary Daftar proses kenaikan kelas
+// @Tags Kenaikan Kelas
+// @Security BearerAuth
+// @Router /kenaikan-kelas [get]
+func GetProsesKenaikan(c *gin.Context) {
+	var list []models.ProsesKenaikan
+	if err := config.DB.Preload("TahunAjaranAsal").Preload("TahunAjaranTujuan").
+		Order("created_at DESC").Find(&list).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal mengambil data proses kenaikan")
+		return
+	}
+	utils.ResponseOK(c, "Daftar proses kenaikan kelas", list)
+}
+
+// GetProsesKenaikanByID godoc
+// @Summary Detail proses kenaikan kelas beserta pemetaan kelas
+// @Tags Kenaikan Kelas
+// @Security BearerAuth
+// @Param id path int true "ID"
+// @Router /kenaikan-kelas/{id} [get]
+func GetProsesKenaikanByID(c *gin.Context) {
+	var proses models.ProsesKenaikan
+	if err := config.DB.Preload("TahunAjaranAsal").Preload("TahunAjaranTujuan").
+		Preload("Pemetaan", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Pemetaan.KelasAsal").Preload("Pemetaan.KelasTujuan").
+		First(&proses, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Proses kenaikan tidak ditemukan")
+		return
+	}
+
+	var keputusan []models.KeputusanKenaikan
+	config.DB.Preload("Siswa").Where("proses_kenaikan_id = ?", proses.ID).Find(&keputusan)
+
+	utils.ResponseOK(c, "Detail proses kenaikan kelas", gin.H{
+		"proses":    proses,
+		"keputusan": keputusan,
+	})
+}
+
+// CreateProsesKenaikan godoc
+// @Summary Buat proses kenaikan kelas dengan usulan pemetaan otomatis
+// @Tags Kenaikan Kelas
+// @Security BearerAuth
+// @Router /kenaikan-kelas [post]
+func CreateProsesKenaikan(c *gin.Context) {
+	var req struct {
+		TahunAjaranAsalID   uint `json:"tahun_ajaran_asal_id" binding:"required"`
+		TahunAjaranTujuanID uint `json:"tahun_ajaran_tujuan_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if req.TahunAjaranAsalID == req.TahunAjaranTujuanID {
+		utils.ResponseBadRequest(c, "Tahun ajaran asal dan tujuan tidak boleh sama", nil)
+		return
+	}
+
+	var asal, tujuan models.TahunAjaran
+	if err := config.DB.First(&asal, req.TahunAjaranAsalID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Tahun ajaran asal tidak ditemukan", nil)
+		return
+	}
+	if err := config.DB.First(&tujuan, req.TahunAjaranTujuanID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Tahun ajaran tujuan tidak ditemukan", nil)
+		return
+	}
+
+	// Satu tahun ajaran asal hanya boleh punya satu proses
+	var existing models.ProsesKenaikan
+	if err := config.DB.Where("tahun_ajaran_asal_id = ?", asal.ID).First(&existing).Error; err == nil {
+		utils.ResponseBadRequest(c, "Proses kenaikan untuk tahun ajaran ini sudah ada", gin.H{"id": existing.ID})
+		return
+	}
+
+	proses := models.ProsesKenaikan{
+		TahunAjaranAsalID:   asal.ID,
+		TahunAjaranTujuanID: tujuan.ID,
+		Status:              models.ProsesKenaikanDraft,
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&proses).Error; err != nil {
+			return err
+		}
+		return services.UsulkanPemetaan(tx, &proses)
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal membuat proses kenaikan")
+		return
+	}
+
+	config.DB.Preload("TahunAjaranAsal").Preload("TahunAjaranTujuan").
+		Preload("Pemetaan.KelasAsal").Preload("Pemetaan.KelasTujuan").
+		First(&proses, proses.ID)
+	utils.ResponseCreated(c, "Proses kenaikan kelas berhasil dibuat", proses)
+}
+
+// UpdatePemetaanKenaikan godoc
+// @Summary Ubah kelas tujuan pada pemetaan kenaikan
+// @Tags Kenaikan Kelas
+// @Security BearerAuth
+// @Param id path int true "ID proses"
+// @Param pemetaan_id path int true "ID pemetaan"
+// @Router /kenaikan-kelas/{id}/pemetaan/{pemetaan_id} [put]
+func UpdatePemetaanKenaikan(c *gin.Context) {
+	proses, ok := ambilProsesDraft(c)
+	if !ok {
+		return
+	}
+
+	var pemetaan models.PemetaanKelasKenaikan
+	if err := config.DB.Where("id = ? AND proses_kenaikan_id = ?", c.Param("pemetaan_id"), proses.ID).
+		First(&pemetaan).Error; err != nil {
+		utils.ResponseNotFound(c, "Pemetaan tidak ditemukan")
+		return
+	}
+
+	var req struct {
+		KelasTujuanID *uint `json:"kelas_tujuan_id"`
+		Lulus         bool  `json:"lulus"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	if req.Lulus {
+		req.KelasTujuanID = nil
+	} else if req.KelasTujuanID != nil {
+		var kelas models.Kelas
+		if err := config.DB.Where("id = ? AND tahun_ajaran_id = ?", *req.KelasTujuanID, proses.TahunAjaranTujuanID).
+			First(&kelas).Error; err != nil {
+			utils.ResponseBadRequest(c, "Kelas tujuan harus berada di tahun ajaran tujuan", nil)
+			return
+		}
+	}
+
+	if err := config.DB.Model(&pemetaan).Updates(map[string]interface{}{
+		"kelas_tujuan_id": req.KelasTujuanID,
+		"lulus":           req.Lulus,
+	}).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal mengupdate pemetaan")
+		return
+	}
+
+	config.DB.Preload("KelasAsal").Preload("KelasTujuan").First(&pemetaan, pemetaan.ID)
+	utils.ResponseOK(c, "Pemetaan berhasil diupdate", pemetaan)
+}
+
+// SetKeputusanKenaikan godoc
+// @Summary Tetapkan keputusan naik / tinggal kelas untuk seorang siswa
+// @Description Wali kelas hanya dapat memutuskan siswa di kelas perwaliannya
+// @Tags Kenaikan Kelas
+// @Security BearerAuth
+// @Param id path int true "ID proses"
+// @Router /kenaikan-kelas/{id}/keputusan [put]
+func SetKeputusanKenaikan(c *gin.Context) {
+	claims := middlewares.GetCurrentUser(c)
+	proses, ok := ambilProsesDraft(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		SiswaID       uint   `json:"siswa_id" binding:"required"`
+		Keputusan     string `json:"keputusan" binding:"required,oneof=naik tinggal"`
+		KelasTujuanID *uint  `json:"kelas_tujuan_id"`
+		Catatan       string `json:"catatan"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	var siswa models.Siswa
+	if err := config.DB.Preload("Kelas").First(&siswa, req.SiswaID).Error; err != nil {
+		utils.ResponseNotFound(c, "Siswa tidak ditemukan")
+		return
+	}
+	if siswa.Kelas == nil || siswa.Kelas.TahunAjaranID != proses.TahunAjaranAsalID {
+		utils.ResponseBadRequest(c, "Siswa tidak berada di kelas tahun ajaran asal", nil)
+		return
+	}
+
+	if claims.Role == models.RoleWaliKelas {
+		var guru models.Guru
+		if err := config.DB.Where("user_id = ?", claims.UserID).First(&guru).Error; err != nil ||
+			siswa.Kelas.WaliKelasID == nil || *siswa.Kelas.WaliKelasID != guru.ID {
+			utils.ResponseForbidden(c, "Anda bukan wali kelas siswa ini")
+			return
+		}
+	}
+
+	if req.Keputusan == models.KeputusanNaik {
+		req.KelasTujuanID = nil
+	} else if req.KelasTujuanID != nil {
+		var kelas models.Kelas
+		if err := config.DB.Where("id = ? AND tahun_ajaran_id = ?", *req.KelasTujuanID, proses.TahunAjaranTujuanID).
+			First(&kelas).Error; err != nil {
+			utils.ResponseBadRequest(c, "Kelas tujuan harus berada di tahun ajaran tujuan", nil)
+			return
+		}
+	}
+
+	keputusan := models.KeputusanKenaikan{
+		ProsesKenaikanID: proses.ID,
+		SiswaID:          siswa.ID,
+		Keputusan:        req.Keputusan,
+		KelasTujuanID:    req.KelasTujuanID,
+		Catatan:          req.Catatan,
+		DiputuskanOleh:   claims.UserID,
+	}
+	if err := config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "proses_kenaikan_id"}, {Name: "siswa_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"keputusan", "kelas_tujuan_id", "catatan", "diputuskan_oleh", "updated_at"}),
+	}).Create(&keputusan).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan keputusan")
+		return
+	}
+
+	utils.ResponseOK(c, "Keputusan kenaikan berhasil disimpan", keputusan)
+}
+
+// GetPreviewKenaikan godoc
+// @Summary Preview hasil kenaikan kelas sebelum diterapkan
+// @Tags Kenaikan Kelas
+// @Security BearerAuth
+// @Param id path int true "ID proses"
+// @Router /kenaikan-kelas/{id}/preview [get]
+func GetPreviewKenaikan(c *gin.Context) {
+	var proses models.ProsesKenaikan
+	if err := config.DB.First(&proses, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Proses kenaikan tidak ditemukan")
+		return
+	}
+
+	preview, err := services.SusunPreviewKenaikan(config.DB, proses)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menyusun preview kenaikan")
+		return
+	}
+	utils.ResponseOK(c, "Preview kenaikan kelas", preview)
+}
+
+// TerapkanKenaikan godoc
+// @Summary Terapkan kenaikan kelas dan kelulusan secara transaksional
+// @Tags Kenaikan Kelas
+// @Security BearerAuth
+// @Param id path int true "ID proses"
+// @Router /kenaikan-kelas/{id}/terapkan [post]
+func TerapkanKenaikan(c *gin.Context) {
+	claims := middlewares.GetCurrentUser(c)
+	proses, ok := ambilProsesDraft(c)
+	if !ok {
+		return
+	}
+
+	hasil, err := services.TerapkanKenaikan(proses.ID, claims.UserID)
+	switch err {
+	case nil:
+	case services.ErrProsesSudahDiterapkan:
+		utils.ResponseBadRequest(c, "Proses kenaikan sudah diterapkan", nil)
+		return
+	case services.ErrPreviewBermasalah:
+		utils.ResponseBadRequest(c, "Masih ada siswa yang belum memiliki kelas tujuan, periksa preview", hasil)
+		return
+	default:
+		utils.ResponseInternalError(c, "Gagal menerapkan kenaikan kelas")
+		return
+	}
+
+	utils.ResponseOK(c, "Kenaikan kelas berhasil diterapkan", gin.H{
+		"jumlah_naik":    hasil.JumlahNaik,
+		"jumlah_tinggal": hasil.JumlahTinggal,
+		"jumlah_lulus":   hasil.JumlahLulus,
+	})
+}
+
+// DeleteProsesKenaikan godoc
+// @Summary Hapus proses kenaikan yang masih draft
+// @Tags Kenaikan Kelas
+// @Security BearerAuth
+// @Param id path int true "ID proses"
+// @Router /kenaikan-kelas/{id} [delete]
+func DeleteProsesKenaikan(c *gin.Context) {
+	proses, ok := ambilProsesDraft(c)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("proses_kenaikan_id = ?", proses.ID).Delete(&models.KeputusanKenaikan{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("proses_kenaikan_id = ?", proses.ID).Delete(&models.PemetaanKelasKenaikan{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&proses).Error
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghapus proses kenaikan")
+		return
+	}
+	utils.ResponseOK(c, "Proses kenaikan berhasil dihapus", nil)
+}
+
+// ambilProsesDraft memuat proses dari parameter :id dan memastikan masih draft
+func ambilProsesDraft(c *gin.Context) (models.ProsesKenaikan, bool) {
+	var proses models.ProsesKenaikan
+	if err := config.DB.First(&proses, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Proses kenaikan tidak ditemukan")
+		return proses, false
+	}
+	if proses.Status != models.ProsesKenaikanDraft {
+		utils.ResponseBadRequest(c, "Proses kenaikan sudah diterapkan dan tidak dapat diubah", nil)
+		return proses, false
+	}
+	return proses, true
+}
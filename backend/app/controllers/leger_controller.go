@@ -0,0 +1,301 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/xuri/excelize/v2"
+	"sim-sekolah/app/middlewares"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// GetLegerKelas godoc
+// @Summary Leger nilai kelas: seluruh siswa × mapel beserta jumlah, rata-rata dan peringkat
+// @Tags Nilai
+// @Security BearerAuth
+// @Param kelas_id path int true "Kelas ID"
+// @Param semester_id query int true "Semester ID"
+// @Param format query string false "json (default) / pdf / xlsx"
+// @Router /nilai/leger/kelas/{kelas_id} [get]
+func GetLegerKelas(c *gin.Context) {
+	var kelas models.Kelas
+	if err := config.DB.Preload("Jurusan").Preload("WaliKelas").First(&kelas, c.Param("kelas_id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Kelas tidak ditemukan")
+		return
+	}
+	semesterID := c.Query("semester_id")
+	if semesterID == "" {
+		utils.ResponseBadRequest(c, "Parameter semester_id wajib diisi", nil)
+		return
+	}
+	var semester models.Semester
+	if err := config.DB.Preload("TahunAjaran").First(&semester, semesterID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Semester tidak ditemukan", nil)
+		return
+	}
+	if kelas.TahunAjaranID != semester.TahunAjaranID {
+		utils.ResponseBadRequest(c, "Kelas dan semester berasal dari tahun ajaran yang berbeda", nil)
+		return
+	}
+	if !pastikanWaliKelas(c, kelas) {
+		return
+	}
+
+	leger, err := services.HitungLeger(kelas, semester)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghitung leger nilai")
+		return
+	}
+
+	namaFile := fmt.Sprintf("leger_%s_%s_%s", namaFileAman(kelas.Nama),
+		namaFileAman(semester.TahunAjaran.Nama), namaFileAman(semester.Nama))
+	switch c.Query("format") {
+	case "pdf":
+		var buf bytes.Buffer
+		if err := renderLegerPDF(leger, services.ProfilSekolah()).Output(&buf); err != nil {
+			utils.ResponseInternalError(c, "Gagal membuat PDF leger")
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename="+namaFile+".pdf")
+		c.Data(200, "application/pdf", buf.Bytes())
+	case "xlsx":
+		f, err := renderLegerXLSX(leger)
+		if err != nil {
+			utils.ResponseInternalError(c, "Gagal membuat file Excel leger")
+			return
+		}
+		defer f.Close()
+		buf, err := f.WriteToBuffer()
+		if err != nil {
+			utils.ResponseInternalError(c, "Gagal membuat file Excel leger")
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename="+namaFile+".xlsx")
+		c.Data(200, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", buf.Bytes())
+	default:
+		utils.ResponseOK(c, "Leger nilai kelas "+kelas.Nama, leger)
+	}
+}
+
+// pastikanWaliKelas menolak wali kelas yang mengakses kelas selain kelasnya
+// sendiri. Role lain diteruskan (pembatasan role diatur di routes).
+func pastikanWaliKelas(c *gin.Context, kelas models.Kelas) bool {
+	claims := middlewares.GetCurrentUser(c)
+	if claims.Role != models.RoleWaliKelas {
+		return true
+	}
+	guru, ok := guruLogin(c)
+	if !ok {
+		return false
+	}
+	if kelas.WaliKelasID == nil || *kelas.WaliKelasID != guru.ID {
+		utils.ResponseForbidden(c, "Anda bukan wali kelas "+kelas.Nama)
+		return false
+	}
+	return true
+}
+
+func formatNilaiLeger(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// ── PDF ───────────────────────────────────────────────────────
+
+func renderLegerPDF(leger services.Leger, sekolah models.ProfilSekolah) *gofpdf.Fpdf {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 12)
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 13)
+	pdf.CellFormat(0, 7, "LEGER NILAI", "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	if sekolah.Nama != "" {
+		pdf.CellFormat(0, 5, sekolah.Nama, "", 1, "C", false, 0, "")
+	}
+	pdf.CellFormat(0, 5, fmt.Sprintf("Kelas %s - Tahun Ajaran %s - Semester %s",
+		leger.Kelas.Nama, leger.Semester.TahunAjaran.Nama, leger.Semester.Nama), "", 1, "C", false, 0, "")
+	pdf.Ln(3)
+
+	lebarHalaman, _ := pdf.GetPageSize()
+	const wNo, wNISN, wNama, wJumlah, wRata, wRank = 8.0, 22.0, 48.0, 16.0, 16.0, 12.0
+	sisa := lebarHalaman - 20 - wNo - wNISN - wNama - wJumlah - wRata - wRank
+	wMapel := sisa
+	if len(leger.Mapel) > 0 {
+		wMapel = sisa / float64(len(leger.Mapel))
+	}
+
+	header := func() {
+		pdf.SetFont("Arial", "B", 7)
+		pdf.SetFillColor(220, 220, 220)
+		pdf.CellFormat(wNo, 6, "No", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(wNISN, 6, "NISN", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(wNama, 6, "Nama Siswa", "1", 0, "C", true, 0, "")
+		for _, m := range leger.Mapel {
+			pdf.CellFormat(wMapel, 6, m.Kode, "1", 0, "C", true, 0, "")
+		}
+		pdf.CellFormat(wJumlah, 6, "Jumlah", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(wRata, 6, "Rata-rata", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(wRank, 6, "Rank", "1", 1, "C", true, 0, "")
+		pdf.SetFont("Arial", "", 7)
+	}
+	header()
+
+	_, tinggiHalaman := pdf.GetPageSize()
+	for i, b := range leger.Baris {
+		if pdf.GetY()+5 > tinggiHalaman-12 {
+			pdf.AddPage()
+			header()
+		}
+		pdf.CellFormat(wNo, 5, strconv.Itoa(i+1), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(wNISN, 5, b.NISN, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(wNama, 5, b.Nama, "1", 0, "L", false, 0, "")
+		for _, m := range leger.Mapel {
+			isi := "-"
+			if v, ok := b.Nilai[m.ID]; ok {
+				isi = fmt.Sprintf("%.0f", v)
+				if v < m.KKM {
+					pdf.SetTextColor(200, 0, 0)
+				}
+			}
+			pdf.CellFormat(wMapel, 5, isi, "1", 0, "C", false, 0, "")
+			pdf.SetTextColor(0, 0, 0)
+		}
+		pdf.CellFormat(wJumlah, 5, fmt.Sprintf("%.0f", b.Jumlah), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(wRata, 5, formatNilaiLeger(b.RataRata), "1", 0, "C", false, 0, "")
+		rank := "-"
+		if b.Peringkat != nil {
+			rank = strconv.Itoa(*b.Peringkat)
+		}
+		pdf.CellFormat(wRank, 5, rank, "1", 1, "C", false, 0, "")
+	}
+
+	// Rata-rata kelas per mapel
+	pdf.SetFont("Arial", "B", 7)
+	pdf.CellFormat(wNo+wNISN+wNama, 5, "Rata-rata kelas", "1", 0, "R", false, 0, "")
+	for _, m := range leger.Mapel {
+		isi := "-"
+		if v, ok := leger.RataRataMapel[m.ID]; ok {
+			isi = fmt.Sprintf("%.1f", v)
+		}
+		pdf.CellFormat(wMapel, 5, isi, "1", 0, "C", false, 0, "")
+	}
+	pdf.CellFormat(wJumlah, 5, "", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(wRata, 5, formatNilaiLeger(leger.RataRataKelas), "1", 0, "C", false, 0, "")
+	pdf.CellFormat(wRank, 5, "", "1", 1, "C", false, 0, "")
+
+	// Keterangan kode mapel
+	pdf.Ln(4)
+	pdf.SetFont("Arial", "B", 7)
+	pdf.Cell(0, 4, "Keterangan:")
+	pdf.Ln(4)
+	pdf.SetFont("Arial", "", 7)
+	for _, m := range leger.Mapel {
+		pdf.Cell(0, 4, fmt.Sprintf("%s = %s (KKM %.0f)", m.Kode, m.Nama, m.KKM))
+		pdf.Ln(3.5)
+	}
+	pdf.Cell(0, 4, "Nilai berwarna merah berada di bawah KKM. Peringkat berdasarkan rata-rata; rata-rata sama mendapat peringkat sama.")
+	return pdf
+}
+
+// ── XLSX ──────────────────────────────────────────────────────
+
+func renderLegerXLSX(leger services.Leger) (*excelize.File, error) {
+	f := excelize.NewFile()
+	const sheet = "Leger"
+	f.SetSheetName("Sheet1", sheet)
+
+	sel := func(kolom, baris int) string {
+		nama, _ := excelize.CoordinatesToCellName(kolom, baris)
+		return nama
+	}
+
+	judul, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 13}})
+	tebal, _ := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"DDDDDD"}},
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Border:    borderTipis(),
+	})
+	biasa, _ := f.NewStyle(&excelize.Style{Border: borderTipis()})
+	dibawahKKM, _ := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Color: "C00000"},
+		Fill:   excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FDE9E9"}},
+		Border: borderTipis(),
+	})
+
+	f.SetCellValue(sheet, "A1", "LEGER NILAI")
+	f.SetCellStyle(sheet, "A1", "A1", judul)
+	f.SetCellValue(sheet, "A2", fmt.Sprintf("Kelas %s - Tahun Ajaran %s - Semester %s",
+		leger.Kelas.Nama, leger.Semester.TahunAjaran.Nama, leger.Semester.Nama))
+
+	const barisHeader = 4
+	kolomMapel := 4
+	header := []string{"No", "NISN", "Nama Siswa"}
+	for _, m := range leger.Mapel {
+		header = append(header, m.Nama)
+	}
+	header = append(header, "Jumlah", "Rata-rata", "Peringkat")
+	for i, h := range header {
+		f.SetCellValue(sheet, sel(i+1, barisHeader), h)
+	}
+	kolomTerakhir := len(header)
+	f.SetCellStyle(sheet, sel(1, barisHeader), sel(kolomTerakhir, barisHeader), tebal)
+
+	for i, b := range leger.Baris {
+		r := barisHeader + 1 + i
+		f.SetCellValue(sheet, sel(1, r), i+1)
+		f.SetCellValue(sheet, sel(2, r), b.NISN)
+		f.SetCellValue(sheet, sel(3, r), b.Nama)
+		f.SetCellStyle(sheet, sel(1, r), sel(kolomTerakhir, r), biasa)
+		for j, m := range leger.Mapel {
+			if v, ok := b.Nilai[m.ID]; ok {
+				f.SetCellValue(sheet, sel(kolomMapel+j, r), v)
+				if v < m.KKM {
+					f.SetCellStyle(sheet, sel(kolomMapel+j, r), sel(kolomMapel+j, r), dibawahKKM)
+				}
+			}
+		}
+		k := kolomMapel + len(leger.Mapel)
+		f.SetCellValue(sheet, sel(k, r), b.Jumlah)
+		f.SetCellValue(sheet, sel(k+1, r), b.RataRata)
+		if b.Peringkat != nil {
+			f.SetCellValue(sheet, sel(k+2, r), *b.Peringkat)
+		}
+	}
+
+	r := barisHeader + 1 + len(leger.Baris)
+	f.SetCellValue(sheet, sel(3, r), "Rata-rata kelas")
+	for j, m := range leger.Mapel {
+		if v, ok := leger.RataRataMapel[m.ID]; ok {
+			f.SetCellValue(sheet, sel(kolomMapel+j, r), v)
+		}
+	}
+	f.SetCellValue(sheet, sel(kolomMapel+len(leger.Mapel)+1, r), leger.RataRataKelas)
+	f.SetCellStyle(sheet, sel(1, r), sel(kolomTerakhir, r), tebal)
+
+	f.SetColWidth(sheet, "C", "C", 30)
+	f.SetColWidth(sheet, "B", "B", 14)
+	if err := f.SetPanes(sheet, &excelize.Panes{
+		Freeze: true, XSplit: 3, YSplit: barisHeader,
+		TopLeftCell: sel(4, barisHeader+1), ActivePane: "bottomRight",
+	}); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func borderTipis() []excelize.Border {
+	return []excelize.Border{
+		{Type: "left", Color: "999999", Style: 1},
+		{Type: "right", Color: "999999", Style: 1},
+		{Type: "top", Color: "999999", Style: 1},
+		{Type: "bottom", Color: "999999", Style: 1},
+	}
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// GetLoginLockouts godoc
+// @Summary Daftar akun / IP yang sedang dikunci karena login gagal berulang
+// @Tags Auth
+// @Security BearerAuth
+// @Param semua query bool false "Tampilkan juga yang tidak terkunci (hanya punya hitungan gagal)"
+// @Param tipe query string false "Filter tipe: email/ip"
+// @Router /login-lockouts [get]
+func GetLoginLockouts(c *gin.Context) {
+	query := config.DB.Model(&models.LoginThrottle{})
+
+	if c.Query("semua") != "true" {
+		query = query.Where("terkunci_sampai > ?", time.Now())
+	}
+	if v := c.Query("tipe"); v != "" {
+		query = query.Where("tipe = ?", v)
+	}
+
+	var list []models.LoginThrottle
+	query.Order("terakhir_gagal DESC").Find(&list)
+	utils.ResponseOK(c, "Daftar lockout login", list)
+}
+
+// ClearLoginLockout godoc
+// @Summary Buka kunci login (hapus hitungan gagal) untuk satu email / IP
+// @Tags Auth
+// @Security BearerAuth
+// @Param id path int true "ID"
+// @Router /login-lockouts/{id} [delete]
+func ClearLoginLockout(c *gin.Context) {
+	var t models.LoginThrottle
+	if err := config.DB.First(&t, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Data lockout tidak ditemukan")
+		return
+	}
+
+	if err := config.DB.Delete(&t).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal membuka kunci login")
+		return
+	}
+	utils.ResponseOK(c, "Kunci login untuk "+t.Kunci+" berhasil dibuka", nil)
+}
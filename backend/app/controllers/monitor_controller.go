@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"math"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// GetMonitorKelengkapan godoc
+// @Summary Persentase kelengkapan input absensi dan nilai per guru
+// @Tags Monitor
+// @Security BearerAuth
+// @Param semester_id query int false "Filter semester (default: semester aktif)"
+// @Param kelas_id query int false "Filter kelas"
+// @Param jurusan_id query int false "Filter jurusan"
+// @Param guru_id query int false "Filter guru"
+// @Param komponen query string false "Komponen nilai yang dipantau: harian / uts / uas (default: ketiganya)"
+// @Param dari query string false "Pertemuan mulai tanggal YYYY-MM-DD"
+// @Param sampai query string false "Pertemuan sampai tanggal YYYY-MM-DD"
+// @Router /monitor/kelengkapan [get]
+func GetMonitorKelengkapan(c *gin.Context) {
+	f, ok := filterAnalitik(c, true)
+	if !ok {
+		return
+	}
+	guru, err := services.MonitorKelengkapan(f, false)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghitung kelengkapan input guru")
+		return
+	}
+
+	var pertemuan, pertemuanTerisi, nilai, nilaiTerisi int64
+	belumLengkap := 0
+	for _, g := range guru {
+		pertemuan += g.JumlahPertemuan
+		pertemuanTerisi += g.PertemuanTerisi
+		nilai += g.JumlahNilai
+		nilaiTerisi += g.NilaiTerisi
+		if g.PertemuanKosong > 0 || g.NilaiKurang > 0 {
+			belumLengkap++
+		}
+	}
+	utils.ResponseOK(c, "Monitor kelengkapan input guru", gin.H{
+		"filter":             f,
+		"batas_input_nilai":  batasInputNilai(f.SemesterID),
+		"jumlah_guru":        len(guru),
+		"guru_belum_lengkap": belumLengkap,
+		"total_pertemuan":    pertemuan,
+		"pertemuan_terisi":   pertemuanTerisi,
+		"total_nilai":        nilai,
+		"nilai_terisi":       nilaiTerisi,
+		"persentase_absensi": persenOpsional(pertemuanTerisi, pertemuan),
+		"persentase_nilai":   persenOpsional(nilaiTerisi, nilai),
+		"data":               guru,
+	})
+}
+
+// GetMonitorKelengkapanGuru godoc
+// @Summary Rincian sesi tanpa absensi dan nilai yang belum diinput seorang guru
+// @Tags Monitor
+// @Security BearerAuth
+// @Param guru_id path int true "Guru ID"
+// @Param semester_id query int false "Filter semester (default: semester aktif)"
+// @Router /monitor/kelengkapan/guru/{guru_id} [get]
+func GetMonitorKelengkapanGuru(c *gin.Context) {
+	var guru models.Guru
+	if err := config.DB.First(&guru, c.Param("guru_id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Guru tidak ditemukan")
+		return
+	}
+	kirimKelengkapanGuru(c, guru)
+}
+
+// GetMonitorKelengkapanSaya godoc
+// @Summary Kelengkapan input absensi dan nilai guru yang sedang login
+// @Tags Monitor
+// @Security BearerAuth
+// @Param semester_id query int false "Filter semester (default: semester aktif)"
+// @Router /monitor/kelengkapan/saya [get]
+func GetMonitorKelengkapanSaya(c *gin.Context) {
+	guru, ok := guruLogin(c)
+	if !ok {
+		return
+	}
+	kirimKelengkapanGuru(c, guru)
+}
+
+func kirimKelengkapanGuru(c *gin.Context, guru models.Guru) {
+	f, ok := filterAnalitik(c, true)
+	if !ok {
+		return
+	}
+	f.GuruID = guru.ID
+
+	hasil, err := services.MonitorKelengkapan(f, true)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghitung kelengkapan input guru")
+		return
+	}
+	data := services.KelengkapanGuru{GuruID: guru.ID, Nama: guru.Nama, NIP: guru.NIP}
+	if len(hasil) > 0 {
+		data = hasil[0]
+	}
+	utils.ResponseOK(c, "Kelengkapan input "+guru.Nama, gin.H{
+		"filter":            f,
+		"batas_input_nilai": batasInputNilai(f.SemesterID),
+		"guru":              data,
+	})
+}
+
+// KirimPengingatKelengkapan godoc
+// @Summary Kirim pengingat kelengkapan input ke guru sekarang (tanpa menunggu jadwal harian)
+// @Tags Monitor
+// @Security BearerAuth
+// @Router /monitor/kelengkapan/kirim-pengingat [post]
+func KirimPengingatKelengkapan(c *gin.Context) {
+	job, err := services.AntrikanPengingatKelengkapan()
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal mengantrikan pengingat")
+		return
+	}
+	utils.ResponseOK(c, "Pengingat diantrikan", gin.H{"job_id": job.ID})
+}
+
+func batasInputNilai(semesterID uint) *time.Time {
+	if semesterID == 0 {
+		return nil
+	}
+	var semester models.Semester
+	if err := config.DB.First(&semester, semesterID).Error; err != nil {
+		return nil
+	}
+	return semester.BatasInputNilai
+}
+
+func persenOpsional(bagian, total int64) *float64 {
+	if total == 0 {
+		return nil
+	}
+	p := math.Round(float64(bagian)/float64(total)*10000) / 100
+	return &p
+}
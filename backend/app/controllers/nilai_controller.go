@@ -4,8 +4,10 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"sim-sekolah/app/middlewares"
 	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
 	"sim-sekolah/config"
 	"sim-sekolah/utils"
 )
@@ -101,6 +103,19 @@ func InputNilai(c *gin.Context) {
 		return
 	}
 
+	// Mapel harus termasuk kurikulum kelas siswa pada semester tersebut
+	if kelas := services.KelasSiswaDiSemester(siswa.ID, semester.ID); kelas != nil {
+		if err := services.CekMapelKurikulum(*kelas, mapel.ID); err != nil {
+			utils.ResponseBadRequest(c, "Mata pelajaran "+mapel.Nama+" tidak termasuk kurikulum kelas "+kelas.Nama, nil)
+			return
+		}
+	}
+
+	// Hanya guru pengampu mapel di kelas siswa yang boleh menginput nilai
+	if !pastikanPengampu(c, siswa.ID, semester.ID, mapel.ID) {
+		return
+	}
+
 	// Cek duplikasi: 1 siswa 1 mapel 1 semester = 1 record nilai
 	var existing models.Nilai
 	err := config.DB.Where("siswa_id = ? AND mata_pelajaran_id = ? AND semester_id = ?",
@@ -142,6 +157,9 @@ func UpdateNilai(c *gin.Context) {
 		utils.ResponseNotFound(c, "Data nilai tidak ditemukan")
 		return
 	}
+	if !pastikanPengampu(c, nilai.SiswaID, nilai.SemesterID, nilai.MataPelajaranID) {
+		return
+	}
 
 	var req struct {
 		NilaiHarian float64 `json:"nilai_harian" binding:"omitempty,min=0,max=100"`
@@ -185,10 +203,33 @@ func DeleteNilai(c *gin.Context) {
 		utils.ResponseNotFound(c, "Data nilai tidak ditemukan")
 		return
 	}
+	claims := middlewares.GetCurrentUser(c)
+	if claims.Role != models.RoleAdmin && !pastikanPengampu(c, nilai.SiswaID, nilai.SemesterID, nilai.MataPelajaranID) {
+		return
+	}
 	config.DB.Delete(&nilai)
 	utils.ResponseOK(c, "Nilai berhasil dihapus", nil)
 }
 
+// pastikanPengampu memastikan guru yang login ditugaskan mengampu mapel di kelas
+// siswa pada semester tersebut. Mengirim response 403 dan mengembalikan false jika tidak.
+func pastikanPengampu(c *gin.Context, siswaID, semesterID, mapelID uint) bool {
+	guru, ok := guruLogin(c)
+	if !ok {
+		return false
+	}
+	kelas := services.KelasSiswaDiSemester(siswaID, semesterID)
+	if kelas == nil {
+		utils.ResponseForbidden(c, "Siswa tidak terdaftar di kelas mana pun pada semester ini")
+		return false
+	}
+	if !services.GuruMengampu(guru.ID, semesterID, kelas.ID, mapelID) {
+		utils.ResponseForbidden(c, "Anda bukan guru pengampu mata pelajaran ini di kelas "+kelas.Nama)
+		return false
+	}
+	return true
+}
+
 // ── Rekap & Rapor ─────────────────────────────────────────────
 
 // GetNilaiSiswa godoc
@@ -230,8 +271,15 @@ func GetNilaiSiswa(c *gin.Context) {
 		rataRata = total / float64(len(nilaiList))
 	}
 
+	// Kelas yang berlaku pada semester tersebut, bukan kelas siswa saat ini
+	var kelasSemester *models.Kelas
+	if id, err := strconv.ParseUint(semesterID, 10, 64); err == nil {
+		kelasSemester = services.KelasSiswaDiSemester(siswa.ID, uint(id))
+	}
+
 	utils.ResponseOK(c, "Daftar nilai siswa", gin.H{
 		"siswa":          siswa,
+		"kelas":          kelasSemester,
 		"nilai":          nilaiList,
 		"total_mapel":    len(nilaiList),
 		"rata_rata":      rataRata,
@@ -288,4 +336,27 @@ func tentukanPredikat(nilaiAkhir float64) string {
 		return "D"
 	}
 	return "E"
-}
\ No newline at end of file
+}
+
+// simpanKomponenNilai mengisi satu komponen (harian/uts/uas) nilai siswa dari
+// sumber lain (tugas, ujian) lalu menghitung ulang nilai akhir dan predikat.
+// Record nilai dibuat jika belum ada.
+func simpanKomponenNilai(tx *gorm.DB, siswaID, mapelID, semesterID uint, komponen string, skor float64) error {
+	var nilai models.Nilai
+	tx.Where("siswa_id = ? AND mata_pelajaran_id = ? AND semester_id = ?",
+		siswaID, mapelID, semesterID).First(&nilai)
+	nilai.SiswaID = siswaID
+	nilai.MataPelajaranID = mapelID
+	nilai.SemesterID = semesterID
+	switch komponen {
+	case models.KomponenHarian:
+		nilai.NilaiHarian = skor
+	case models.KomponenUTS:
+		nilai.NilaiUTS = skor
+	case models.KomponenUAS:
+		nilai.NilaiUAS = skor
+	}
+	nilai.NilaiAkhir = hitungNilaiAkhir(nilai.NilaiHarian, nilai.NilaiUTS, nilai.NilaiUAS)
+	nilai.Predikat = tentukanPredikat(nilai.NilaiAkhir)
+	return tx.Save(&nilai).Error
+}
@@ -1,14 +1,18 @@
 package controllers
 
 import (
+	"context"
+	"log"
 	"strconv"
 
 	"sim-sekolah/app/middlewares"
 	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
 	"sim-sekolah/config"
 	"sim-sekolah/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // ── GET /notifications ─────────────────────────────────────────────────────
@@ -174,12 +178,56 @@ func SendNotification(userID uint, notifType models.NotificationType, icon, titl
 	config.DB.Create(&notif)
 }
 
-// SendNotificationToRole — kirim notifikasi ke semua user dengan role tertentu
+// JobNotifikasiRole adalah tipe job antrian untuk mengirim notifikasi ke
+// seluruh user dengan role tertentu
+const JobNotifikasiRole = "notifikasi_role"
+
+type payloadNotifikasiRole struct {
+	RoleID  uint                    `json:"role_id"`
+	Type    models.NotificationType `json:"type"`
+	Icon    string                  `json:"icon"`
+	Title   string                  `json:"title"`
+	Message string                  `json:"message"`
+	Link    string                  `json:"link"`
+}
+
+// SendNotificationToRole — kirim notifikasi ke semua user dengan role tertentu.
+// Jumlah penerima bisa ratusan, jadi pengiriman dilakukan lewat antrian job.
 func SendNotificationToRole(roleID uint, notifType models.NotificationType, icon, title, message, link string) {
-	var users []models.User
-	config.DB.Where("role_id = ? AND is_active = true", roleID).Find(&users)
+	payload := payloadNotifikasiRole{
+		RoleID: roleID, Type: notifType, Icon: icon,
+		Title: title, Message: message, Link: link,
+	}
+	if _, err := services.AntrikanJob(JobNotifikasiRole, payload); err != nil {
+		log.Printf("[notifikasi] gagal mengantrikan notifikasi role %d: %v", roleID, err)
+	}
+}
 
-	for _, u := range users {
-		SendNotification(u.ID, notifType, icon, title, message, link)
+// jobNotifikasiRole menyimpan notifikasi untuk semua penerima dalam satu
+// transaksi, sehingga percobaan ulang tidak menghasilkan notifikasi ganda
+func jobNotifikasiRole(ctx context.Context, _ *models.Job, p payloadNotifikasiRole) error {
+	var userIDs []uint
+	if err := config.DB.WithContext(ctx).Model(&models.User{}).
+		Where("role_id = ? AND is_active = true", p.RoleID).
+		Pluck("id", &userIDs).Error; err != nil {
+		return err
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	list := make([]models.Notification, len(userIDs))
+	for i, id := range userIDs {
+		list[i] = models.Notification{
+			UserID:  id,
+			Type:    p.Type,
+			Icon:    p.Icon,
+			Title:   p.Title,
+			Message: p.Message,
+			Link:    p.Link,
+		}
 	}
-}
\ No newline at end of file
+	return config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&list, 500).Error
+	})
+}
@@ -124,11 +124,12 @@ func CreateOrangTua(c *gin.Context) {
 	var ot models.OrangTua
 	err := config.DB.Transaction(func(tx *gorm.DB) error {
 		user := models.User{
-			RoleID:   roleOT.ID,
-			Nama:     req.Nama,
-			Email:    req.Email,
-			Password: req.Password,
-			IsActive: true,
+			RoleID:             roleOT.ID,
+			Nama:               req.Nama,
+			Email:              req.Email,
+			Password:           req.Password,
+			IsActive:           true,
+			WajibGantiPassword: true,
 		}
 		if err := tx.Create(&user).Error; err != nil {
 			return err
@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"sim-sekolah/app/middlewares"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// GetPengampu godoc
+// @Summary Daftar penugasan guru pengampu
+// @Tags Pengampu
+// @Security BearerAuth
+// @Param semester_id query int false "Filter semester"
+// @Param guru_id query int false "Filter guru"
+// @Param kelas_id query int false "Filter kelas"
+// @Router /pengampu [get]
+func GetPengampu(c *gin.Context) {
+	query := config.DB.Model(&models.PengampuMapel{}).
+		Preload("Guru").Preload("Kelas").Preload("MataPelajaran").Preload("Semester.TahunAjaran")
+
+	if v := c.Query("semester_id"); v != "" {
+		query = query.Where("semester_id = ?", v)
+	}
+	if v := c.Query("guru_id"); v != "" {
+		query = query.Where("guru_id = ?", v)
+	}
+	if v := c.Query("kelas_id"); v != "" {
+		query = query.Where("kelas_id = ?", v)
+	}
+
+	var list []models.PengampuMapel
+	query.Order("semester_id DESC, kelas_id ASC, mata_pelajaran_id ASC").Find(&list)
+	utils.ResponseOK(c, "Daftar guru pengampu", list)
+}
+
+// GetPengampuSaya godoc
+// @Summary Penugasan mengajar guru yang sedang login
+// @Tags Pengampu
+// @Security BearerAuth
+// @Param semester_id query int false "Filter semester"
+// @Router /pengampu/saya [get]
+func GetPengampuSaya(c *gin.Context) {
+	guru, ok := guruLogin(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Preload("Kelas").Preload("MataPelajaran").Preload("Semester").
+		Where("guru_id = ?", guru.ID)
+	if v := c.Query("semester_id"); v != "" {
+		query = query.Where("semester_id = ?", v)
+	}
+
+	var list []models.PengampuMapel
+	query.Order("semester_id DESC, kelas_id ASC").Find(&list)
+	utils.ResponseOK(c, "Penugasan mengajar saya", list)
+}
+
+// CreatePengampu godoc
+// @Summary Tetapkan guru pengampu mapel di sebuah kelas
+// @Tags Pengampu
+// @Security BearerAuth
+// @Router /pengampu [post]
+func CreatePengampu(c *gin.Context) {
+	var req struct {
+		SemesterID      uint `json:"semester_id" binding:"required"`
+		KelasID         uint `json:"kelas_id" binding:"required"`
+		MataPelajaranID uint `json:"mata_pelajaran_id" binding:"required"`
+		GuruID          uint `json:"guru_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	// Validasi FK + kurikulum (sama seperti jadwal)
+	if err := validateJadwalFK(c, req.KelasID, req.GuruID, req.MataPelajaranID, req.SemesterID); err != nil {
+		return
+	}
+
+	var kelas models.Kelas
+	var sem models.Semester
+	config.DB.First(&kelas, req.KelasID)
+	config.DB.First(&sem, req.SemesterID)
+	if kelas.TahunAjaranID != sem.TahunAjaranID {
+		utils.ResponseBadRequest(c, "Kelas dan semester berasal dari tahun ajaran yang berbeda", nil)
+		return
+	}
+
+	var existing models.PengampuMapel
+	if err := config.DB.Preload("Guru").
+		Where("semester_id = ? AND kelas_id = ? AND mata_pelajaran_id = ?", req.SemesterID, req.KelasID, req.MataPelajaranID).
+		First(&existing).Error; err == nil {
+		utils.ResponseBadRequest(c, "Mapel ini di kelas tersebut sudah diampu oleh "+existing.Guru.Nama, gin.H{"id": existing.ID})
+		return
+	}
+
+	p := models.PengampuMapel{
+		SemesterID:      req.SemesterID,
+		KelasID:         req.KelasID,
+		MataPelajaranID: req.MataPelajaranID,
+		GuruID:          req.GuruID,
+	}
+	if err := config.DB.Create(&p).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan penugasan")
+		return
+	}
+
+	config.DB.Preload("Guru").Preload("Kelas").Preload("MataPelajaran").Preload("Semester").First(&p, p.ID)
+	utils.ResponseCreated(c, "Guru pengampu berhasil ditetapkan", p)
+}
+
+// UpdatePengampu godoc
+// @Summary Ganti guru pengampu
+// @Tags Pengampu
+// @Security BearerAuth
+// @Param id path int true "ID"
+// @Router /pengampu/{id} [put]
+func UpdatePengampu(c *gin.Context) {
+	var p models.PengampuMapel
+	if err := config.DB.First(&p, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Penugasan tidak ditemukan")
+		return
+	}
+
+	var req struct {
+		GuruID uint `json:"guru_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	var guru models.Guru
+	if err := config.DB.First(&guru, req.GuruID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Guru tidak ditemukan", nil)
+		return
+	}
+
+	config.DB.Model(&p).Update("guru_id", guru.ID)
+	config.DB.Preload("Guru").Preload("Kelas").Preload("MataPelajaran").Preload("Semester").First(&p, p.ID)
+	utils.ResponseOK(c, "Guru pengampu berhasil diganti", p)
+}
+
+// DeletePengampu godoc
+// @Summary Hapus penugasan guru pengampu
+// @Tags Pengampu
+// @Security BearerAuth
+// @Param id path int true "ID"
+// @Router /pengampu/{id} [delete]
+func DeletePengampu(c *gin.Context) {
+	var p models.PengampuMapel
+	if err := config.DB.First(&p, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Penugasan tidak ditemukan")
+		return
+	}
+	config.DB.Delete(&p)
+	utils.ResponseOK(c, "Penugasan berhasil dihapus", nil)
+}
+
+// SinkronPengampuJadwal godoc
+// @Summary Buat penugasan guru pengampu dari jadwal yang sudah ada
+// @Tags Pengampu
+// @Security BearerAuth
+// @Router /pengampu/sinkron-jadwal [post]
+func SinkronPengampuJadwal(c *gin.Context) {
+	var req struct {
+		SemesterID uint `json:"semester_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	hasil, err := services.SinkronPengampuDariJadwal(req.SemesterID)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menyinkronkan penugasan dari jadwal")
+		return
+	}
+	utils.ResponseOK(c, strconv.Itoa(hasil.Dibuat)+" penugasan dibuat dari jadwal", hasil)
+}
+
+// GetBebanMengajar godoc
+// @Summary Laporan beban mengajar guru (total JP per minggu)
+// @Tags Pengampu
+// @Security BearerAuth
+// @Param semester_id query int true "Semester ID"
+// @Param guru_id query int false "Filter guru"
+// @Router /pengampu/beban-mengajar [get]
+func GetBebanMengajar(c *gin.Context) {
+	semesterID, err := strconv.ParseUint(c.Query("semester_id"), 10, 64)
+	if err != nil {
+		utils.ResponseBadRequest(c, "Parameter semester_id wajib diisi", nil)
+		return
+	}
+	guruID, _ := strconv.ParseUint(c.Query("guru_id"), 10, 64)
+
+	utils.ResponseOK(c, "Beban mengajar guru", services.HitungBebanMengajar(uint(semesterID), uint(guruID)))
+}
+
+// guruLogin mengambil data guru milik user yang sedang login
+func guruLogin(c *gin.Context) (models.Guru, bool) {
+	claims := middlewares.GetCurrentUser(c)
+	var guru models.Guru
+	if err := config.DB.Where("user_id = ?", claims.UserID).First(&guru).Error; err != nil {
+		utils.ResponseForbidden(c, "Akun ini tidak terdaftar sebagai guru")
+		return guru, false
+	}
+	return guru, true
+}
@@ -4,9 +4,7 @@ import (
 	"fmt"
 	//"net/http"
 	"os"
-	"path/filepath"
 	"strings"
-	"time"
 
 	"sim-sekolah/app/middlewares"
 	"sim-sekolah/app/models"
@@ -111,55 +109,38 @@ func UploadFotoProfil(c *gin.Context) {
 	}
 	defer file.Close()
 
-	// Validasi tipe file
-	ext := strings.ToLower(filepath.Ext(header.Filename))
-	allowedExt := map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".webp": true}
-	if !allowedExt[ext] {
-		utils.ResponseBadRequest(c, "Format file tidak didukung. Gunakan JPG, PNG, atau WebP", nil)
-		return
-	}
-
-	// Validasi ukuran file (maks 2MB)
-	if header.Size > 2*1024*1024 {
-		utils.ResponseBadRequest(c, "Ukuran file maksimal 2MB", nil)
-		return
-	}
-
-	// Buat direktori uploads/foto-profil jika belum ada
-	uploadDir := "uploads/foto-profil"
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		utils.ResponseInternalError(c, "Gagal membuat direktori upload")
-		return
-	}
-
-	// Hapus foto lama jika ada
 	var user models.User
 	if err := config.DB.First(&user, claims.UserID).Error; err != nil {
 		utils.ResponseNotFound(c, "User tidak ditemukan")
 		return
 	}
-	if user.FotoProfil != "" {
-		oldPath := strings.TrimPrefix(user.FotoProfil, "/")
-		os.Remove(oldPath) // ignore error jika file sudah tidak ada
-	}
-
-	// Generate nama file unik: foto_{userID}_{timestamp}{ext}
-	fileName := fmt.Sprintf("foto_%d_%d%s", claims.UserID, time.Now().UnixMilli(), ext)
-	filePath := filepath.Join(uploadDir, fileName)
 
-	// Simpan file ke disk
-	if err := c.SaveUploadedFile(header, filePath); err != nil {
-		utils.ResponseInternalError(c, "Gagal menyimpan file")
+	// Validasi format, isi, dan ukuran (maks 2MB) lalu simpan ke
+	// uploads/foto-profil dengan nama unik foto_{userID}_{timestamp}{ext}
+	filePath, ok := utils.SimpanUpload(c, header, utils.AturanUpload{
+		Dir:        "uploads/foto-profil",
+		Prefix:     fmt.Sprintf("foto_%d", claims.UserID),
+		Ekstensi:   []string{".jpg", ".jpeg", ".png", ".webp"},
+		MaksByte:   2 * 1024 * 1024,
+		TipeKonten: []string{"image/jpeg", "image/png", "image/webp"},
+	})
+	if !ok {
 		return
 	}
 
 	// Simpan path relatif ke DB (misal: uploads/foto-profil/foto_1_xxx.jpg)
+	fotoLama := user.FotoProfil
 	if err := config.DB.Model(&user).Update("foto_profil", filePath).Error; err != nil {
 		os.Remove(filePath) // rollback: hapus file yang baru diupload
 		utils.ResponseInternalError(c, "Gagal menyimpan path foto")
 		return
 	}
 
+	// Hapus foto lama setelah foto baru tersimpan
+	if fotoLama != "" {
+		os.Remove(strings.TrimPrefix(fotoLama, "/")) // ignore error jika file sudah tidak ada
+	}
+
 	utils.ResponseOK(c, "Foto profil berhasil diperbarui", gin.H{
 		"foto_profil": buildFotoURL(c, filePath),
 	})
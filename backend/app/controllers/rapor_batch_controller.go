@@ -0,0 +1,400 @@
+package controllers
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"sim-sekolah/app/middlewares"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// GenerateRaporKelas godoc
+// @Summary Generate rapor seluruh siswa sebuah kelas di latar belakang
+// @Tags Rapor
+// @Security BearerAuth
+// @Param kelas_id path int true "Kelas ID"
+// @Router /rapor/generate/kelas/{kelas_id} [post]
+func GenerateRaporKelas(c *gin.Context) {
+	var req struct {
+		SemesterID uint   `json:"semester_id" binding:"required"`
+		TemplateID *uint  `json:"template_rapor_id"`
+		Format     string `json:"format" binding:"omitempty,oneof=merdeka klasik"`
+		Alasan     string `json:"alasan"`    // alasan revisi untuk siswa yang rapornya sudah terbit
+		Terbitkan  bool   `json:"terbitkan"` // langsung terbitkan versi yang dibuat
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	var kelas models.Kelas
+	if err := config.DB.First(&kelas, c.Param("kelas_id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Kelas tidak ditemukan")
+		return
+	}
+	var semester models.Semester
+	if err := config.DB.Preload("TahunAjaran").First(&semester, req.SemesterID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Semester tidak ditemukan", nil)
+		return
+	}
+	if kelas.TahunAjaranID != semester.TahunAjaranID {
+		utils.ResponseBadRequest(c, "Kelas dan semester berasal dari tahun ajaran yang berbeda", nil)
+		return
+	}
+
+	// Wali kelas hanya boleh generate rapor kelasnya sendiri
+	if !pastikanWaliKelas(c, kelas) {
+		return
+	}
+
+	// Satu kelas-semester hanya boleh punya satu proses berjalan
+	var berjalan models.RaporBatch
+	if err := config.DB.Where("kelas_id = ? AND semester_id = ? AND status IN ?",
+		kelas.ID, semester.ID, []string{models.BatchAntri, models.BatchProses}).
+		First(&berjalan).Error; err == nil {
+		utils.ResponseBadRequest(c, "Generate rapor kelas ini masih berjalan", gin.H{"batch_id": berjalan.ID})
+		return
+	}
+
+	template, ok := pilihTemplateRapor(c, semester, req.TemplateID, req.Format)
+	if !ok {
+		return
+	}
+
+	// Siswa yang berada di kelas ini pada semester tersebut
+	siswaIDs := services.SiswaIDKelasSemester(kelas.ID, semester)
+	if len(siswaIDs) == 0 {
+		utils.ResponseBadRequest(c, "Tidak ada siswa di kelas ini pada semester tersebut", nil)
+		return
+	}
+
+	batch := models.RaporBatch{
+		KelasID:         kelas.ID,
+		SemesterID:      semester.ID,
+		TemplateRaporID: req.TemplateID,
+		Format:          template.Format,
+		Status:          models.BatchAntri,
+		Total:           len(siswaIDs),
+		DibuatOleh:      middlewares.GetCurrentUser(c).UserID,
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&batch).Error; err != nil {
+			return err
+		}
+		// Tidak dicoba ulang: progres batch akan terhitung dua kali
+		_, err := services.AntrikanJobTx(tx, JobRaporBatch,
+			payloadRaporBatch{BatchID: batch.ID, SiswaIDs: siswaIDs, Alasan: req.Alasan, Terbitkan: req.Terbitkan},
+			services.OpsiJob{MaksPercobaan: 1})
+		return err
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal membuat proses generate rapor")
+		return
+	}
+
+	utils.ResponseAccepted(c, "Generate rapor kelas "+kelas.Nama+" sedang diproses", gin.H{
+		"batch":  batch,
+		"status": "/api/v1/rapor/batch/" + strconv.Itoa(int(batch.ID)),
+	})
+}
+
+// GetRaporBatch godoc
+// @Summary Status dan progres generate rapor kelas
+// @Tags Rapor
+// @Security BearerAuth
+// @Param id path int true "Batch ID"
+// @Router /rapor/batch/{id} [get]
+func GetRaporBatch(c *gin.Context) {
+	var batch models.RaporBatch
+	if err := config.DB.Preload("Kelas").Preload("Semester.TahunAjaran").First(&batch, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Proses generate rapor tidak ditemukan")
+		return
+	}
+	if !pastikanWaliKelas(c, batch.Kelas) {
+		return
+	}
+
+	progres := 0.0
+	if batch.Total > 0 {
+		progres = float64(batch.Selesai+batch.Gagal) / float64(batch.Total) * 100
+	}
+	hasil := gin.H{
+		"batch":   batch,
+		"progres": progres,
+	}
+	if batch.Status == models.BatchSelesai {
+		base := "/api/v1/rapor/batch/" + strconv.Itoa(int(batch.ID)) + "/download"
+		hasil["download_zip"] = base + "?tipe=zip"
+		hasil["download_pdf"] = base + "?tipe=pdf"
+	}
+	utils.ResponseOK(c, "Status generate rapor", hasil)
+}
+
+// DownloadRaporBatch godoc
+// @Summary Download hasil generate rapor kelas (ZIP atau satu PDF gabungan)
+// @Tags Rapor
+// @Security BearerAuth
+// @Param id path int true "Batch ID"
+// @Param tipe query string false "zip (default) / pdf"
+// @Router /rapor/batch/{id}/download [get]
+func DownloadRaporBatch(c *gin.Context) {
+	var batch models.RaporBatch
+	if err := config.DB.Preload("Kelas").First(&batch, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Proses generate rapor tidak ditemukan")
+		return
+	}
+	if !pastikanWaliKelas(c, batch.Kelas) {
+		return
+	}
+	if batch.Status != models.BatchSelesai {
+		utils.ResponseBadRequest(c, "Generate rapor belum selesai (status: "+batch.Status+")", nil)
+		return
+	}
+
+	path, contentType := batch.ZipPath, "application/zip"
+	if c.Query("tipe") == "pdf" {
+		path, contentType = batch.GabunganPath, "application/pdf"
+	}
+	if _, err := os.Stat(path); path == "" || os.IsNotExist(err) {
+		utils.ResponseNotFound(c, "File hasil generate tidak ditemukan di server")
+		return
+	}
+
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Transfer-Encoding", "binary")
+	c.Header("Content-Disposition", "attachment; filename="+filepath.Base(path))
+	c.Header("Content-Type", contentType)
+	c.File(path)
+}
+
+// ── Worker ────────────────────────────────────────────────────
+
+// JobRaporBatch adalah tipe job antrian untuk generate rapor satu kelas
+const JobRaporBatch = "rapor_batch"
+
+type payloadRaporBatch struct {
+	BatchID   uint   `json:"batch_id"`
+	SiswaIDs  []uint `json:"siswa_ids"`
+	Alasan    string `json:"alasan,omitempty"`
+	Terbitkan bool   `json:"terbitkan,omitempty"`
+}
+
+type hasilRaporSiswa struct {
+	versi models.RaporVersi
+	data  DataRapor
+}
+
+// jobRaporBatch memuat ulang batch, semester dan template dari database lalu
+// menjalankan proses generate
+func jobRaporBatch(ctx context.Context, _ *models.Job, p payloadRaporBatch) error {
+	var batch models.RaporBatch
+	if err := config.DB.First(&batch, p.BatchID).Error; err != nil {
+		return services.GagalPermanen(fmt.Errorf("batch rapor %d tidak ditemukan", p.BatchID))
+	}
+	var semester models.Semester
+	if err := config.DB.Preload("TahunAjaran").First(&semester, batch.SemesterID).Error; err != nil {
+		tandaiBatchGagal(batch.ID, "Semester tidak ditemukan")
+		return services.GagalPermanen(err)
+	}
+
+	template := services.TemplateRaporTahunAjaran(semester.TahunAjaranID)
+	if batch.TemplateRaporID != nil {
+		if err := config.DB.First(&template, *batch.TemplateRaporID).Error; err != nil {
+			tandaiBatchGagal(batch.ID, "Template rapor tidak ditemukan")
+			return services.GagalPermanen(err)
+		}
+	}
+	if batch.Format != "" {
+		template.Format = batch.Format
+	}
+
+	opsi := opsiGenerateRapor{Alasan: p.Alasan, Terbitkan: p.Terbitkan, Oleh: batch.DibuatOleh}
+	return jalankanRaporBatch(ctx, batch.ID, p.SiswaIDs, semester, template, opsi)
+}
+
+// jalankanRaporBatch merender rapor setiap siswa secara paralel dengan jumlah
+// worker terbatas (RAPOR_BATCH_WORKERS), lalu menyusun ZIP dan PDF gabungan.
+// Jika ctx dibatalkan (server dimatikan), siswa yang belum diproses dilewati
+// dan batch ditandai gagal.
+func jalankanRaporBatch(ctx context.Context, batchID uint, siswaIDs []uint, semester models.Semester, template models.TemplateRapor, opsi opsiGenerateRapor) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[rapor-batch] batch %d panic: %v", batchID, r)
+			tandaiBatchGagal(batchID, fmt.Sprintf("Terjadi kesalahan: %v", r))
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	mulai := time.Now()
+	config.DB.Model(&models.RaporBatch{}).Where("id = ?", batchID).
+		Updates(map[string]interface{}{"status": models.BatchProses, "mulai_at": mulai})
+
+	jumlahWorker := config.GetEnvInt("RAPOR_BATCH_WORKERS", 4)
+	if jumlahWorker < 1 {
+		jumlahWorker = 1
+	}
+
+	antrian := make(chan uint)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		berhasil []hasilRaporSiswa
+		gagal    []string
+	)
+	for i := 0; i < jumlahWorker; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for siswaID := range antrian {
+				hasil, err := raporSatuSiswa(siswaID, semester, template, opsi)
+
+				mu.Lock()
+				kolom := "selesai"
+				if err != nil {
+					kolom = "gagal"
+					gagal = append(gagal, err.Error())
+				} else {
+					berhasil = append(berhasil, hasil)
+				}
+				mu.Unlock()
+
+				config.DB.Model(&models.RaporBatch{}).Where("id = ?", batchID).
+					Update(kolom, gorm.Expr(kolom+" + 1"))
+			}
+		}()
+	}
+kirim:
+	for _, id := range siswaIDs {
+		select {
+		case antrian <- id:
+		case <-ctx.Done():
+			break kirim
+		}
+	}
+	close(antrian)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		tandaiBatchGagal(batchID, "Proses dihentikan karena server dimatikan")
+		return ctx.Err()
+	}
+	if len(berhasil) == 0 {
+		tandaiBatchGagal(batchID, strings.Join(gagal, "\n"))
+		return errors.New("tidak ada rapor yang berhasil dibuat")
+	}
+
+	sort.Slice(berhasil, func(i, j int) bool {
+		return berhasil[i].data.Siswa.Nama < berhasil[j].data.Siswa.Nama
+	})
+
+	dir := filepath.Join("./storage/rapor/batch", strconv.Itoa(int(batchID)))
+	os.MkdirAll(dir, 0755)
+	namaKelas := "kelas"
+	if k := berhasil[0].data.Siswa.Kelas; k != nil {
+		namaKelas = namaFileAman(k.Nama)
+	}
+	namaDasar := fmt.Sprintf("rapor_%s_%s_%s", namaKelas,
+		namaFileAman(semester.TahunAjaran.Nama), namaFileAman(semester.Nama))
+
+	zipPath := filepath.Join(dir, namaDasar+".zip")
+	if err := buatZipRapor(zipPath, berhasil); err != nil {
+		tandaiBatchGagal(batchID, "Gagal membuat ZIP: "+err.Error())
+		return err
+	}
+
+	data := make([]DataRapor, len(berhasil))
+	for i, h := range berhasil {
+		data[i] = h.data
+	}
+	gabunganPath := filepath.Join(dir, namaDasar+".pdf")
+	if err := renderRaporGabungan(data).OutputFileAndClose(gabunganPath); err != nil {
+		tandaiBatchGagal(batchID, "Gagal membuat PDF gabungan: "+err.Error())
+		return err
+	}
+
+	selesai := time.Now()
+	config.DB.Model(&models.RaporBatch{}).Where("id = ?", batchID).Updates(map[string]interface{}{
+		"status":        models.BatchSelesai,
+		"keterangan":    strings.Join(gagal, "\n"),
+		"zip_path":      zipPath,
+		"gabungan_path": gabunganPath,
+		"selesai_at":    selesai,
+	})
+	log.Printf("[rapor-batch] batch %d selesai: %d berhasil, %d gagal dalam %s",
+		batchID, len(berhasil), len(gagal), selesai.Sub(mulai).Round(time.Millisecond))
+	return nil
+}
+
+// raporSatuSiswa memuat data siswa lalu membuat rapornya
+func raporSatuSiswa(siswaID uint, semester models.Semester, template models.TemplateRapor, opsi opsiGenerateRapor) (hasilRaporSiswa, error) {
+	var siswa models.Siswa
+	if err := config.DB.First(&siswa, siswaID).Error; err != nil {
+		return hasilRaporSiswa{}, fmt.Errorf("siswa #%d: tidak ditemukan", siswaID)
+	}
+	siswa.Kelas = services.KelasSiswaDiSemester(siswa.ID, semester.ID)
+
+	versi, data, err := generateRaporSiswa(siswa, semester, template, opsi)
+	if err != nil {
+		return hasilRaporSiswa{}, fmt.Errorf("%s (%s): %v", siswa.Nama, siswa.NISN, err)
+	}
+	return hasilRaporSiswa{versi: versi, data: data}, nil
+}
+
+func buatZipRapor(path string, list []hasilRaporSiswa) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for _, h := range list {
+		nama := fmt.Sprintf("%s_%s.pdf", namaFileAman(h.data.Siswa.NISN), namaFileAman(h.data.Siswa.Nama))
+		w, err := zw.Create(nama)
+		if err != nil {
+			return err
+		}
+		src, err := os.Open(h.versi.FilePath)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, src)
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func tandaiBatchGagal(batchID uint, keterangan string) {
+	config.DB.Model(&models.RaporBatch{}).Where("id = ?", batchID).Updates(map[string]interface{}{
+		"status":     models.BatchGagal,
+		"keterangan": keterangan,
+		"selesai_at": time.Now(),
+	})
+}
+
+var polaNamaFile = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// namaFileAman mengganti karakter selain huruf/angka dengan garis bawah
+func namaFileAman(s string) string {
+	return strings.Trim(polaNamaFile.ReplaceAllString(s, "_"), "_")
+}
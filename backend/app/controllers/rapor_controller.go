@@ -1,16 +1,16 @@
 package controllers
 
 import (
-	"fmt"
+	"errors"
 	"os"
-	"path/filepath"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jung-kurt/gofpdf"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"sim-sekolah/app/middlewares"
 	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
 	"sim-sekolah/config"
 	"sim-sekolah/utils"
 )
@@ -67,8 +67,12 @@ func GetRaporByID(c *gin.Context) {
 // @Router /rapor/generate [post]
 func GenerateRapor(c *gin.Context) {
 	var req struct {
-		SiswaID    uint `json:"siswa_id" binding:"required"`
-		SemesterID uint `json:"semester_id" binding:"required"`
+		SiswaID    uint   `json:"siswa_id" binding:"required"`
+		SemesterID uint   `json:"semester_id" binding:"required"`
+		TemplateID *uint  `json:"template_rapor_id"`                               // kosong = template tahun ajaran
+		Format     string `json:"format" binding:"omitempty,oneof=merdeka klasik"` // kosong = format template
+		Alasan     string `json:"alasan"`                                          // wajib jika rapor sudah terbit
+		Terbitkan  bool   `json:"terbitkan"`                                       // langsung terbitkan versi ini
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
@@ -87,63 +91,63 @@ func GenerateRapor(c *gin.Context) {
 		return
 	}
 
-	// Ambil semua nilai siswa di semester ini
-	var nilaiList []models.Nilai
-	config.DB.Preload("MataPelajaran").
-		Where("siswa_id = ? AND semester_id = ?", req.SiswaID, req.SemesterID).
-		Order("mata_pelajaran_id ASC").
-		Find(&nilaiList)
-
-	if len(nilaiList) == 0 {
-		utils.ResponseBadRequest(c, "Belum ada nilai untuk siswa di semester ini", nil)
+	// Rapor memakai kelas yang berlaku di semester tersebut (bisa berbeda dengan kelas saat ini)
+	siswa.Kelas = services.KelasSiswaDiSemester(siswa.ID, semester.ID)
+	if !pastikanWaliKelasRapor(c, siswa.Kelas) {
 		return
 	}
 
-	// Ambil data absensi (ringkasan)
-	// type AbsensiRekap struct {
-	// 	Hadir int64
-	// 	Izin  int64
-	// 	Sakit int64
-	// 	Alfa  int64
-	// }
-	var absensiRekap AbsensiRekap
-	config.DB.Model(&models.Absensi{}).
-		Select("SUM(CASE WHEN status='hadir' THEN 1 ELSE 0 END) as hadir, "+
-			"SUM(CASE WHEN status='izin' THEN 1 ELSE 0 END) as izin, "+
-			"SUM(CASE WHEN status='sakit' THEN 1 ELSE 0 END) as sakit, "+
-			"SUM(CASE WHEN status='alfa' THEN 1 ELSE 0 END) as alfa").
-		Joins("JOIN jadwals ON jadwals.id = absensis.jadwal_id").
-		Where("absensis.siswa_id = ? AND jadwals.semester_id = ?", req.SiswaID, req.SemesterID).
-		Scan(&absensiRekap)
-
-	// Generate PDF
-	filename := fmt.Sprintf("rapor_%d_sem%d_%d.pdf", req.SiswaID, req.SemesterID, time.Now().Unix())
-	outputDir := "./storage/rapor"
-	os.MkdirAll(outputDir, 0755)
-	filepath := filepath.Join(outputDir, filename)
+	template, ok := pilihTemplateRapor(c, semester, req.TemplateID, req.Format)
+	if !ok {
+		return
+	}
 
-	err := buatPDFRapor(filepath, siswa, semester, nilaiList, absensiRekap)
+	versi, _, err := generateRaporSiswa(siswa, semester, template, opsiGenerateRapor{
+		Alasan:    req.Alasan,
+		Terbitkan: req.Terbitkan,
+		Oleh:      middlewares.GetCurrentUser(c).UserID,
+	})
+	if err == errBelumAdaNilai {
+		utils.ResponseBadRequest(c, "Belum ada nilai untuk siswa di semester ini", nil)
+		return
+	}
+	if err == errAlasanRevisi {
+		utils.ResponseBadRequest(c, "Rapor sudah terbit; isi alasan untuk membuat versi revisi", nil)
+		return
+	}
 	if err != nil {
 		utils.ResponseInternalError(c, "Gagal membuat PDF rapor: "+err.Error())
 		return
 	}
+	var rapor models.Rapor
+	config.DB.Preload("Siswa").Preload("Semester").First(&rapor, versi.RaporID)
 
-	// Simpan record rapor ke DB
-	rapor := models.Rapor{
-		SiswaID:    req.SiswaID,
-		SemesterID: req.SemesterID,
-		FilePath:   filepath,
-		Status:     "published",
-	}
-	config.DB.Create(&rapor)
-	config.DB.Preload("Siswa").Preload("Semester").First(&rapor, rapor.ID)
-
-	utils.ResponseCreated(c, "Rapor berhasil digenerate", gin.H{
+	base := "/api/v1/rapor/" + strconv.Itoa(int(rapor.ID))
+	utils.ResponseCreated(c, "Rapor versi "+strconv.Itoa(versi.Nomor)+" berhasil digenerate ("+versi.Status+")", gin.H{
 		"rapor":    rapor,
-		"download": "/api/v1/rapor/" + strconv.Itoa(int(rapor.ID)) + "/download",
+		"versi":    versi,
+		"download": base + "/versi/" + strconv.Itoa(versi.Nomor) + "/download",
 	})
 }
 
+// pilihTemplateRapor memakai template yang diminta, atau template tahun ajaran
+// semester jika tidak ada. format (jika diisi) menimpa format template.
+func pilihTemplateRapor(c *gin.Context, semester models.Semester, templateID *uint, format string) (models.TemplateRapor, bool) {
+	template := services.TemplateRaporTahunAjaran(semester.TahunAjaranID)
+	if templateID != nil {
+		if err := config.DB.First(&template, *templateID).Error; err != nil {
+			utils.ResponseBadRequest(c, "Template rapor tidak ditemukan", nil)
+			return template, false
+		}
+	}
+	if format != "" {
+		template.Format = format
+	}
+	return template, true
+}
+
+var errBelumAdaNilai = errors.New("belum ada nilai untuk siswa di semester ini")
+
 // DownloadRapor godoc
 // @Summary Download file PDF rapor
 // @Tags Rapor
@@ -157,17 +161,14 @@ func DownloadRapor(c *gin.Context) {
 		return
 	}
 
-	// Cek file ada
-	if _, err := os.Stat(rapor.FilePath); os.IsNotExist(err) {
-		utils.ResponseNotFound(c, "File rapor tidak ditemukan di server")
+	// Siswa & orang tua hanya boleh mengunduh rapor yang sudah terbit
+	claims := middlewares.GetCurrentUser(c)
+	if (claims.Role == models.RoleSiswa || claims.Role == models.RoleOrangTua) && rapor.VersiTerbit == 0 {
+		utils.ResponseNotFound(c, "Rapor belum diterbitkan")
 		return
 	}
 
-	c.Header("Content-Description", "File Transfer")
-	c.Header("Content-Transfer-Encoding", "binary")
-	c.Header("Content-Disposition", "attachment; filename="+filepath.Base(rapor.FilePath))
-	c.Header("Content-Type", "application/pdf")
-	c.File(rapor.FilePath)
+	kirimFilePDF(c, rapor.FilePath)
 }
 
 // GetRaporSaya godoc
@@ -187,7 +188,7 @@ func GetRaporSaya(c *gin.Context) {
 		}
 		var raporList []models.Rapor
 		config.DB.Preload("Semester.TahunAjaran").
-			Where("siswa_id = ?", siswa.ID).
+			Where("siswa_id = ? AND versi_terbit > 0", siswa.ID).
 			Order("created_at DESC").
 			Find(&raporList)
 		utils.ResponseOK(c, "Daftar rapor saya", raporList)
@@ -205,7 +206,7 @@ func GetRaporSaya(c *gin.Context) {
 		}
 		var raporList []models.Rapor
 		config.DB.Preload("Semester.TahunAjaran").Preload("Siswa").
-			Where("siswa_id = ?", link.SiswaID).
+			Where("siswa_id = ? AND versi_terbit > 0", link.SiswaID).
 			Order("created_at DESC").
 			Find(&raporList)
 		utils.ResponseOK(c, "Daftar rapor anak", raporList)
@@ -216,148 +217,58 @@ func GetRaporSaya(c *gin.Context) {
 }
 
 // DeleteRapor godoc
-// @Summary Hapus rapor (soft, ubah status jadi draft)
+// @Summary Hapus draft terakhir rapor (versi yang sudah terbit tidak bisa dihapus)
 // @Tags Rapor
 // @Security BearerAuth
 // @Param id path int true "ID"
 // @Router /rapor/{id} [delete]
 func DeleteRapor(c *gin.Context) {
 	var rapor models.Rapor
-	if err := config.DB.First(&rapor, c.Param("id")).Error; err != nil {
+	var draft models.RaporVersi
+	var errTidakAdaDraft = errors.New("tidak ada draft")
+	raporDihapus := false
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&rapor, c.Param("id")).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("rapor_id = ? AND nomor = ?", rapor.ID, rapor.VersiTerakhir).
+			First(&draft).Error; err != nil || draft.Status != models.RaporDraft {
+			return errTidakAdaDraft
+		}
+		if err := tx.Delete(&draft).Error; err != nil {
+			return err
+		}
+
+		// Rapor tanpa versi tersisa ikut dihapus
+		var sebelumnya models.RaporVersi
+		if err := tx.Where("rapor_id = ?", rapor.ID).Order("nomor DESC").First(&sebelumnya).Error; err != nil {
+			raporDihapus = true
+			return tx.Delete(&rapor).Error
+		}
+		update := map[string]interface{}{"versi_terakhir": sebelumnya.Nomor}
+		if rapor.VersiTerbit == 0 {
+			update["file_path"] = sebelumnya.FilePath
+		}
+		return tx.Model(&rapor).Updates(update).Error
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
 		utils.ResponseNotFound(c, "Rapor tidak ditemukan")
 		return
+	case errors.Is(err, errTidakAdaDraft):
+		utils.ResponseBadRequest(c, "Tidak ada draft untuk dihapus; rapor yang sudah terbit hanya bisa direvisi", nil)
+		return
+	case err != nil:
+		utils.ResponseInternalError(c, "Gagal menghapus draft rapor")
+		return
 	}
-	// Soft delete: ubah status jadi draft
-	config.DB.Model(&rapor).Update("status", "draft")
-	utils.ResponseOK(c, "Rapor berhasil diarsipkan (status: draft)", nil)
-}
-
-// ── PDF Generator ─────────────────────────────────────────────
-
-type AbsensiRekap struct {
-	Hadir int64
-	Izin  int64
-	Sakit int64
-	Alfa  int64
-}
-
-func buatPDFRapor(
-	outputPath string,
-	siswa models.Siswa,
-	semester models.Semester,
-	nilaiList []models.Nilai,
-	absensi AbsensiRekap,
-) error {
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.AddPage()
-
-	// Header
-	pdf.SetFont("Arial", "B", 16)
-	pdf.Cell(0, 10, "RAPOR SISWA")
-	pdf.Ln(6)
-	pdf.SetFont("Arial", "", 10)
-	pdf.Cell(0, 6, "Tahun Ajaran: "+semester.TahunAjaran.Nama+" - Semester "+semester.Nama)
-	pdf.Ln(10)
-
-	// Identitas Siswa
-	pdf.SetFont("Arial", "B", 11)
-	pdf.Cell(0, 6, "IDENTITAS SISWA")
-	pdf.Ln(6)
-	pdf.SetFont("Arial", "", 10)
-	pdf.Cell(40, 6, "Nama")
-	pdf.Cell(5, 6, ":")
-	pdf.Cell(0, 6, siswa.Nama)
-	pdf.Ln(5)
-	pdf.Cell(40, 6, "NISN")
-	pdf.Cell(5, 6, ":")
-	pdf.Cell(0, 6, siswa.NISN)
-	pdf.Ln(5)
-	if siswa.Kelas != nil {
-		pdf.Cell(40, 6, "Kelas")
-		pdf.Cell(5, 6, ":")
-		pdf.Cell(0, 6, siswa.Kelas.Nama)
-		pdf.Ln(5)
-	}
-	pdf.Ln(5)
-
-	// Tabel Nilai
-	pdf.SetFont("Arial", "B", 11)
-	pdf.Cell(0, 6, "DAFTAR NILAI")
-	pdf.Ln(6)
-
-	// Header tabel
-	pdf.SetFont("Arial", "B", 9)
-	pdf.SetFillColor(220, 220, 220)
-	pdf.CellFormat(10, 7, "No", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(70, 7, "Mata Pelajaran", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(20, 7, "Harian", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(20, 7, "UTS", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(20, 7, "UAS", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(25, 7, "Akhir", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(25, 7, "Predikat", "1", 0, "C", true, 0, "")
-	pdf.Ln(-1)
-
-	// Isi tabel
-	pdf.SetFont("Arial", "", 9)
-	totalNilai := 0.0
-	for i, n := range nilaiList {
-		pdf.CellFormat(10, 6, strconv.Itoa(i+1), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(70, 6, n.MataPelajaran.Nama, "1", 0, "L", false, 0, "")
-		pdf.CellFormat(20, 6, fmt.Sprintf("%.1f", n.NilaiHarian), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(20, 6, fmt.Sprintf("%.1f", n.NilaiUTS), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(20, 6, fmt.Sprintf("%.1f", n.NilaiUAS), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(25, 6, fmt.Sprintf("%.2f", n.NilaiAkhir), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(25, 6, n.Predikat, "1", 0, "C", false, 0, "")
-		pdf.Ln(-1)
-		totalNilai += n.NilaiAkhir
-	}
-
-	// Rata-rata
-	rataRata := totalNilai / float64(len(nilaiList))
-	pdf.SetFont("Arial", "B", 9)
-	pdf.CellFormat(140, 6, "RATA-RATA", "1", 0, "R", false, 0, "")
-	pdf.CellFormat(25, 6, fmt.Sprintf("%.2f", rataRata), "1", 0, "C", false, 0, "")
-	pdf.CellFormat(25, 6, tentukanPredikat(rataRata), "1", 0, "C", false, 0, "")
-	pdf.Ln(10)
-
-	// Kehadiran
-	pdf.SetFont("Arial", "B", 11)
-	pdf.Cell(0, 6, "KEHADIRAN")
-	pdf.Ln(6)
-	pdf.SetFont("Arial", "", 10)
-	pdf.Cell(40, 6, "Hadir")
-	pdf.Cell(5, 6, ":")
-	pdf.Cell(0, 6, fmt.Sprintf("%d hari", absensi.Hadir))
-	pdf.Ln(5)
-	pdf.Cell(40, 6, "Izin")
-	pdf.Cell(5, 6, ":")
-	pdf.Cell(0, 6, fmt.Sprintf("%d hari", absensi.Izin))
-	pdf.Ln(5)
-	pdf.Cell(40, 6, "Sakit")
-	pdf.Cell(5, 6, ":")
-	pdf.Cell(0, 6, fmt.Sprintf("%d hari", absensi.Sakit))
-	pdf.Ln(5)
-	pdf.Cell(40, 6, "Alfa")
-	pdf.Cell(5, 6, ":")
-	pdf.Cell(0, 6, fmt.Sprintf("%d hari", absensi.Alfa))
-	pdf.Ln(15)
 
-	// TTD
-	pdf.SetFont("Arial", "", 9)
-	pdf.Cell(100, 6, "")
-	pdf.Cell(0, 6, "Banda Aceh, "+time.Now().Format("02 January 2006"))
-	pdf.Ln(5)
-	pdf.Cell(100, 6, "Orang Tua / Wali")
-	pdf.Cell(0, 6, "Wali Kelas")
-	pdf.Ln(20)
-	pdf.Cell(100, 6, "___________________")
-	if siswa.Kelas != nil && siswa.Kelas.WaliKelas != nil {
-		pdf.Cell(0, 6, siswa.Kelas.WaliKelas.Nama)
-	} else {
-		pdf.Cell(0, 6, "___________________")
+	os.Remove(draft.FilePath)
+	if raporDihapus {
+		utils.ResponseOK(c, "Draft rapor dihapus", nil)
+		return
 	}
-
-	return pdf.OutputFileAndClose(outputPath)
+	utils.ResponseOK(c, "Draft rapor versi "+strconv.Itoa(draft.Nomor)+" dihapus", nil)
 }
 
 // Helper untuk predikat (sama seperti di nilai_controller)
@@ -0,0 +1,670 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+)
+
+// ── PDF Generator ─────────────────────────────────────────────
+
+type AbsensiRekap struct {
+	Hadir int64 `json:"hadir"`
+	Izin  int64 `json:"izin"`
+	Sakit int64 `json:"sakit"`
+	Alfa  int64 `json:"alfa"`
+}
+
+// Format rapor yang didukung
+const (
+	FormatRaporMerdeka = "merdeka" // capaian kompetensi, P5, ekstrakurikuler
+	FormatRaporKlasik  = "klasik"  // kolom Harian/UTS/UAS/Akhir
+)
+
+// EkskulRapor adalah satu baris kegiatan ekstrakurikuler di rapor
+type EkskulRapor struct {
+	Nama       string `json:"nama"`
+	Predikat   string `json:"predikat"`
+	Keterangan string `json:"keterangan"`
+}
+
+// DataRapor berisi seluruh data yang dicetak di rapor seorang siswa
+type DataRapor struct {
+	Template  models.TemplateRapor
+	Sekolah   models.ProfilSekolah
+	Siswa     models.Siswa
+	Semester  models.Semester
+	Nilai     []models.Nilai
+	Absensi   AbsensiRekap
+	Deskripsi map[uint]string // mapel ID → teks capaian kompetensi
+	P5        []services.ProjekP5Siswa
+	Ekskul    []EkskulRapor
+	Peringkat *PeringkatRapor             // nil jika template tidak menampilkan peringkat
+	Perilaku  *services.RingkasanPerilaku // nil jika template tidak menampilkan perilaku
+
+	Verifikasi *VerifikasiRapor // nil = tanpa QR (misalnya preview template)
+}
+
+// PeringkatRapor adalah posisi siswa di kelasnya menurut leger nilai
+type PeringkatRapor struct {
+	Peringkat   int `json:"peringkat"`
+	JumlahSiswa int `json:"jumlah_siswa"`
+}
+
+// VerifikasiRapor dicetak sebagai QR dan teks di akhir rapor
+type VerifikasiRapor struct {
+	Kode string
+	URL  string
+	Hash string
+}
+
+// susunDataRapor mengumpulkan nilai, kehadiran, capaian kompetensi, projek P5 dan
+// ekstrakurikuler siswa pada semester tersebut. siswa.Kelas diharapkan sudah
+// berisi kelas semester itu.
+func susunDataRapor(siswa models.Siswa, semester models.Semester, template models.TemplateRapor) DataRapor {
+	data := DataRapor{
+		Template: template,
+		Sekolah:  services.ProfilSekolah(),
+		Siswa:    siswa,
+		Semester: semester,
+	}
+
+	config.DB.Preload("MataPelajaran").
+		Where("siswa_id = ? AND semester_id = ?", siswa.ID, semester.ID).
+		Order("mata_pelajaran_id ASC").
+		Find(&data.Nilai)
+
+	if rekap, err := services.RekapAbsensiSiswa(siswa.ID, services.FilterRekapAbsensi{SemesterID: semester.ID}); err == nil {
+		data.Absensi = AbsensiRekap{Hadir: rekap.Hadir, Izin: rekap.Izin, Sakit: rekap.Sakit, Alfa: rekap.Alfa}
+	}
+
+	if template.TampilkanPeringkat && siswa.Kelas != nil {
+		if p, dari, ok := services.PeringkatSiswa(*siswa.Kelas, semester, siswa.ID); ok {
+			data.Peringkat = &PeringkatRapor{Peringkat: p, JumlahSiswa: dari}
+		}
+	}
+
+	if template.TampilkanPerilaku {
+		perilaku := services.RingkasanPerilakuSiswa(siswa.ID, semester.ID)
+		data.Perilaku = &perilaku
+	}
+
+	if template.Format != FormatRaporKlasik {
+		data.Deskripsi = services.DeskripsiCapaianSiswa(siswa, semester.ID)
+		if template.TampilkanP5 {
+			data.P5 = services.P5Siswa(siswa.ID, semester.ID)
+		}
+		if template.TampilkanEkskul {
+			for _, e := range services.EkskulSiswa(siswa.ID, semester.ID) {
+				data.Ekskul = append(data.Ekskul, EkskulRapor{Nama: e.Nama, Predikat: e.Predikat, Keterangan: e.Keterangan})
+			}
+		}
+	}
+	return data
+}
+
+func buatPDFRapor(outputPath string, data DataRapor) error {
+	return renderRapor(data).OutputFileAndClose(outputPath)
+}
+
+// renderRapor menyusun dokumen PDF rapor sesuai template
+func renderRapor(data DataRapor) *gofpdf.Fpdf {
+	pdf := pdfRapor(data.Template)
+	tulisRapor(pdf, data)
+	return pdf
+}
+
+// renderRaporGabungan menyusun rapor beberapa siswa dalam satu dokumen; setiap
+// rapor dimulai di halaman baru. Semua data harus memakai template yang sama.
+func renderRaporGabungan(list []DataRapor) *gofpdf.Fpdf {
+	pdf := pdfRapor(list[0].Template)
+	for _, data := range list {
+		tulisRapor(pdf, data)
+	}
+	return pdf
+}
+
+// pdfRapor membuat dokumen kosong dengan ukuran kertas dan catatan kaki template
+func pdfRapor(t models.TemplateRapor) *gofpdf.Fpdf {
+	pdf := pdfBaru(t.UkuranKertas)
+	if t.CatatanKaki != "" {
+		pdf.SetFooterFunc(func() {
+			pdf.SetY(-12)
+			pdf.SetFont("Arial", "I", 7)
+			lebarHalaman, _ := pdf.GetPageSize()
+			kiri, _, kanan, _ := pdf.GetMargins()
+			pdf.CellFormat(lebarHalaman-kiri-kanan-20, 4, t.CatatanKaki, "", 0, "L", false, 0, "")
+			pdf.CellFormat(20, 4, fmt.Sprintf("Hal. %d", pdf.PageNo()), "", 0, "R", false, 0, "")
+		})
+	}
+	return pdf
+}
+
+// tulisRapor menulis rapor satu siswa mulai dari halaman baru
+func tulisRapor(pdf *gofpdf.Fpdf, data DataRapor) {
+	t := data.Template
+	pdf.AddPage()
+
+	if t.TampilkanKop {
+		tulisKopSekolah(pdf, data.Sekolah, t.TampilkanLogo)
+	}
+	tulisIdentitasRapor(pdf, data)
+
+	if t.Format == FormatRaporKlasik {
+		tulisNilaiKlasik(pdf, data.Nilai)
+	} else {
+		bagian := 'A'
+		judul := func(s string) string {
+			j := string(bagian) + ". " + s
+			bagian++
+			return j
+		}
+		tulisIntrakurikuler(pdf, judul("INTRAKURIKULER"), data)
+		if t.TampilkanP5 {
+			tulisProjekP5(pdf, judul("PROJEK PENGUATAN PROFIL PELAJAR PANCASILA"), data.P5)
+		}
+		if t.TampilkanEkskul {
+			tulisEkstrakurikuler(pdf, judul("EKSTRAKURIKULER"), data.Ekskul)
+		}
+	}
+	if data.Perilaku != nil {
+		tulisPerilaku(pdf, *data.Perilaku)
+	}
+	if t.TampilkanKehadiran {
+		tulisKehadiran(pdf, data)
+	}
+	tulisTandaTangan(pdf, data)
+	if data.Verifikasi != nil {
+		tulisVerifikasi(pdf, *data.Verifikasi)
+	}
+}
+
+// pdfBaru membuat dokumen potret dengan ukuran kertas template
+func pdfBaru(ukuran string) *gofpdf.Fpdf {
+	switch ukuran {
+	case models.KertasF4:
+		return gofpdf.NewCustom(&gofpdf.InitType{
+			OrientationStr: "P",
+			UnitStr:        "mm",
+			Size:           gofpdf.SizeType{Wd: 215, Ht: 330},
+		})
+	case models.KertasLetter:
+		return gofpdf.New("P", "mm", "Letter", "")
+	}
+	return gofpdf.New("P", "mm", "A4", "")
+}
+
+func tulisKopSekolah(pdf *gofpdf.Fpdf, sekolah models.ProfilSekolah, tampilkanLogo bool) {
+	if sekolah.Nama == "" {
+		return
+	}
+
+	kiri, atas, kanan, _ := pdf.GetMargins()
+	lebarHalaman, _ := pdf.GetPageSize()
+	if tampilkanLogo && sekolah.LogoPath != "" {
+		if _, err := os.Stat(sekolah.LogoPath); err == nil {
+			pdf.ImageOptions(sekolah.LogoPath, kiri, atas, 22, 0, false, gofpdf.ImageOptions{ReadDpi: true}, 0, "")
+		}
+	}
+
+	pdf.SetFont("Arial", "B", 14)
+	pdf.CellFormat(0, 7, strings.ToUpper(sekolah.Nama), "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	if sekolah.NPSN != "" {
+		pdf.CellFormat(0, 5, "NPSN: "+sekolah.NPSN, "", 1, "C", false, 0, "")
+	}
+	if sekolah.Alamat != "" {
+		pdf.CellFormat(0, 5, sekolah.Alamat, "", 1, "C", false, 0, "")
+	}
+	var kontak []string
+	if sekolah.Telepon != "" {
+		kontak = append(kontak, "Telp. "+sekolah.Telepon)
+	}
+	if sekolah.Email != "" {
+		kontak = append(kontak, sekolah.Email)
+	}
+	if sekolah.Website != "" {
+		kontak = append(kontak, sekolah.Website)
+	}
+	if len(kontak) > 0 {
+		pdf.CellFormat(0, 5, strings.Join(kontak, " | "), "", 1, "C", false, 0, "")
+	}
+
+	// Garis pemisah kop, di bawah logo jika logo lebih tinggi dari teks
+	y := pdf.GetY() + 2
+	if y < atas+24 {
+		y = atas + 24
+	}
+	pdf.SetLineWidth(0.6)
+	pdf.Line(kiri, y, lebarHalaman-kanan, y)
+	pdf.SetLineWidth(0.2)
+	pdf.SetY(y + 4)
+}
+
+func tulisIdentitasRapor(pdf *gofpdf.Fpdf, data DataRapor) {
+	judul := data.Template.Judul
+	if judul == "" {
+		judul = "LAPORAN HASIL BELAJAR"
+		if data.Template.Format == FormatRaporKlasik {
+			judul = "RAPOR SISWA"
+		}
+	}
+
+	// Header
+	pdf.SetFont("Arial", "B", 16)
+	pdf.Cell(0, 10, judul)
+	pdf.Ln(6)
+	pdf.SetFont("Arial", "", 10)
+	pdf.Cell(0, 6, "Tahun Ajaran: "+data.Semester.TahunAjaran.Nama+" - Semester "+data.Semester.Nama)
+	pdf.Ln(10)
+
+	// Identitas Siswa
+	pdf.SetFont("Arial", "B", 11)
+	pdf.Cell(0, 6, "IDENTITAS SISWA")
+	pdf.Ln(6)
+	pdf.SetFont("Arial", "", 10)
+	pdf.Cell(40, 6, "Nama")
+	pdf.Cell(5, 6, ":")
+	pdf.Cell(0, 6, data.Siswa.Nama)
+	pdf.Ln(5)
+	pdf.Cell(40, 6, "NISN")
+	pdf.Cell(5, 6, ":")
+	pdf.Cell(0, 6, data.Siswa.NISN)
+	pdf.Ln(5)
+	if data.Siswa.Kelas != nil {
+		pdf.Cell(40, 6, "Kelas")
+		pdf.Cell(5, 6, ":")
+		pdf.Cell(0, 6, data.Siswa.Kelas.Nama)
+		pdf.Ln(5)
+	}
+	if data.Peringkat != nil {
+		pdf.Cell(40, 6, "Peringkat")
+		pdf.Cell(5, 6, ":")
+		pdf.Cell(0, 6, fmt.Sprintf("%d dari %d siswa", data.Peringkat.Peringkat, data.Peringkat.JumlahSiswa))
+		pdf.Ln(5)
+	}
+	pdf.Ln(5)
+}
+
+func tulisNilaiKlasik(pdf *gofpdf.Fpdf, nilaiList []models.Nilai) {
+	// Tabel Nilai
+	pdf.SetFont("Arial", "B", 11)
+	pdf.Cell(0, 6, "DAFTAR NILAI")
+	pdf.Ln(6)
+
+	// Header tabel
+	pdf.SetFont("Arial", "B", 9)
+	pdf.SetFillColor(220, 220, 220)
+	pdf.CellFormat(10, 7, "No", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(70, 7, "Mata Pelajaran", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(20, 7, "Harian", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(20, 7, "UTS", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(20, 7, "UAS", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(25, 7, "Akhir", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(25, 7, "Predikat", "1", 0, "C", true, 0, "")
+	pdf.Ln(-1)
+
+	// Isi tabel
+	pdf.SetFont("Arial", "", 9)
+	totalNilai := 0.0
+	for i, n := range nilaiList {
+		pdf.CellFormat(10, 6, strconv.Itoa(i+1), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(70, 6, n.MataPelajaran.Nama, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(20, 6, fmt.Sprintf("%.1f", n.NilaiHarian), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(20, 6, fmt.Sprintf("%.1f", n.NilaiUTS), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(20, 6, fmt.Sprintf("%.1f", n.NilaiUAS), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(25, 6, fmt.Sprintf("%.2f", n.NilaiAkhir), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(25, 6, n.Predikat, "1", 0, "C", false, 0, "")
+		pdf.Ln(-1)
+		totalNilai += n.NilaiAkhir
+	}
+
+	// Rata-rata
+	rataRata := 0.0
+	if len(nilaiList) > 0 {
+		rataRata = totalNilai / float64(len(nilaiList))
+	}
+	pdf.SetFont("Arial", "B", 9)
+	pdf.CellFormat(140, 6, "RATA-RATA", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(25, 6, fmt.Sprintf("%.2f", rataRata), "1", 0, "C", false, 0, "")
+	pdf.CellFormat(25, 6, tentukanPredikat(rataRata), "1", 0, "C", false, 0, "")
+	pdf.Ln(10)
+}
+
+func tulisIntrakurikuler(pdf *gofpdf.Fpdf, judul string, data DataRapor) {
+	pdf.SetFont("Arial", "B", 11)
+	pdf.Cell(0, 6, judul)
+	pdf.Ln(6)
+
+	lebar := []float64{10, 50, 20, 110}
+	pdf.SetFont("Arial", "B", 9)
+	pdf.SetFillColor(220, 220, 220)
+	pdf.CellFormat(lebar[0], 7, "No", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(lebar[1], 7, "Mata Pelajaran", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(lebar[2], 7, "Nilai Akhir", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(lebar[3], 7, "Capaian Kompetensi", "1", 0, "C", true, 0, "")
+	pdf.Ln(-1)
+
+	pdf.SetFont("Arial", "", 9)
+	for i, n := range data.Nilai {
+		deskripsi := data.Deskripsi[n.MataPelajaranID]
+		if deskripsi == "" {
+			deskripsi = "-"
+		}
+		barisTabel(pdf, lebar,
+			[]string{strconv.Itoa(i + 1), n.MataPelajaran.Nama, fmt.Sprintf("%.0f", n.NilaiAkhir), deskripsi},
+			[]string{"C", "L", "C", "L"})
+	}
+	pdf.Ln(6)
+}
+
+func tulisProjekP5(pdf *gofpdf.Fpdf, judul string, projek []services.ProjekP5Siswa) {
+	pdf.SetFont("Arial", "B", 11)
+	pdf.Cell(0, 6, judul)
+	pdf.Ln(6)
+
+	if len(projek) == 0 {
+		pdf.SetFont("Arial", "", 9)
+		pdf.Cell(0, 6, "Belum ada penilaian projek pada semester ini.")
+		pdf.Ln(10)
+		return
+	}
+
+	lebar := []float64{80, 20, 90}
+	for i, p := range projek {
+		pdf.SetFont("Arial", "B", 10)
+		pdf.MultiCell(0, 5, fmt.Sprintf("%d. %s", i+1, p.Projek.Judul), "", "L", false)
+		pdf.SetFont("Arial", "I", 9)
+		pdf.MultiCell(0, 5, "Tema: "+p.Projek.Tema, "", "L", false)
+		if p.Projek.Deskripsi != "" {
+			pdf.SetFont("Arial", "", 9)
+			pdf.MultiCell(0, 5, p.Projek.Deskripsi, "", "L", false)
+		}
+		pdf.Ln(1)
+
+		pdf.SetFont("Arial", "B", 9)
+		pdf.SetFillColor(220, 220, 220)
+		pdf.CellFormat(lebar[0], 7, "Dimensi", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(lebar[1], 7, "Capaian", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(lebar[2], 7, "Catatan", "1", 0, "C", true, 0, "")
+		pdf.Ln(-1)
+
+		pdf.SetFont("Arial", "", 9)
+		for _, n := range p.Nilai {
+			catatan := n.Catatan
+			if catatan == "" {
+				catatan = "-"
+			}
+			barisTabel(pdf, lebar, []string{n.Dimensi, n.Predikat, catatan}, []string{"L", "C", "L"})
+		}
+		pdf.Ln(4)
+	}
+
+	pdf.SetFont("Arial", "I", 8)
+	pdf.MultiCell(0, 4, "Keterangan: MB = Mulai Berkembang, SB = Sedang Berkembang, "+
+		"BSH = Berkembang Sesuai Harapan, SAB = Sangat Berkembang", "", "L", false)
+	pdf.Ln(6)
+}
+
+func tulisEkstrakurikuler(pdf *gofpdf.Fpdf, judul string, ekskul []EkskulRapor) {
+	pdf.SetFont("Arial", "B", 11)
+	pdf.Cell(0, 6, judul)
+	pdf.Ln(6)
+
+	lebar := []float64{10, 60, 25, 95}
+	pdf.SetFont("Arial", "B", 9)
+	pdf.SetFillColor(220, 220, 220)
+	pdf.CellFormat(lebar[0], 7, "No", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(lebar[1], 7, "Kegiatan", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(lebar[2], 7, "Predikat", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(lebar[3], 7, "Keterangan", "1", 0, "C", true, 0, "")
+	pdf.Ln(-1)
+
+	pdf.SetFont("Arial", "", 9)
+	if len(ekskul) == 0 {
+		barisTabel(pdf, lebar, []string{"-", "-", "-", "-"}, []string{"C", "C", "C", "C"})
+	}
+	for i, e := range ekskul {
+		barisTabel(pdf, lebar,
+			[]string{strconv.Itoa(i + 1), e.Nama, e.Predikat, e.Keterangan},
+			[]string{"C", "L", "C", "L"})
+	}
+	pdf.Ln(6)
+}
+
+func tulisPerilaku(pdf *gofpdf.Fpdf, r services.RingkasanPerilaku) {
+	pdf.SetFont("Arial", "B", 11)
+	pdf.Cell(0, 6, "PERILAKU")
+	pdf.Ln(6)
+
+	lebar := []float64{35, 25, 130}
+	pdf.SetFont("Arial", "B", 9)
+	pdf.SetFillColor(220, 220, 220)
+	pdf.CellFormat(lebar[0], 7, "Predikat", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(lebar[1], 7, "Poin", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(lebar[2], 7, "Deskripsi", "1", 0, "C", true, 0, "")
+	pdf.Ln(-1)
+
+	pdf.SetFont("Arial", "", 9)
+	barisTabel(pdf, lebar,
+		[]string{r.Predikat, strconv.Itoa(r.TotalPoin), r.Deskripsi},
+		[]string{"C", "C", "L"})
+	pdf.Ln(6)
+}
+
+func tulisKehadiran(pdf *gofpdf.Fpdf, data DataRapor) {
+	labelAlfa := "Alfa"
+	if data.Template.Format != FormatRaporKlasik {
+		labelAlfa = "Tanpa Keterangan"
+	}
+
+	pdf.SetFont("Arial", "B", 11)
+	pdf.Cell(0, 6, "KEHADIRAN")
+	pdf.Ln(6)
+	pdf.SetFont("Arial", "", 10)
+	pdf.Cell(40, 6, "Hadir")
+	pdf.Cell(5, 6, ":")
+	pdf.Cell(0, 6, fmt.Sprintf("%d hari", data.Absensi.Hadir))
+	pdf.Ln(5)
+	pdf.Cell(40, 6, "Izin")
+	pdf.Cell(5, 6, ":")
+	pdf.Cell(0, 6, fmt.Sprintf("%d hari", data.Absensi.Izin))
+	pdf.Ln(5)
+	pdf.Cell(40, 6, "Sakit")
+	pdf.Cell(5, 6, ":")
+	pdf.Cell(0, 6, fmt.Sprintf("%d hari", data.Absensi.Sakit))
+	pdf.Ln(5)
+	pdf.Cell(40, 6, labelAlfa)
+	pdf.Cell(5, 6, ":")
+	pdf.Cell(0, 6, fmt.Sprintf("%d hari", data.Absensi.Alfa))
+	pdf.Ln(15)
+}
+
+func tulisTandaTangan(pdf *gofpdf.Fpdf, data DataRapor) {
+	t := data.Template
+	tinggi := 40.0
+	if t.TtdKepalaSekolah {
+		tinggi += 40
+	}
+
+	// Tanda tangan tidak boleh terpotong ke halaman berikutnya
+	_, tinggiHalaman := pdf.GetPageSize()
+	_, _, _, marginBawah := pdf.GetMargins()
+	if pdf.GetY()+tinggi > tinggiHalaman-marginBawah {
+		pdf.AddPage()
+	}
+
+	tanggal := time.Now().Format("02 January 2006")
+	if data.Sekolah.Kota != "" {
+		tanggal = data.Sekolah.Kota + ", " + tanggal
+	}
+	namaWali := "___________________"
+	if data.Siswa.Kelas != nil && data.Siswa.Kelas.WaliKelas != nil {
+		namaWali = data.Siswa.Kelas.WaliKelas.Nama
+	}
+
+	pdf.SetFont("Arial", "", 9)
+	pdf.Cell(100, 6, "")
+	pdf.Cell(0, 6, tanggal)
+	pdf.Ln(5)
+	if t.TtdOrangTua {
+		pdf.Cell(100, 6, "Orang Tua / Wali")
+	} else {
+		pdf.Cell(100, 6, "")
+	}
+	pdf.Cell(0, 6, "Wali Kelas")
+	pdf.Ln(20)
+	if t.TtdOrangTua {
+		pdf.Cell(100, 6, "___________________")
+	} else {
+		pdf.Cell(100, 6, "")
+	}
+	pdf.Cell(0, 6, namaWali)
+	pdf.Ln(10)
+
+	if t.TtdKepalaSekolah {
+		nama := data.Sekolah.KepalaSekolahNama
+		if nama == "" {
+			nama = "___________________"
+		}
+		pdf.CellFormat(0, 5, "Mengetahui,", "", 1, "C", false, 0, "")
+		pdf.CellFormat(0, 5, "Kepala Sekolah", "", 1, "C", false, 0, "")
+		pdf.Ln(18)
+		pdf.SetFont("Arial", "B", 9)
+		pdf.CellFormat(0, 5, nama, "", 1, "C", false, 0, "")
+		if data.Sekolah.KepalaSekolahNIP != "" {
+			pdf.SetFont("Arial", "", 9)
+			pdf.CellFormat(0, 5, "NIP. "+data.Sekolah.KepalaSekolahNIP, "", 1, "C", false, 0, "")
+		}
+	}
+}
+
+// tulisVerifikasi mencetak QR menuju halaman verifikasi publik beserta kode
+// dan hash konten rapor
+func tulisVerifikasi(pdf *gofpdf.Fpdf, v VerifikasiRapor) {
+	const sisi = 24.0
+
+	_, tinggiHalaman := pdf.GetPageSize()
+	_, _, _, marginBawah := pdf.GetMargins()
+	pdf.Ln(6)
+	if pdf.GetY()+sisi > tinggiHalaman-marginBawah {
+		pdf.AddPage()
+	}
+
+	gambar, err := services.QRCodePNG(v.URL, 240)
+	if err != nil {
+		return
+	}
+	nama := "qr-rapor-" + v.Kode
+	pdf.RegisterImageOptionsReader(nama, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(gambar))
+
+	kiri, _, _, _ := pdf.GetMargins()
+	atas := pdf.GetY()
+	pdf.ImageOptions(nama, kiri, atas, sisi, sisi, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+	pdf.SetXY(kiri+sisi+3, atas+2)
+	pdf.SetFont("Arial", "B", 8)
+	pdf.Cell(0, 4, "Verifikasi keaslian rapor")
+	pdf.SetXY(kiri+sisi+3, atas+7)
+	pdf.SetFont("Arial", "", 7)
+	pdf.Cell(0, 4, "Pindai QR atau buka: "+v.URL)
+	pdf.SetXY(kiri+sisi+3, atas+11)
+	pdf.Cell(0, 4, "Kode verifikasi: "+v.Kode)
+	pdf.SetXY(kiri+sisi+3, atas+15)
+	pdf.SetFont("Courier", "", 6)
+	pdf.Cell(0, 4, "SHA-256: "+v.Hash)
+	pdf.SetY(atas + sisi)
+}
+
+// barisTabel menulis satu baris tabel yang selnya boleh lebih dari satu baris teks;
+// tinggi baris mengikuti sel dengan teks terpanjang
+func barisTabel(pdf *gofpdf.Fpdf, lebar []float64, isi []string, rata []string) {
+	const tinggiTeks = 5.0
+
+	jumlahBaris := 1
+	for i, s := range isi {
+		if n := len(pdf.SplitLines([]byte(s), lebar[i]-2)); n > jumlahBaris {
+			jumlahBaris = n
+		}
+	}
+	tinggi := float64(jumlahBaris) * tinggiTeks
+
+	_, tinggiHalaman := pdf.GetPageSize()
+	_, _, _, marginBawah := pdf.GetMargins()
+	if pdf.GetY()+tinggi > tinggiHalaman-marginBawah {
+		pdf.AddPage()
+	}
+
+	xAwal, y := pdf.GetXY()
+	x := xAwal
+	for i, s := range isi {
+		pdf.Rect(x, y, lebar[i], tinggi, "D")
+		pdf.SetXY(x, y)
+		pdf.MultiCell(lebar[i], tinggiTeks, s, "", rata[i], false)
+		x += lebar[i]
+	}
+	pdf.SetXY(xAwal, y+tinggi)
+}
+
+// contohDataRapor membuat data rapor fiktif untuk pratinjau template
+func contohDataRapor(template models.TemplateRapor, sekolah models.ProfilSekolah) DataRapor {
+	siswa := models.Siswa{Nama: "Siswa Contoh", NISN: "0012345678"}
+	siswa.Kelas = &models.Kelas{Nama: "X Contoh", WaliKelas: &models.Guru{Nama: "Wali Kelas Contoh"}}
+
+	mapel := []string{"Pendidikan Agama", "Bahasa Indonesia", "Matematika", "Bahasa Inggris"}
+	data := DataRapor{
+		Template: template,
+		Sekolah:  sekolah,
+		Siswa:    siswa,
+		Semester: models.Semester{
+			Nama:        "Ganjil",
+			TahunAjaran: models.TahunAjaran{Nama: fmt.Sprintf("%d/%d", time.Now().Year(), time.Now().Year()+1)},
+		},
+		Absensi:   AbsensiRekap{Hadir: 110, Izin: 2, Sakit: 3, Alfa: 1},
+		Deskripsi: map[uint]string{},
+		Ekskul:    []EkskulRapor{{Nama: "Pramuka", Predikat: "Baik", Keterangan: "Aktif mengikuti kegiatan"}},
+	}
+	if template.TampilkanPeringkat {
+		data.Peringkat = &PeringkatRapor{Peringkat: 3, JumlahSiswa: 32}
+	}
+	if template.TampilkanPerilaku {
+		data.Perilaku = &services.RingkasanPerilaku{
+			JumlahPelanggaran: 1,
+			TotalPoin:         5,
+			Predikat:          services.PredikatSikap(5),
+			Deskripsi:         "Tercatat 1 pelanggaran (5 poin). Secara umum menunjukkan sikap yang baik.",
+		}
+	}
+	for i, nama := range mapel {
+		id := uint(i + 1)
+		akhir := 78.0 + float64(i*4)
+		data.Nilai = append(data.Nilai, models.Nilai{
+			MataPelajaranID: id,
+			NilaiHarian:     akhir,
+			NilaiUTS:        akhir - 2,
+			NilaiUAS:        akhir + 2,
+			NilaiAkhir:      akhir,
+			Predikat:        tentukanPredikat(akhir),
+			MataPelajaran:   models.MataPelajaran{ID: id, Nama: nama},
+		})
+		data.Deskripsi[id] = services.SusunDeskripsiCapaian(siswa.Nama,
+			[]string{"memahami konsep dasar " + strings.ToLower(nama), "menerapkan konsep dalam soal sederhana"},
+			[]string{"menyelesaikan soal analisis"})
+	}
+	data.P5 = []services.ProjekP5Siswa{{
+		Projek: models.ProjekP5{Judul: "Projek Contoh", Tema: "Gaya Hidup Berkelanjutan"},
+		Nilai: []models.NilaiP5{
+			{Dimensi: models.DimensiP5[2], Predikat: models.P5BerkembangSesuaiHarapan},
+			{Dimensi: models.DimensiP5[5], Predikat: models.P5SedangBerkembang},
+		},
+	}}
+	return data
+}
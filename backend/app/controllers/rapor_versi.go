@@ -0,0 +1,504 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sim-sekolah/app/middlewares"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+var errAlasanRevisi = errors.New("rapor sudah terbit, isi alasan untuk membuat versi revisi")
+
+// opsiGenerateRapor mengatur pembuatan versi rapor
+type opsiGenerateRapor struct {
+	Alasan    string // wajib jika rapor sudah pernah terbit
+	Terbitkan bool   // langsung terbitkan versi yang dibuat
+	Oleh      uint   // user ID pembuat
+}
+
+// SnapshotRapor adalah salinan data yang dicetak pada satu versi rapor,
+// disimpan agar isi versi lama tetap bisa ditelusuri walaupun nilai berubah
+type SnapshotRapor struct {
+	Siswa struct {
+		ID    uint   `json:"id"`
+		NISN  string `json:"nisn"`
+		Nama  string `json:"nama"`
+		Kelas string `json:"kelas"`
+	} `json:"siswa"`
+	Semester struct {
+		ID          uint   `json:"id"`
+		Nama        string `json:"nama"`
+		TahunAjaran string `json:"tahun_ajaran"`
+	} `json:"semester"`
+	Template struct {
+		ID     uint   `json:"id"`
+		Nama   string `json:"nama"`
+		Format string `json:"format"`
+	} `json:"template"`
+	Nilai   []SnapshotNilai `json:"nilai"`
+	Absensi AbsensiRekap    `json:"absensi"`
+	P5      []struct {
+		Judul string            `json:"judul"`
+		Nilai map[string]string `json:"nilai"` // dimensi → predikat
+	} `json:"p5,omitempty"`
+	Ekskul     []EkskulRapor               `json:"ekskul,omitempty"`
+	Peringkat  *PeringkatRapor             `json:"peringkat,omitempty"`
+	Perilaku   *services.RingkasanPerilaku `json:"perilaku,omitempty"`
+	DibuatPada time.Time                   `json:"dibuat_pada"`
+}
+
+type SnapshotNilai struct {
+	MataPelajaranID uint    `json:"mata_pelajaran_id"`
+	MataPelajaran   string  `json:"mata_pelajaran"`
+	NilaiHarian     float64 `json:"nilai_harian"`
+	NilaiUTS        float64 `json:"nilai_uts"`
+	NilaiUAS        float64 `json:"nilai_uas"`
+	NilaiAkhir      float64 `json:"nilai_akhir"`
+	Predikat        string  `json:"predikat"`
+	Deskripsi       string  `json:"deskripsi,omitempty"`
+}
+
+func snapshotRapor(data DataRapor) SnapshotRapor {
+	var s SnapshotRapor
+	s.Siswa.ID = data.Siswa.ID
+	s.Siswa.NISN = data.Siswa.NISN
+	s.Siswa.Nama = data.Siswa.Nama
+	if data.Siswa.Kelas != nil {
+		s.Siswa.Kelas = data.Siswa.Kelas.Nama
+	}
+	s.Semester.ID = data.Semester.ID
+	s.Semester.Nama = data.Semester.Nama
+	s.Semester.TahunAjaran = data.Semester.TahunAjaran.Nama
+	s.Template.ID = data.Template.ID
+	s.Template.Nama = data.Template.Nama
+	s.Template.Format = data.Template.Format
+
+	for _, n := range data.Nilai {
+		s.Nilai = append(s.Nilai, SnapshotNilai{
+			MataPelajaranID: n.MataPelajaranID,
+			MataPelajaran:   n.MataPelajaran.Nama,
+			NilaiHarian:     n.NilaiHarian,
+			NilaiUTS:        n.NilaiUTS,
+			NilaiUAS:        n.NilaiUAS,
+			NilaiAkhir:      n.NilaiAkhir,
+			Predikat:        n.Predikat,
+			Deskripsi:       data.Deskripsi[n.MataPelajaranID],
+		})
+	}
+	s.Absensi = data.Absensi
+	for _, p := range data.P5 {
+		nilai := map[string]string{}
+		for _, n := range p.Nilai {
+			nilai[n.Dimensi] = n.Predikat
+		}
+		s.P5 = append(s.P5, struct {
+			Judul string            `json:"judul"`
+			Nilai map[string]string `json:"nilai"`
+		}{Judul: p.Projek.Judul, Nilai: nilai})
+	}
+	s.Ekskul = data.Ekskul
+	s.Peringkat = data.Peringkat
+	s.Perilaku = data.Perilaku
+	s.DibuatPada = time.Now()
+	return s
+}
+
+// kunciRapor mengambil (atau membuat) rapor siswa+semester dan menguncinya
+// sampai transaksi selesai
+func kunciRapor(tx *gorm.DB, siswaID, semesterID uint) (models.Rapor, error) {
+	rapor := models.Rapor{SiswaID: siswaID, SemesterID: semesterID, Status: models.RaporDraft}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rapor).Error; err != nil {
+		return rapor, err
+	}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("siswa_id = ? AND semester_id = ?", siswaID, semesterID).
+		First(&rapor).Error
+	return rapor, err
+}
+
+// generateRaporSiswa membuat PDF rapor seorang siswa sebagai versi baru.
+// Draft terakhir yang belum terbit ditimpa (file lamanya dihapus); jika rapor
+// sudah terbit dibuat versi revisi yang wajib disertai alasan.
+// siswa.Kelas diharapkan sudah berisi kelas yang berlaku di semester tersebut.
+func generateRaporSiswa(siswa models.Siswa, semester models.Semester, template models.TemplateRapor, opsi opsiGenerateRapor) (models.RaporVersi, DataRapor, error) {
+	data := susunDataRapor(siswa, semester, template)
+	if len(data.Nilai) == 0 {
+		return models.RaporVersi{}, data, errBelumAdaNilai
+	}
+	snapshot, err := json.Marshal(snapshotRapor(data))
+	if err != nil {
+		return models.RaporVersi{}, data, err
+	}
+	hashKonten, err := services.HashKonten(snapshot)
+	if err != nil {
+		return models.RaporVersi{}, data, err
+	}
+
+	outputDir := "./storage/rapor"
+	os.MkdirAll(outputDir, 0755)
+
+	var versi models.RaporVersi
+	var path, fileLama string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		rapor, err := kunciRapor(tx, siswa.ID, semester.ID)
+		if err != nil {
+			return err
+		}
+
+		var draft *models.RaporVersi
+		if rapor.VersiTerakhir > 0 {
+			var terakhir models.RaporVersi
+			if err := tx.Where("rapor_id = ? AND nomor = ?", rapor.ID, rapor.VersiTerakhir).
+				First(&terakhir).Error; err == nil && terakhir.Status == models.RaporDraft {
+				draft = &terakhir
+			}
+		}
+		if draft == nil && rapor.VersiTerbit > 0 && strings.TrimSpace(opsi.Alasan) == "" {
+			return errAlasanRevisi
+		}
+
+		if draft != nil {
+			versi = *draft
+			fileLama = draft.FilePath
+		} else {
+			versi = models.RaporVersi{
+				RaporID: rapor.ID,
+				Nomor:   rapor.VersiTerakhir + 1,
+				Status:  models.RaporDraft,
+			}
+		}
+
+		// Draft yang ditimpa tetap memakai kode verifikasinya
+		if versi.KodeVerifikasi == nil {
+			kode, err := services.KodeVerifikasiAcak()
+			if err != nil {
+				return err
+			}
+			versi.KodeVerifikasi = &kode
+		}
+		data.Verifikasi = &VerifikasiRapor{
+			Kode: *versi.KodeVerifikasi,
+			URL:  services.URLVerifikasiRapor(*versi.KodeVerifikasi),
+			Hash: hashKonten,
+		}
+
+		path = filepath.Join(outputDir, fmt.Sprintf("rapor_%d_sem%d_v%d_%d.pdf",
+			siswa.ID, semester.ID, versi.Nomor, time.Now().UnixNano()))
+		if err := buatPDFRapor(path, data); err != nil {
+			return err
+		}
+		hashFile, err := services.HashFile(path)
+		if err != nil {
+			return err
+		}
+
+		versi.FilePath = path
+		versi.Snapshot = string(snapshot)
+		versi.HashKonten = hashKonten
+		versi.HashFile = hashFile
+		versi.Format = template.Format
+		versi.TemplateRaporID = nil
+		if template.ID != 0 {
+			id := template.ID
+			versi.TemplateRaporID = &id
+		}
+		if a := strings.TrimSpace(opsi.Alasan); a != "" {
+			versi.Alasan = a
+		}
+		if opsi.Oleh != 0 {
+			oleh := opsi.Oleh
+			versi.DibuatOleh = &oleh
+		}
+		if err := tx.Save(&versi).Error; err != nil {
+			return err
+		}
+
+		update := map[string]interface{}{"versi_terakhir": versi.Nomor}
+		if rapor.VersiTerbit == 0 {
+			update["file_path"] = path
+		}
+		if err := tx.Model(&rapor).Updates(update).Error; err != nil {
+			return err
+		}
+
+		if opsi.Terbitkan {
+			return terbitkanVersi(tx, &rapor, &versi, opsi.Oleh)
+		}
+		return nil
+	})
+	if err != nil {
+		if path != "" {
+			os.Remove(path)
+		}
+		return models.RaporVersi{}, data, err
+	}
+	if fileLama != "" && fileLama != path {
+		os.Remove(fileLama)
+	}
+	return versi, data, nil
+}
+
+// terbitkanVersi menerbitkan versi draft; versi yang sebelumnya terbit
+// berubah status menjadi revised
+func terbitkanVersi(tx *gorm.DB, rapor *models.Rapor, versi *models.RaporVersi, oleh uint) error {
+	if rapor.VersiTerbit > 0 {
+		if err := tx.Model(&models.RaporVersi{}).
+			Where("rapor_id = ? AND nomor = ?", rapor.ID, rapor.VersiTerbit).
+			Update("status", models.RaporRevised).Error; err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	versi.Status = models.RaporPublished
+	versi.DiterbitkanPada = &now
+	if oleh != 0 {
+		versi.DiterbitkanOleh = &oleh
+	}
+	if err := tx.Model(versi).Updates(map[string]interface{}{
+		"status":           versi.Status,
+		"diterbitkan_pada": versi.DiterbitkanPada,
+		"diterbitkan_oleh": versi.DiterbitkanOleh,
+	}).Error; err != nil {
+		return err
+	}
+
+	rapor.Status = models.RaporPublished
+	rapor.VersiTerbit = versi.Nomor
+	rapor.FilePath = versi.FilePath
+	return tx.Model(rapor).Updates(map[string]interface{}{
+		"status":       rapor.Status,
+		"versi_terbit": rapor.VersiTerbit,
+		"file_path":    rapor.FilePath,
+	}).Error
+}
+
+// ── Handlers ──────────────────────────────────────────────────
+
+// TerbitkanRapor godoc
+// @Summary Terbitkan draft terakhir rapor
+// @Tags Rapor
+// @Security BearerAuth
+// @Param id path int true "Rapor ID"
+// @Router /rapor/{id}/terbitkan [post]
+func TerbitkanRapor(c *gin.Context) {
+	claims := middlewares.GetCurrentUser(c)
+
+	var rapor models.Rapor
+	if err := config.DB.First(&rapor, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Rapor tidak ditemukan")
+		return
+	}
+	if !pastikanWaliKelasRapor(c, services.KelasSiswaDiSemester(rapor.SiswaID, rapor.SemesterID)) {
+		return
+	}
+
+	var versi models.RaporVersi
+	var errTidakAdaDraft = errors.New("tidak ada draft")
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&rapor, c.Param("id")).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("rapor_id = ? AND nomor = ?", rapor.ID, rapor.VersiTerakhir).
+			First(&versi).Error; err != nil || versi.Status != models.RaporDraft {
+			return errTidakAdaDraft
+		}
+		return terbitkanVersi(tx, &rapor, &versi, claims.UserID)
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ResponseNotFound(c, "Rapor tidak ditemukan")
+		return
+	case errors.Is(err, errTidakAdaDraft):
+		utils.ResponseBadRequest(c, "Tidak ada draft rapor yang bisa diterbitkan", nil)
+		return
+	case err != nil:
+		utils.ResponseInternalError(c, "Gagal menerbitkan rapor")
+		return
+	}
+
+	utils.ResponseOK(c, "Rapor versi "+strconv.Itoa(versi.Nomor)+" diterbitkan", gin.H{
+		"rapor": rapor,
+		"versi": versi,
+	})
+}
+
+// GetVersiRapor godoc
+// @Summary Riwayat versi rapor
+// @Tags Rapor
+// @Security BearerAuth
+// @Param id path int true "Rapor ID"
+// @Router /rapor/{id}/versi [get]
+func GetVersiRapor(c *gin.Context) {
+	var rapor models.Rapor
+	if err := config.DB.First(&rapor, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Rapor tidak ditemukan")
+		return
+	}
+	if !pastikanWaliKelasRapor(c, services.KelasSiswaDiSemester(rapor.SiswaID, rapor.SemesterID)) {
+		return
+	}
+	var list []models.RaporVersi
+	config.DB.Where("rapor_id = ?", rapor.ID).Order("nomor DESC").Find(&list)
+	utils.ResponseOK(c, "Riwayat versi rapor", list)
+}
+
+// GetVersiRaporDetail godoc
+// @Summary Detail satu versi rapor beserta snapshot datanya
+// @Tags Rapor
+// @Security BearerAuth
+// @Param id path int true "Rapor ID"
+// @Param nomor path int true "Nomor versi"
+// @Router /rapor/{id}/versi/{nomor} [get]
+func GetVersiRaporDetail(c *gin.Context) {
+	versi, ok := cariVersiRapor(c)
+	if !ok {
+		return
+	}
+	utils.ResponseOK(c, "Detail versi rapor", gin.H{
+		"versi":    versi,
+		"snapshot": json.RawMessage(versi.Snapshot),
+	})
+}
+
+// DownloadVersiRapor godoc
+// @Summary Download PDF versi tertentu sebuah rapor
+// @Tags Rapor
+// @Security BearerAuth
+// @Param id path int true "Rapor ID"
+// @Param nomor path int true "Nomor versi"
+// @Router /rapor/{id}/versi/{nomor}/download [get]
+func DownloadVersiRapor(c *gin.Context) {
+	versi, ok := cariVersiRapor(c)
+	if !ok {
+		return
+	}
+	kirimFilePDF(c, versi.FilePath)
+}
+
+// CabutVersiRapor godoc
+// @Summary Cabut versi rapor yang sudah terbit sehingga tidak lagi sah saat diverifikasi
+// @Tags Rapor
+// @Security BearerAuth
+// @Param id path int true "Rapor ID"
+// @Param nomor path int true "Nomor versi"
+// @Router /rapor/{id}/versi/{nomor}/cabut [post]
+func CabutVersiRapor(c *gin.Context) {
+	var req struct {
+		Alasan string `json:"alasan" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Alasan pencabutan wajib diisi", err.Error())
+		return
+	}
+	claims := middlewares.GetCurrentUser(c)
+
+	var versi models.RaporVersi
+	var errBukanTerbit = errors.New("versi belum terbit")
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var rapor models.Rapor
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&rapor, c.Param("id")).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("rapor_id = ? AND nomor = ?", rapor.ID, c.Param("nomor")).First(&versi).Error; err != nil {
+			return err
+		}
+		if versi.Status != models.RaporPublished && versi.Status != models.RaporRevised {
+			return errBukanTerbit
+		}
+
+		now := time.Now()
+		versi.Status = models.RaporRevoked
+		versi.DicabutOleh = &claims.UserID
+		versi.DicabutPada = &now
+		versi.AlasanCabut = req.Alasan
+		if err := tx.Model(&versi).Updates(map[string]interface{}{
+			"status":       versi.Status,
+			"dicabut_oleh": versi.DicabutOleh,
+			"dicabut_pada": versi.DicabutPada,
+			"alasan_cabut": versi.AlasanCabut,
+		}).Error; err != nil {
+			return err
+		}
+
+		// Versi yang sedang berlaku dicabut: rapor kembali belum terbit
+		if rapor.VersiTerbit == versi.Nomor {
+			return tx.Model(&rapor).Updates(map[string]interface{}{
+				"status":       models.RaporDraft,
+				"versi_terbit": 0,
+			}).Error
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ResponseNotFound(c, "Versi rapor tidak ditemukan")
+		return
+	case errors.Is(err, errBukanTerbit):
+		utils.ResponseBadRequest(c, "Hanya versi yang sudah terbit yang bisa dicabut (status: "+versi.Status+")", nil)
+		return
+	case err != nil:
+		utils.ResponseInternalError(c, "Gagal mencabut versi rapor")
+		return
+	}
+	utils.ResponseOK(c, "Rapor versi "+strconv.Itoa(versi.Nomor)+" dicabut", versi)
+}
+
+func cariVersiRapor(c *gin.Context) (models.RaporVersi, bool) {
+	var versi models.RaporVersi
+	var rapor models.Rapor
+	if err := config.DB.First(&rapor, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Rapor tidak ditemukan")
+		return versi, false
+	}
+	if !pastikanWaliKelasRapor(c, services.KelasSiswaDiSemester(rapor.SiswaID, rapor.SemesterID)) {
+		return versi, false
+	}
+	if err := config.DB.Where("rapor_id = ? AND nomor = ?", rapor.ID, c.Param("nomor")).
+		First(&versi).Error; err != nil {
+		utils.ResponseNotFound(c, "Versi rapor tidak ditemukan")
+		return versi, false
+	}
+	return versi, true
+}
+
+// pastikanWaliKelasRapor memastikan wali kelas hanya membuat, menerbitkan atau
+// membaca riwayat versi rapor siswa yang pada semester rapor berada di kelas
+// perwaliannya
+func pastikanWaliKelasRapor(c *gin.Context, kelas *models.Kelas) bool {
+	if middlewares.GetCurrentUser(c).Role != models.RoleWaliKelas {
+		return true
+	}
+	if kelas == nil {
+		utils.ResponseForbidden(c, "Siswa tidak terdaftar di kelas mana pun pada semester ini")
+		return false
+	}
+	return pastikanWaliKelas(c, *kelas)
+}
+
+// kirimFilePDF mengirim file PDF rapor sebagai lampiran
+func kirimFilePDF(c *gin.Context, path string) {
+	if _, err := os.Stat(path); path == "" || os.IsNotExist(err) {
+		utils.ResponseNotFound(c, "File rapor tidak ditemukan di server")
+		return
+	}
+
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Transfer-Encoding", "binary")
+	c.Header("Content-Disposition", "attachment; filename="+filepath.Base(path))
+	c.Header("Content-Type", "application/pdf")
+	c.File(path)
+}
@@ -50,6 +50,7 @@ func CreateRole(c *gin.Context) {
 	var req struct {
 		Nama      string `json:"nama" binding:"required,min=3,max=50"`
 		Deskripsi string `json:"deskripsi" binding:"max=255"`
+		Wajib2FA  bool   `json:"wajib_2fa"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
@@ -63,7 +64,7 @@ func CreateRole(c *gin.Context) {
 		return
 	}
 
-	role := models.Role{Nama: req.Nama, Deskripsi: req.Deskripsi}
+	role := models.Role{Nama: req.Nama, Deskripsi: req.Deskripsi, Wajib2FA: req.Wajib2FA}
 	if err := config.DB.Create(&role).Error; err != nil {
 		utils.ResponseInternalError(c, "Gagal membuat role")
 		return
@@ -91,6 +92,7 @@ func UpdateRole(c *gin.Context) {
 	var req struct {
 		Nama      string `json:"nama" binding:"omitempty,min=3,max=50"`
 		Deskripsi string `json:"deskripsi"`
+		Wajib2FA  *bool  `json:"wajib_2fa"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
@@ -101,6 +103,9 @@ func UpdateRole(c *gin.Context) {
 		role.Nama = req.Nama
 	}
 	role.Deskripsi = req.Deskripsi
+	if req.Wajib2FA != nil {
+		role.Wajib2FA = *req.Wajib2FA
+	}
 
 	if err := config.DB.Save(&role).Error; err != nil {
 		utils.ResponseInternalError(c, "Gagal mengupdate role")
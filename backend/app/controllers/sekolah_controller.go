@@ -0,0 +1,332 @@
+package controllers
+
+import (
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// ── Profil Sekolah ────────────────────────────────────────────
+
+// GetProfilSekolah godoc
+// @Summary Identitas sekolah
+// @Tags Sekolah
+// @Security BearerAuth
+// @Router /sekolah/profil [get]
+func GetProfilSekolah(c *gin.Context) {
+	utils.ResponseOK(c, "Profil sekolah", services.ProfilSekolah())
+}
+
+// UpdateProfilSekolah godoc
+// @Summary Update identitas sekolah
+// @Tags Sekolah
+// @Security BearerAuth
+// @Router /sekolah/profil [put]
+func UpdateProfilSekolah(c *gin.Context) {
+	var req struct {
+		Nama              *string `json:"nama" binding:"omitempty,max=150"`
+		NPSN              *string `json:"npsn" binding:"omitempty,max=20"`
+		Alamat            *string `json:"alamat"`
+		Kota              *string `json:"kota" binding:"omitempty,max=100"`
+		Telepon           *string `json:"telepon" binding:"omitempty,max=30"`
+		Email             *string `json:"email" binding:"omitempty,max=100"`
+		Website           *string `json:"website" binding:"omitempty,max=100"`
+		KepalaSekolahNama *string `json:"kepala_sekolah_nama" binding:"omitempty,max=100"`
+		KepalaSekolahNIP  *string `json:"kepala_sekolah_nip" binding:"omitempty,max=30"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	profil := services.ProfilSekolah()
+	isi := []struct {
+		nilai *string
+		field *string
+	}{
+		{req.Nama, &profil.Nama},
+		{req.NPSN, &profil.NPSN},
+		{req.Alamat, &profil.Alamat},
+		{req.Kota, &profil.Kota},
+		{req.Telepon, &profil.Telepon},
+		{req.Email, &profil.Email},
+		{req.Website, &profil.Website},
+		{req.KepalaSekolahNama, &profil.KepalaSekolahNama},
+		{req.KepalaSekolahNIP, &profil.KepalaSekolahNIP},
+	}
+	for _, f := range isi {
+		if f.nilai != nil {
+			*f.field = strings.TrimSpace(*f.nilai)
+		}
+	}
+
+	if err := config.DB.Save(&profil).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan profil sekolah")
+		return
+	}
+	utils.ResponseOK(c, "Profil sekolah berhasil diupdate", profil)
+}
+
+// UploadLogoSekolah godoc
+// @Summary Upload logo sekolah (JPG/PNG, maks 1MB)
+// @Tags Sekolah
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Param logo formData file true "File logo"
+// @Router /sekolah/logo [post]
+func UploadLogoSekolah(c *gin.Context) {
+	file, header, err := c.Request.FormFile("logo")
+	if err != nil {
+		utils.ResponseBadRequest(c, "File tidak ditemukan", err.Error())
+		return
+	}
+	defer file.Close()
+
+	profil := services.ProfilSekolah()
+	logoLama := profil.LogoPath
+
+	// PDF generator hanya mendukung JPG dan PNG
+	filePath, ok := utils.SimpanUpload(c, header, utils.AturanUpload{
+		Dir:      "uploads/sekolah",
+		Prefix:   "logo",
+		Ekstensi: []string{".jpg", ".jpeg", ".png"},
+		MaksByte: 1024 * 1024,
+	})
+	if !ok {
+		return
+	}
+
+	profil.LogoPath = filePath
+	if err := config.DB.Save(&profil).Error; err != nil {
+		os.Remove(filePath) // rollback: hapus file yang baru diupload
+		utils.ResponseInternalError(c, "Gagal menyimpan path logo")
+		return
+	}
+	if logoLama != "" {
+		os.Remove(logoLama)
+	}
+
+	utils.ResponseOK(c, "Logo sekolah berhasil diupload", gin.H{
+		"logo_path": filePath,
+		"logo_url":  "/" + filePath,
+	})
+}
+
+// ── Template Rapor ────────────────────────────────────────────
+
+type TemplateRaporRequest struct {
+	Nama               string `json:"nama" binding:"required,max=100"`
+	Format             string `json:"format" binding:"omitempty,oneof=merdeka klasik"`
+	UkuranKertas       string `json:"ukuran_kertas" binding:"omitempty,oneof=A4 F4 Letter"`
+	Judul              string `json:"judul" binding:"max=100"`
+	TampilkanKop       *bool  `json:"tampilkan_kop"`
+	TampilkanLogo      *bool  `json:"tampilkan_logo"`
+	TampilkanP5        *bool  `json:"tampilkan_p5"`
+	TampilkanEkskul    *bool  `json:"tampilkan_ekskul"`
+	TampilkanKehadiran *bool  `json:"tampilkan_kehadiran"`
+	TampilkanPeringkat *bool  `json:"tampilkan_peringkat"`
+	TampilkanPerilaku  *bool  `json:"tampilkan_perilaku"`
+	TtdOrangTua        *bool  `json:"ttd_orang_tua"`
+	TtdKepalaSekolah   *bool  `json:"ttd_kepala_sekolah"`
+	CatatanKaki        string `json:"catatan_kaki"`
+	IsDefault          bool   `json:"is_default"`
+}
+
+// terapkanOpsiTemplate menyalin opsi tampilan yang dikirim ke template
+func terapkanOpsiTemplate(t *models.TemplateRapor, req TemplateRaporRequest) {
+	opsi := []struct {
+		nilai *bool
+		field *bool
+	}{
+		{req.TampilkanKop, &t.TampilkanKop},
+		{req.TampilkanLogo, &t.TampilkanLogo},
+		{req.TampilkanP5, &t.TampilkanP5},
+		{req.TampilkanEkskul, &t.TampilkanEkskul},
+		{req.TampilkanKehadiran, &t.TampilkanKehadiran},
+		{req.TampilkanPeringkat, &t.TampilkanPeringkat},
+		{req.TampilkanPerilaku, &t.TampilkanPerilaku},
+		{req.TtdOrangTua, &t.TtdOrangTua},
+		{req.TtdKepalaSekolah, &t.TtdKepalaSekolah},
+	}
+	for _, o := range opsi {
+		if o.nilai != nil {
+			*o.field = *o.nilai
+		}
+	}
+}
+
+// simpanTemplateRapor menyimpan template; jika default, template lain tidak lagi default
+func simpanTemplateRapor(t *models.TemplateRapor) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if t.IsDefault {
+			if err := tx.Model(&models.TemplateRapor{}).Where("id != ?", t.ID).Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(t).Error
+	})
+}
+
+// GetTemplateRapor godoc
+// @Summary Daftar template rapor
+// @Tags Sekolah
+// @Security BearerAuth
+// @Router /template-rapor [get]
+func GetTemplateRapor(c *gin.Context) {
+	var list []models.TemplateRapor
+	config.DB.Order("is_default DESC, nama ASC").Find(&list)
+	utils.ResponseOK(c, "Daftar template rapor", list)
+}
+
+// GetTemplateRaporByID godoc
+// @Summary Detail template rapor
+// @Tags Sekolah
+// @Security BearerAuth
+// @Param id path int true "ID"
+// @Router /template-rapor/{id} [get]
+func GetTemplateRaporByID(c *gin.Context) {
+	var t models.TemplateRapor
+	if err := config.DB.First(&t, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Template rapor tidak ditemukan")
+		return
+	}
+	utils.ResponseOK(c, "Detail template rapor", t)
+}
+
+// CreateTemplateRapor godoc
+// @Summary Tambah template rapor
+// @Tags Sekolah
+// @Security BearerAuth
+// @Router /template-rapor [post]
+func CreateTemplateRapor(c *gin.Context) {
+	var req TemplateRaporRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	var existing models.TemplateRapor
+	if err := config.DB.Where("nama = ?", req.Nama).First(&existing).Error; err == nil {
+		utils.ResponseBadRequest(c, "Nama template sudah dipakai", nil)
+		return
+	}
+
+	// Opsi yang tidak dikirim mengikuti template bawaan
+	t := services.TemplateRaporBawaan()
+	t.Nama = req.Nama
+	t.Judul = req.Judul
+	t.CatatanKaki = req.CatatanKaki
+	t.IsDefault = req.IsDefault
+	if req.Format != "" {
+		t.Format = req.Format
+	}
+	if req.UkuranKertas != "" {
+		t.UkuranKertas = req.UkuranKertas
+	}
+	terapkanOpsiTemplate(&t, req)
+
+	if err := simpanTemplateRapor(&t); err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan template rapor")
+		return
+	}
+	utils.ResponseCreated(c, "Template rapor berhasil ditambahkan", t)
+}
+
+// UpdateTemplateRapor godoc
+// @Summary Update template rapor
+// @Tags Sekolah
+// @Security BearerAuth
+// @Param id path int true "ID"
+// @Router /template-rapor/{id} [put]
+func UpdateTemplateRapor(c *gin.Context) {
+	var t models.TemplateRapor
+	if err := config.DB.First(&t, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Template rapor tidak ditemukan")
+		return
+	}
+
+	var req TemplateRaporRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	if req.Nama != t.Nama {
+		var existing models.TemplateRapor
+		if err := config.DB.Where("nama = ? AND id != ?", req.Nama, t.ID).First(&existing).Error; err == nil {
+			utils.ResponseBadRequest(c, "Nama template sudah dipakai", nil)
+			return
+		}
+		t.Nama = req.Nama
+	}
+	if req.Format != "" {
+		t.Format = req.Format
+	}
+	if req.UkuranKertas != "" {
+		t.UkuranKertas = req.UkuranKertas
+	}
+	t.Judul = req.Judul
+	t.CatatanKaki = req.CatatanKaki
+	t.IsDefault = req.IsDefault
+	terapkanOpsiTemplate(&t, req)
+
+	if err := simpanTemplateRapor(&t); err != nil {
+		utils.ResponseInternalError(c, "Gagal mengupdate template rapor")
+		return
+	}
+	utils.ResponseOK(c, "Template rapor berhasil diupdate", t)
+}
+
+// DeleteTemplateRapor godoc
+// @Summary Hapus template rapor
+// @Tags Sekolah
+// @Security BearerAuth
+// @Param id path int true "ID"
+// @Router /template-rapor/{id} [delete]
+func DeleteTemplateRapor(c *gin.Context) {
+	var t models.TemplateRapor
+	if err := config.DB.First(&t, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Template rapor tidak ditemukan")
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.TahunAjaran{}).Where("template_rapor_id = ?", t.ID).Count(&count)
+	if count > 0 {
+		utils.ResponseBadRequest(c, "Template masih dipakai oleh tahun ajaran, tidak bisa dihapus", nil)
+		return
+	}
+
+	config.DB.Delete(&t)
+	utils.ResponseOK(c, "Template rapor berhasil dihapus", nil)
+}
+
+// PreviewTemplateRapor godoc
+// @Summary Pratinjau template rapor dengan data contoh (PDF)
+// @Tags Sekolah
+// @Security BearerAuth
+// @Param id path int true "ID template, 0 untuk template bawaan"
+// @Produce application/pdf
+// @Router /template-rapor/{id}/preview [get]
+func PreviewTemplateRapor(c *gin.Context) {
+	template := services.TemplateRaporBawaan()
+	if c.Param("id") != "0" {
+		if err := config.DB.First(&template, c.Param("id")).Error; err != nil {
+			utils.ResponseNotFound(c, "Template rapor tidak ditemukan")
+			return
+		}
+	}
+
+	pdf := renderRapor(contohDataRapor(template, services.ProfilSekolah()))
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "inline; filename=preview_rapor.pdf")
+	if err := pdf.Output(c.Writer); err != nil {
+		utils.ResponseInternalError(c, "Gagal membuat pratinjau: "+err.Error())
+	}
+}
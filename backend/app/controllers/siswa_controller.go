@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
 	"sim-sekolah/config"
 	"sim-sekolah/utils"
 )
@@ -49,12 +50,14 @@ type UpdateSiswaRequest struct {
 // @Param limit query int false "Limit"
 // @Param search query string false "Cari nama/NISN/NIS"
 // @Param kelas_id query int false "Filter kelas"
+// @Param status query string false "Filter status (aktif/alumni)"
 // @Router /siswa [get]
 func GetSiswa(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "15"))
 	search := c.Query("search")
 	kelasID := c.Query("kelas_id")
+	status := c.Query("status")
 
 	if page < 1 {
 		page = 1
@@ -71,6 +74,9 @@ func GetSiswa(c *gin.Context) {
 	if kelasID != "" {
 		query = query.Where("kelas_id = ?", kelasID)
 	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
 
 	var total int64
 	query.Count(&total)
@@ -155,11 +161,12 @@ func CreateSiswa(c *gin.Context) {
 	var siswa models.Siswa
 	err := config.DB.Transaction(func(tx *gorm.DB) error {
 		user := models.User{
-			RoleID:   roleSiswa.ID,
-			Nama:     req.Nama,
-			Email:    req.Email,
-			Password: req.Password,
-			IsActive: true,
+			RoleID:             roleSiswa.ID,
+			Nama:               req.Nama,
+			Email:              req.Email,
+			Password:           req.Password,
+			IsActive:           true,
+			WajibGantiPassword: true,
 		}
 		if err := tx.Create(&user).Error; err != nil {
 			return err
@@ -175,7 +182,13 @@ func CreateSiswa(c *gin.Context) {
 			Alamat:       req.Alamat,
 			KelasID:      req.KelasID,
 		}
-		return tx.Create(&siswa).Error
+		if err := tx.Create(&siswa).Error; err != nil {
+			return err
+		}
+		if req.KelasID != nil {
+			return services.CatatPerubahanKelas(tx, siswa.ID, req.KelasID, models.RiwayatPendaftaran, "", time.Now())
+		}
+		return nil
 	})
 
 	if err != nil {
@@ -238,6 +251,12 @@ func UpdateSiswa(c *gin.Context) {
 				return err
 			}
 		}
+		if req.KelasID != nil {
+			if err := services.CatatPerubahanKelas(tx, siswa.ID, req.KelasID, models.RiwayatPindahKelas,
+				"Perubahan kelas melalui update data siswa", time.Now()); err != nil {
+				return err
+			}
+		}
 
 		// Update user
 		userUpdates := map[string]interface{}{}
@@ -293,12 +312,44 @@ func PindahKelas(c *gin.Context) {
 		return
 	}
 
-	config.DB.Model(&siswa).Update("kelas_id", req.KelasID)
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&siswa).Update("kelas_id", req.KelasID).Error; err != nil {
+			return err
+		}
+		return services.CatatPerubahanKelas(tx, siswa.ID, &req.KelasID, models.RiwayatPindahKelas, req.Alasan, time.Now())
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal memindahkan siswa")
+		return
+	}
+
 	config.DB.Preload("User").Preload("Kelas.Jurusan").First(&siswa, siswa.ID)
 
 	utils.ResponseOK(c, "Siswa berhasil dipindah ke kelas "+kelas.Nama, siswa)
 }
 
+// GetRiwayatKelasSiswa godoc
+// @Summary Riwayat keanggotaan kelas seorang siswa
+// @Tags Siswa
+// @Security BearerAuth
+// @Param id path int true "Siswa ID"
+// @Router /siswa/{id}/riwayat-kelas [get]
+func GetRiwayatKelasSiswa(c *gin.Context) {
+	var siswa models.Siswa
+	if err := config.DB.First(&siswa, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Siswa tidak ditemukan")
+		return
+	}
+
+	var riwayat []models.RiwayatKelas
+	config.DB.Preload("Kelas.Jurusan").Preload("TahunAjaran").
+		Where("siswa_id = ?", siswa.ID).
+		Order("tanggal_mulai ASC, id ASC").
+		Find(&riwayat)
+
+	utils.ResponseOK(c, "Riwayat kelas siswa", riwayat)
+}
+
 // DeleteSiswa godoc
 // @Summary Hapus siswa (soft delete)
 // @Tags Siswa
@@ -1,8 +1,14 @@
 package controllers
 
 import (
+	"errors"
+	"strconv"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
 	"sim-sekolah/config"
 	"sim-sekolah/utils"
 )
@@ -85,8 +91,9 @@ func UpdateTahunAjaran(c *gin.Context) {
 	}
 
 	var req struct {
-		Nama    string `json:"nama"`
-		IsAktif *bool  `json:"is_aktif"`
+		Nama            string `json:"nama"`
+		IsAktif         *bool  `json:"is_aktif"`
+		TemplateRaporID *uint  `json:"template_rapor_id"` // 0 = kembali ke template default
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
@@ -103,6 +110,18 @@ func UpdateTahunAjaran(c *gin.Context) {
 		}
 		ta.IsAktif = *req.IsAktif
 	}
+	if req.TemplateRaporID != nil {
+		if *req.TemplateRaporID == 0 {
+			ta.TemplateRaporID = nil
+		} else {
+			var t models.TemplateRapor
+			if err := config.DB.First(&t, *req.TemplateRaporID).Error; err != nil {
+				utils.ResponseBadRequest(c, "Template rapor tidak ditemukan", nil)
+				return
+			}
+			ta.TemplateRaporID = &t.ID
+		}
+	}
 
 	if err := config.DB.Save(&ta).Error; err != nil {
 		utils.ResponseInternalError(c, "Gagal mengupdate tahun ajaran")
@@ -137,6 +156,89 @@ func DeleteTahunAjaran(c *gin.Context) {
 	utils.ResponseOK(c, "Tahun ajaran berhasil dihapus", nil)
 }
 
+// RolloverTahunAjaran godoc
+// @Summary Buat tahun ajaran baru dengan menyalin kelas, semester, wali kelas dan jadwal
+// @Description Gunakan ?preview=true untuk melihat hasil tanpa menyimpan apa pun
+// @Tags Tahun Ajaran
+// @Security BearerAuth
+// @Param id path int true "ID tahun ajaran asal"
+// @Param preview query bool false "Hanya preview"
+// @Router /tahun-ajaran/{id}/rollover [post]
+func RolloverTahunAjaran(c *gin.Context) {
+	asalID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ResponseBadRequest(c, "ID tahun ajaran tidak valid", nil)
+		return
+	}
+
+	var req struct {
+		NamaBaru       string          `json:"nama_baru" binding:"required"` // "2025/2026"
+		GantiNamaKelas map[uint]string `json:"ganti_nama_kelas"`             // {"<kelas_id>": "X RPL 3"}
+		SalinWaliKelas *bool           `json:"salin_wali_kelas"`             // default true
+		SalinJadwal    bool            `json:"salin_jadwal"`
+		Aktifkan       bool            `json:"aktifkan"`
+		GanjilMulai    string          `json:"ganjil_mulai"` // YYYY-MM-DD
+		GanjilSelesai  string          `json:"ganjil_selesai"`
+		GenapMulai     string          `json:"genap_mulai"`
+		GenapSelesai   string          `json:"genap_selesai"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	opsi := services.OpsiRollover{
+		NamaBaru:       req.NamaBaru,
+		GantiNamaKelas: req.GantiNamaKelas,
+		SalinWaliKelas: req.SalinWaliKelas == nil || *req.SalinWaliKelas,
+		SalinJadwal:    req.SalinJadwal,
+		Aktifkan:       req.Aktifkan,
+	}
+	var errTanggal [4]error
+	opsi.GanjilMulai, errTanggal[0] = parseTanggalOpsional(req.GanjilMulai)
+	opsi.GanjilSelesai, errTanggal[1] = parseTanggalOpsional(req.GanjilSelesai)
+	opsi.GenapMulai, errTanggal[2] = parseTanggalOpsional(req.GenapMulai)
+	opsi.GenapSelesai, errTanggal[3] = parseTanggalOpsional(req.GenapSelesai)
+	for _, e := range errTanggal {
+		if e != nil {
+			utils.ResponseBadRequest(c, "Format tanggal semester tidak valid (gunakan YYYY-MM-DD)", nil)
+			return
+		}
+	}
+
+	preview := c.Query("preview") == "true"
+	hasil, err := services.RolloverTahunAjaran(uint(asalID), opsi, preview)
+	switch {
+	case err == services.ErrTahunAjaranSudahAda:
+		utils.ResponseBadRequest(c, "Tahun ajaran "+req.NamaBaru+" sudah ada", nil)
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.ResponseNotFound(c, "Tahun ajaran asal tidak ditemukan")
+		return
+	case err != nil:
+		utils.ResponseInternalError(c, "Gagal melakukan rollover tahun ajaran")
+		return
+	}
+
+	if preview {
+		utils.ResponseOK(c, "Preview rollover tahun ajaran (belum disimpan)", hasil)
+		return
+	}
+	utils.ResponseCreated(c, "Tahun ajaran "+hasil.TahunAjaran.Nama+" berhasil dibuat", hasil)
+}
+
+// parseTanggalOpsional mem-parse tanggal YYYY-MM-DD; string kosong menghasilkan nil
+func parseTanggalOpsional(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
 // ── Semester ──────────────────────────────────────────────────
 
 // GetSemester godoc
@@ -178,9 +280,12 @@ func GetSemesterAktif(c *gin.Context) {
 // @Router /semester [post]
 func CreateSemester(c *gin.Context) {
 	var req struct {
-		TahunAjaranID uint   `json:"tahun_ajaran_id" binding:"required"`
-		Nama          string `json:"nama" binding:"required"`  // "Ganjil" / "Genap"
-		IsAktif       bool   `json:"is_aktif"`
+		TahunAjaranID   uint   `json:"tahun_ajaran_id" binding:"required"`
+		Nama            string `json:"nama" binding:"required"` // "Ganjil" / "Genap"
+		IsAktif         bool   `json:"is_aktif"`
+		TanggalMulai    string `json:"tanggal_mulai"` // YYYY-MM-DD
+		TanggalSelesai  string `json:"tanggal_selesai"`
+		BatasInputNilai string `json:"batas_input_nilai"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
@@ -201,11 +306,26 @@ func CreateSemester(c *gin.Context) {
 		return
 	}
 
+	tanggalMulai, err1 := parseTanggalOpsional(req.TanggalMulai)
+	tanggalSelesai, err2 := parseTanggalOpsional(req.TanggalSelesai)
+	batasNilai, err3 := parseTanggalOpsional(req.BatasInputNilai)
+	if err1 != nil || err2 != nil || err3 != nil {
+		utils.ResponseBadRequest(c, "Format tanggal tidak valid (gunakan YYYY-MM-DD)", nil)
+		return
+	}
+
 	if req.IsAktif {
 		config.DB.Model(&models.Semester{}).Where("is_aktif = true").Update("is_aktif", false)
 	}
 
-	sem := models.Semester{TahunAjaranID: req.TahunAjaranID, Nama: req.Nama, IsAktif: req.IsAktif}
+	sem := models.Semester{
+		TahunAjaranID:   req.TahunAjaranID,
+		Nama:            req.Nama,
+		IsAktif:         req.IsAktif,
+		TanggalMulai:    tanggalMulai,
+		TanggalSelesai:  tanggalSelesai,
+		BatasInputNilai: batasNilai,
+	}
 	config.DB.Create(&sem)
 	config.DB.Preload("TahunAjaran").First(&sem, sem.ID)
 	utils.ResponseCreated(c, "Semester berhasil dibuat", sem)
@@ -225,14 +345,41 @@ func UpdateSemester(c *gin.Context) {
 	}
 
 	var req struct {
-		Nama    string `json:"nama"`
-		IsAktif *bool  `json:"is_aktif"`
+		Nama            string `json:"nama"`
+		IsAktif         *bool  `json:"is_aktif"`
+		TanggalMulai    string `json:"tanggal_mulai"`
+		TanggalSelesai  string `json:"tanggal_selesai"`
+		BatasInputNilai string `json:"batas_input_nilai"`
 	}
 	c.ShouldBindJSON(&req)
 
 	if req.Nama != "" {
 		sem.Nama = req.Nama
 	}
+	if req.TanggalMulai != "" {
+		t, err := parseTanggalOpsional(req.TanggalMulai)
+		if err != nil {
+			utils.ResponseBadRequest(c, "Format tanggal mulai tidak valid (gunakan YYYY-MM-DD)", nil)
+			return
+		}
+		sem.TanggalMulai = t
+	}
+	if req.TanggalSelesai != "" {
+		t, err := parseTanggalOpsional(req.TanggalSelesai)
+		if err != nil {
+			utils.ResponseBadRequest(c, "Format tanggal selesai tidak valid (gunakan YYYY-MM-DD)", nil)
+			return
+		}
+		sem.TanggalSelesai = t
+	}
+	if req.BatasInputNilai != "" {
+		t, err := parseTanggalOpsional(req.BatasInputNilai)
+		if err != nil {
+			utils.ResponseBadRequest(c, "Format batas input nilai tidak valid (gunakan YYYY-MM-DD)", nil)
+			return
+		}
+		sem.BatasInputNilai = t
+	}
 	if req.IsAktif != nil {
 		if *req.IsAktif {
 			config.DB.Model(&models.Semester{}).Where("id != ?", sem.ID).Update("is_aktif", false)
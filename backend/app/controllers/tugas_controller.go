@@ -0,0 +1,827 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"sim-sekolah/app/middlewares"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+const (
+	formatTenggatTugas = "2006-01-02 15:04"
+	maksLampiranTugas  = 5
+	maksUkuranJawaban  = 10 * 1024 * 1024
+)
+
+// Dokumen tugas: PDF, gambar, dan dokumen Office (terdeteksi sebagai zip,
+// strukturnya diperiksa oleh utils.SimpanUpload)
+var (
+	ekstensiDokumenTugas = []string{".pdf", ".jpg", ".jpeg", ".png", ".docx", ".pptx", ".xlsx"}
+	tipeDokumenTugas     = []string{"application/pdf", "image/jpeg", "image/png", "application/zip"}
+)
+
+// ── DTOs ──────────────────────────────────────────────────────
+
+type TugasRequest struct {
+	KelasID         uint   `json:"kelas_id" binding:"required"`
+	MataPelajaranID uint   `json:"mata_pelajaran_id" binding:"required"`
+	SemesterID      uint   `json:"semester_id"` // kosong = semester aktif
+	Judul           string `json:"judul" binding:"required,max=200"`
+	Deskripsi       string `json:"deskripsi"`
+	Tenggat         string `json:"tenggat" binding:"required"` // "2025-02-12 23:59"
+	TolakTerlambat  bool   `json:"tolak_terlambat"`
+	KomponenNilai   string `json:"komponen_nilai" binding:"omitempty,oneof=harian uts uas"`
+}
+
+type UpdateTugasRequest struct {
+	Judul          string `json:"judul" binding:"required,max=200"`
+	Deskripsi      string `json:"deskripsi"`
+	Tenggat        string `json:"tenggat" binding:"required"`
+	TolakTerlambat bool   `json:"tolak_terlambat"`
+	KomponenNilai  string `json:"komponen_nilai" binding:"omitempty,oneof=harian uts uas"`
+}
+
+type NilaiPengumpulanRequest struct {
+	Nilai      *float64 `json:"nilai" binding:"required,min=0,max=100"`
+	UmpanBalik string   `json:"umpan_balik"`
+}
+
+type TerapkanNilaiTugasRequest struct {
+	KelasID         uint   `json:"kelas_id" binding:"required"`
+	MataPelajaranID uint   `json:"mata_pelajaran_id" binding:"required"`
+	SemesterID      uint   `json:"semester_id" binding:"required"`
+	Komponen        string `json:"komponen" binding:"required,oneof=harian uts uas"`
+}
+
+// ── Helper akses ──────────────────────────────────────────────
+
+// queryTugasTerlihat membatasi tugas sesuai role: admin dan kepala sekolah
+// melihat semua, guru melihat tugas buatannya, wali kelas juga melihat tugas
+// di kelas perwaliannya.
+func queryTugasTerlihat(c *gin.Context) (*gorm.DB, bool) {
+	query := config.DB.Model(&models.Tugas{})
+
+	claims := middlewares.GetCurrentUser(c)
+	switch claims.Role {
+	case models.RoleAdmin, models.RoleKepalaSekolah:
+		return query, true
+	case models.RoleGuru, models.RoleWaliKelas:
+		guru, ok := guruLogin(c)
+		if !ok {
+			return nil, false
+		}
+		if claims.Role == models.RoleWaliKelas {
+			return query.Where("tugas.guru_id = ? OR tugas.kelas_id IN (SELECT id FROM kelas WHERE wali_kelas_id = ?)",
+				guru.ID, guru.ID), true
+		}
+		return query.Where("tugas.guru_id = ?", guru.ID), true
+	}
+	utils.ResponseForbidden(c, "Akses ditolak")
+	return nil, false
+}
+
+// ambilTugas memuat tugas yang boleh dilihat user yang sedang login
+func ambilTugas(c *gin.Context) (models.Tugas, bool) {
+	var tugas models.Tugas
+	query, ok := queryTugasTerlihat(c)
+	if !ok {
+		return tugas, false
+	}
+	if err := query.Where("tugas.id = ?", c.Param("id")).
+		Preload("Kelas").Preload("MataPelajaran").Preload("Guru").Preload("Lampiran").
+		First(&tugas).Error; err != nil {
+		utils.ResponseNotFound(c, "Tugas tidak ditemukan")
+		return tugas, false
+	}
+	return tugas, true
+}
+
+// ambilTugasMilikSaya memuat tugas buatan guru yang sedang login
+func ambilTugasMilikSaya(c *gin.Context) (models.Tugas, bool) {
+	var tugas models.Tugas
+	guru, ok := guruLogin(c)
+	if !ok {
+		return tugas, false
+	}
+	if err := config.DB.Preload("Lampiran").First(&tugas, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Tugas tidak ditemukan")
+		return tugas, false
+	}
+	if tugas.GuruID != guru.ID {
+		utils.ResponseForbidden(c, "Anda hanya dapat mengelola tugas milik sendiri")
+		return tugas, false
+	}
+	return tugas, true
+}
+
+func parseTenggatTugas(c *gin.Context, s string) (time.Time, bool) {
+	tenggat, err := time.ParseInLocation(formatTenggatTugas, s, time.Local)
+	if err != nil {
+		utils.ResponseBadRequest(c, "Format tenggat harus YYYY-MM-DD HH:MM", nil)
+		return tenggat, false
+	}
+	return tenggat, true
+}
+
+// ── CRUD ──────────────────────────────────────────────────────
+
+// GetTugas godoc
+// @Summary List tugas
+// @Tags Tugas
+// @Security BearerAuth
+// @Param kelas_id query int false "Filter kelas"
+// @Param mata_pelajaran_id query int false "Filter mata pelajaran"
+// @Param semester_id query int false "Filter semester"
+// @Param guru_id query int false "Filter guru"
+// @Param page query int false "Halaman" default(1)
+// @Param limit query int false "Jumlah per halaman" default(20)
+// @Router /tugas [get]
+func GetTugas(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	if page < 1 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	query, ok := queryTugasTerlihat(c)
+	if !ok {
+		return
+	}
+	if kelasID := c.Query("kelas_id"); kelasID != "" {
+		query = query.Where("tugas.kelas_id = ?", kelasID)
+	}
+	if mapelID := c.Query("mata_pelajaran_id"); mapelID != "" {
+		query = query.Where("tugas.mata_pelajaran_id = ?", mapelID)
+	}
+	if semesterID := c.Query("semester_id"); semesterID != "" {
+		query = query.Where("tugas.semester_id = ?", semesterID)
+	}
+	if guruID := c.Query("guru_id"); guruID != "" {
+		query = query.Where("tugas.guru_id = ?", guruID)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var list []models.Tugas
+	if err := query.Preload("Kelas").Preload("MataPelajaran").Preload("Guru").
+		Offset(offset).Limit(limit).
+		Order("tugas.tenggat DESC").
+		Find(&list).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal mengambil data tugas")
+		return
+	}
+	utils.ResponsePaginated(c, "Daftar tugas", list, page, limit, total)
+}
+
+// GetTugasByID godoc
+// @Summary Detail tugas beserta lampiran dan ringkasan pengumpulan
+// @Tags Tugas
+// @Security BearerAuth
+// @Param id path int true "Tugas ID"
+// @Router /tugas/{id} [get]
+func GetTugasByID(c *gin.Context) {
+	tugas, ok := ambilTugas(c)
+	if !ok {
+		return
+	}
+	var ringkasan struct {
+		Dikumpulkan int64 `json:"dikumpulkan"`
+		Terlambat   int64 `json:"terlambat"`
+		Dinilai     int64 `json:"dinilai"`
+	}
+	config.DB.Model(&models.PengumpulanTugas{}).
+		Where("tugas_id = ?", tugas.ID).
+		Select(`COUNT(*) AS dikumpulkan,
+			COUNT(*) FILTER (WHERE terlambat) AS terlambat,
+			COUNT(*) FILTER (WHERE nilai IS NOT NULL) AS dinilai`).
+		Scan(&ringkasan)
+
+	var jumlahSiswa int64
+	services.QuerySiswaDiKelas(models.Kelas{ID: tugas.KelasID}).Count(&jumlahSiswa)
+
+	utils.ResponseOK(c, "Detail tugas", gin.H{
+		"tugas":        tugas,
+		"jumlah_siswa": jumlahSiswa,
+		"pengumpulan":  ringkasan,
+	})
+}
+
+// CreateTugas godoc
+// @Summary Buat tugas untuk kelas dan mapel yang diampu
+// @Tags Tugas
+// @Security BearerAuth
+// @Param body body TugasRequest true "Data tugas"
+// @Router /tugas [post]
+func CreateTugas(c *gin.Context) {
+	var req TugasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	guru, ok := guruLogin(c)
+	if !ok {
+		return
+	}
+	tenggat, ok := parseTenggatTugas(c, req.Tenggat)
+	if !ok {
+		return
+	}
+
+	var semester models.Semester
+	query := config.DB.Model(&models.Semester{})
+	if req.SemesterID != 0 {
+		query = query.Where("id = ?", req.SemesterID)
+	} else {
+		query = query.Where("is_aktif = ?", true)
+	}
+	if err := query.First(&semester).Error; err != nil {
+		utils.ResponseBadRequest(c, "Semester tidak ditemukan", nil)
+		return
+	}
+	var kelas models.Kelas
+	if err := config.DB.First(&kelas, req.KelasID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Kelas tidak ditemukan", nil)
+		return
+	}
+	if !services.GuruMengampu(guru.ID, semester.ID, kelas.ID, req.MataPelajaranID) {
+		utils.ResponseForbidden(c, "Anda bukan guru pengampu mata pelajaran ini di kelas "+kelas.Nama)
+		return
+	}
+
+	tugas := models.Tugas{
+		KelasID:         kelas.ID,
+		MataPelajaranID: req.MataPelajaranID,
+		SemesterID:      semester.ID,
+		GuruID:          guru.ID,
+		Judul:           req.Judul,
+		Deskripsi:       req.Deskripsi,
+		Tenggat:         tenggat,
+		TolakTerlambat:  req.TolakTerlambat,
+		KomponenNilai:   req.KomponenNilai,
+	}
+	if err := config.DB.Create(&tugas).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan tugas")
+		return
+	}
+	config.DB.Preload("Kelas").Preload("MataPelajaran").First(&tugas, tugas.ID)
+	utils.ResponseCreated(c, "Tugas berhasil dibuat", tugas)
+}
+
+// UpdateTugas godoc
+// @Summary Update tugas (kelas, mapel, dan semester tidak dapat diubah)
+// @Tags Tugas
+// @Security BearerAuth
+// @Param id path int true "Tugas ID"
+// @Param body body UpdateTugasRequest true "Data tugas"
+// @Router /tugas/{id} [put]
+func UpdateTugas(c *gin.Context) {
+	tugas, ok := ambilTugasMilikSaya(c)
+	if !ok {
+		return
+	}
+	var req UpdateTugasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	tenggat, ok := parseTenggatTugas(c, req.Tenggat)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&tugas).Updates(map[string]interface{}{
+			"judul":           req.Judul,
+			"deskripsi":       req.Deskripsi,
+			"tenggat":         tenggat,
+			"tolak_terlambat": req.TolakTerlambat,
+			"komponen_nilai":  req.KomponenNilai,
+		}).Error; err != nil {
+			return err
+		}
+		// Tenggat bisa dimundurkan/dimajukan: hitung ulang penanda terlambat
+		return tx.Model(&models.PengumpulanTugas{}).
+			Where("tugas_id = ?", tugas.ID).
+			Update("terlambat", gorm.Expr("dikumpulkan_pada > ?", tenggat)).Error
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal mengupdate tugas")
+		return
+	}
+	config.DB.Preload("Kelas").Preload("MataPelajaran").Preload("Lampiran").First(&tugas, tugas.ID)
+	utils.ResponseOK(c, "Tugas berhasil diupdate", tugas)
+}
+
+// DeleteTugas godoc
+// @Summary Hapus tugas beserta lampiran dan seluruh pengumpulannya
+// @Tags Tugas
+// @Security BearerAuth
+// @Param id path int true "Tugas ID"
+// @Router /tugas/{id} [delete]
+func DeleteTugas(c *gin.Context) {
+	var tugas models.Tugas
+	if middlewares.GetCurrentUser(c).Role == models.RoleAdmin {
+		if err := config.DB.Preload("Lampiran").First(&tugas, c.Param("id")).Error; err != nil {
+			utils.ResponseNotFound(c, "Tugas tidak ditemukan")
+			return
+		}
+	} else {
+		var ok bool
+		if tugas, ok = ambilTugasMilikSaya(c); !ok {
+			return
+		}
+	}
+
+	var pengumpulan []models.PengumpulanTugas
+	config.DB.Where("tugas_id = ? AND file_path <> ''", tugas.ID).Find(&pengumpulan)
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tugas_id = ?", tugas.ID).Delete(&models.PengumpulanTugas{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tugas_id = ?", tugas.ID).Delete(&models.LampiranTugas{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&tugas).Error
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghapus tugas")
+		return
+	}
+	for _, l := range tugas.Lampiran {
+		os.Remove(l.FilePath)
+	}
+	for _, p := range pengumpulan {
+		os.Remove(p.FilePath)
+	}
+	utils.ResponseOK(c, "Tugas berhasil dihapus", nil)
+}
+
+// UploadLampiranTugas godoc
+// @Summary Upload lampiran soal/materi tugas (PDF, gambar, dokumen Office; maks 5MB)
+// @Tags Tugas
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Param id path int true "Tugas ID"
+// @Param file formData file true "File lampiran"
+// @Router /tugas/{id}/lampiran [post]
+func UploadLampiranTugas(c *gin.Context) {
+	tugas, ok := ambilTugasMilikSaya(c)
+	if !ok {
+		return
+	}
+	if len(tugas.Lampiran) >= maksLampiranTugas {
+		utils.ResponseBadRequest(c, fmt.Sprintf("Maksimal %d lampiran per tugas", maksLampiranTugas), nil)
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		utils.ResponseBadRequest(c, "File tidak ditemukan", err.Error())
+		return
+	}
+	defer file.Close()
+
+	filePath, ok := utils.SimpanUpload(c, header, utils.AturanUpload{
+		Dir:        "storage/tugas",
+		Prefix:     fmt.Sprintf("tugas_%d", tugas.ID),
+		Ekstensi:   ekstensiDokumenTugas,
+		MaksByte:   maksUkuranLampiran,
+		TipeKonten: tipeDokumenTugas,
+	})
+	if !ok {
+		return
+	}
+
+	lampiran := models.LampiranTugas{
+		TugasID:  tugas.ID,
+		NamaFile: header.Filename,
+		FilePath: filePath,
+		Ukuran:   header.Size,
+	}
+	if err := config.DB.Create(&lampiran).Error; err != nil {
+		os.Remove(filePath) // rollback: hapus file yang baru diupload
+		utils.ResponseInternalError(c, "Gagal menyimpan lampiran")
+		return
+	}
+	utils.ResponseCreated(c, "Lampiran berhasil diupload", gin.H{
+		"lampiran": lampiran,
+		"url":      fmt.Sprintf("/api/v1/tugas/%d/lampiran/%d", tugas.ID, lampiran.ID),
+	})
+}
+
+// DownloadLampiranTugas godoc
+// @Summary Unduh lampiran tugas (guru/staf yang dapat melihat tugas, siswa di kelasnya, atau orang tuanya)
+// @Tags Tugas
+// @Security BearerAuth
+// @Param id path int true "Tugas ID"
+// @Param lampiran_id path int true "Lampiran ID"
+// @Router /tugas/{id}/lampiran/{lampiran_id} [get]
+func DownloadLampiranTugas(c *gin.Context) {
+	var tugas models.Tugas
+	claims := middlewares.GetCurrentUser(c)
+	switch claims.Role {
+	case models.RoleSiswa, models.RoleOrangTua:
+		if err := config.DB.First(&tugas, c.Param("id")).Error; err != nil {
+			utils.ResponseNotFound(c, "Tugas tidak ditemukan")
+			return
+		}
+		diizinkan := false
+		for _, siswaID := range siswaTerkaitLogin(claims) {
+			if services.SiswaMenempatiKelas(siswaID, tugas.KelasID) {
+				diizinkan = true
+				break
+			}
+		}
+		if !diizinkan {
+			utils.ResponseNotFound(c, "Tugas tidak ditemukan")
+			return
+		}
+	default:
+		var ok bool
+		if tugas, ok = ambilTugas(c); !ok {
+			return
+		}
+	}
+
+	var lampiran models.LampiranTugas
+	if err := config.DB.Where("id = ? AND tugas_id = ?", c.Param("lampiran_id"), tugas.ID).
+		First(&lampiran).Error; err != nil {
+		utils.ResponseNotFound(c, "Lampiran tidak ditemukan")
+		return
+	}
+	utils.KirimFile(c, lampiran.FilePath)
+}
+
+// DeleteLampiranTugas godoc
+// @Summary Hapus lampiran tugas
+// @Tags Tugas
+// @Security BearerAuth
+// @Param id path int true "Tugas ID"
+// @Param lampiran_id path int true "Lampiran ID"
+// @Router /tugas/{id}/lampiran/{lampiran_id} [delete]
+func DeleteLampiranTugas(c *gin.Context) {
+	tugas, ok := ambilTugasMilikSaya(c)
+	if !ok {
+		return
+	}
+	var lampiran models.LampiranTugas
+	if err := config.DB.Where("id = ? AND tugas_id = ?", c.Param("lampiran_id"), tugas.ID).
+		First(&lampiran).Error; err != nil {
+		utils.ResponseNotFound(c, "Lampiran tidak ditemukan")
+		return
+	}
+	if err := config.DB.Delete(&lampiran).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menghapus lampiran")
+		return
+	}
+	os.Remove(lampiran.FilePath)
+	utils.ResponseOK(c, "Lampiran berhasil dihapus", nil)
+}
+
+// ── Pengumpulan & penilaian ───────────────────────────────────
+
+// GetPengumpulanTugas godoc
+// @Summary Daftar siswa kelas beserta status pengumpulan tugas
+// @Tags Tugas
+// @Security BearerAuth
+// @Param id path int true "Tugas ID"
+// @Router /tugas/{id}/pengumpulan [get]
+func GetPengumpulanTugas(c *gin.Context) {
+	tugas, ok := ambilTugas(c)
+	if !ok {
+		return
+	}
+
+	var siswaList []models.Siswa
+	config.DB.Where("id IN (?)", services.QuerySiswaDiKelas(models.Kelas{ID: tugas.KelasID})).
+		Order("nama ASC").Find(&siswaList)
+
+	var pengumpulan []models.PengumpulanTugas
+	config.DB.Where("tugas_id = ?", tugas.ID).Find(&pengumpulan)
+	perSiswa := make(map[uint]models.PengumpulanTugas, len(pengumpulan))
+	for _, p := range pengumpulan {
+		perSiswa[p.SiswaID] = p
+	}
+
+	type barisPengumpulan struct {
+		Siswa       models.Siswa             `json:"siswa"`
+		Status      string                   `json:"status"` // belum / dikumpulkan / dinilai
+		Pengumpulan *models.PengumpulanTugas `json:"pengumpulan"`
+	}
+	hasil := make([]barisPengumpulan, 0, len(siswaList))
+	for _, s := range siswaList {
+		baris := barisPengumpulan{Siswa: s, Status: "belum"}
+		if p, ada := perSiswa[s.ID]; ada {
+			baris.Pengumpulan = &p
+			baris.Status = "dikumpulkan"
+			if p.Nilai != nil {
+				baris.Status = "dinilai"
+			}
+		}
+		hasil = append(hasil, baris)
+	}
+	utils.ResponseOK(c, "Pengumpulan tugas "+tugas.Judul, hasil)
+}
+
+// GetTugasSaya godoc
+// @Summary Tugas kelas siswa (atau anak) yang sedang login beserta status pengumpulannya
+// @Tags Tugas
+// @Security BearerAuth
+// @Param semester_id query int false "Semester (default: semester aktif)"
+// @Param siswa_id query int false "Anak yang dipilih (khusus orang tua)"
+// @Router /tugas/saya [get]
+func GetTugasSaya(c *gin.Context) {
+	siswaID, ok := siswaMilikLogin(c)
+	if !ok {
+		return
+	}
+	var semester models.Semester
+	query := config.DB.Model(&models.Semester{})
+	if semesterID := c.Query("semester_id"); semesterID != "" {
+		query = query.Where("id = ?", semesterID)
+	} else {
+		query = query.Where("is_aktif = ?", true)
+	}
+	if err := query.First(&semester).Error; err != nil {
+		utils.ResponseNotFound(c, "Semester tidak ditemukan")
+		return
+	}
+	kelas := services.KelasSiswaDiSemester(siswaID, semester.ID)
+	if kelas == nil {
+		utils.ResponseNotFound(c, "Siswa tidak terdaftar di kelas mana pun pada semester ini")
+		return
+	}
+
+	var list []models.Tugas
+	config.DB.Preload("MataPelajaran").Preload("Guru").Preload("Lampiran").
+		Where("kelas_id = ? AND semester_id = ?", kelas.ID, semester.ID).
+		Order("tenggat DESC").
+		Find(&list)
+
+	var pengumpulan []models.PengumpulanTugas
+	config.DB.Where("siswa_id = ? AND tugas_id IN (?)", siswaID,
+		config.DB.Model(&models.Tugas{}).Select("id").Where("kelas_id = ? AND semester_id = ?", kelas.ID, semester.ID)).
+		Find(&pengumpulan)
+	perTugas := make(map[uint]models.PengumpulanTugas, len(pengumpulan))
+	for _, p := range pengumpulan {
+		perTugas[p.TugasID] = p
+	}
+
+	type tugasSiswa struct {
+		models.Tugas
+		Pengumpulan *models.PengumpulanTugas `json:"pengumpulan"`
+	}
+	hasil := make([]tugasSiswa, 0, len(list))
+	for _, t := range list {
+		baris := tugasSiswa{Tugas: t}
+		if p, ada := perTugas[t.ID]; ada {
+			baris.Pengumpulan = &p
+		}
+		hasil = append(hasil, baris)
+	}
+	utils.ResponseOK(c, "Tugas saya", gin.H{
+		"semester": semester,
+		"kelas":    kelas,
+		"tugas":    hasil,
+	})
+}
+
+// KumpulkanTugas godoc
+// @Summary Kumpulkan jawaban tugas (teks dan/atau file; dapat diulang selama belum dinilai)
+// @Tags Tugas
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Param id path int true "Tugas ID"
+// @Param jawaban formData string false "Jawaban teks"
+// @Param file formData file false "File jawaban (PDF, gambar, dokumen Office; maks 10MB)"
+// @Router /tugas/{id}/kumpul [post]
+func KumpulkanTugas(c *gin.Context) {
+	siswa, ok := siswaLogin(c)
+	if !ok {
+		return
+	}
+	var tugas models.Tugas
+	if err := config.DB.First(&tugas, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Tugas tidak ditemukan")
+		return
+	}
+
+	sekarang := time.Now()
+	terlambat, err := services.CekPengumpulan(tugas, siswa.ID, sekarang)
+	if err != nil {
+		if errors.Is(err, services.ErrSiswaBukanKelasTugas) {
+			utils.ResponseForbidden(c, err.Error())
+			return
+		}
+		utils.ResponseBadRequest(c, err.Error(), nil)
+		return
+	}
+
+	jawaban := c.PostForm("jawaban")
+	file, header, errFile := c.Request.FormFile("file")
+	if jawaban == "" && errFile != nil {
+		utils.ResponseBadRequest(c, "Isi jawaban atau lampirkan file", nil)
+		return
+	}
+
+	// Pengumpulan ulang menggantikan seluruh jawaban sebelumnya
+	var pengumpulan models.PengumpulanTugas
+	config.DB.Where("tugas_id = ? AND siswa_id = ?", tugas.ID, siswa.ID).First(&pengumpulan)
+	fileLama := pengumpulan.FilePath
+	pengumpulan.NamaFile, pengumpulan.FilePath, pengumpulan.Ukuran = "", "", 0
+
+	if errFile == nil {
+		defer file.Close()
+		filePath, ok := utils.SimpanUpload(c, header, utils.AturanUpload{
+			Dir:        "storage/pengumpulan",
+			Prefix:     fmt.Sprintf("tugas_%d_siswa_%d", tugas.ID, siswa.ID),
+			Ekstensi:   ekstensiDokumenTugas,
+			MaksByte:   maksUkuranJawaban,
+			TipeKonten: tipeDokumenTugas,
+		})
+		if !ok {
+			return
+		}
+		pengumpulan.NamaFile = header.Filename
+		pengumpulan.FilePath = filePath
+		pengumpulan.Ukuran = header.Size
+	}
+
+	pengumpulan.TugasID = tugas.ID
+	pengumpulan.SiswaID = siswa.ID
+	pengumpulan.Jawaban = jawaban
+	pengumpulan.DikumpulkanPada = sekarang
+	pengumpulan.Terlambat = terlambat
+	if err := config.DB.Save(&pengumpulan).Error; err != nil {
+		if errFile == nil {
+			os.Remove(pengumpulan.FilePath) // rollback: hapus file yang baru diupload
+		}
+		utils.ResponseInternalError(c, "Gagal menyimpan pengumpulan tugas")
+		return
+	}
+	if fileLama != "" {
+		os.Remove(fileLama)
+	}
+
+	pesan := "Tugas berhasil dikumpulkan"
+	if terlambat {
+		pesan = "Tugas berhasil dikumpulkan (terlambat)"
+	}
+	utils.ResponseOK(c, pesan, pengumpulan)
+}
+
+// DownloadPengumpulanTugas godoc
+// @Summary Unduh file jawaban siswa (hanya siswa pengumpul, guru pembuat tugas, atau admin)
+// @Tags Tugas
+// @Security BearerAuth
+// @Param id path int true "Pengumpulan ID"
+// @Router /tugas/pengumpulan/{id}/file [get]
+func DownloadPengumpulanTugas(c *gin.Context) {
+	var pengumpulan models.PengumpulanTugas
+	if err := config.DB.Preload("Tugas").First(&pengumpulan, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Pengumpulan tidak ditemukan")
+		return
+	}
+
+	claims := middlewares.GetCurrentUser(c)
+	switch claims.Role {
+	case models.RoleAdmin:
+	case models.RoleSiswa:
+		siswa, ok := siswaLogin(c)
+		if !ok {
+			return
+		}
+		if pengumpulan.SiswaID != siswa.ID {
+			utils.ResponseNotFound(c, "Pengumpulan tidak ditemukan")
+			return
+		}
+	default:
+		guru, ok := guruLogin(c)
+		if !ok {
+			return
+		}
+		if pengumpulan.Tugas.GuruID != guru.ID {
+			utils.ResponseForbidden(c, "Hanya guru pembuat tugas yang dapat mengunduh jawaban ini")
+			return
+		}
+	}
+	if pengumpulan.FilePath == "" {
+		utils.ResponseNotFound(c, "Pengumpulan ini tidak memiliki file")
+		return
+	}
+	utils.KirimFile(c, pengumpulan.FilePath)
+}
+
+// siswaTerkaitLogin mengembalikan ID siswa milik akun yang login: siswa itu
+// sendiri, atau seluruh anak dari orang tua yang login
+func siswaTerkaitLogin(claims *utils.JWTClaims) []uint {
+	var ids []uint
+	switch claims.Role {
+	case models.RoleSiswa:
+		config.DB.Model(&models.Siswa{}).Where("user_id = ?", claims.UserID).Pluck("id", &ids)
+	case models.RoleOrangTua:
+		config.DB.Model(&models.OrangTuaSiswa{}).
+			Joins("JOIN orang_tuas ON orang_tuas.id = orang_tua_siswas.orang_tua_id").
+			Where("orang_tuas.user_id = ?", claims.UserID).
+			Pluck("orang_tua_siswas.siswa_id", &ids)
+	}
+	return ids
+}
+
+// NilaiPengumpulan godoc
+// @Summary Beri nilai dan umpan balik untuk jawaban siswa
+// @Tags Tugas
+// @Security BearerAuth
+// @Param id path int true "Pengumpulan ID"
+// @Param body body NilaiPengumpulanRequest true "Nilai"
+// @Router /tugas/pengumpulan/{id}/nilai [put]
+func NilaiPengumpulan(c *gin.Context) {
+	var req NilaiPengumpulanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	guru, ok := guruLogin(c)
+	if !ok {
+		return
+	}
+	var pengumpulan models.PengumpulanTugas
+	if err := config.DB.Preload("Tugas").First(&pengumpulan, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Pengumpulan tidak ditemukan")
+		return
+	}
+	if pengumpulan.Tugas.GuruID != guru.ID {
+		utils.ResponseForbidden(c, "Anda hanya dapat menilai tugas milik sendiri")
+		return
+	}
+
+	sekarang := time.Now()
+	if err := config.DB.Model(&pengumpulan).Updates(map[string]interface{}{
+		"nilai":        *req.Nilai,
+		"umpan_balik":  req.UmpanBalik,
+		"dinilai_pada": sekarang,
+	}).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan nilai")
+		return
+	}
+	config.DB.Preload("Siswa").First(&pengumpulan, pengumpulan.ID)
+	utils.ResponseOK(c, "Nilai tugas berhasil disimpan", pengumpulan)
+}
+
+// TerapkanNilaiTugas godoc
+// @Summary Isi komponen nilai (harian/UTS/UAS) dari rata-rata nilai tugas kelas
+// @Description Hanya tugas bertanda komponen yang sama dan sudah lewat tenggat yang dihitung; tugas yang tidak dikumpulkan bernilai 0
+// @Tags Tugas
+// @Security BearerAuth
+// @Param body body TerapkanNilaiTugasRequest true "Kelas, mapel, semester, komponen"
+// @Router /tugas/terapkan-nilai [post]
+func TerapkanNilaiTugas(c *gin.Context) {
+	var req TerapkanNilaiTugasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	guru, ok := guruLogin(c)
+	if !ok {
+		return
+	}
+	if !services.GuruMengampu(guru.ID, req.SemesterID, req.KelasID, req.MataPelajaranID) {
+		utils.ResponseForbidden(c, "Anda bukan guru pengampu mata pelajaran ini di kelas tersebut")
+		return
+	}
+
+	rekap, err := services.HitungRataRataTugas(req.KelasID, req.MataPelajaranID, req.SemesterID, req.Komponen)
+	if err != nil {
+		utils.ResponseBadRequest(c, err.Error(), nil)
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		for siswaID, rataRata := range rekap.RataRata {
+			if err := simpanKomponenNilai(tx, siswaID, req.MataPelajaranID, req.SemesterID, req.Komponen, rataRata); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan nilai")
+		return
+	}
+	utils.ResponseOK(c, fmt.Sprintf("Nilai %s %d siswa diisi dari %d tugas", req.Komponen, len(rekap.RataRata), rekap.JumlahTugas), rekap)
+}
@@ -0,0 +1,302 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+	"sim-sekolah/app/middlewares"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Verify2FA godoc
+// @Summary Verifikasi kode 2FA (langkah kedua login)
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Router /auth/2fa/verify [post]
+func Verify2FA(c *gin.Context) {
+	var req struct {
+		PartialToken string `json:"partial_token" binding:"required"`
+		Kode         string `json:"kode" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	claims, err := utils.ValidatePartialToken(req.PartialToken)
+	if err != nil {
+		utils.ResponseUnauthorized(c, "Sesi login sudah kadaluarsa, silakan login ulang")
+		return
+	}
+
+	// Kode 2FA yang salah ikut dihitung pada lockout akun / IP
+	if sisa := services.CekLockout(claims.Email, c.ClientIP()); sisa > 0 {
+		utils.ResponseTooManyRequests(c, fmt.Sprintf(
+			"Terlalu banyak percobaan login gagal. Coba lagi dalam %d menit", int(math.Ceil(sisa.Minutes()))))
+		return
+	}
+
+	var user models.User
+	if err := config.DB.Preload("Role").Where("is_active = true").First(&user, claims.UserID).Error; err != nil || !user.TOTPAktif {
+		utils.ResponseUnauthorized(c, "Sesi login sudah kadaluarsa, silakan login ulang")
+		return
+	}
+
+	if !services.VerifikasiKode2FA(&user, req.Kode) {
+		loginGagal(c, claims.Email, &user, "Kode autentikasi tidak valid")
+		return
+	}
+
+	kirimLoginBerhasil(c, user)
+}
+
+// Get2FAStatus godoc
+// @Summary Status 2FA pengguna yang sedang login
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Router /auth/2fa/status [get]
+func Get2FAStatus(c *gin.Context) {
+	claims := middlewares.GetCurrentUser(c)
+
+	var user models.User
+	if err := config.DB.Preload("Role").First(&user, claims.UserID).Error; err != nil {
+		utils.ResponseNotFound(c, "User tidak ditemukan")
+		return
+	}
+
+	utils.ResponseOK(c, "Status 2FA", gin.H{
+		"totp_aktif":          user.TOTPAktif,
+		"wajib_2fa":           user.Role.Wajib2FA,
+		"sisa_recovery_codes": services.SisaRecoveryCode(user.ID),
+	})
+}
+
+// Setup2FA godoc
+// @Summary Buat secret TOTP baru untuk didaftarkan di aplikasi authenticator
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Router /auth/2fa/setup [post]
+func Setup2FA(c *gin.Context) {
+	claims := middlewares.GetCurrentUser(c)
+
+	var user models.User
+	if err := config.DB.First(&user, claims.UserID).Error; err != nil {
+		utils.ResponseNotFound(c, "User tidak ditemukan")
+		return
+	}
+	if user.TOTPAktif {
+		utils.ResponseBadRequest(c, "2FA sudah aktif. Nonaktifkan terlebih dahulu untuk mendaftarkan ulang", nil)
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal membuat secret 2FA")
+		return
+	}
+
+	// Secret disimpan tetapi belum aktif sampai dikonfirmasi dengan kode yang valid
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"totp_secret":        secret,
+		"totp_step_terakhir": 0,
+	}).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan secret 2FA")
+		return
+	}
+
+	utils.ResponseOK(c, "Pindai QR code lalu konfirmasi dengan kode dari aplikasi authenticator", gin.H{
+		"secret":      secret,
+		"otpauth_uri": utils.TOTPProvisioningURI(services.IssuerTOTP(), user.Email, secret),
+	})
+}
+
+// Aktifkan2FA godoc
+// @Summary Konfirmasi dan aktifkan 2FA
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Router /auth/2fa/aktifkan [post]
+func Aktifkan2FA(c *gin.Context) {
+	claims := middlewares.GetCurrentUser(c)
+
+	var req struct {
+		Kode string `json:"kode" binding:"required,len=6"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	var user models.User
+	if err := config.DB.Preload("Role").First(&user, claims.UserID).Error; err != nil {
+		utils.ResponseNotFound(c, "User tidak ditemukan")
+		return
+	}
+	if user.TOTPAktif {
+		utils.ResponseBadRequest(c, "2FA sudah aktif", nil)
+		return
+	}
+	if user.TOTPSecret == "" {
+		utils.ResponseBadRequest(c, "Jalankan setup 2FA terlebih dahulu", nil)
+		return
+	}
+	if !services.VerifikasiTOTP(&user, req.Kode) {
+		utils.ResponseBadRequest(c, "Kode autentikasi tidak valid", nil)
+		return
+	}
+
+	var kodeList []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("totp_aktif", true).Error; err != nil {
+			return err
+		}
+		var err error
+		kodeList, err = services.BuatRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal mengaktifkan 2FA")
+		return
+	}
+
+	services.CatatAktivitas(models.ActivityLog{
+		UserID:    user.ID,
+		Action:    "ENABLE_2FA",
+		Entity:    "auth",
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	// Token baru tanpa flag wajib 2FA
+	user.TOTPAktif = true
+	token, _, err := buatTokenUser(user)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal membuat token")
+		return
+	}
+
+	utils.ResponseOK(c, "2FA berhasil diaktifkan. Simpan recovery code di tempat yang aman", gin.H{
+		"token":          token,
+		"recovery_codes": kodeList,
+	})
+}
+
+// Nonaktifkan2FA godoc
+// @Summary Nonaktifkan 2FA
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Router /auth/2fa/nonaktifkan [post]
+func Nonaktifkan2FA(c *gin.Context) {
+	claims := middlewares.GetCurrentUser(c)
+
+	var req struct {
+		Password string `json:"password" binding:"required"`
+		Kode     string `json:"kode" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	var user models.User
+	if err := config.DB.Preload("Role").First(&user, claims.UserID).Error; err != nil {
+		utils.ResponseNotFound(c, "User tidak ditemukan")
+		return
+	}
+	if !user.TOTPAktif {
+		utils.ResponseBadRequest(c, "2FA belum aktif", nil)
+		return
+	}
+	if user.Role.Wajib2FA {
+		utils.ResponseForbidden(c, "Role Anda mewajibkan 2FA sehingga tidak dapat dinonaktifkan")
+		return
+	}
+	if !user.CheckPassword(req.Password) {
+		utils.ResponseBadRequest(c, "Password salah", nil)
+		return
+	}
+	if !services.VerifikasiKode2FA(&user, req.Kode) {
+		utils.ResponseBadRequest(c, "Kode autentikasi tidak valid", nil)
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_aktif":         false,
+			"totp_secret":        "",
+			"totp_step_terakhir": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menonaktifkan 2FA")
+		return
+	}
+
+	services.CatatAktivitas(models.ActivityLog{
+		UserID:    user.ID,
+		Action:    "DISABLE_2FA",
+		Entity:    "auth",
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	utils.ResponseOK(c, "2FA berhasil dinonaktifkan", nil)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Buat ulang recovery code 2FA (kode lama hangus)
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Router /auth/2fa/recovery-codes [post]
+func RegenerateRecoveryCodes(c *gin.Context) {
+	claims := middlewares.GetCurrentUser(c)
+
+	var req struct {
+		Kode string `json:"kode" binding:"required,len=6"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, claims.UserID).Error; err != nil {
+		utils.ResponseNotFound(c, "User tidak ditemukan")
+		return
+	}
+	if !user.TOTPAktif {
+		utils.ResponseBadRequest(c, "2FA belum aktif", nil)
+		return
+	}
+	if !services.VerifikasiTOTP(&user, req.Kode) {
+		utils.ResponseBadRequest(c, "Kode autentikasi tidak valid", nil)
+		return
+	}
+
+	kodeList, err := services.BuatRecoveryCodes(config.DB, user.ID)
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal membuat recovery code")
+		return
+	}
+
+	utils.ResponseOK(c, "Recovery code baru berhasil dibuat", gin.H{
+		"recovery_codes": kodeList,
+	})
+}
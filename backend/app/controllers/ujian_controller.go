@@ -0,0 +1,1134 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"sim-sekolah/app/middlewares"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+const formatWaktuUjian = "2006-01-02 15:04"
+
+// ── DTOs ──────────────────────────────────────────────────────
+
+type OpsiSoalRequest struct {
+	Label string `json:"label" binding:"required,max=2"`
+	Teks  string `json:"teks" binding:"required"`
+}
+
+type SoalUjianRequest struct {
+	MataPelajaranID uint              `json:"mata_pelajaran_id" binding:"required"`
+	Tipe            string            `json:"tipe" binding:"required,oneof=pilihan_ganda isian esai"`
+	Pertanyaan      string            `json:"pertanyaan" binding:"required"`
+	KunciJawaban    string            `json:"kunci_jawaban"`                          // PG: label opsi; isian: alternatif dipisah "|"
+	Bobot           float64           `json:"bobot" binding:"omitempty,gt=0,max=100"` // kosong = 1
+	Opsi            []OpsiSoalRequest `json:"opsi" binding:"dive"`
+}
+
+type UjianRequest struct {
+	Judul           string `json:"judul" binding:"required,max=200"`
+	MataPelajaranID uint   `json:"mata_pelajaran_id" binding:"required"`
+	SemesterID      uint   `json:"semester_id"` // kosong = semester aktif
+	Komponen        string `json:"komponen" binding:"required,oneof=harian uts uas"`
+	DurasiMenit     int    `json:"durasi_menit" binding:"required,min=1,max=600"`
+	SoalIDs         []uint `json:"soal_ids" binding:"required,min=1"` // urutan asli soal
+}
+
+type SesiUjianRequest struct {
+	KelasID     uint   `json:"kelas_id" binding:"required"`
+	MulaiPada   string `json:"mulai_pada" binding:"required"`   // "2025-03-10 07:30"
+	SelesaiPada string `json:"selesai_pada" binding:"required"` // "2025-03-10 09:30"
+}
+
+type JawabanUjianRequest struct {
+	SoalID  uint   `json:"soal_id" binding:"required"`
+	Jawaban string `json:"jawaban"`
+}
+
+type NilaiEsaiRequest struct {
+	Skor *float64 `json:"skor" binding:"required,min=0"`
+}
+
+// ── Helper akses ──────────────────────────────────────────────
+
+// siswaLogin mengambil data siswa milik user yang sedang login
+func siswaLogin(c *gin.Context) (models.Siswa, bool) {
+	claims := middlewares.GetCurrentUser(c)
+	var siswa models.Siswa
+	if err := config.DB.Where("user_id = ?", claims.UserID).First(&siswa).Error; err != nil {
+		utils.ResponseNotFound(c, "Data siswa tidak ditemukan")
+		return siswa, false
+	}
+	return siswa, true
+}
+
+// validasiSoal memeriksa kelengkapan opsi dan kunci sesuai tipe soal
+func validasiSoal(c *gin.Context, req *SoalUjianRequest) bool {
+	switch req.Tipe {
+	case models.SoalPilihanGanda:
+		if len(req.Opsi) < 2 {
+			utils.ResponseBadRequest(c, "Soal pilihan ganda minimal memiliki 2 opsi", nil)
+			return false
+		}
+		label := map[string]bool{}
+		for i := range req.Opsi {
+			req.Opsi[i].Label = strings.ToUpper(strings.TrimSpace(req.Opsi[i].Label))
+			if label[req.Opsi[i].Label] {
+				utils.ResponseBadRequest(c, "Label opsi "+req.Opsi[i].Label+" ganda", nil)
+				return false
+			}
+			label[req.Opsi[i].Label] = true
+		}
+		req.KunciJawaban = strings.ToUpper(strings.TrimSpace(req.KunciJawaban))
+		if !label[req.KunciJawaban] {
+			utils.ResponseBadRequest(c, "Kunci jawaban harus salah satu label opsi", nil)
+			return false
+		}
+	case models.SoalIsian:
+		if strings.TrimSpace(req.KunciJawaban) == "" {
+			utils.ResponseBadRequest(c, "Soal isian wajib memiliki kunci jawaban", nil)
+			return false
+		}
+		req.Opsi = nil
+	case models.SoalEsai:
+		req.Opsi = nil
+	}
+	if req.Bobot == 0 {
+		req.Bobot = 1
+	}
+	return true
+}
+
+// ambilSoalMilikSaya memuat soal yang boleh diubah user yang sedang login
+// (admin atau guru pembuatnya)
+func ambilSoalMilikSaya(c *gin.Context) (models.SoalUjian, bool) {
+	var soal models.SoalUjian
+	if err := config.DB.Preload("Opsi").First(&soal, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Soal tidak ditemukan")
+		return soal, false
+	}
+	if middlewares.GetCurrentUser(c).Role == models.RoleAdmin {
+		return soal, true
+	}
+	guru, ok := guruLogin(c)
+	if !ok {
+		return soal, false
+	}
+	if soal.GuruID != guru.ID {
+		utils.ResponseForbidden(c, "Anda hanya dapat mengubah soal milik sendiri")
+		return soal, false
+	}
+	return soal, true
+}
+
+// soalSudahDikerjakan memeriksa apakah soal dipakai ujian yang sudah mulai
+// dikerjakan siswa; soal seperti ini tidak boleh diubah agar hasil tetap sah
+func soalSudahDikerjakan(soalID uint) bool {
+	var n int64
+	config.DB.Table("butir_ujians b").
+		Joins("JOIN sesi_ujians s ON s.ujian_id = b.ujian_id").
+		Joins("JOIN percobaan_ujians p ON p.sesi_id = s.id").
+		Where("b.soal_id = ?", soalID).
+		Count(&n)
+	return n > 0
+}
+
+func ujianSudahDikerjakan(ujianID uint) bool {
+	var n int64
+	config.DB.Model(&models.PercobaanUjian{}).
+		Where("sesi_id IN (?)", config.DB.Model(&models.SesiUjian{}).Select("id").Where("ujian_id = ?", ujianID)).
+		Count(&n)
+	return n > 0
+}
+
+// queryUjianTerlihat membatasi ujian sesuai role: admin dan kepala sekolah
+// melihat semua, guru melihat ujian buatannya, wali kelas juga melihat ujian
+// yang dijadwalkan di kelas perwaliannya.
+func queryUjianTerlihat(c *gin.Context) (*gorm.DB, bool) {
+	query := config.DB.Model(&models.Ujian{})
+
+	claims := middlewares.GetCurrentUser(c)
+	switch claims.Role {
+	case models.RoleAdmin, models.RoleKepalaSekolah:
+		return query, true
+	case models.RoleGuru, models.RoleWaliKelas:
+		guru, ok := guruLogin(c)
+		if !ok {
+			return nil, false
+		}
+		if claims.Role == models.RoleWaliKelas {
+			return query.Where(`ujians.guru_id = ? OR ujians.id IN (
+				SELECT ujian_id FROM sesi_ujians WHERE kelas_id IN (SELECT id FROM kelas WHERE wali_kelas_id = ?))`,
+				guru.ID, guru.ID), true
+		}
+		return query.Where("ujians.guru_id = ?", guru.ID), true
+	}
+	utils.ResponseForbidden(c, "Akses ditolak")
+	return nil, false
+}
+
+// ambilUjianMilikSaya memuat ujian buatan guru yang sedang login; admin boleh
+// mengelola semua ujian
+func ambilUjianMilikSaya(c *gin.Context) (models.Ujian, bool) {
+	var ujian models.Ujian
+	if err := config.DB.First(&ujian, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Ujian tidak ditemukan")
+		return ujian, false
+	}
+	if middlewares.GetCurrentUser(c).Role == models.RoleAdmin {
+		return ujian, true
+	}
+	guru, ok := guruLogin(c)
+	if !ok {
+		return ujian, false
+	}
+	if ujian.GuruID != guru.ID {
+		utils.ResponseForbidden(c, "Anda hanya dapat mengelola ujian milik sendiri")
+		return ujian, false
+	}
+	return ujian, true
+}
+
+// validasiSoalUjian memastikan semua soal ada di bank soal mapel ujian
+func validasiSoalUjian(c *gin.Context, mapelID uint, soalIDs []uint) bool {
+	var n int64
+	config.DB.Model(&models.SoalUjian{}).
+		Where("id IN ? AND mata_pelajaran_id = ?", soalIDs, mapelID).
+		Count(&n)
+	if int(n) != len(soalIDs) {
+		utils.ResponseBadRequest(c, "Semua soal harus berasal dari bank soal mata pelajaran ujian dan tidak boleh ganda", nil)
+		return false
+	}
+	return true
+}
+
+func simpanButirUjian(tx *gorm.DB, ujianID uint, soalIDs []uint) error {
+	if err := tx.Where("ujian_id = ?", ujianID).Delete(&models.ButirUjian{}).Error; err != nil {
+		return err
+	}
+	butir := make([]models.ButirUjian, len(soalIDs))
+	for i, id := range soalIDs {
+		butir[i] = models.ButirUjian{UjianID: ujianID, SoalID: id, Urutan: i + 1}
+	}
+	return tx.Create(&butir).Error
+}
+
+// ambilPercobaanSaya memuat percobaan ujian milik siswa yang sedang login dan
+// menutupnya jika waktu sudah habis
+func ambilPercobaanSaya(c *gin.Context) (models.PercobaanUjian, bool) {
+	var p models.PercobaanUjian
+	siswa, ok := siswaLogin(c)
+	if !ok {
+		return p, false
+	}
+	if err := config.DB.Where("id = ? AND siswa_id = ?", c.Param("id"), siswa.ID).First(&p).Error; err != nil {
+		utils.ResponseNotFound(c, "Percobaan ujian tidak ditemukan")
+		return p, false
+	}
+	if err := services.KedaluwarsaPercobaan(&p); err != nil {
+		utils.ResponseInternalError(c, "Gagal menutup ujian yang sudah habis waktunya")
+		return p, false
+	}
+	return p, true
+}
+
+// lembarUjian menyusun soal sesuai urutan acak percobaan tanpa kunci jawaban,
+// beserta jawaban yang sudah tersimpan dan sisa waktu menurut server. Skor per
+// soal baru ditampilkan setelah sesi ujian berakhir agar siswa yang selesai
+// lebih dulu tidak dapat membocorkan kunci jawaban ke teman sekelasnya.
+func lembarUjian(p models.PercobaanUjian) gin.H {
+	soalIDs := services.UrutanSoalPercobaan(p)
+	var soalList []models.SoalUjian
+	config.DB.Unscoped().
+		Preload("Opsi", func(db *gorm.DB) *gorm.DB { return db.Order("label ASC") }).
+		Where("id IN ?", soalIDs).Find(&soalList)
+	perID := make(map[uint]models.SoalUjian, len(soalList))
+	for _, s := range soalList {
+		perID[s.ID] = s
+	}
+
+	var jawabanList []models.JawabanUjian
+	config.DB.Where("percobaan_id = ?", p.ID).Find(&jawabanList)
+	jawaban := make(map[uint]models.JawabanUjian, len(jawabanList))
+	for _, j := range jawabanList {
+		jawaban[j.SoalID] = j
+	}
+
+	type opsiSiswa struct {
+		Label string `json:"label"`
+		Teks  string `json:"teks"`
+	}
+	type soalSiswa struct {
+		No         int         `json:"no"`
+		ID         uint        `json:"id"`
+		Tipe       string      `json:"tipe"`
+		Pertanyaan string      `json:"pertanyaan"`
+		Bobot      float64     `json:"bobot"`
+		Opsi       []opsiSiswa `json:"opsi,omitempty"`
+		Jawaban    string      `json:"jawaban"`
+		Skor       *float64    `json:"skor,omitempty"` // hanya setelah sesi ujian berakhir
+	}
+	var sesi models.SesiUjian
+	config.DB.First(&sesi, p.SesiID)
+	hasilDibuka := p.Status == models.PercobaanSelesai && !sesi.SelesaiPada.IsZero() &&
+		time.Now().After(sesi.SelesaiPada)
+	soal := make([]soalSiswa, 0, len(soalIDs))
+	for i, id := range soalIDs {
+		s, ada := perID[id]
+		if !ada {
+			continue
+		}
+		baris := soalSiswa{No: i + 1, ID: s.ID, Tipe: s.Tipe, Pertanyaan: s.Pertanyaan, Bobot: s.Bobot}
+		for _, o := range s.Opsi {
+			baris.Opsi = append(baris.Opsi, opsiSiswa{Label: o.Label, Teks: o.Teks})
+		}
+		if j, ada := jawaban[id]; ada {
+			baris.Jawaban = j.Jawaban
+			if hasilDibuka {
+				baris.Skor = j.Skor
+			}
+		}
+		soal = append(soal, baris)
+	}
+	return gin.H{
+		"percobaan":         p,
+		"sisa_detik":        services.SisaWaktuUjian(p),
+		"hasil_dibuka":      hasilDibuka,
+		"hasil_dibuka_pada": sesi.SelesaiPada,
+		"soal":              soal,
+	}
+}
+
+// ── Bank soal ─────────────────────────────────────────────────
+
+// GetSoalUjian godoc
+// @Summary List bank soal
+// @Tags Ujian
+// @Security BearerAuth
+// @Param mata_pelajaran_id query int false "Filter mata pelajaran"
+// @Param tipe query string false "pilihan_ganda / isian / esai"
+// @Param q query string false "Cari pertanyaan"
+// @Param page query int false "Halaman" default(1)
+// @Param limit query int false "Jumlah per halaman" default(20)
+// @Router /ujian/soal [get]
+func GetSoalUjian(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	if page < 1 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	query := config.DB.Model(&models.SoalUjian{})
+	if mapelID := c.Query("mata_pelajaran_id"); mapelID != "" {
+		query = query.Where("mata_pelajaran_id = ?", mapelID)
+	}
+	if tipe := c.Query("tipe"); tipe != "" {
+		query = query.Where("tipe = ?", tipe)
+	}
+	if q := c.Query("q"); q != "" {
+		query = query.Where("pertanyaan ILIKE ?", "%"+q+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var list []models.SoalUjian
+	if err := query.Preload("MataPelajaran").
+		Preload("Opsi", func(db *gorm.DB) *gorm.DB { return db.Order("label ASC") }).
+		Offset(offset).Limit(limit).Order("id DESC").
+		Find(&list).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal mengambil bank soal")
+		return
+	}
+	utils.ResponsePaginated(c, "Daftar bank soal", list, page, limit, total)
+}
+
+// CreateSoalUjian godoc
+// @Summary Tambah soal ke bank soal
+// @Tags Ujian
+// @Security BearerAuth
+// @Param body body SoalUjianRequest true "Data soal"
+// @Router /ujian/soal [post]
+func CreateSoalUjian(c *gin.Context) {
+	var req SoalUjianRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if !validasiSoal(c, &req) {
+		return
+	}
+	guru, ok := guruLogin(c)
+	if !ok {
+		return
+	}
+	var mapel models.MataPelajaran
+	if err := config.DB.First(&mapel, req.MataPelajaranID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Mata pelajaran tidak ditemukan", nil)
+		return
+	}
+
+	soal := models.SoalUjian{
+		MataPelajaranID: mapel.ID,
+		GuruID:          guru.ID,
+		Tipe:            req.Tipe,
+		Pertanyaan:      req.Pertanyaan,
+		KunciJawaban:    req.KunciJawaban,
+		Bobot:           req.Bobot,
+	}
+	for _, o := range req.Opsi {
+		soal.Opsi = append(soal.Opsi, models.OpsiSoal{Label: o.Label, Teks: o.Teks})
+	}
+	if err := config.DB.Create(&soal).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan soal")
+		return
+	}
+	utils.ResponseCreated(c, "Soal berhasil ditambahkan", soal)
+}
+
+// UpdateSoalUjian godoc
+// @Summary Update soal (ditolak jika soal sudah dipakai ujian yang dikerjakan)
+// @Tags Ujian
+// @Security BearerAuth
+// @Param id path int true "Soal ID"
+// @Param body body SoalUjianRequest true "Data soal"
+// @Router /ujian/soal/{id} [put]
+func UpdateSoalUjian(c *gin.Context) {
+	soal, ok := ambilSoalMilikSaya(c)
+	if !ok {
+		return
+	}
+	var req SoalUjianRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if !validasiSoal(c, &req) {
+		return
+	}
+	if soalSudahDikerjakan(soal.ID) {
+		utils.ResponseBadRequest(c, "Soal sudah dipakai dalam ujian yang dikerjakan siswa. Buat soal baru untuk perubahan.", nil)
+		return
+	}
+	if req.MataPelajaranID != soal.MataPelajaranID {
+		var n int64
+		config.DB.Model(&models.ButirUjian{}).Where("soal_id = ?", soal.ID).Count(&n)
+		if n > 0 {
+			utils.ResponseBadRequest(c, "Mata pelajaran soal yang sudah dipakai ujian tidak dapat diubah", nil)
+			return
+		}
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&soal).Updates(map[string]interface{}{
+			"mata_pelajaran_id": req.MataPelajaranID,
+			"tipe":              req.Tipe,
+			"pertanyaan":        req.Pertanyaan,
+			"kunci_jawaban":     req.KunciJawaban,
+			"bobot":             req.Bobot,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("soal_id = ?", soal.ID).Delete(&models.OpsiSoal{}).Error; err != nil {
+			return err
+		}
+		for _, o := range req.Opsi {
+			if err := tx.Create(&models.OpsiSoal{SoalID: soal.ID, Label: o.Label, Teks: o.Teks}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal mengupdate soal")
+		return
+	}
+	config.DB.Preload("Opsi", func(db *gorm.DB) *gorm.DB { return db.Order("label ASC") }).First(&soal, soal.ID)
+	utils.ResponseOK(c, "Soal berhasil diupdate", soal)
+}
+
+// DeleteSoalUjian godoc
+// @Summary Hapus soal dari bank soal
+// @Tags Ujian
+// @Security BearerAuth
+// @Param id path int true "Soal ID"
+// @Router /ujian/soal/{id} [delete]
+func DeleteSoalUjian(c *gin.Context) {
+	soal, ok := ambilSoalMilikSaya(c)
+	if !ok {
+		return
+	}
+	var n int64
+	config.DB.Model(&models.ButirUjian{}).
+		Where("soal_id = ? AND ujian_id NOT IN (?)", soal.ID,
+			config.DB.Table("sesi_ujians s").Select("s.ujian_id").Joins("JOIN percobaan_ujians p ON p.sesi_id = s.id")).
+		Count(&n)
+	if n > 0 {
+		utils.ResponseBadRequest(c, "Soal masih dipakai ujian yang belum dilaksanakan. Keluarkan dari ujian terlebih dahulu.", nil)
+		return
+	}
+	// Soft delete: hasil ujian yang sudah memakai soal ini tetap utuh
+	config.DB.Delete(&soal)
+	utils.ResponseOK(c, "Soal berhasil dihapus", nil)
+}
+
+// ── Ujian ─────────────────────────────────────────────────────
+
+// GetUjian godoc
+// @Summary List ujian
+// @Tags Ujian
+// @Security BearerAuth
+// @Param mata_pelajaran_id query int false "Filter mata pelajaran"
+// @Param semester_id query int false "Filter semester"
+// @Param komponen query string false "harian / uts / uas"
+// @Param page query int false "Halaman" default(1)
+// @Param limit query int false "Jumlah per halaman" default(20)
+// @Router /ujian [get]
+func GetUjian(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	if page < 1 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	query, ok := queryUjianTerlihat(c)
+	if !ok {
+		return
+	}
+	if mapelID := c.Query("mata_pelajaran_id"); mapelID != "" {
+		query = query.Where("ujians.mata_pelajaran_id = ?", mapelID)
+	}
+	if semesterID := c.Query("semester_id"); semesterID != "" {
+		query = query.Where("ujians.semester_id = ?", semesterID)
+	}
+	if komponen := c.Query("komponen"); komponen != "" {
+		query = query.Where("ujians.komponen = ?", komponen)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var list []models.Ujian
+	if err := query.Preload("MataPelajaran").Preload("Guru").Preload("Sesi.Kelas").
+		Offset(offset).Limit(limit).Order("ujians.id DESC").
+		Find(&list).Error; err != nil {
+		utils.ResponseInternalError(c, "Gagal mengambil data ujian")
+		return
+	}
+	utils.ResponsePaginated(c, "Daftar ujian", list, page, limit, total)
+}
+
+// GetUjianByID godoc
+// @Summary Detail ujian beserta soal dan sesi kelas
+// @Tags Ujian
+// @Security BearerAuth
+// @Param id path int true "Ujian ID"
+// @Router /ujian/{id} [get]
+func GetUjianByID(c *gin.Context) {
+	query, ok := queryUjianTerlihat(c)
+	if !ok {
+		return
+	}
+	var ujian models.Ujian
+	if err := query.Where("ujians.id = ?", c.Param("id")).
+		Preload("MataPelajaran").Preload("Guru").Preload("Sesi.Kelas").
+		Preload("Butir", func(db *gorm.DB) *gorm.DB { return db.Order("urutan ASC") }).
+		Preload("Butir.Soal", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Butir.Soal.Opsi", func(db *gorm.DB) *gorm.DB { return db.Order("label ASC") }).
+		First(&ujian).Error; err != nil {
+		utils.ResponseNotFound(c, "Ujian tidak ditemukan")
+		return
+	}
+	utils.ResponseOK(c, "Detail ujian", ujian)
+}
+
+// CreateUjian godoc
+// @Summary Buat ujian dari bank soal
+// @Tags Ujian
+// @Security BearerAuth
+// @Param body body UjianRequest true "Data ujian"
+// @Router /ujian [post]
+func CreateUjian(c *gin.Context) {
+	var req UjianRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	guru, ok := guruLogin(c)
+	if !ok {
+		return
+	}
+	var semester models.Semester
+	query := config.DB.Model(&models.Semester{})
+	if req.SemesterID != 0 {
+		query = query.Where("id = ?", req.SemesterID)
+	} else {
+		query = query.Where("is_aktif = ?", true)
+	}
+	if err := query.First(&semester).Error; err != nil {
+		utils.ResponseBadRequest(c, "Semester tidak ditemukan", nil)
+		return
+	}
+	if !validasiSoalUjian(c, req.MataPelajaranID, req.SoalIDs) {
+		return
+	}
+
+	ujian := models.Ujian{
+		Judul:           req.Judul,
+		MataPelajaranID: req.MataPelajaranID,
+		SemesterID:      semester.ID,
+		GuruID:          guru.ID,
+		Komponen:        req.Komponen,
+		DurasiMenit:     req.DurasiMenit,
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&ujian).Error; err != nil {
+			return err
+		}
+		return simpanButirUjian(tx, ujian.ID, req.SoalIDs)
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan ujian")
+		return
+	}
+	config.DB.Preload("MataPelajaran").Preload("Butir").First(&ujian, ujian.ID)
+	utils.ResponseCreated(c, "Ujian berhasil dibuat", ujian)
+}
+
+// UpdateUjian godoc
+// @Summary Update ujian (ditolak jika sudah ada siswa yang mengerjakan)
+// @Tags Ujian
+// @Security BearerAuth
+// @Param id path int true "Ujian ID"
+// @Param body body UjianRequest true "Data ujian"
+// @Router /ujian/{id} [put]
+func UpdateUjian(c *gin.Context) {
+	ujian, ok := ambilUjianMilikSaya(c)
+	if !ok {
+		return
+	}
+	var req UjianRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	if ujianSudahDikerjakan(ujian.ID) {
+		utils.ResponseBadRequest(c, "Ujian yang sudah dikerjakan siswa tidak dapat diubah", nil)
+		return
+	}
+	if req.MataPelajaranID != ujian.MataPelajaranID {
+		var n int64
+		config.DB.Model(&models.SesiUjian{}).Where("ujian_id = ?", ujian.ID).Count(&n)
+		if n > 0 {
+			utils.ResponseBadRequest(c, "Hapus sesi ujian terlebih dahulu sebelum mengganti mata pelajaran", nil)
+			return
+		}
+	}
+	if !validasiSoalUjian(c, req.MataPelajaranID, req.SoalIDs) {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&ujian).Updates(map[string]interface{}{
+			"judul":             req.Judul,
+			"mata_pelajaran_id": req.MataPelajaranID,
+			"komponen":          req.Komponen,
+			"durasi_menit":      req.DurasiMenit,
+		}).Error; err != nil {
+			return err
+		}
+		return simpanButirUjian(tx, ujian.ID, req.SoalIDs)
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal mengupdate ujian")
+		return
+	}
+	config.DB.Preload("MataPelajaran").Preload("Butir").Preload("Sesi").First(&ujian, ujian.ID)
+	utils.ResponseOK(c, "Ujian berhasil diupdate", ujian)
+}
+
+// DeleteUjian godoc
+// @Summary Hapus ujian (ditolak jika sudah ada siswa yang mengerjakan)
+// @Tags Ujian
+// @Security BearerAuth
+// @Param id path int true "Ujian ID"
+// @Router /ujian/{id} [delete]
+func DeleteUjian(c *gin.Context) {
+	ujian, ok := ambilUjianMilikSaya(c)
+	if !ok {
+		return
+	}
+	if ujianSudahDikerjakan(ujian.ID) {
+		utils.ResponseBadRequest(c, "Ujian yang sudah dikerjakan siswa tidak dapat dihapus", nil)
+		return
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("ujian_id = ?", ujian.ID).Delete(&models.SesiUjian{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("ujian_id = ?", ujian.ID).Delete(&models.ButirUjian{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&ujian).Error
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menghapus ujian")
+		return
+	}
+	utils.ResponseOK(c, "Ujian berhasil dihapus", nil)
+}
+
+// ── Sesi ──────────────────────────────────────────────────────
+
+// SimpanSesiUjian godoc
+// @Summary Jadwalkan (atau ubah jendela waktu) ujian untuk satu kelas
+// @Tags Ujian
+// @Security BearerAuth
+// @Param id path int true "Ujian ID"
+// @Param body body SesiUjianRequest true "Kelas dan jendela waktu"
+// @Router /ujian/{id}/sesi [post]
+func SimpanSesiUjian(c *gin.Context) {
+	ujian, ok := ambilUjianMilikSaya(c)
+	if !ok {
+		return
+	}
+	var req SesiUjianRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	mulai, errMulai := time.ParseInLocation(formatWaktuUjian, req.MulaiPada, time.Local)
+	selesai, errSelesai := time.ParseInLocation(formatWaktuUjian, req.SelesaiPada, time.Local)
+	if errMulai != nil || errSelesai != nil {
+		utils.ResponseBadRequest(c, "Format waktu harus YYYY-MM-DD HH:MM", nil)
+		return
+	}
+	if !selesai.After(mulai) {
+		utils.ResponseBadRequest(c, "Waktu selesai harus setelah waktu mulai", nil)
+		return
+	}
+
+	var kelas models.Kelas
+	if err := config.DB.First(&kelas, req.KelasID).Error; err != nil {
+		utils.ResponseBadRequest(c, "Kelas tidak ditemukan", nil)
+		return
+	}
+	if middlewares.GetCurrentUser(c).Role != models.RoleAdmin &&
+		!services.GuruMengampu(ujian.GuruID, ujian.SemesterID, kelas.ID, ujian.MataPelajaranID) {
+		utils.ResponseForbidden(c, "Anda bukan guru pengampu mata pelajaran ini di kelas "+kelas.Nama)
+		return
+	}
+
+	var sesi models.SesiUjian
+	err := config.DB.Where("ujian_id = ? AND kelas_id = ?", ujian.ID, kelas.ID).First(&sesi).Error
+	if err == nil {
+		// Jendela waktu sesi yang berjalan boleh diubah (misal perpanjangan);
+		// batas waktu percobaan yang sudah dimulai tidak ikut berubah
+		config.DB.Model(&sesi).Updates(map[string]interface{}{"mulai_pada": mulai, "selesai_pada": selesai})
+	} else {
+		sesi = models.SesiUjian{UjianID: ujian.ID, KelasID: kelas.ID, MulaiPada: mulai, SelesaiPada: selesai}
+		if err := config.DB.Create(&sesi).Error; err != nil {
+			utils.ResponseInternalError(c, "Gagal menyimpan sesi ujian")
+			return
+		}
+	}
+	config.DB.Preload("Kelas").First(&sesi, sesi.ID)
+	utils.ResponseOK(c, "Sesi ujian kelas "+kelas.Nama+" disimpan", sesi)
+}
+
+// DeleteSesiUjian godoc
+// @Summary Hapus sesi ujian kelas (ditolak jika sudah ada siswa yang mengerjakan)
+// @Tags Ujian
+// @Security BearerAuth
+// @Param id path int true "Ujian ID"
+// @Param sesi_id path int true "Sesi ID"
+// @Router /ujian/{id}/sesi/{sesi_id} [delete]
+func DeleteSesiUjian(c *gin.Context) {
+	ujian, ok := ambilUjianMilikSaya(c)
+	if !ok {
+		return
+	}
+	var sesi models.SesiUjian
+	if err := config.DB.Where("id = ? AND ujian_id = ?", c.Param("sesi_id"), ujian.ID).First(&sesi).Error; err != nil {
+		utils.ResponseNotFound(c, "Sesi ujian tidak ditemukan")
+		return
+	}
+	var n int64
+	config.DB.Model(&models.PercobaanUjian{}).Where("sesi_id = ?", sesi.ID).Count(&n)
+	if n > 0 {
+		utils.ResponseBadRequest(c, "Sesi yang sudah dikerjakan siswa tidak dapat dihapus", nil)
+		return
+	}
+	config.DB.Delete(&sesi)
+	utils.ResponseOK(c, "Sesi ujian berhasil dihapus", nil)
+}
+
+// ── Pengerjaan (siswa) ────────────────────────────────────────
+
+// GetUjianSaya godoc
+// @Summary Ujian terjadwal untuk kelas siswa (atau anak) yang sedang login
+// @Tags Ujian
+// @Security BearerAuth
+// @Param semester_id query int false "Semester (default: semester aktif)"
+// @Param siswa_id query int false "Anak yang dipilih (khusus orang tua)"
+// @Router /ujian/saya [get]
+func GetUjianSaya(c *gin.Context) {
+	siswaID, ok := siswaMilikLogin(c)
+	if !ok {
+		return
+	}
+	var semester models.Semester
+	query := config.DB.Model(&models.Semester{})
+	if semesterID := c.Query("semester_id"); semesterID != "" {
+		query = query.Where("id = ?", semesterID)
+	} else {
+		query = query.Where("is_aktif = ?", true)
+	}
+	if err := query.First(&semester).Error; err != nil {
+		utils.ResponseNotFound(c, "Semester tidak ditemukan")
+		return
+	}
+	kelas := services.KelasSiswaDiSemester(siswaID, semester.ID)
+	if kelas == nil {
+		utils.ResponseNotFound(c, "Siswa tidak terdaftar di kelas mana pun pada semester ini")
+		return
+	}
+
+	var sesiList []models.SesiUjian
+	config.DB.Preload("Ujian.MataPelajaran").
+		Joins("JOIN ujians ON ujians.id = sesi_ujians.ujian_id").
+		Where("sesi_ujians.kelas_id = ? AND ujians.semester_id = ?", kelas.ID, semester.ID).
+		Order("sesi_ujians.mulai_pada ASC").
+		Find(&sesiList)
+
+	sesiIDs := make([]uint, len(sesiList))
+	for i, s := range sesiList {
+		sesiIDs[i] = s.ID
+	}
+	var percobaan []models.PercobaanUjian
+	if len(sesiIDs) > 0 {
+		config.DB.Where("siswa_id = ? AND sesi_id IN ?", siswaID, sesiIDs).Find(&percobaan)
+	}
+	perSesi := make(map[uint]models.PercobaanUjian, len(percobaan))
+	for _, p := range percobaan {
+		perSesi[p.SesiID] = p
+	}
+
+	type ujianSiswa struct {
+		Sesi      models.SesiUjian       `json:"sesi"`
+		Percobaan *models.PercobaanUjian `json:"percobaan"`
+	}
+	hasil := make([]ujianSiswa, 0, len(sesiList))
+	for _, s := range sesiList {
+		baris := ujianSiswa{Sesi: s}
+		if p, ada := perSesi[s.ID]; ada {
+			baris.Percobaan = &p
+		}
+		hasil = append(hasil, baris)
+	}
+	utils.ResponseOK(c, "Ujian saya", gin.H{
+		"semester": semester,
+		"kelas":    kelas,
+		"ujian":    hasil,
+	})
+}
+
+// MulaiUjian godoc
+// @Summary Mulai (atau lanjutkan) mengerjakan ujian pada sesi kelas
+// @Tags Ujian
+// @Security BearerAuth
+// @Param id path int true "Sesi ID"
+// @Router /ujian/sesi/{id}/mulai [post]
+func MulaiUjian(c *gin.Context) {
+	siswa, ok := siswaLogin(c)
+	if !ok {
+		return
+	}
+	var sesi models.SesiUjian
+	if err := config.DB.First(&sesi, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Sesi ujian tidak ditemukan")
+		return
+	}
+
+	p, err := services.MulaiPercobaan(sesi, siswa.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBukanPesertaUjian):
+			utils.ResponseForbidden(c, err.Error())
+		case errors.Is(err, services.ErrUjianBelumDibuka), errors.Is(err, services.ErrUjianSudahDitutup),
+			errors.Is(err, services.ErrUjianTanpaSoal):
+			utils.ResponseBadRequest(c, err.Error(), nil)
+		default:
+			utils.ResponseInternalError(c, "Gagal memulai ujian")
+		}
+		return
+	}
+	if err := services.KedaluwarsaPercobaan(&p); err != nil {
+		utils.ResponseInternalError(c, "Gagal menutup ujian yang sudah habis waktunya")
+		return
+	}
+	utils.ResponseOK(c, "Selamat mengerjakan", lembarUjian(p))
+}
+
+// GetPercobaanUjian godoc
+// @Summary Lembar ujian siswa: soal (urutan acak), jawaban tersimpan, dan sisa waktu
+// @Tags Ujian
+// @Security BearerAuth
+// @Param id path int true "Percobaan ID"
+// @Router /ujian/percobaan/{id} [get]
+func GetPercobaanUjian(c *gin.Context) {
+	p, ok := ambilPercobaanSaya(c)
+	if !ok {
+		return
+	}
+	utils.ResponseOK(c, "Lembar ujian", lembarUjian(p))
+}
+
+// SimpanJawabanUjian godoc
+// @Summary Simpan jawaban satu soal (autosave)
+// @Tags Ujian
+// @Security BearerAuth
+// @Param id path int true "Percobaan ID"
+// @Param body body JawabanUjianRequest true "Jawaban"
+// @Router /ujian/percobaan/{id}/jawaban [put]
+func SimpanJawabanUjian(c *gin.Context) {
+	var req JawabanUjianRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	p, ok := ambilPercobaanSaya(c)
+	if !ok {
+		return
+	}
+	if err := services.SimpanJawabanUjian(&p, req.SoalID, req.Jawaban); err != nil {
+		switch {
+		case errors.Is(err, services.ErrPercobaanSudahSelesai), errors.Is(err, services.ErrWaktuUjianHabis),
+			errors.Is(err, services.ErrSoalBukanBagianUjian):
+			utils.ResponseBadRequest(c, err.Error(), nil)
+		default:
+			utils.ResponseInternalError(c, "Gagal menyimpan jawaban")
+		}
+		return
+	}
+	utils.ResponseOK(c, "Jawaban tersimpan", gin.H{
+		"soal_id":    req.SoalID,
+		"sisa_detik": services.SisaWaktuUjian(p),
+	})
+}
+
+// SelesaikanUjian godoc
+// @Summary Selesaikan ujian; soal objektif langsung dinilai, skor per soal tampil setelah sesi berakhir
+// @Tags Ujian
+// @Security BearerAuth
+// @Param id path int true "Percobaan ID"
+// @Router /ujian/percobaan/{id}/selesai [post]
+func SelesaikanUjian(c *gin.Context) {
+	p, ok := ambilPercobaanSaya(c)
+	if !ok {
+		return
+	}
+	if err := services.SelesaikanPercobaan(&p); err != nil {
+		utils.ResponseInternalError(c, "Gagal menyelesaikan ujian")
+		return
+	}
+	utils.ResponseOK(c, "Ujian selesai", lembarUjian(p))
+}
+
+// ── Koreksi & nilai (guru) ────────────────────────────────────
+
+// GetHasilUjian godoc
+// @Summary Hasil ujian seluruh peserta
+// @Tags Ujian
+// @Security BearerAuth
+// @Param id path int true "Ujian ID"
+// @Param kelas_id query int false "Filter kelas"
+// @Router /ujian/{id}/hasil [get]
+func GetHasilUjian(c *gin.Context) {
+	query, ok := queryUjianTerlihat(c)
+	if !ok {
+		return
+	}
+	var ujian models.Ujian
+	if err := query.Where("ujians.id = ?", c.Param("id")).First(&ujian).Error; err != nil {
+		utils.ResponseNotFound(c, "Ujian tidak ditemukan")
+		return
+	}
+
+	sesiQuery := config.DB.Model(&models.SesiUjian{}).Select("id").Where("ujian_id = ?", ujian.ID)
+	if kelasID := c.Query("kelas_id"); kelasID != "" {
+		sesiQuery = sesiQuery.Where("kelas_id = ?", kelasID)
+	}
+	var list []models.PercobaanUjian
+	config.DB.Preload("Siswa").Preload("Sesi.Kelas").
+		Where("sesi_id IN (?)", sesiQuery).
+		Order("sesi_id ASC, mulai_pada ASC").
+		Find(&list)
+	for i := range list {
+		services.KedaluwarsaPercobaan(&list[i])
+	}
+
+	var ringkasan struct {
+		Peserta      int     `json:"peserta"`
+		Berlangsung  int     `json:"berlangsung"`
+		MenungguEsai int     `json:"menunggu_esai"`
+		RataRata     float64 `json:"rata_rata"`
+	}
+	var total float64
+	var dinilai int
+	for _, p := range list {
+		ringkasan.Peserta++
+		switch {
+		case p.Status == models.PercobaanBerlangsung:
+			ringkasan.Berlangsung++
+		case p.NilaiAkhir == nil:
+			ringkasan.MenungguEsai++
+		default:
+			total += *p.NilaiAkhir
+			dinilai++
+		}
+	}
+	if dinilai > 0 {
+		ringkasan.RataRata = total / float64(dinilai)
+	}
+	utils.ResponseOK(c, "Hasil ujian "+ujian.Judul, gin.H{
+		"ringkasan": ringkasan,
+		"percobaan": list,
+	})
+}
+
+// GetEsaiBelumDinilai godoc
+// @Summary Jawaban esai yang menunggu penilaian guru
+// @Tags Ujian
+// @Security BearerAuth
+// @Param id path int true "Ujian ID"
+// @Router /ujian/{id}/esai [get]
+func GetEsaiBelumDinilai(c *gin.Context) {
+	ujian, ok := ambilUjianMilikSaya(c)
+	if !ok {
+		return
+	}
+	var list []models.PercobaanUjian
+	config.DB.Preload("Siswa").
+		Preload("Jawaban", "skor IS NULL").
+		Preload("Jawaban.Soal", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("sesi_id IN (?) AND status = ? AND nilai_akhir IS NULL",
+			config.DB.Model(&models.SesiUjian{}).Select("id").Where("ujian_id = ?", ujian.ID),
+			models.PercobaanSelesai).
+		Find(&list)
+	utils.ResponseOK(c, "Jawaban esai belum dinilai", list)
+}
+
+// NilaiJawabanEsai godoc
+// @Summary Beri skor jawaban esai (0 sampai bobot soal)
+// @Tags Ujian
+// @Security BearerAuth
+// @Param id path int true "Jawaban ID"
+// @Param body body NilaiEsaiRequest true "Skor"
+// @Router /ujian/jawaban/{id}/nilai [put]
+func NilaiJawabanEsai(c *gin.Context) {
+	var req NilaiEsaiRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ResponseBadRequest(c, "Validasi gagal", err.Error())
+		return
+	}
+	guru, ok := guruLogin(c)
+	if !ok {
+		return
+	}
+	var jawaban models.JawabanUjian
+	if err := config.DB.Preload("Soal", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		First(&jawaban, c.Param("id")).Error; err != nil {
+		utils.ResponseNotFound(c, "Jawaban tidak ditemukan")
+		return
+	}
+	var percobaan models.PercobaanUjian
+	if err := config.DB.Preload("Sesi.Ujian").First(&percobaan, jawaban.PercobaanID).Error; err != nil {
+		utils.ResponseNotFound(c, "Percobaan ujian tidak ditemukan")
+		return
+	}
+	if percobaan.Sesi.Ujian.GuruID != guru.ID {
+		utils.ResponseForbidden(c, "Anda hanya dapat menilai ujian milik sendiri")
+		return
+	}
+	if jawaban.Soal.Tipe != models.SoalEsai {
+		utils.ResponseBadRequest(c, "Hanya jawaban esai yang dinilai manual", nil)
+		return
+	}
+	if percobaan.Status != models.PercobaanSelesai {
+		utils.ResponseBadRequest(c, "Siswa masih mengerjakan ujian ini", nil)
+		return
+	}
+	if *req.Skor > jawaban.Soal.Bobot {
+		utils.ResponseBadRequest(c, fmt.Sprintf("Skor maksimal soal ini %.2f", jawaban.Soal.Bobot), nil)
+		return
+	}
+
+	if err := services.NilaiJawabanEsai(&jawaban, *req.Skor); err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan skor")
+		return
+	}
+	config.DB.First(&percobaan, percobaan.ID)
+	utils.ResponseOK(c, "Skor esai disimpan", gin.H{
+		"jawaban":   jawaban,
+		"percobaan": percobaan,
+	})
+}
+
+// TerapkanNilaiUjian godoc
+// @Summary Masukkan nilai akhir ujian ke komponen Nilai (harian/UTS/UAS) peserta
+// @Description Ditolak jika masih ada siswa yang mengerjakan atau esai yang belum dinilai. Siswa yang tidak mengikuti ujian tidak diubah nilainya.
+// @Tags Ujian
+// @Security BearerAuth
+// @Param id path int true "Ujian ID"
+// @Router /ujian/{id}/terapkan-nilai [post]
+func TerapkanNilaiUjian(c *gin.Context) {
+	ujian, ok := ambilUjianMilikSaya(c)
+	if !ok {
+		return
+	}
+	nilaiSiswa, err := services.NilaiAkhirUjian(ujian.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrMasihAdaUjianBerjalan) || errors.Is(err, services.ErrMasihAdaEsaiBelumNilai) {
+			utils.ResponseBadRequest(c, err.Error(), nil)
+			return
+		}
+		utils.ResponseInternalError(c, "Gagal menghitung nilai ujian")
+		return
+	}
+	if len(nilaiSiswa) == 0 {
+		utils.ResponseBadRequest(c, "Belum ada siswa yang mengerjakan ujian ini", nil)
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		for siswaID, nilai := range nilaiSiswa {
+			if err := simpanKomponenNilai(tx, siswaID, ujian.MataPelajaranID, ujian.SemesterID, ujian.Komponen, nilai); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.ResponseInternalError(c, "Gagal menyimpan nilai")
+		return
+	}
+	utils.ResponseOK(c, fmt.Sprintf("Nilai %s %d siswa diisi dari ujian %s", ujian.Komponen, len(nilaiSiswa), ujian.Judul), nilaiSiswa)
+}
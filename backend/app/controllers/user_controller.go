@@ -116,11 +116,12 @@ func CreateUser(c *gin.Context) {
 	}
 
 	user := models.User{
-		RoleID:   req.RoleID,
-		Nama:     req.Nama,
-		Email:    req.Email,
-		Password: req.Password, // akan di-hash oleh BeforeCreate hook
-		IsActive: true,
+		RoleID:             req.RoleID,
+		Nama:               req.Nama,
+		Email:              req.Email,
+		Password:           req.Password, // akan di-hash oleh BeforeCreate hook
+		IsActive:           true,
+		WajibGantiPassword: true,
 	}
 
 	if err := config.DB.Create(&user).Error; err != nil {
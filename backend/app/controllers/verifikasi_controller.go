@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// VerifikasiRaporPublik godoc
+// @Summary Verifikasi keaslian rapor dari kode QR (tanpa login)
+// @Tags Verifikasi
+// @Param code path string true "Kode verifikasi yang tercetak di rapor"
+// @Param hash query string false "Hash SHA-256 yang tercetak di rapor, untuk dicocokkan"
+// @Router /verify/rapor/{code} [get]
+func VerifikasiRaporPublik(c *gin.Context) {
+	kode := strings.ToUpper(strings.TrimSpace(c.Param("code")))
+
+	var versi models.RaporVersi
+	if err := config.DB.Where("kode_verifikasi = ?", kode).First(&versi).Error; err != nil {
+		utils.ResponseNotFound(c, "Kode verifikasi tidak dikenal")
+		return
+	}
+	var rapor models.Rapor
+	if err := config.DB.Preload("Siswa").Preload("Semester.TahunAjaran").
+		First(&rapor, versi.RaporID).Error; err != nil {
+		utils.ResponseNotFound(c, "Kode verifikasi tidak dikenal")
+		return
+	}
+
+	// Isi snapshot harus sama dengan hash yang dicatat saat rapor dibuat
+	hash, err := services.HashKonten([]byte(versi.Snapshot))
+	kontenUtuh := err == nil && hash == versi.HashKonten
+
+	// File PDF di server harus ada dan tidak boleh berubah sejak dibuat
+	hashFile, err := services.HashFile(versi.FilePath)
+	fileAda := err == nil
+	fileUtuh := fileAda && hashFile == versi.HashFile
+
+	// Hash yang tercetak di kertas (opsional) harus sama dengan hash versi ini
+	hashCetak := strings.ToLower(strings.TrimSpace(c.Query("hash")))
+	hashCetakCocok := hashCetak == "" || hashCetak == versi.HashKonten
+
+	valid := false
+	var keterangan string
+	switch {
+	case !fileAda:
+		keterangan = "File rapor tidak ditemukan di server sehingga keasliannya tidak dapat dipastikan"
+	case !kontenUtuh || !fileUtuh:
+		keterangan = "Data rapor tidak cocok dengan hash yang tercatat (kemungkinan telah diubah)"
+	case !hashCetakCocok:
+		keterangan = "Hash yang tercetak tidak cocok dengan rapor ini"
+	case versi.Status == models.RaporRevoked:
+		keterangan = "Rapor ini telah dicabut oleh sekolah"
+		if versi.AlasanCabut != "" {
+			keterangan += ": " + versi.AlasanCabut
+		}
+	case versi.Status == models.RaporRevised:
+		keterangan = "Rapor ini sudah digantikan oleh versi yang lebih baru"
+		if rapor.VersiTerbit > 0 {
+			keterangan = "Rapor ini sudah digantikan oleh versi " + strconv.Itoa(rapor.VersiTerbit)
+		}
+	case versi.Status != models.RaporPublished:
+		keterangan = "Rapor ini belum diterbitkan"
+	default:
+		valid = true
+		keterangan = "Rapor asli dan masih berlaku"
+	}
+
+	utils.ResponseOK(c, "Hasil verifikasi rapor", gin.H{
+		"valid":            valid,
+		"keterangan":       keterangan,
+		"status":           versi.Status,
+		"nama_siswa":       rapor.Siswa.Nama,
+		"semester":         rapor.Semester.Nama,
+		"tahun_ajaran":     rapor.Semester.TahunAjaran.Nama,
+		"sekolah":          services.ProfilSekolah().Nama,
+		"versi":            versi.Nomor,
+		"diterbitkan_pada": versi.DiterbitkanPada,
+		"hash":             versi.HashKonten,
+		"hash_cocok":       kontenUtuh && fileUtuh && hashCetakCocok,
+	})
+}
@@ -1,8 +1,10 @@
 package middlewares
 
 import (
+	"time"
+
 	"sim-sekolah/app/models"
-	"sim-sekolah/config"
+	"sim-sekolah/app/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,15 +21,16 @@ func ActivityLogger(action, entity string) gin.HandlerFunc {
 				return
 			}
 
-			log := models.ActivityLog{
+			// Disimpan oleh penulis log di JobRunner agar tidak menahan response;
+			// sisa antrian dikuras saat server dimatikan
+			services.CatatAktivitas(models.ActivityLog{
 				UserID:    claims.UserID,
 				Action:    action,
 				Entity:    entity,
 				IPAddress: c.ClientIP(),
 				UserAgent: c.Request.UserAgent(),
-			}
-			// Fire and forget — jangan blok response
-			go config.DB.Create(&log)
+				CreatedAt: time.Now(),
+			})
 		}
 	}
 }
\ No newline at end of file
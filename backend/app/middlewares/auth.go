@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -27,6 +28,10 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		claims, err := utils.ValidateToken(parts[1])
+		// Token parsial 2FA tidak boleh dipakai sebagai access token
+		if err == nil && claims.Tahap != "" {
+			err = errors.New("token parsial")
+		}
 		if err != nil {
 			utils.ResponseUnauthorized(c, "Token tidak valid atau sudah kadaluarsa")
 			c.Abort()
@@ -39,6 +44,49 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// jalurGantiPassword adalah endpoint yang tetap boleh diakses oleh akun
+// yang wajib mengganti password terlebih dahulu
+var jalurGantiPassword = map[string]bool{
+	"/api/v1/auth/me":              true,
+	"/api/v1/auth/change-password": true,
+	"/api/v1/profile":              true,
+}
+
+// jalurSetup2FA adalah endpoint yang tetap boleh diakses oleh akun
+// yang role-nya mewajibkan 2FA tetapi belum mengaktifkannya
+var jalurSetup2FA = map[string]bool{
+	"/api/v1/auth/me":              true,
+	"/api/v1/auth/change-password": true,
+	"/api/v1/auth/2fa/status":      true,
+	"/api/v1/auth/2fa/setup":       true,
+	"/api/v1/auth/2fa/aktifkan":    true,
+}
+
+// AccountGuardMiddleware memblokir akses akun yang wajib ganti password
+// atau wajib mengaktifkan 2FA ke semua endpoint selain yang diperlukan
+// untuk menyelesaikannya
+func AccountGuardMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := GetCurrentUser(c)
+		if claims == nil {
+			c.Next()
+			return
+		}
+
+		if claims.WajibGantiPassword && !jalurGantiPassword[c.FullPath()] {
+			utils.ResponseForbidden(c, "Anda wajib mengganti password sebelum melanjutkan")
+			c.Abort()
+			return
+		}
+		if claims.Wajib2FA && !jalurSetup2FA[c.FullPath()] {
+			utils.ResponseForbidden(c, "Role Anda mewajibkan autentikasi dua faktor. Aktifkan 2FA terlebih dahulu")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 // RoleMiddleware membatasi akses berdasarkan role
 func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sim-sekolah/app/models"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+const PerangkatGerbangKey = "perangkatGerbang"
+
+// PerangkatGerbangMiddleware mengautentikasi pembaca kartu lewat header
+// X-API-Key dan mencatat waktu terakhir perangkat aktif
+func PerangkatGerbangMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("X-API-Key")
+		if key == "" {
+			utils.ResponseUnauthorized(c, "API key perangkat tidak ditemukan")
+			c.Abort()
+			return
+		}
+		perangkat, err := services.PerangkatDariAPIKey(key)
+		if err != nil {
+			utils.ResponseUnauthorized(c, "API key perangkat tidak valid atau perangkat nonaktif")
+			c.Abort()
+			return
+		}
+
+		now := time.Now()
+		config.DB.Model(&models.PerangkatGerbang{}).Where("id = ?", perangkat.ID).
+			UpdateColumn("terakhir_aktif", now)
+		perangkat.TerakhirAktif = &now
+
+		c.Set(PerangkatGerbangKey, perangkat)
+		c.Next()
+	}
+}
+
+// GetPerangkatGerbang mengambil perangkat yang sudah diautentikasi middleware
+func GetPerangkatGerbang(c *gin.Context) models.PerangkatGerbang {
+	perangkat, _ := c.Get(PerangkatGerbangKey)
+	p, _ := perangkat.(models.PerangkatGerbang)
+	return p
+}
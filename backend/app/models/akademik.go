@@ -8,21 +8,25 @@ import (
 // ── Akademik Dasar ─────────────────────────────────────────────
 
 type TahunAjaran struct {
-	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	Nama      string    `gorm:"type:varchar(20);not null" json:"nama"` // contoh: "2024/2025"
-	IsAktif   bool      `gorm:"default:false" json:"is_aktif"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Nama            string    `gorm:"type:varchar(20);not null" json:"nama"` // contoh: "2024/2025"
+	IsAktif         bool      `gorm:"default:false" json:"is_aktif"`
+	TemplateRaporID *uint     `json:"template_rapor_id"` // kosong = template default
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
 }
 
 type Semester struct {
-	ID           uint        `gorm:"primaryKey;autoIncrement" json:"id"`
-	TahunAjaranID uint       `gorm:"not null;index" json:"tahun_ajaran_id"`
-	Nama         string      `gorm:"type:varchar(20);not null" json:"nama"` // "Ganjil" / "Genap"
-	IsAktif      bool        `gorm:"default:false" json:"is_aktif"`
-	CreatedAt    time.Time   `json:"created_at"`
-	UpdatedAt    time.Time   `json:"updated_at"`
-	TahunAjaran  TahunAjaran `gorm:"foreignKey:TahunAjaranID" json:"tahun_ajaran,omitempty"`
+	ID              uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	TahunAjaranID   uint        `gorm:"not null;index" json:"tahun_ajaran_id"`
+	Nama            string      `gorm:"type:varchar(20);not null" json:"nama"` // "Ganjil" / "Genap"
+	IsAktif         bool        `gorm:"default:false" json:"is_aktif"`
+	TanggalMulai    *time.Time  `json:"tanggal_mulai,omitempty"`
+	TanggalSelesai  *time.Time  `json:"tanggal_selesai,omitempty"`
+	BatasInputNilai *time.Time  `json:"batas_input_nilai,omitempty"` // tenggat guru mengisi nilai sebelum rapor
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+	TahunAjaran     TahunAjaran `gorm:"foreignKey:TahunAjaranID" json:"tahun_ajaran,omitempty"`
 }
 
 type Jurusan struct {
@@ -73,6 +77,8 @@ type Siswa struct {
 	TanggalLahir *time.Time     `json:"tanggal_lahir,omitempty"`
 	Alamat       string         `gorm:"type:text" json:"alamat"`
 	KelasID      *uint          `gorm:"index" json:"kelas_id"`
+	Status       string         `gorm:"type:varchar(10);default:'aktif';index" json:"status"` // aktif / alumni
+	TanggalLulus *time.Time     `json:"tanggal_lulus,omitempty"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
@@ -166,14 +172,19 @@ type Nilai struct {
 	Semester        Semester      `gorm:"foreignKey:SemesterID" json:"semester,omitempty"`
 }
 
+// Rapor adalah rapor logis seorang siswa di satu semester. Isi PDF-nya
+// tersimpan per versi di RaporVersi; FilePath menunjuk versi yang berlaku
+// (versi terbit, atau draft terakhir jika belum pernah terbit).
 type Rapor struct {
-	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	SiswaID    uint      `gorm:"not null;index" json:"siswa_id"`
-	SemesterID uint      `gorm:"not null;index" json:"semester_id"`
-	FilePath   string    `gorm:"type:varchar(255)" json:"file_path"`
-	Status     string    `gorm:"type:varchar(20);default:'draft'" json:"status"` // draft/published
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
-	Siswa      Siswa     `gorm:"foreignKey:SiswaID" json:"siswa,omitempty"`
-	Semester   Semester  `gorm:"foreignKey:SemesterID" json:"semester,omitempty"`
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	SiswaID       uint      `gorm:"not null;uniqueIndex:idx_rapor_siswa_semester" json:"siswa_id"`
+	SemesterID    uint      `gorm:"not null;uniqueIndex:idx_rapor_siswa_semester;index" json:"semester_id"`
+	FilePath      string    `gorm:"type:varchar(255)" json:"file_path"`
+	Status        string    `gorm:"type:varchar(20);default:'draft'" json:"status"` // draft/published
+	VersiTerbit   int       `gorm:"not null;default:0" json:"versi_terbit"`         // 0 = belum pernah terbit
+	VersiTerakhir int       `gorm:"not null;default:0" json:"versi_terakhir"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Siswa         Siswa     `gorm:"foreignKey:SiswaID" json:"siswa,omitempty"`
+	Semester      Semester  `gorm:"foreignKey:SemesterID" json:"semester,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Tipe kunci pelacakan percobaan login
+const (
+	ThrottleEmail = "email"
+	ThrottleIP    = "ip"
+)
+
+// LoginThrottle mencatat percobaan login gagal per akun (email) atau per IP.
+// Satu baris per kombinasi tipe + kunci.
+type LoginThrottle struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Tipe           string     `gorm:"type:varchar(10);not null;uniqueIndex:idx_throttle_tipe_kunci" json:"tipe"` // email / ip
+	Kunci          string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_throttle_tipe_kunci" json:"kunci"`
+	JumlahGagal    int        `gorm:"default:0" json:"jumlah_gagal"`
+	TerakhirGagal  *time.Time `json:"terakhir_gagal,omitempty"`
+	TerkunciSampai *time.Time `gorm:"index" json:"terkunci_sampai,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// IsTerkunci mengecek apakah kunci ini sedang dalam masa lockout
+func (t *LoginThrottle) IsTerkunci(now time.Time) bool {
+	return t.TerkunciSampai != nil && t.TerkunciSampai.After(now)
+}
+
+// PasswordResetToken menyimpan token reset password sekali pakai.
+// Token asli hanya dikirim lewat email; yang disimpan adalah hash SHA-256-nya.
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	IPAddress string     `gorm:"type:varchar(45)" json:"ip_address"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Relasi
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// RecoveryCode adalah kode cadangan sekali pakai untuk login jika
+// perangkat authenticator hilang. Hanya hash-nya yang disimpan.
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"type:varchar(64);not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Relasi
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
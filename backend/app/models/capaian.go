@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+)
+
+// Status capaian tujuan pembelajaran seorang siswa
+const (
+	CapaianTercapai       = "tercapai"
+	CapaianPerluBimbingan = "perlu_bimbingan"
+)
+
+// Predikat capaian dimensi Projek Penguatan Profil Pelajar Pancasila (P5)
+const (
+	P5MulaiBerkembang         = "MB"
+	P5SedangBerkembang        = "SB"
+	P5BerkembangSesuaiHarapan = "BSH"
+	P5SangatBerkembang        = "SAB"
+)
+
+// DimensiP5 adalah enam dimensi Profil Pelajar Pancasila
+var DimensiP5 = []string{
+	"Beriman, Bertakwa kepada Tuhan YME, dan Berakhlak Mulia",
+	"Berkebinekaan Global",
+	"Bergotong Royong",
+	"Mandiri",
+	"Bernalar Kritis",
+	"Kreatif",
+}
+
+// ── Tujuan Pembelajaran ────────────────────────────────────────
+
+// TujuanPembelajaran adalah TP sebuah mapel pada satu tingkat. Deskripsi ditulis
+// sebagai frasa kerja (misalnya "menganalisis struktur teks eksplanasi") karena
+// dirangkai langsung menjadi teks capaian kompetensi di rapor.
+type TujuanPembelajaran struct {
+	ID              uint          `gorm:"primaryKey;autoIncrement" json:"id"`
+	MataPelajaranID uint          `gorm:"not null;index" json:"mata_pelajaran_id"`
+	Tingkat         string        `gorm:"type:varchar(5);not null;index" json:"tingkat"`
+	Kode            string        `gorm:"type:varchar(20)" json:"kode"`
+	Deskripsi       string        `gorm:"type:text;not null" json:"deskripsi"`
+	Urutan          int           `gorm:"default:0" json:"urutan"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+	MataPelajaran   MataPelajaran `gorm:"foreignKey:MataPelajaranID" json:"mata_pelajaran,omitempty"`
+}
+
+// CapaianTujuanPembelajaran mencatat TP yang dipilih guru sebagai tercapai atau
+// perlu bimbingan untuk seorang siswa pada satu semester
+type CapaianTujuanPembelajaran struct {
+	ID                   uint               `gorm:"primaryKey;autoIncrement" json:"id"`
+	SiswaID              uint               `gorm:"not null;uniqueIndex:idx_capaian_siswa_semester_tp" json:"siswa_id"`
+	SemesterID           uint               `gorm:"not null;uniqueIndex:idx_capaian_siswa_semester_tp" json:"semester_id"`
+	TujuanPembelajaranID uint               `gorm:"not null;uniqueIndex:idx_capaian_siswa_semester_tp" json:"tujuan_pembelajaran_id"`
+	Status               string             `gorm:"type:varchar(20);not null" json:"status"` // tercapai / perlu_bimbingan
+	GuruID               *uint              `json:"guru_id"`
+	CreatedAt            time.Time          `json:"created_at"`
+	UpdatedAt            time.Time          `json:"updated_at"`
+	TujuanPembelajaran   TujuanPembelajaran `gorm:"foreignKey:TujuanPembelajaranID" json:"tujuan_pembelajaran,omitempty"`
+}
+
+// ── Projek P5 ──────────────────────────────────────────────────
+
+type ProjekP5 struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	SemesterID uint      `gorm:"not null;index" json:"semester_id"`
+	KelasID    uint      `gorm:"not null;index" json:"kelas_id"`
+	Tema       string    `gorm:"type:varchar(100);not null" json:"tema"`
+	Judul      string    `gorm:"type:varchar(150);not null" json:"judul"`
+	Deskripsi  string    `gorm:"type:text" json:"deskripsi"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Semester   Semester  `gorm:"foreignKey:SemesterID" json:"semester,omitempty"`
+	Kelas      Kelas     `gorm:"foreignKey:KelasID" json:"kelas,omitempty"`
+}
+
+// NilaiP5 adalah predikat capaian seorang siswa pada satu dimensi projek
+type NilaiP5 struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProjekP5ID uint      `gorm:"not null;uniqueIndex:idx_nilai_p5_projek_siswa_dimensi" json:"projek_p5_id"`
+	SiswaID    uint      `gorm:"not null;uniqueIndex:idx_nilai_p5_projek_siswa_dimensi;index" json:"siswa_id"`
+	Dimensi    string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_nilai_p5_projek_siswa_dimensi" json:"dimensi"`
+	Predikat   string    `gorm:"type:varchar(3);not null" json:"predikat"` // MB / SB / BSH / SAB
+	Catatan    string    `gorm:"type:text" json:"catatan"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	ProjekP5   ProjekP5  `gorm:"foreignKey:ProjekP5ID" json:"projek,omitempty"`
+}
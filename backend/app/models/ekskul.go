@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+)
+
+// Predikat nilai ekstrakurikuler di rapor
+var PredikatEkskul = []string{"Sangat Baik", "Baik", "Cukup", "Kurang"}
+
+// Ekstrakurikuler adalah kegiatan/klub sekolah beserta guru pembinanya
+type Ekstrakurikuler struct {
+	ID            uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Nama          string         `gorm:"type:varchar(100);uniqueIndex;not null" json:"nama"`
+	Deskripsi     string         `gorm:"type:text" json:"deskripsi"`
+	PembinaGuruID uint           `gorm:"not null;index" json:"pembina_guru_id"`
+	Tempat        string         `gorm:"type:varchar(100)" json:"tempat"`
+	IsWajib       bool           `gorm:"default:false" json:"is_wajib"` // misal Pramuka pada Kurikulum Merdeka
+	IsAktif       bool           `gorm:"default:true" json:"is_aktif"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	Pembina       Guru           `gorm:"foreignKey:PembinaGuruID" json:"pembina,omitempty"`
+	Jadwal        []JadwalEkskul `gorm:"foreignKey:EkskulID" json:"jadwal,omitempty"`
+}
+
+// JadwalEkskul adalah slot latihan rutin mingguan sebuah ekstrakurikuler
+type JadwalEkskul struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	EkskulID   uint      `gorm:"not null;index" json:"ekskul_id"`
+	HariKe     int       `gorm:"not null" json:"hari_ke"` // 1=Senin … 7=Minggu
+	JamMulai   string    `gorm:"type:varchar(5);not null" json:"jam_mulai"`
+	JamSelesai string    `gorm:"type:varchar(5);not null" json:"jam_selesai"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// AnggotaEkskul mencatat keikutsertaan siswa pada satu semester sekaligus
+// predikat dan keterangan yang diisi pembina untuk rapor
+type AnggotaEkskul struct {
+	ID          uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	EkskulID    uint            `gorm:"not null;uniqueIndex:idx_anggota_ekskul_siswa_semester" json:"ekskul_id"`
+	SiswaID     uint            `gorm:"not null;uniqueIndex:idx_anggota_ekskul_siswa_semester;index" json:"siswa_id"`
+	SemesterID  uint            `gorm:"not null;uniqueIndex:idx_anggota_ekskul_siswa_semester;index" json:"semester_id"`
+	Predikat    string          `gorm:"type:varchar(15)" json:"predikat"` // kosong = belum dinilai
+	Keterangan  string          `gorm:"type:text" json:"keterangan"`
+	DinilaiPada *time.Time      `json:"dinilai_pada"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Ekskul      Ekstrakurikuler `gorm:"foreignKey:EkskulID" json:"ekskul,omitempty"`
+	Siswa       Siswa           `gorm:"foreignKey:SiswaID" json:"siswa,omitempty"`
+}
+
+// PertemuanEkskul adalah satu kali kegiatan ekstrakurikuler yang dipresensi
+type PertemuanEkskul struct {
+	ID         uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	EkskulID   uint            `gorm:"not null;uniqueIndex:idx_pertemuan_ekskul_tanggal" json:"ekskul_id"`
+	SemesterID uint            `gorm:"not null;index" json:"semester_id"`
+	Tanggal    time.Time       `gorm:"type:date;not null;uniqueIndex:idx_pertemuan_ekskul_tanggal" json:"tanggal"`
+	Materi     string          `gorm:"type:varchar(255)" json:"materi"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	Absensi    []AbsensiEkskul `gorm:"foreignKey:PertemuanID" json:"absensi,omitempty"`
+}
+
+// AbsensiEkskul adalah kehadiran seorang anggota pada satu pertemuan
+type AbsensiEkskul struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	PertemuanID uint      `gorm:"not null;uniqueIndex:idx_absensi_ekskul_pertemuan_siswa" json:"pertemuan_id"`
+	SiswaID     uint      `gorm:"not null;uniqueIndex:idx_absensi_ekskul_pertemuan_siswa;index" json:"siswa_id"`
+	Status      string    `gorm:"type:varchar(10);not null" json:"status"` // hadir/izin/sakit/alfa
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Siswa       Siswa     `gorm:"foreignKey:SiswaID" json:"siswa,omitempty"`
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+)
+
+// Pemilik kartu / kehadiran gerbang
+const (
+	PemilikSiswa = "siswa"
+	PemilikGuru  = "guru"
+)
+
+// Arah dan status tap kartu di gerbang
+const (
+	TapDatang            = "datang"
+	TapPulang            = "pulang"
+	TapBerulang          = "berulang" // tap ulang dalam jeda singkat, tidak mengubah jam
+	TapDiterima          = "diterima"
+	TapDuplikat          = "duplikat" // event yang sama dikirim ulang oleh perangkat
+	TapKartuTidakDikenal = "kartu_tidak_dikenal"
+)
+
+// PerangkatGerbang adalah pembaca kartu yang mengirim tap lewat API perangkat.
+// API key hanya ditampilkan sekali saat dibuat; yang disimpan hanya hash-nya.
+type PerangkatGerbang struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Nama          string     `gorm:"type:varchar(100);not null" json:"nama"`
+	Lokasi        string     `gorm:"type:varchar(100)" json:"lokasi"`
+	KeyPrefix     string     `gorm:"type:varchar(16);uniqueIndex;not null" json:"key_prefix"` // bagian depan API key untuk pencarian
+	KeyHash       string     `gorm:"type:varchar(64);not null" json:"-"`
+	IsAktif       bool       `gorm:"default:true" json:"is_aktif"`
+	TerakhirAktif *time.Time `json:"terakhir_aktif"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// KartuAkses memetakan UID kartu ke siswa atau guru
+type KartuAkses struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UID        string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"uid"` // huruf besar tanpa pemisah
+	SiswaID    *uint     `gorm:"index" json:"siswa_id"`
+	GuruID     *uint     `gorm:"index" json:"guru_id"`
+	IsAktif    bool      `gorm:"default:true" json:"is_aktif"` // nonaktifkan kartu hilang
+	Keterangan string    `gorm:"type:varchar(255)" json:"keterangan"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Siswa      *Siswa    `gorm:"foreignKey:SiswaID" json:"siswa,omitempty"`
+	Guru       *Guru     `gorm:"foreignKey:GuruID" json:"guru,omitempty"`
+}
+
+// TapGerbang adalah log mentah setiap tap yang diterima dari perangkat.
+// EventID unik per perangkat sehingga upload ulang batch offline tidak
+// tercatat dua kali.
+type TapGerbang struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	PerangkatID uint      `gorm:"not null;uniqueIndex:idx_tap_perangkat_event" json:"perangkat_id"`
+	EventID     string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_tap_perangkat_event" json:"event_id"`
+	UID         string    `gorm:"type:varchar(50);not null;index" json:"uid"`
+	Waktu       time.Time `gorm:"not null;index" json:"waktu"` // waktu tap menurut perangkat
+	KartuID     *uint     `json:"kartu_id"`
+	Pemilik     string    `gorm:"type:varchar(10)" json:"pemilik"` // siswa / guru, kosong jika kartu tidak dikenal
+	PemilikID   *uint     `json:"pemilik_id"`
+	Arah        string    `gorm:"type:varchar(10)" json:"arah"`
+	Status      string    `gorm:"type:varchar(20);not null;index" json:"status"`
+	Offline     bool      `gorm:"default:false" json:"offline"` // dikirim lewat batch upload
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// KehadiranGerbang merangkum jam datang dan pulang seseorang dalam satu hari
+type KehadiranGerbang struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Tanggal        time.Time  `gorm:"type:date;not null;uniqueIndex:idx_kehadiran_gerbang" json:"tanggal"`
+	Pemilik        string     `gorm:"type:varchar(10);not null;uniqueIndex:idx_kehadiran_gerbang" json:"pemilik"`
+	PemilikID      uint       `gorm:"not null;uniqueIndex:idx_kehadiran_gerbang" json:"pemilik_id"`
+	JamDatang      time.Time  `gorm:"not null" json:"jam_datang"`
+	JamPulang      *time.Time `json:"jam_pulang"`
+	Terlambat      bool       `gorm:"default:false;index" json:"terlambat"`
+	MenitTerlambat int        `json:"menit_terlambat"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// JadwalBel menentukan jam masuk dan pulang sekolah per hari. Hari tanpa
+// jadwal bel tidak menandai keterlambatan.
+type JadwalBel struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	HariKe         int       `gorm:"uniqueIndex;not null" json:"hari_ke"`        // 1=Senin … 7=Minggu
+	JamMasuk       string    `gorm:"type:varchar(5);not null" json:"jam_masuk"`  // "07:00"
+	JamPulang      string    `gorm:"type:varchar(5);not null" json:"jam_pulang"` // "14:00"
+	ToleransiMenit int       `gorm:"default:0" json:"toleransi_menit"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+// Status job di antrian latar belakang
+const (
+	JobAntri   = "antri"
+	JobProses  = "proses"
+	JobSelesai = "selesai"
+	JobGagal   = "gagal" // percobaan habis
+	JobBatal   = "batal"
+)
+
+// Job adalah satu pekerjaan di antrian latar belakang. Worker mengambil job
+// dengan SELECT ... FOR UPDATE SKIP LOCKED sehingga aman dijalankan oleh
+// beberapa instance server sekaligus.
+type Job struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Tipe          string     `gorm:"type:varchar(50);not null;index" json:"tipe"`
+	Payload       string     `gorm:"type:jsonb;not null;default:'{}'" json:"payload"`
+	Status        string     `gorm:"type:varchar(10);not null;default:'antri';index:idx_job_status_jalankan" json:"status"`
+	JalankanPada  time.Time  `gorm:"not null;index:idx_job_status_jalankan" json:"jalankan_pada"`
+	Percobaan     int        `gorm:"not null;default:0" json:"percobaan"`
+	MaksPercobaan int        `gorm:"not null;default:5" json:"maks_percobaan"`
+	ErrorTerakhir string     `gorm:"type:text" json:"error_terakhir,omitempty"`
+	DikunciOleh   string     `gorm:"type:varchar(100)" json:"dikunci_oleh,omitempty"` // ID worker
+	DikunciPada   *time.Time `json:"dikunci_pada,omitempty"`
+	SelesaiPada   *time.Time `json:"selesai_pada,omitempty"`
+	JadwalID      *uint      `gorm:"index" json:"jadwal_id,omitempty"` // berasal dari job terjadwal
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// JobTerjadwal membuat Job baru secara berkala sesuai ekspresi cron
+// (format 5 kolom: menit jam tanggal bulan hari). Didaftarkan dari kode saat
+// server start; admin dapat menonaktifkannya.
+type JobTerjadwal struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Nama           string     `gorm:"type:varchar(100);uniqueIndex;not null" json:"nama"`
+	Tipe           string     `gorm:"type:varchar(50);not null" json:"tipe"`
+	Payload        string     `gorm:"type:jsonb;not null;default:'{}'" json:"payload"`
+	Cron           string     `gorm:"type:varchar(100);not null" json:"cron"`
+	Aktif          bool       `gorm:"not null" json:"aktif"`
+	BerikutnyaPada time.Time  `gorm:"not null;index" json:"berikutnya_pada"`
+	TerakhirDibuat *time.Time `json:"terakhir_dibuat,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
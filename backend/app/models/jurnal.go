@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+// Status review jurnal mengajar
+const (
+	JurnalDiajukan  = "diajukan"
+	JurnalDisetujui = "disetujui"
+	JurnalRevisi    = "revisi" // dikembalikan ke guru untuk diperbaiki
+)
+
+// JurnalMengajar mencatat materi yang diajarkan pada satu pertemuan jadwal.
+// Satu jadwal hanya punya satu jurnal per tanggal; absensi pertemuan yang sama
+// dihubungkan lewat jadwal_id + tanggal.
+type JurnalMengajar struct {
+	ID                   uint             `gorm:"primaryKey;autoIncrement" json:"id"`
+	JadwalID             uint             `gorm:"not null;uniqueIndex:idx_jurnal_jadwal_tanggal" json:"jadwal_id"`
+	Tanggal              time.Time        `gorm:"type:date;not null;uniqueIndex:idx_jurnal_jadwal_tanggal;index" json:"tanggal"`
+	GuruID               uint             `gorm:"not null;index" json:"guru_id"` // guru yang mengisi jurnal
+	Topik                string           `gorm:"type:varchar(255);not null" json:"topik"`
+	KegiatanPembelajaran string           `gorm:"type:text" json:"kegiatan_pembelajaran"`
+	Catatan              string           `gorm:"type:text" json:"catatan"`
+	Status               string           `gorm:"type:varchar(20);not null;default:'diajukan';index" json:"status"`
+	CatatanReview        string           `gorm:"type:text" json:"catatan_review"`
+	DireviewOleh         *uint            `json:"direview_oleh"`
+	DireviewPada         *time.Time       `json:"direview_pada"`
+	CreatedAt            time.Time        `json:"created_at"`
+	UpdatedAt            time.Time        `json:"updated_at"`
+	Jadwal               Jadwal           `gorm:"foreignKey:JadwalID" json:"jadwal,omitempty"`
+	Guru                 Guru             `gorm:"foreignKey:GuruID" json:"guru,omitempty"`
+	Lampiran             []LampiranJurnal `gorm:"foreignKey:JurnalID" json:"lampiran,omitempty"`
+}
+
+// LampiranJurnal adalah file pendukung jurnal (materi, foto kegiatan, dll)
+type LampiranJurnal struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	JurnalID  uint      `gorm:"not null;index" json:"jurnal_id"`
+	NamaFile  string    `gorm:"type:varchar(255);not null" json:"nama_file"` // nama asli saat diupload
+	FilePath  string    `gorm:"type:varchar(255);not null" json:"file_path"`
+	Ukuran    int64     `json:"ukuran"`
+	CreatedAt time.Time `json:"created_at"`
+}
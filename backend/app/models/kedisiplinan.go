@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+)
+
+// Kategori jenis pelanggaran
+const (
+	PelanggaranRingan = "ringan"
+	PelanggaranSedang = "sedang"
+	PelanggaranBerat  = "berat"
+)
+
+// Status sanksi siswa
+const (
+	SanksiAktif   = "aktif"
+	SanksiSelesai = "selesai"
+	SanksiBatal   = "batal"
+)
+
+// JenisPelanggaran adalah katalog tata tertib beserta poinnya
+type JenisPelanggaran struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Kode      string    `gorm:"type:varchar(20);uniqueIndex;not null" json:"kode"`
+	Nama      string    `gorm:"type:varchar(150);not null" json:"nama"`
+	Kategori  string    `gorm:"type:varchar(10);not null;default:'ringan'" json:"kategori"` // ringan / sedang / berat
+	Poin      int       `gorm:"not null" json:"poin"`
+	Deskripsi string    `gorm:"type:text" json:"deskripsi"`
+	IsAktif   bool      `gorm:"default:true" json:"is_aktif"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PelanggaranSiswa mencatat satu kejadian pelanggaran. Poin disalin dari
+// katalog saat dicatat agar perubahan katalog tidak mengubah riwayat.
+type PelanggaranSiswa struct {
+	ID                 uint             `gorm:"primaryKey;autoIncrement" json:"id"`
+	SiswaID            uint             `gorm:"not null;index" json:"siswa_id"`
+	JenisPelanggaranID uint             `gorm:"not null;index" json:"jenis_pelanggaran_id"`
+	SemesterID         uint             `gorm:"not null;index" json:"semester_id"`
+	Tanggal            time.Time        `gorm:"type:date;not null;index" json:"tanggal"`
+	Poin               int              `gorm:"not null" json:"poin"`
+	Kronologi          string           `gorm:"type:text" json:"kronologi"`
+	BuktiPath          string           `gorm:"type:varchar(255)" json:"bukti_path"`
+	PelaporGuruID      uint             `gorm:"not null;index" json:"pelapor_guru_id"`
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+	Siswa              Siswa            `gorm:"foreignKey:SiswaID" json:"siswa,omitempty"`
+	JenisPelanggaran   JenisPelanggaran `gorm:"foreignKey:JenisPelanggaranID" json:"jenis_pelanggaran,omitempty"`
+	Pelapor            Guru             `gorm:"foreignKey:PelaporGuruID" json:"pelapor,omitempty"`
+}
+
+// AmbangPoin adalah batas akumulasi poin dalam satu tahun ajaran yang memicu sanksi
+type AmbangPoin struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Poin          int       `gorm:"uniqueIndex;not null" json:"poin"`
+	Nama          string    `gorm:"type:varchar(100);not null" json:"nama"` // misal "Surat Peringatan 1"
+	Sanksi        string    `gorm:"type:text" json:"sanksi"`
+	KirimOrangTua bool      `gorm:"default:true" json:"kirim_orang_tua"` // notifikasi ke orang tua saat tercapai
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// SanksiSiswa tercatat otomatis saat akumulasi poin siswa mencapai ambang.
+// Setiap ambang hanya tercapai sekali per tahun ajaran.
+type SanksiSiswa struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	SiswaID       uint       `gorm:"not null;uniqueIndex:idx_sanksi_siswa_ambang" json:"siswa_id"`
+	AmbangPoinID  uint       `gorm:"not null;uniqueIndex:idx_sanksi_siswa_ambang" json:"ambang_poin_id"`
+	TahunAjaranID uint       `gorm:"not null;uniqueIndex:idx_sanksi_siswa_ambang" json:"tahun_ajaran_id"`
+	TotalPoin     int        `gorm:"not null" json:"total_poin"` // akumulasi saat ambang tercapai
+	Status        string     `gorm:"type:varchar(10);not null;default:'aktif';index" json:"status"`
+	Catatan       string     `gorm:"type:text" json:"catatan"`
+	SelesaiPada   *time.Time `json:"selesai_pada"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Siswa         Siswa      `gorm:"foreignKey:SiswaID" json:"siswa,omitempty"`
+	AmbangPoin    AmbangPoin `gorm:"foreignKey:AmbangPoinID" json:"ambang_poin,omitempty"`
+}
+
+// CatatanKonseling adalah catatan sesi bimbingan konseling. Bersifat rahasia:
+// hanya guru BK dan wali kelas siswa yang dapat membacanya.
+type CatatanKonseling struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	SiswaID        uint      `gorm:"not null;index" json:"siswa_id"`
+	KonselorGuruID uint      `gorm:"not null;index" json:"konselor_guru_id"`
+	Tanggal        time.Time `gorm:"type:date;not null" json:"tanggal"`
+	Bidang         string    `gorm:"type:varchar(20)" json:"bidang"` // pribadi / sosial / belajar / karir
+	Permasalahan   string    `gorm:"type:text;not null" json:"permasalahan"`
+	Penanganan     string    `gorm:"type:text" json:"penanganan"`
+	TindakLanjut   string    `gorm:"type:text" json:"tindak_lanjut"`
+	PelanggaranID  *uint     `gorm:"index" json:"pelanggaran_id"` // jika konseling berawal dari pelanggaran
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	Siswa          Siswa     `gorm:"foreignKey:SiswaID" json:"siswa,omitempty"`
+	Konselor       Guru      `gorm:"foreignKey:KonselorGuruID" json:"konselor,omitempty"`
+}
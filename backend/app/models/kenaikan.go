@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+)
+
+// Status siswa
+const (
+	StatusSiswaAktif  = "aktif"
+	StatusSiswaAlumni = "alumni"
+)
+
+// Status proses kenaikan kelas
+const (
+	ProsesKenaikanDraft      = "draft"
+	ProsesKenaikanDiterapkan = "diterapkan"
+)
+
+// Keputusan kenaikan per siswa
+const (
+	KeputusanNaik    = "naik"
+	KeputusanTinggal = "tinggal"
+	KeputusanLulus   = "lulus"
+)
+
+// ── Kenaikan Kelas & Kelulusan ─────────────────────────────────
+
+// ProsesKenaikan adalah satu batch kenaikan kelas akhir tahun dari
+// tahun ajaran asal ke tahun ajaran tujuan
+type ProsesKenaikan struct {
+	ID                  uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	TahunAjaranAsalID   uint        `gorm:"not null;index" json:"tahun_ajaran_asal_id"`
+	TahunAjaranTujuanID uint        `gorm:"not null;index" json:"tahun_ajaran_tujuan_id"`
+	Status              string      `gorm:"type:varchar(20);default:'draft'" json:"status"` // draft / diterapkan
+	DiterapkanPada      *time.Time  `json:"diterapkan_pada,omitempty"`
+	DiterapkanOleh      *uint       `json:"diterapkan_oleh,omitempty"`
+	CreatedAt           time.Time   `json:"created_at"`
+	UpdatedAt           time.Time   `json:"updated_at"`
+	TahunAjaranAsal     TahunAjaran `gorm:"foreignKey:TahunAjaranAsalID" json:"tahun_ajaran_asal,omitempty"`
+	TahunAjaranTujuan   TahunAjaran `gorm:"foreignKey:TahunAjaranTujuanID" json:"tahun_ajaran_tujuan,omitempty"`
+
+	Pemetaan []PemetaanKelasKenaikan `gorm:"foreignKey:ProsesKenaikanID" json:"pemetaan,omitempty"`
+}
+
+// PemetaanKelasKenaikan memetakan kelas asal ke kelas tujuan.
+// Kelas tingkat akhir (XII) ditandai Lulus dan tidak memiliki kelas tujuan.
+type PemetaanKelasKenaikan struct {
+	ID               uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProsesKenaikanID uint      `gorm:"not null;uniqueIndex:idx_pemetaan_proses_kelas" json:"proses_kenaikan_id"`
+	KelasAsalID      uint      `gorm:"not null;uniqueIndex:idx_pemetaan_proses_kelas" json:"kelas_asal_id"`
+	KelasTujuanID    *uint     `gorm:"index" json:"kelas_tujuan_id"`
+	Lulus            bool      `gorm:"default:false" json:"lulus"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	KelasAsal        Kelas     `gorm:"foreignKey:KelasAsalID" json:"kelas_asal,omitempty"`
+	KelasTujuan      *Kelas    `gorm:"foreignKey:KelasTujuanID" json:"kelas_tujuan,omitempty"`
+}
+
+// KeputusanKenaikan menyimpan pengecualian per siswa, misalnya tinggal kelas.
+// Siswa tanpa keputusan mengikuti pemetaan kelasnya.
+type KeputusanKenaikan struct {
+	ID               uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProsesKenaikanID uint      `gorm:"not null;uniqueIndex:idx_keputusan_proses_siswa" json:"proses_kenaikan_id"`
+	SiswaID          uint      `gorm:"not null;uniqueIndex:idx_keputusan_proses_siswa" json:"siswa_id"`
+	Keputusan        string    `gorm:"type:varchar(10);not null" json:"keputusan"` // naik / tinggal
+	KelasTujuanID    *uint     `json:"kelas_tujuan_id"`                            // opsional, untuk siswa tinggal kelas
+	Catatan          string    `gorm:"type:text" json:"catatan"`
+	DiputuskanOleh   uint      `gorm:"not null" json:"diputuskan_oleh"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	Siswa            Siswa     `gorm:"foreignKey:SiswaID" json:"siswa,omitempty"`
+}
+
+// ── Riwayat Kelas ──────────────────────────────────────────────
+
+// Sumber perubahan keanggotaan kelas
+const (
+	RiwayatPendaftaran  = "pendaftaran"
+	RiwayatPindahKelas  = "pindah_kelas"
+	RiwayatKenaikan     = "kenaikan"
+	RiwayatTinggalKelas = "tinggal_kelas"
+	RiwayatLulus        = "lulus"
+	RiwayatMigrasi      = "migrasi"
+)
+
+// RiwayatKelas mencatat keanggotaan siswa di sebuah kelas dalam rentang waktu.
+// TanggalSelesai nil berarti siswa masih berada di kelas tersebut.
+type RiwayatKelas struct {
+	ID             uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	SiswaID        uint        `gorm:"not null;index" json:"siswa_id"`
+	KelasID        uint        `gorm:"not null;index" json:"kelas_id"`
+	TahunAjaranID  uint        `gorm:"not null;index" json:"tahun_ajaran_id"`
+	TanggalMulai   time.Time   `gorm:"not null" json:"tanggal_mulai"`
+	TanggalSelesai *time.Time  `gorm:"index" json:"tanggal_selesai"`
+	Sumber         string      `gorm:"type:varchar(20);not null" json:"sumber"`
+	Alasan         string      `gorm:"type:text" json:"alasan"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+	Kelas          Kelas       `gorm:"foreignKey:KelasID" json:"kelas,omitempty"`
+	TahunAjaran    TahunAjaran `gorm:"foreignKey:TahunAjaranID" json:"tahun_ajaran,omitempty"`
+}
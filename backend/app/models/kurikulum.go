@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// Kelompok mata pelajaran dalam struktur kurikulum
+const (
+	KelompokUmum        = "umum"
+	KelompokKejuruan    = "kejuruan"
+	KelompokMuatanLokal = "muatan_lokal"
+)
+
+// ── Kurikulum ──────────────────────────────────────────────────
+
+// KurikulumMapel memetakan mata pelajaran ke jurusan dan tingkat beserta jam
+// pelajaran (JP) per minggu. JurusanID nil berarti berlaku untuk semua jurusan
+// pada tingkat tersebut (misalnya mapel umum).
+type KurikulumMapel struct {
+	ID              uint          `gorm:"primaryKey;autoIncrement" json:"id"`
+	JurusanID       *uint         `gorm:"index" json:"jurusan_id"`
+	Tingkat         string        `gorm:"type:varchar(5);not null;index" json:"tingkat"` // "X", "XI", "XII"
+	MataPelajaranID uint          `gorm:"not null;index" json:"mata_pelajaran_id"`
+	Kelompok        string        `gorm:"type:varchar(20);not null;default:'umum'" json:"kelompok"` // umum / kejuruan / muatan_lokal
+	JamPerMinggu    int           `gorm:"not null;default:0" json:"jam_per_minggu"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+	Jurusan         *Jurusan      `gorm:"foreignKey:JurusanID" json:"jurusan,omitempty"`
+	MataPelajaran   MataPelajaran `gorm:"foreignKey:MataPelajaranID" json:"mata_pelajaran,omitempty"`
+}
+
+// ── Guru Pengampu ──────────────────────────────────────────────
+
+// PengampuMapel menetapkan guru yang mengampu sebuah mapel di sebuah kelas pada
+// satu semester. Satu mapel di satu kelas hanya diampu oleh satu guru.
+type PengampuMapel struct {
+	ID              uint          `gorm:"primaryKey;autoIncrement" json:"id"`
+	SemesterID      uint          `gorm:"not null;uniqueIndex:idx_pengampu_semester_kelas_mapel" json:"semester_id"`
+	KelasID         uint          `gorm:"not null;uniqueIndex:idx_pengampu_semester_kelas_mapel" json:"kelas_id"`
+	MataPelajaranID uint          `gorm:"not null;uniqueIndex:idx_pengampu_semester_kelas_mapel" json:"mata_pelajaran_id"`
+	GuruID          uint          `gorm:"not null;index" json:"guru_id"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+	Semester        Semester      `gorm:"foreignKey:SemesterID" json:"semester,omitempty"`
+	Kelas           Kelas         `gorm:"foreignKey:KelasID" json:"kelas,omitempty"`
+	MataPelajaran   MataPelajaran `gorm:"foreignKey:MataPelajaranID" json:"mata_pelajaran,omitempty"`
+	Guru            Guru          `gorm:"foreignKey:GuruID" json:"guru,omitempty"`
+}
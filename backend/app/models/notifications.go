@@ -15,6 +15,7 @@ const (
 	NotifJadwal    NotificationType = "jadwal"
 	NotifRapor     NotificationType = "rapor"
 	NotifKehadiran NotificationType = "kehadiran"
+	NotifPerilaku  NotificationType = "perilaku"
 	NotifGeneral   NotificationType = "general"
 )
 
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// Status proses generate rapor massal
+const (
+	BatchAntri   = "antri"
+	BatchProses  = "proses"
+	BatchSelesai = "selesai"
+	BatchGagal   = "gagal"
+)
+
+// RaporBatch mencatat proses generate rapor seluruh siswa sebuah kelas.
+// Progres (Selesai/Gagal dari Total) diperbarui selama proses berjalan.
+type RaporBatch struct {
+	ID              uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	KelasID         uint       `gorm:"not null;index" json:"kelas_id"`
+	SemesterID      uint       `gorm:"not null;index" json:"semester_id"`
+	TemplateRaporID *uint      `json:"template_rapor_id"`
+	Format          string     `gorm:"type:varchar(10)" json:"format"`
+	Status          string     `gorm:"type:varchar(10);not null;default:'antri';index" json:"status"`
+	Total           int        `json:"total"`
+	Selesai         int        `json:"selesai"`
+	Gagal           int        `json:"gagal"`
+	Keterangan      string     `gorm:"type:text" json:"keterangan"` // daftar siswa yang gagal / pesan error
+	ZipPath         string     `gorm:"type:varchar(255)" json:"-"`
+	GabunganPath    string     `gorm:"type:varchar(255)" json:"-"`
+	DibuatOleh      uint       `gorm:"not null" json:"dibuat_oleh"`
+	MulaiAt         *time.Time `json:"mulai_at"`
+	SelesaiAt       *time.Time `json:"selesai_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	Kelas           Kelas      `gorm:"foreignKey:KelasID" json:"kelas,omitempty"`
+	Semester        Semester   `gorm:"foreignKey:SemesterID" json:"semester,omitempty"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+// Status rapor dan versi rapor.
+// draft → published; versi published menjadi revised ketika versi revisi
+// sesudahnya diterbitkan, atau revoked jika dicabut.
+const (
+	RaporDraft     = "draft"
+	RaporPublished = "published"
+	RaporRevised   = "revised"
+	RaporRevoked   = "revoked" // dicabut admin, tidak sah lagi
+)
+
+// RaporVersi adalah satu versi PDF rapor beserta snapshot data (nilai,
+// kehadiran, capaian) yang dipakai saat dibuat. Versi yang sudah terbit tidak
+// pernah diubah; perbaikan dilakukan dengan versi baru yang mencantumkan alasan.
+type RaporVersi struct {
+	ID              uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	RaporID         uint       `gorm:"not null;uniqueIndex:idx_rapor_versi_nomor" json:"rapor_id"`
+	Nomor           int        `gorm:"not null;uniqueIndex:idx_rapor_versi_nomor" json:"nomor"`
+	Status          string     `gorm:"type:varchar(20);not null;default:'draft'" json:"status"` // draft/published/revised/revoked
+	FilePath        string     `gorm:"type:varchar(255)" json:"file_path"`
+	Snapshot        string     `gorm:"type:jsonb;not null;default:'{}'" json:"-"`
+	TemplateRaporID *uint      `json:"template_rapor_id"`
+	Format          string     `gorm:"type:varchar(10)" json:"format"`
+	Alasan          string     `gorm:"type:text" json:"alasan"` // wajib untuk versi revisi
+	DibuatOleh      *uint      `json:"dibuat_oleh"`
+	DiterbitkanOleh *uint      `json:"diterbitkan_oleh"`
+	DiterbitkanPada *time.Time `json:"diterbitkan_pada"`
+
+	// Verifikasi keaslian: kode dicetak sebagai QR di PDF. HashKonten adalah
+	// SHA-256 snapshot (juga dicetak), HashFile adalah SHA-256 file PDF.
+	KodeVerifikasi *string    `gorm:"type:varchar(20);uniqueIndex" json:"kode_verifikasi"`
+	HashKonten     string     `gorm:"type:varchar(64)" json:"hash_konten"`
+	HashFile       string     `gorm:"type:varchar(64)" json:"hash_file"`
+	DicabutOleh    *uint      `json:"dicabut_oleh,omitempty"`
+	DicabutPada    *time.Time `json:"dicabut_pada,omitempty"`
+	AlasanCabut    string     `gorm:"type:text" json:"alasan_cabut,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -12,12 +12,14 @@ const (
 	RoleWaliKelas    = "wali_kelas"
 	RoleSiswa        = "siswa"
 	RoleOrangTua     = "orang_tua"
+	RoleGuruBK       = "guru_bk" // guru bimbingan konseling
 )
 
 type Role struct {
 	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
 	Nama        string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"nama"`
 	Deskripsi   string    `gorm:"type:varchar(255)" json:"deskripsi"`
+	Wajib2FA    bool      `gorm:"default:false" json:"wajib_2fa"` // user dengan role ini wajib mengaktifkan 2FA
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 
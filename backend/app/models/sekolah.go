@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+)
+
+// ProfilSekolahID adalah ID satu-satunya baris profil sekolah
+const ProfilSekolahID = 1
+
+// Ukuran kertas rapor yang didukung
+const (
+	KertasA4     = "A4"
+	KertasF4     = "F4" // 215 x 330 mm (folio)
+	KertasLetter = "Letter"
+)
+
+// ProfilSekolah menyimpan identitas sekolah yang dicetak di rapor dan dokumen
+// lain. Hanya ada satu baris (ID = ProfilSekolahID).
+type ProfilSekolah struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	Nama              string    `gorm:"type:varchar(150)" json:"nama"`
+	NPSN              string    `gorm:"type:varchar(20)" json:"npsn"`
+	Alamat            string    `gorm:"type:text" json:"alamat"`
+	Kota              string    `gorm:"type:varchar(100)" json:"kota"` // tempat penandatanganan rapor
+	Telepon           string    `gorm:"type:varchar(30)" json:"telepon"`
+	Email             string    `gorm:"type:varchar(100)" json:"email"`
+	Website           string    `gorm:"type:varchar(100)" json:"website"`
+	LogoPath          string    `gorm:"type:varchar(255)" json:"logo_path"`
+	KepalaSekolahNama string    `gorm:"type:varchar(100)" json:"kepala_sekolah_nama"`
+	KepalaSekolahNIP  string    `gorm:"type:varchar(30)" json:"kepala_sekolah_nip"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// TemplateRapor mengatur tata letak PDF rapor. Tahun ajaran dapat memilih
+// template sendiri; jika tidak, dipakai template dengan IsDefault = true.
+type TemplateRapor struct {
+	ID                 uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Nama               string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"nama"`
+	Format             string    `gorm:"type:varchar(10);not null;default:'merdeka'" json:"format"` // merdeka / klasik
+	UkuranKertas       string    `gorm:"type:varchar(10);not null;default:'A4'" json:"ukuran_kertas"`
+	Judul              string    `gorm:"type:varchar(100)" json:"judul"` // kosong = judul bawaan format
+	TampilkanKop       bool      `json:"tampilkan_kop"`
+	TampilkanLogo      bool      `json:"tampilkan_logo"`
+	TampilkanP5        bool      `json:"tampilkan_p5"`
+	TampilkanEkskul    bool      `json:"tampilkan_ekskul"`
+	TampilkanKehadiran bool      `json:"tampilkan_kehadiran"`
+	TampilkanPeringkat bool      `json:"tampilkan_peringkat"` // peringkat di kelas (dari leger)
+	TampilkanPerilaku  bool      `json:"tampilkan_perilaku"`  // ringkasan poin pelanggaran & predikat sikap
+	TtdOrangTua        bool      `json:"ttd_orang_tua"`
+	TtdKepalaSekolah   bool      `json:"ttd_kepala_sekolah"`
+	CatatanKaki        string    `gorm:"type:text" json:"catatan_kaki"`
+	IsDefault          bool      `gorm:"default:false" json:"is_default"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+)
+
+// Status sesi absensi QR
+const (
+	SesiAbsensiDibuka  = "dibuka"
+	SesiAbsensiDitutup = "ditutup"
+)
+
+// SesiAbsensi adalah mode absensi mandiri untuk satu pertemuan jadwal: selama
+// sesi dibuka, layar guru menampilkan QR yang berganti setiap IntervalDetik dan
+// siswa memindainya dari akun masing-masing.
+type SesiAbsensi struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	JadwalID       uint       `gorm:"not null;uniqueIndex:idx_sesi_absensi_jadwal_tanggal" json:"jadwal_id"`
+	Tanggal        time.Time  `gorm:"type:date;not null;uniqueIndex:idx_sesi_absensi_jadwal_tanggal" json:"tanggal"`
+	GuruID         uint       `gorm:"not null;index" json:"guru_id"`      // guru yang membuka sesi
+	Rahasia        string     `gorm:"type:varchar(64);not null" json:"-"` // kunci HMAC token QR
+	IntervalDetik  int        `gorm:"not null;default:30" json:"interval_detik"`
+	WajibPerangkat bool       `gorm:"default:false" json:"wajib_perangkat"` // check-in harus dari perangkat terdaftar siswa
+	Status         string     `gorm:"type:varchar(10);not null;default:'dibuka';index" json:"status"`
+	DibukaPada     time.Time  `json:"dibuka_pada"`
+	DitutupPada    *time.Time `json:"ditutup_pada"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	Jadwal         Jadwal     `gorm:"foreignKey:JadwalID" json:"jadwal,omitempty"`
+}
+
+// CheckinAbsensi mencatat setiap pemindaian QR yang diterima. Satu siswa hanya
+// sekali per sesi, dan satu perangkat tidak dapat dipakai check-in untuk dua
+// siswa dalam sesi yang sama.
+type CheckinAbsensi struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	SesiID      uint      `gorm:"not null;uniqueIndex:idx_checkin_sesi_siswa;uniqueIndex:idx_checkin_sesi_perangkat,where:perangkat_id <> ''" json:"sesi_id"`
+	SiswaID     uint      `gorm:"not null;uniqueIndex:idx_checkin_sesi_siswa" json:"siswa_id"`
+	AbsensiID   uint      `gorm:"not null" json:"absensi_id"`
+	Jendela     int64     `gorm:"not null" json:"jendela"` // nomor jendela waktu token yang dipindai
+	PerangkatID string    `gorm:"type:varchar(100);uniqueIndex:idx_checkin_sesi_perangkat,where:perangkat_id <> ''" json:"perangkat_id"`
+	IP          string    `gorm:"type:varchar(45)" json:"ip"`
+	CreatedAt   time.Time `json:"created_at"`
+	Siswa       Siswa     `gorm:"foreignKey:SiswaID" json:"siswa,omitempty"`
+}
+
+// PerangkatSiswa mengikat akun siswa ke satu perangkat. Perangkat didaftarkan
+// otomatis saat check-in pertama dan hanya dapat diganti oleh guru/admin.
+type PerangkatSiswa struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	SiswaID     uint      `gorm:"not null;uniqueIndex" json:"siswa_id"`
+	PerangkatID string    `gorm:"type:varchar(100);not null" json:"perangkat_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
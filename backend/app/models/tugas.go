@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+)
+
+// Komponen nilai yang dapat diisi dari rata-rata nilai tugas
+const (
+	KomponenHarian = "harian"
+	KomponenUTS    = "uts"
+	KomponenUAS    = "uas"
+)
+
+// Tugas adalah penugasan guru untuk satu kelas pada satu mapel dan semester
+type Tugas struct {
+	ID              uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	KelasID         uint            `gorm:"not null;index:idx_tugas_kelas_mapel_semester" json:"kelas_id"`
+	MataPelajaranID uint            `gorm:"not null;index:idx_tugas_kelas_mapel_semester" json:"mata_pelajaran_id"`
+	SemesterID      uint            `gorm:"not null;index:idx_tugas_kelas_mapel_semester" json:"semester_id"`
+	GuruID          uint            `gorm:"not null;index" json:"guru_id"`
+	Judul           string          `gorm:"type:varchar(200);not null" json:"judul"`
+	Deskripsi       string          `gorm:"type:text" json:"deskripsi"`
+	Tenggat         time.Time       `gorm:"not null;index" json:"tenggat"`
+	TolakTerlambat  bool            `gorm:"default:false" json:"tolak_terlambat"`   // true = pengumpulan ditutup setelah tenggat
+	KomponenNilai   string          `gorm:"type:varchar(10)" json:"komponen_nilai"` // kosong / harian / uts / uas
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	Kelas           Kelas           `gorm:"foreignKey:KelasID" json:"kelas,omitempty"`
+	MataPelajaran   MataPelajaran   `gorm:"foreignKey:MataPelajaranID" json:"mata_pelajaran,omitempty"`
+	Guru            Guru            `gorm:"foreignKey:GuruID" json:"guru,omitempty"`
+	Lampiran        []LampiranTugas `gorm:"foreignKey:TugasID" json:"lampiran,omitempty"`
+}
+
+// LampiranTugas adalah file soal/materi yang dilampirkan guru pada tugas
+type LampiranTugas struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TugasID   uint      `gorm:"not null;index" json:"tugas_id"`
+	NamaFile  string    `gorm:"type:varchar(255);not null" json:"nama_file"`
+	FilePath  string    `gorm:"type:varchar(255);not null" json:"file_path"`
+	Ukuran    int64     `json:"ukuran"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PengumpulanTugas adalah jawaban seorang siswa untuk satu tugas. Siswa dapat
+// mengumpulkan ulang selama jawabannya belum dinilai.
+type PengumpulanTugas struct {
+	ID              uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TugasID         uint       `gorm:"not null;uniqueIndex:idx_pengumpulan_tugas_siswa" json:"tugas_id"`
+	SiswaID         uint       `gorm:"not null;uniqueIndex:idx_pengumpulan_tugas_siswa;index" json:"siswa_id"`
+	Jawaban         string     `gorm:"type:text" json:"jawaban"`
+	NamaFile        string     `gorm:"type:varchar(255)" json:"nama_file"`
+	FilePath        string     `gorm:"type:varchar(255)" json:"file_path"`
+	Ukuran          int64      `json:"ukuran"`
+	DikumpulkanPada time.Time  `gorm:"not null" json:"dikumpulkan_pada"`
+	Terlambat       bool       `gorm:"default:false;index" json:"terlambat"`
+	Nilai           *float64   `json:"nilai"` // nil = belum dinilai
+	UmpanBalik      string     `gorm:"type:text" json:"umpan_balik"`
+	DinilaiPada     *time.Time `json:"dinilai_pada"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	Tugas           Tugas      `gorm:"foreignKey:TugasID" json:"tugas,omitempty"`
+	Siswa           Siswa      `gorm:"foreignKey:SiswaID" json:"siswa,omitempty"`
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tipe soal ujian
+const (
+	SoalPilihanGanda = "pilihan_ganda"
+	SoalIsian        = "isian" // jawaban singkat, dicocokkan otomatis
+	SoalEsai         = "esai"  // dinilai manual oleh guru
+)
+
+// Status percobaan ujian siswa
+const (
+	PercobaanBerlangsung = "berlangsung"
+	PercobaanSelesai     = "selesai"
+)
+
+// SoalUjian adalah butir bank soal per mata pelajaran. Soal dihapus secara soft
+// delete agar hasil ujian yang sudah memakainya tetap dapat ditampilkan.
+type SoalUjian struct {
+	ID              uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	MataPelajaranID uint           `gorm:"not null;index" json:"mata_pelajaran_id"`
+	GuruID          uint           `gorm:"not null;index" json:"guru_id"` // pembuat soal
+	Tipe            string         `gorm:"type:varchar(15);not null;index" json:"tipe"`
+	Pertanyaan      string         `gorm:"type:text;not null" json:"pertanyaan"`
+	KunciJawaban    string         `gorm:"type:text" json:"kunci_jawaban"` // label opsi / alternatif isian dipisah "|" / rubrik esai
+	Bobot           float64        `gorm:"not null;default:1" json:"bobot"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+	MataPelajaran   MataPelajaran  `gorm:"foreignKey:MataPelajaranID" json:"mata_pelajaran,omitempty"`
+	Opsi            []OpsiSoal     `gorm:"foreignKey:SoalID" json:"opsi,omitempty"`
+}
+
+// OpsiSoal adalah pilihan jawaban soal pilihan ganda
+type OpsiSoal struct {
+	ID     uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	SoalID uint   `gorm:"not null;index" json:"soal_id"`
+	Label  string `gorm:"type:varchar(2);not null" json:"label"` // A, B, C, ...
+	Teks   string `gorm:"type:text;not null" json:"teks"`
+}
+
+// Ujian adalah paket soal UTS/UAS untuk satu mapel dan semester. Nilai akhirnya
+// dapat dimasukkan ke komponen Nilai yang sesuai.
+type Ujian struct {
+	ID              uint          `gorm:"primaryKey;autoIncrement" json:"id"`
+	Judul           string        `gorm:"type:varchar(200);not null" json:"judul"`
+	MataPelajaranID uint          `gorm:"not null;index" json:"mata_pelajaran_id"`
+	SemesterID      uint          `gorm:"not null;index" json:"semester_id"`
+	GuruID          uint          `gorm:"not null;index" json:"guru_id"`
+	Komponen        string        `gorm:"type:varchar(10);not null" json:"komponen"` // harian / uts / uas
+	DurasiMenit     int           `gorm:"not null" json:"durasi_menit"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+	MataPelajaran   MataPelajaran `gorm:"foreignKey:MataPelajaranID" json:"mata_pelajaran,omitempty"`
+	Guru            Guru          `gorm:"foreignKey:GuruID" json:"guru,omitempty"`
+	Butir           []ButirUjian  `gorm:"foreignKey:UjianID" json:"butir,omitempty"`
+	Sesi            []SesiUjian   `gorm:"foreignKey:UjianID" json:"sesi,omitempty"`
+}
+
+// ButirUjian menghubungkan ujian dengan soal dari bank soal
+type ButirUjian struct {
+	ID      uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UjianID uint      `gorm:"not null;uniqueIndex:idx_butir_ujian_soal" json:"ujian_id"`
+	SoalID  uint      `gorm:"not null;uniqueIndex:idx_butir_ujian_soal" json:"soal_id"`
+	Urutan  int       `gorm:"not null" json:"urutan"` // urutan asli; tiap siswa mendapat urutan acak
+	Soal    SoalUjian `gorm:"foreignKey:SoalID" json:"soal,omitempty"`
+}
+
+// SesiUjian adalah jendela waktu pelaksanaan ujian untuk satu kelas
+type SesiUjian struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UjianID     uint      `gorm:"not null;uniqueIndex:idx_sesi_ujian_kelas" json:"ujian_id"`
+	KelasID     uint      `gorm:"not null;uniqueIndex:idx_sesi_ujian_kelas;index" json:"kelas_id"`
+	MulaiPada   time.Time `gorm:"not null" json:"mulai_pada"`
+	SelesaiPada time.Time `gorm:"not null" json:"selesai_pada"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Kelas       Kelas     `gorm:"foreignKey:KelasID" json:"kelas,omitempty"`
+	Ujian       Ujian     `gorm:"foreignKey:UjianID" json:"ujian,omitempty"`
+}
+
+// PercobaanUjian adalah pengerjaan ujian oleh seorang siswa. Batas waktu
+// dihitung server saat siswa mulai dan tidak melewati akhir sesi.
+type PercobaanUjian struct {
+	ID          uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	SesiID      uint           `gorm:"not null;uniqueIndex:idx_percobaan_sesi_siswa" json:"sesi_id"`
+	SiswaID     uint           `gorm:"not null;uniqueIndex:idx_percobaan_sesi_siswa;index" json:"siswa_id"`
+	UrutanSoal  string         `gorm:"type:text;not null" json:"-"` // ID soal teracak, dipisah koma
+	MulaiPada   time.Time      `gorm:"not null" json:"mulai_pada"`
+	BatasWaktu  time.Time      `gorm:"not null;index" json:"batas_waktu"`
+	SelesaiPada *time.Time     `json:"selesai_pada"`
+	Status      string         `gorm:"type:varchar(15);not null;default:'berlangsung';index" json:"status"`
+	Skor        float64        `json:"skor"`        // jumlah skor butir yang sudah dinilai
+	NilaiAkhir  *float64       `json:"nilai_akhir"` // 0-100; nil = belum selesai / esai belum dinilai
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	Sesi        SesiUjian      `gorm:"foreignKey:SesiID" json:"sesi,omitempty"`
+	Siswa       Siswa          `gorm:"foreignKey:SiswaID" json:"siswa,omitempty"`
+	Jawaban     []JawabanUjian `gorm:"foreignKey:PercobaanID" json:"jawaban,omitempty"`
+}
+
+// JawabanUjian adalah jawaban siswa untuk satu soal (disimpan otomatis saat
+// mengerjakan)
+type JawabanUjian struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	PercobaanID uint       `gorm:"not null;uniqueIndex:idx_jawaban_percobaan_soal" json:"percobaan_id"`
+	SoalID      uint       `gorm:"not null;uniqueIndex:idx_jawaban_percobaan_soal" json:"soal_id"`
+	Jawaban     string     `gorm:"type:text" json:"jawaban"`
+	Skor        *float64   `json:"skor"` // nil = belum dinilai
+	DinilaiPada *time.Time `json:"dinilai_pada"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Soal        SoalUjian  `gorm:"foreignKey:SoalID" json:"soal,omitempty"`
+}
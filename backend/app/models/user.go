@@ -8,18 +8,22 @@ import (
 )
 
 type User struct {
-	ID         uint           `gorm:"primaryKey;autoIncrement" json:"id"`
-	RoleID     uint           `gorm:"not null;index" json:"role_id"`
-	Nama       string         `gorm:"type:varchar(100);not null" json:"nama"`
-	Email      string         `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`
-	Password   string         `gorm:"type:varchar(255);not null" json:"-"`
-	Telepon    string         `gorm:"type:varchar(20)" json:"telepon,omitempty"`
-	FotoProfil string         `gorm:"type:varchar(500)" json:"foto_profil,omitempty"`
-	IsActive   bool           `gorm:"default:true" json:"is_active"`
-	LastLogin  *time.Time     `json:"last_login,omitempty"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                 uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	RoleID             uint           `gorm:"not null;index" json:"role_id"`
+	Nama               string         `gorm:"type:varchar(100);not null" json:"nama"`
+	Email              string         `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`
+	Password           string         `gorm:"type:varchar(255);not null" json:"-"`
+	Telepon            string         `gorm:"type:varchar(20)" json:"telepon,omitempty"`
+	FotoProfil         string         `gorm:"type:varchar(500)" json:"foto_profil,omitempty"`
+	IsActive           bool           `gorm:"default:true" json:"is_active"`
+	WajibGantiPassword bool           `gorm:"default:false" json:"wajib_ganti_password"` // akun buatan admin, wajib ganti password saat login pertama
+	TOTPSecret         string         `gorm:"type:varchar(64)" json:"-"`                 // secret 2FA (base32); terisi saat setup, aktif setelah diverifikasi
+	TOTPAktif          bool           `gorm:"default:false" json:"totp_aktif"`
+	TOTPStepTerakhir   int64          `gorm:"default:0" json:"-"` // langkah TOTP terakhir yang dipakai, mencegah replay kode
+	LastLogin          *time.Time     `json:"last_login,omitempty"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relasi
 	Role Role `gorm:"foreignKey:RoleID" json:"role,omitempty"`
@@ -51,4 +55,4 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 		u.Password = string(hashed)
 	}
 	return nil
-}
\ No newline at end of file
+}
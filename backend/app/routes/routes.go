@@ -26,15 +26,35 @@ func RegisterRoutes(r *gin.Engine) {
 	auth := api.Group("/auth")
 	{
 		auth.POST("/login", controllers.Login)
+		auth.POST("/forgot-password", controllers.ForgotPassword)
+		auth.POST("/reset-password", controllers.ResetPassword)
+		auth.POST("/2fa/verify", controllers.Verify2FA)
+	}
+
+	// ── Verifikasi rapor (public) ────────────────────────────────
+	api.GET("/verify/rapor/:code", controllers.VerifikasiRaporPublik)
+
+	// ── API perangkat gerbang (autentikasi X-API-Key) ───────────
+	perangkatGerbang := api.Group("/perangkat-gerbang")
+	perangkatGerbang.Use(middlewares.PerangkatGerbangMiddleware())
+	{
+		perangkatGerbang.GET("/ping", controllers.PingPerangkatGerbang)
+		perangkatGerbang.POST("/tap", controllers.TapPerangkatGerbang)
+		perangkatGerbang.POST("/tap/batch", controllers.BatchTapPerangkatGerbang)
 	}
 
 	// ── Protected Routes ─────────────────────────────────────────
 	protected := api.Group("")
-	protected.Use(middlewares.AuthMiddleware())
+	protected.Use(middlewares.AuthMiddleware(), middlewares.AccountGuardMiddleware())
 	{
 		// Auth
 		protected.GET("/auth/me", controllers.Me)
 		protected.PUT("/auth/change-password", controllers.ChangePassword)
+		protected.GET("/auth/2fa/status", controllers.Get2FAStatus)
+		protected.POST("/auth/2fa/setup", controllers.Setup2FA)
+		protected.POST("/auth/2fa/aktifkan", controllers.Aktifkan2FA)
+		protected.POST("/auth/2fa/nonaktifkan", controllers.Nonaktifkan2FA)
+		protected.POST("/auth/2fa/recovery-codes", controllers.RegenerateRecoveryCodes)
 
 		// ── Roles (admin only) ────────────────────────────────────
 		roles := protected.Group("/roles")
@@ -67,6 +87,17 @@ func RegisterRoutes(r *gin.Engine) {
 			)
 		}
 
+		// ── Lockout Login (admin only) ────────────────────────────
+		lockouts := protected.Group("/login-lockouts")
+		lockouts.Use(middlewares.RoleMiddleware(models.RoleAdmin))
+		{
+			lockouts.GET("", controllers.GetLoginLockouts)
+			lockouts.DELETE("/:id",
+				middlewares.ActivityLogger("UNLOCK", "login_lockout"),
+				controllers.ClearLoginLockout,
+			)
+		}
+
 		// ── Profil (semua user) ───────────────────────────────────
 		profile := protected.Group("/profile")
 		{
@@ -95,11 +126,16 @@ func RegisterRoutes(r *gin.Engine) {
 			ta.POST("", controllers.CreateTahunAjaran)
 			ta.PUT("/:id", controllers.UpdateTahunAjaran)
 			ta.DELETE("/:id", controllers.DeleteTahunAjaran)
+			ta.POST("/:id/rollover",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("ROLLOVER", "tahun_ajaran"),
+				controllers.RolloverTahunAjaran,
+			)
 		}
 
 		// ── Semester (admin) ──────────────────────────────────────
 		sem := protected.Group("/semester")
-		sem.Use(middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru, models.RoleOrangTua, models.RoleSiswa))
+		sem.Use(middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru, models.RoleGuruBK, models.RoleOrangTua, models.RoleSiswa))
 		{
 			sem.GET("", controllers.GetSemester)
 			sem.GET("/aktif", controllers.GetSemesterAktif)
@@ -130,6 +166,67 @@ func RegisterRoutes(r *gin.Engine) {
 			mp.DELETE("/:id", controllers.DeleteMataPelajaran)
 		}
 
+		// ── Kurikulum ─────────────────────────────────────────────
+		kur := protected.Group("/kurikulum")
+		{
+			kur.GET("",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru),
+				controllers.GetKurikulum,
+			)
+			kur.POST("",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("CREATE", "kurikulum"),
+				controllers.CreateKurikulum,
+			)
+			kur.PUT("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("UPDATE", "kurikulum"),
+				controllers.UpdateKurikulum,
+			)
+			kur.DELETE("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("DELETE", "kurikulum"),
+				controllers.DeleteKurikulum,
+			)
+		}
+
+		// ── Guru Pengampu ─────────────────────────────────────────
+		pengampu := protected.Group("/pengampu")
+		{
+			pengampu.GET("",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru),
+				controllers.GetPengampu,
+			)
+			pengampu.GET("/saya",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetPengampuSaya,
+			)
+			pengampu.GET("/beban-mengajar",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah),
+				controllers.GetBebanMengajar,
+			)
+			pengampu.POST("",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("CREATE", "pengampu"),
+				controllers.CreatePengampu,
+			)
+			pengampu.POST("/sinkron-jadwal",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("SYNC", "pengampu"),
+				controllers.SinkronPengampuJadwal,
+			)
+			pengampu.PUT("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("UPDATE", "pengampu"),
+				controllers.UpdatePengampu,
+			)
+			pengampu.DELETE("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("DELETE", "pengampu"),
+				controllers.DeletePengampu,
+			)
+		}
+
 		// ── Guru (admin) ──────────────────────────────────────────
 		guru := protected.Group("/guru")
 		guru.Use(middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas, models.RoleKepalaSekolah))
@@ -154,19 +251,19 @@ func RegisterRoutes(r *gin.Engine) {
 		kelas := protected.Group("/kelas")
 		{
 			kelas.GET("",
-				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru, models.RoleOrangTua, models.RoleSiswa),
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru, models.RoleGuruBK, models.RoleOrangTua, models.RoleSiswa),
 				controllers.GetKelas,
 			)
 			kelas.GET("/summary",
-				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru, models.RoleOrangTua, models.RoleSiswa),
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru, models.RoleGuruBK, models.RoleOrangTua, models.RoleSiswa),
 				controllers.GetKelasWithJumlahSiswa,
 			)
 			kelas.GET("/:id",
-				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru, models.RoleOrangTua, models.RoleSiswa),
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru, models.RoleGuruBK, models.RoleOrangTua, models.RoleSiswa),
 				controllers.GetKelasByID,
 			)
 			kelas.GET("/:id/siswa",
-				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleWaliKelas, models.RoleGuru, models.RoleOrangTua, models.RoleSiswa),
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleWaliKelas, models.RoleGuru, models.RoleGuruBK, models.RoleOrangTua, models.RoleSiswa),
 				controllers.GetSiswaByKelas,
 			)
 			kelas.POST("",
@@ -190,11 +287,11 @@ func RegisterRoutes(r *gin.Engine) {
 		siswRoute := protected.Group("/siswa")
 		{
 			siswRoute.GET("",
-				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru),
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru, models.RoleGuruBK),
 				controllers.GetSiswa,
 			)
 			siswRoute.GET("/:id",
-				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru, models.RoleSiswa),
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru, models.RoleGuruBK, models.RoleSiswa),
 				controllers.GetSiswaByID,
 			)
 			siswRoute.POST("",
@@ -207,6 +304,10 @@ func RegisterRoutes(r *gin.Engine) {
 				middlewares.ActivityLogger("UPDATE", "siswa"),
 				controllers.UpdateSiswa,
 			)
+			siswRoute.GET("/:id/riwayat-kelas",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru),
+				controllers.GetRiwayatKelasSiswa,
+			)
 			siswRoute.PATCH("/:id/pindah-kelas",
 				middlewares.RoleMiddleware(models.RoleAdmin),
 				middlewares.ActivityLogger("PINDAH_KELAS", "siswa"),
@@ -223,6 +324,48 @@ func RegisterRoutes(r *gin.Engine) {
 			)
 		}
 
+		// ── Kenaikan Kelas & Kelulusan ────────────────────────────
+		kenaikan := protected.Group("/kenaikan-kelas")
+		{
+			kenaikan.GET("",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas),
+				controllers.GetProsesKenaikan,
+			)
+			kenaikan.GET("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas),
+				controllers.GetProsesKenaikanByID,
+			)
+			kenaikan.GET("/:id/preview",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas),
+				controllers.GetPreviewKenaikan,
+			)
+			kenaikan.POST("",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("CREATE", "kenaikan_kelas"),
+				controllers.CreateProsesKenaikan,
+			)
+			kenaikan.PUT("/:id/pemetaan/:pemetaan_id",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("UPDATE_PEMETAAN", "kenaikan_kelas"),
+				controllers.UpdatePemetaanKenaikan,
+			)
+			kenaikan.PUT("/:id/keputusan",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleWaliKelas),
+				middlewares.ActivityLogger("KEPUTUSAN", "kenaikan_kelas"),
+				controllers.SetKeputusanKenaikan,
+			)
+			kenaikan.POST("/:id/terapkan",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("TERAPKAN", "kenaikan_kelas"),
+				controllers.TerapkanKenaikan,
+			)
+			kenaikan.DELETE("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("DELETE", "kenaikan_kelas"),
+				controllers.DeleteProsesKenaikan,
+			)
+		}
+
 		// ── Orang Tua ─────────────────────────────────────────────
 		otRoute := protected.Group("/orang-tua")
 		{
@@ -332,6 +475,40 @@ func RegisterRoutes(r *gin.Engine) {
 			middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas),
 			controllers.GetRekapAbsensiKelas,
 			)
+
+			// Absensi mandiri siswa dengan QR
+			absensi.POST("/sesi",
+			middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+			middlewares.ActivityLogger("CREATE", "sesi_absensi"),
+			controllers.BukaSesiAbsensi,
+			)
+			absensi.GET("/sesi/:id",
+			middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+			controllers.GetSesiAbsensi,
+			)
+			absensi.GET("/sesi/:id/qr",
+			middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+			controllers.GetQRSesiAbsensi,
+			)
+			absensi.POST("/sesi/:id/tutup",
+			middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+			middlewares.ActivityLogger("UPDATE", "sesi_absensi"),
+			controllers.TutupSesiAbsensi,
+			)
+			absensi.PUT("/sesi/:id/siswa/:siswa_id",
+			middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+			middlewares.ActivityLogger("UPDATE", "absensi"),
+			controllers.OverrideAbsensiSesi,
+			)
+			absensi.POST("/checkin",
+			middlewares.RoleMiddleware(models.RoleSiswa),
+			controllers.CheckinAbsensiQR,
+			)
+			absensi.DELETE("/perangkat/:siswa_id",
+			middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+			middlewares.ActivityLogger("DELETE", "perangkat_siswa"),
+			controllers.ResetPerangkatSiswa,
+			)
 			absensi.GET("/:id",
 			middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
 			controllers.GetAbsensiByID,
@@ -373,6 +550,10 @@ func RegisterRoutes(r *gin.Engine) {
 			middlewares.RoleMiddleware(models.RoleSiswa, models.RoleOrangTua),
 			controllers.GetNilaiSaya,
 			)
+			nilai.GET("/leger/kelas/:kelas_id",
+			middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas),
+			controllers.GetLegerKelas,
+			)
 			nilai.GET("/:id",
 			middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas, models.RoleSiswa, models.RoleOrangTua),
 			controllers.GetNilaiByID,
@@ -394,6 +575,638 @@ func RegisterRoutes(r *gin.Engine) {
 			)
 		}
 
+		// ── Capaian Kompetensi & Projek P5 ───────────────
+		tp := protected.Group("/tujuan-pembelajaran")
+		{
+			tp.GET("",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetTujuanPembelajaran,
+			)
+			tp.POST("",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("CREATE", "tujuan_pembelajaran"),
+				controllers.CreateTujuanPembelajaran,
+			)
+			tp.PUT("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "tujuan_pembelajaran"),
+				controllers.UpdateTujuanPembelajaran,
+			)
+			tp.DELETE("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("DELETE", "tujuan_pembelajaran"),
+				controllers.DeleteTujuanPembelajaran,
+			)
+		}
+
+		capaian := protected.Group("/capaian")
+		{
+			capaian.GET("/siswa/:siswa_id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetCapaianSiswa,
+			)
+			capaian.POST("",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "capaian"),
+				controllers.SimpanCapaian,
+			)
+		}
+
+		p5 := protected.Group("/projek-p5")
+		{
+			p5.GET("",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetProjekP5,
+			)
+			p5.GET("/:id/nilai",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetNilaiP5,
+			)
+			p5.POST("",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleWaliKelas),
+				middlewares.ActivityLogger("CREATE", "projek_p5"),
+				controllers.CreateProjekP5,
+			)
+			p5.PUT("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "projek_p5"),
+				controllers.UpdateProjekP5,
+			)
+			p5.DELETE("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleWaliKelas),
+				middlewares.ActivityLogger("DELETE", "projek_p5"),
+				controllers.DeleteProjekP5,
+			)
+			p5.POST("/:id/nilai",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "nilai_p5"),
+				controllers.SimpanNilaiP5,
+			)
+		}
+
+		// ── Antrian Job (admin) ──────────────────────────
+		jobs := protected.Group("/jobs")
+		jobs.Use(middlewares.RoleMiddleware(models.RoleAdmin))
+		{
+			jobs.GET("", controllers.GetJobs)
+			jobs.GET("/ringkasan", controllers.GetRingkasanJob)
+			jobs.GET("/terjadwal", controllers.GetJobTerjadwal)
+			jobs.PUT("/terjadwal/:id",
+				middlewares.ActivityLogger("UPDATE", "job_terjadwal"),
+				controllers.UpdateJobTerjadwal,
+			)
+			jobs.GET("/:id", controllers.GetJobByID)
+			jobs.POST("/:id/ulangi",
+				middlewares.ActivityLogger("RETRY", "job"),
+				controllers.UlangiJob,
+			)
+			jobs.POST("/:id/batal",
+				middlewares.ActivityLogger("CANCEL", "job"),
+				controllers.BatalkanJob,
+			)
+		}
+
+		// ── Analytics (kepala sekolah) ───────────────────
+		analytics := protected.Group("/analytics")
+		analytics.Use(middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah))
+		{
+			analytics.GET("/kehadiran/tren", controllers.GetTrenKehadiran)
+			analytics.GET("/nilai/distribusi", controllers.GetDistribusiNilai)
+			analytics.GET("/nilai/dibawah-kkm", controllers.GetNilaiDibawahKKM)
+			analytics.GET("/kelengkapan-input", controllers.GetKelengkapanInputGuru)
+			analytics.GET("/perbandingan-tahunan", controllers.GetPerbandinganTahunan)
+		}
+
+		// ── Monitor kelengkapan input guru ───────────────
+		monitor := protected.Group("/monitor")
+		{
+			monitor.GET("/kelengkapan",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah),
+				controllers.GetMonitorKelengkapan,
+			)
+			monitor.GET("/kelengkapan/saya",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetMonitorKelengkapanSaya,
+			)
+			monitor.GET("/kelengkapan/guru/:guru_id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah),
+				controllers.GetMonitorKelengkapanGuru,
+			)
+			monitor.POST("/kelengkapan/kirim-pengingat",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("CREATE", "pengingat_kelengkapan"),
+				controllers.KirimPengingatKelengkapan,
+			)
+		}
+
+		// ── Gerbang (kartu & perangkat) ──────────────────
+		gerbang := protected.Group("/gerbang")
+		{
+			gerbang.GET("/perangkat",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				controllers.GetDaftarPerangkatGerbang,
+			)
+			gerbang.POST("/perangkat",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("CREATE", "perangkat_gerbang"),
+				controllers.CreatePerangkatGerbang,
+			)
+			gerbang.PUT("/perangkat/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("UPDATE", "perangkat_gerbang"),
+				controllers.UpdatePerangkatGerbang,
+			)
+			gerbang.POST("/perangkat/:id/regenerate-key",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("UPDATE", "perangkat_gerbang"),
+				controllers.RegenerateKeyPerangkatGerbang,
+			)
+			gerbang.DELETE("/perangkat/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("DELETE", "perangkat_gerbang"),
+				controllers.DeletePerangkatGerbang,
+			)
+			gerbang.GET("/kartu",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				controllers.GetKartuAkses,
+			)
+			gerbang.POST("/kartu",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("CREATE", "kartu_akses"),
+				controllers.CreateKartuAkses,
+			)
+			gerbang.PUT("/kartu/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("UPDATE", "kartu_akses"),
+				controllers.UpdateKartuAkses,
+			)
+			gerbang.DELETE("/kartu/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("DELETE", "kartu_akses"),
+				controllers.DeleteKartuAkses,
+			)
+			gerbang.GET("/tap",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				controllers.GetTapGerbang,
+			)
+			gerbang.GET("/kehadiran",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas),
+				controllers.GetKehadiranGerbang,
+			)
+			gerbang.POST("/isi-absensi",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("CREATE", "absensi_gerbang"),
+				controllers.IsiAbsensiGerbang,
+			)
+			gerbang.GET("/jadwal-bel", controllers.GetJadwalBel)
+			gerbang.PUT("/jadwal-bel/:hari_ke",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("UPDATE", "jadwal_bel"),
+				controllers.SetJadwalBel,
+			)
+			gerbang.DELETE("/jadwal-bel/:hari_ke",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("DELETE", "jadwal_bel"),
+				controllers.DeleteJadwalBel,
+			)
+		}
+
+		// ── Jurnal Mengajar ──────────────────────────────
+		jurnal := protected.Group("/jurnal")
+		{
+			jurnal.GET("",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetJurnal,
+			)
+			jurnal.GET("/guru/:guru_id/pdf",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas),
+				controllers.ExportJurnalBulananPDF,
+			)
+			jurnal.GET("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetJurnalByID,
+			)
+			jurnal.POST("",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("CREATE", "jurnal"),
+				controllers.CreateJurnal,
+			)
+			jurnal.PUT("/:id",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "jurnal"),
+				controllers.UpdateJurnal,
+			)
+			jurnal.DELETE("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("DELETE", "jurnal"),
+				controllers.DeleteJurnal,
+			)
+			jurnal.POST("/:id/lampiran",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("CREATE", "lampiran_jurnal"),
+				controllers.UploadLampiranJurnal,
+			)
+			jurnal.GET("/:id/lampiran/:lampiran_id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas),
+				controllers.DownloadLampiranJurnal,
+			)
+			jurnal.DELETE("/:id/lampiran/:lampiran_id",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("DELETE", "lampiran_jurnal"),
+				controllers.DeleteLampiranJurnal,
+			)
+			jurnal.POST("/:id/review",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "review_jurnal"),
+				controllers.ReviewJurnal,
+			)
+		}
+
+		// ── Ekstrakurikuler ──────────────────────────────
+		ekskul := protected.Group("/ekskul")
+		{
+			ekskul.GET("",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru, models.RoleGuruBK, models.RoleSiswa, models.RoleOrangTua),
+				controllers.GetEkskul,
+			)
+			ekskul.GET("/saya",
+				middlewares.RoleMiddleware(models.RoleSiswa, models.RoleOrangTua),
+				controllers.GetEkskulSaya,
+			)
+			ekskul.GET("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas, models.RoleGuru, models.RoleGuruBK, models.RoleSiswa, models.RoleOrangTua),
+				controllers.GetEkskulByID,
+			)
+			ekskul.POST("",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("CREATE", "ekskul"),
+				controllers.CreateEkskul,
+			)
+			ekskul.PUT("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("UPDATE", "ekskul"),
+				controllers.UpdateEkskul,
+			)
+			ekskul.DELETE("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("DELETE", "ekskul"),
+				controllers.DeleteEkskul,
+			)
+			// Anggota, presensi dan nilai dikelola admin atau guru pembina
+			ekskul.GET("/:id/anggota",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetAnggotaEkskul,
+			)
+			ekskul.POST("/:id/anggota",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("CREATE", "anggota_ekskul"),
+				controllers.TambahAnggotaEkskul,
+			)
+			ekskul.DELETE("/:id/anggota/:anggota_id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("DELETE", "anggota_ekskul"),
+				controllers.HapusAnggotaEkskul,
+			)
+			ekskul.GET("/:id/pertemuan",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetPertemuanEkskul,
+			)
+			ekskul.POST("/:id/pertemuan",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("CREATE", "pertemuan_ekskul"),
+				controllers.SimpanPertemuanEkskul,
+			)
+			ekskul.DELETE("/:id/pertemuan/:pertemuan_id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("DELETE", "pertemuan_ekskul"),
+				controllers.DeletePertemuanEkskul,
+			)
+			ekskul.PUT("/:id/nilai",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "nilai_ekskul"),
+				controllers.SimpanNilaiEkskul,
+			)
+		}
+
+		// ── Tugas ────────────────────────────────────────
+		tugas := protected.Group("/tugas")
+		{
+			tugas.GET("",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetTugas,
+			)
+			tugas.GET("/saya",
+				middlewares.RoleMiddleware(models.RoleSiswa, models.RoleOrangTua),
+				controllers.GetTugasSaya,
+			)
+			tugas.POST("/terapkan-nilai",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "nilai_tugas"),
+				controllers.TerapkanNilaiTugas,
+			)
+			tugas.GET("/pengumpulan/:id/file",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas, models.RoleSiswa),
+				controllers.DownloadPengumpulanTugas,
+			)
+			tugas.PUT("/pengumpulan/:id/nilai",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "pengumpulan_tugas"),
+				controllers.NilaiPengumpulan,
+			)
+			tugas.GET("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetTugasByID,
+			)
+			tugas.POST("",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("CREATE", "tugas"),
+				controllers.CreateTugas,
+			)
+			tugas.PUT("/:id",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "tugas"),
+				controllers.UpdateTugas,
+			)
+			tugas.DELETE("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("DELETE", "tugas"),
+				controllers.DeleteTugas,
+			)
+			tugas.POST("/:id/lampiran",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("CREATE", "lampiran_tugas"),
+				controllers.UploadLampiranTugas,
+			)
+			tugas.GET("/:id/lampiran/:lampiran_id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas, models.RoleSiswa, models.RoleOrangTua),
+				controllers.DownloadLampiranTugas,
+			)
+			tugas.DELETE("/:id/lampiran/:lampiran_id",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("DELETE", "lampiran_tugas"),
+				controllers.DeleteLampiranTugas,
+			)
+			tugas.GET("/:id/pengumpulan",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetPengumpulanTugas,
+			)
+			tugas.POST("/:id/kumpul",
+				middlewares.RoleMiddleware(models.RoleSiswa),
+				middlewares.ActivityLogger("CREATE", "pengumpulan_tugas"),
+				controllers.KumpulkanTugas,
+			)
+		}
+
+		// ── Ujian (CBT) ──────────────────────────────────
+		ujian := protected.Group("/ujian")
+		{
+			// Bank soal
+			ujian.GET("/soal",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetSoalUjian,
+			)
+			ujian.POST("/soal",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("CREATE", "soal_ujian"),
+				controllers.CreateSoalUjian,
+			)
+			ujian.PUT("/soal/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "soal_ujian"),
+				controllers.UpdateSoalUjian,
+			)
+			ujian.DELETE("/soal/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("DELETE", "soal_ujian"),
+				controllers.DeleteSoalUjian,
+			)
+
+			// Pengerjaan oleh siswa
+			ujian.GET("/saya",
+				middlewares.RoleMiddleware(models.RoleSiswa, models.RoleOrangTua),
+				controllers.GetUjianSaya,
+			)
+			ujian.POST("/sesi/:id/mulai",
+				middlewares.RoleMiddleware(models.RoleSiswa),
+				middlewares.ActivityLogger("CREATE", "percobaan_ujian"),
+				controllers.MulaiUjian,
+			)
+			ujian.GET("/percobaan/:id",
+				middlewares.RoleMiddleware(models.RoleSiswa),
+				controllers.GetPercobaanUjian,
+			)
+			ujian.PUT("/percobaan/:id/jawaban",
+				middlewares.RoleMiddleware(models.RoleSiswa),
+				controllers.SimpanJawabanUjian,
+			)
+			ujian.POST("/percobaan/:id/selesai",
+				middlewares.RoleMiddleware(models.RoleSiswa),
+				middlewares.ActivityLogger("UPDATE", "percobaan_ujian"),
+				controllers.SelesaikanUjian,
+			)
+
+			// Koreksi esai
+			ujian.PUT("/jawaban/:id/nilai",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "jawaban_ujian"),
+				controllers.NilaiJawabanEsai,
+			)
+
+			// Ujian & sesi kelas
+			ujian.GET("",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetUjian,
+			)
+			ujian.GET("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetUjianByID,
+			)
+			ujian.POST("",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("CREATE", "ujian"),
+				controllers.CreateUjian,
+			)
+			ujian.PUT("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "ujian"),
+				controllers.UpdateUjian,
+			)
+			ujian.DELETE("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("DELETE", "ujian"),
+				controllers.DeleteUjian,
+			)
+			ujian.POST("/:id/sesi",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "sesi_ujian"),
+				controllers.SimpanSesiUjian,
+			)
+			ujian.DELETE("/:id/sesi/:sesi_id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("DELETE", "sesi_ujian"),
+				controllers.DeleteSesiUjian,
+			)
+			ujian.GET("/:id/hasil",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetHasilUjian,
+			)
+			ujian.GET("/:id/esai",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				controllers.GetEsaiBelumDinilai,
+			)
+			ujian.POST("/:id/terapkan-nilai",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuru, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "nilai_ujian"),
+				controllers.TerapkanNilaiUjian,
+			)
+		}
+
+		// ── Kedisiplinan & Bimbingan Konseling ───────────
+		disiplin := protected.Group("/kedisiplinan")
+		{
+			disiplin.GET("/jenis",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuruBK, models.RoleWaliKelas, models.RoleGuru),
+				controllers.GetJenisPelanggaran,
+			)
+			disiplin.POST("/jenis",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuruBK),
+				middlewares.ActivityLogger("CREATE", "jenis_pelanggaran"),
+				controllers.CreateJenisPelanggaran,
+			)
+			disiplin.PUT("/jenis/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuruBK),
+				middlewares.ActivityLogger("UPDATE", "jenis_pelanggaran"),
+				controllers.UpdateJenisPelanggaran,
+			)
+			disiplin.DELETE("/jenis/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuruBK),
+				middlewares.ActivityLogger("DELETE", "jenis_pelanggaran"),
+				controllers.DeleteJenisPelanggaran,
+			)
+			disiplin.GET("/ambang",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuruBK, models.RoleWaliKelas, models.RoleGuru),
+				controllers.GetAmbangPoin,
+			)
+			disiplin.POST("/ambang",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuruBK),
+				middlewares.ActivityLogger("CREATE", "ambang_poin"),
+				controllers.CreateAmbangPoin,
+			)
+			disiplin.PUT("/ambang/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuruBK),
+				middlewares.ActivityLogger("UPDATE", "ambang_poin"),
+				controllers.UpdateAmbangPoin,
+			)
+			disiplin.DELETE("/ambang/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuruBK),
+				middlewares.ActivityLogger("DELETE", "ambang_poin"),
+				controllers.DeleteAmbangPoin,
+			)
+			disiplin.GET("/pelanggaran",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuruBK, models.RoleWaliKelas, models.RoleGuru),
+				controllers.GetPelanggaran,
+			)
+			disiplin.POST("/pelanggaran",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas, models.RoleGuruBK),
+				middlewares.ActivityLogger("CREATE", "pelanggaran"),
+				controllers.CreatePelanggaran,
+			)
+			disiplin.POST("/pelanggaran/:id/bukti",
+				middlewares.RoleMiddleware(models.RoleGuru, models.RoleWaliKelas, models.RoleGuruBK),
+				middlewares.ActivityLogger("UPDATE", "bukti_pelanggaran"),
+				controllers.UploadBuktiPelanggaran,
+			)
+			disiplin.GET("/pelanggaran/:id/bukti",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuruBK, models.RoleWaliKelas, models.RoleGuru, models.RoleSiswa, models.RoleOrangTua),
+				controllers.DownloadBuktiPelanggaran,
+			)
+			disiplin.DELETE("/pelanggaran/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuruBK, models.RoleWaliKelas, models.RoleGuru),
+				middlewares.ActivityLogger("DELETE", "pelanggaran"),
+				controllers.DeletePelanggaran,
+			)
+			disiplin.GET("/siswa/:siswa_id/ringkasan",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuruBK, models.RoleWaliKelas),
+				controllers.GetRingkasanKedisiplinanSiswa,
+			)
+			disiplin.GET("/saya",
+				middlewares.RoleMiddleware(models.RoleSiswa, models.RoleOrangTua),
+				controllers.GetKedisiplinanSaya,
+			)
+			disiplin.GET("/sanksi",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleGuruBK, models.RoleWaliKelas),
+				controllers.GetSanksi,
+			)
+			disiplin.PUT("/sanksi/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin, models.RoleGuruBK, models.RoleWaliKelas),
+				middlewares.ActivityLogger("UPDATE", "sanksi_siswa"),
+				controllers.UpdateSanksi,
+			)
+			// Catatan konseling bersifat rahasia: admin & kepala sekolah tidak diberi akses
+			disiplin.GET("/konseling",
+				middlewares.RoleMiddleware(models.RoleGuruBK, models.RoleWaliKelas),
+				controllers.GetKonseling,
+			)
+			disiplin.GET("/konseling/:id",
+				middlewares.RoleMiddleware(models.RoleGuruBK, models.RoleWaliKelas),
+				controllers.GetKonselingByID,
+			)
+			disiplin.POST("/konseling",
+				middlewares.RoleMiddleware(models.RoleGuruBK),
+				middlewares.ActivityLogger("CREATE", "catatan_konseling"),
+				controllers.CreateKonseling,
+			)
+			disiplin.PUT("/konseling/:id",
+				middlewares.RoleMiddleware(models.RoleGuruBK),
+				middlewares.ActivityLogger("UPDATE", "catatan_konseling"),
+				controllers.UpdateKonseling,
+			)
+			disiplin.DELETE("/konseling/:id",
+				middlewares.RoleMiddleware(models.RoleGuruBK),
+				middlewares.ActivityLogger("DELETE", "catatan_konseling"),
+				controllers.DeleteKonseling,
+			)
+		}
+
+		// ── Profil Sekolah & Template Rapor ──────────────
+		sekolah := protected.Group("/sekolah")
+		{
+			sekolah.GET("/profil", controllers.GetProfilSekolah)
+			sekolah.PUT("/profil",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("UPDATE", "profil_sekolah"),
+				controllers.UpdateProfilSekolah,
+			)
+			sekolah.POST("/logo",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("UPDATE", "profil_sekolah"),
+				controllers.UploadLogoSekolah,
+			)
+		}
+
+		templateRapor := protected.Group("/template-rapor")
+		templateRapor.Use(middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah))
+		{
+			templateRapor.GET("", controllers.GetTemplateRapor)
+			templateRapor.GET("/:id", controllers.GetTemplateRaporByID)
+			templateRapor.GET("/:id/preview", controllers.PreviewTemplateRapor)
+			templateRapor.POST("",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("CREATE", "template_rapor"),
+				controllers.CreateTemplateRapor,
+			)
+			templateRapor.PUT("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("UPDATE", "template_rapor"),
+				controllers.UpdateTemplateRapor,
+			)
+			templateRapor.DELETE("/:id",
+				middlewares.RoleMiddleware(models.RoleAdmin),
+				middlewares.ActivityLogger("DELETE", "template_rapor"),
+				controllers.DeleteTemplateRapor,
+			)
+		}
+
 		// ── Rapor ────────────────────────────────────────
 		rapor := protected.Group("/rapor")
 		{
@@ -418,6 +1231,41 @@ func RegisterRoutes(r *gin.Engine) {
 			middlewares.ActivityLogger("GENERATE", "rapor"),
 			controllers.GenerateRapor,
 			)
+			rapor.POST("/generate/kelas/:kelas_id",
+			middlewares.RoleMiddleware(models.RoleAdmin, models.RoleWaliKelas),
+			middlewares.ActivityLogger("GENERATE", "rapor_kelas"),
+			controllers.GenerateRaporKelas,
+			)
+			rapor.GET("/batch/:id",
+			middlewares.RoleMiddleware(models.RoleAdmin, models.RoleWaliKelas),
+			controllers.GetRaporBatch,
+			)
+			rapor.GET("/batch/:id/download",
+			middlewares.RoleMiddleware(models.RoleAdmin, models.RoleWaliKelas),
+			controllers.DownloadRaporBatch,
+			)
+			rapor.POST("/:id/terbitkan",
+			middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas),
+			middlewares.ActivityLogger("PUBLISH", "rapor"),
+			controllers.TerbitkanRapor,
+			)
+			rapor.GET("/:id/versi",
+			middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas),
+			controllers.GetVersiRapor,
+			)
+			rapor.GET("/:id/versi/:nomor",
+			middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas),
+			controllers.GetVersiRaporDetail,
+			)
+			rapor.GET("/:id/versi/:nomor/download",
+			middlewares.RoleMiddleware(models.RoleAdmin, models.RoleKepalaSekolah, models.RoleWaliKelas),
+			controllers.DownloadVersiRapor,
+			)
+			rapor.POST("/:id/versi/:nomor/cabut",
+			middlewares.RoleMiddleware(models.RoleAdmin),
+			middlewares.ActivityLogger("REVOKE", "rapor"),
+			controllers.CabutVersiRapor,
+			)
 			rapor.DELETE("/:id",
 			middlewares.RoleMiddleware(models.RoleAdmin),
 			middlewares.ActivityLogger("DELETE", "rapor"),
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+const maksBatchAktivitas = 100
+
+// antrianAktivitas menampung log aktivitas yang disimpan oleh penulisAktivitas
+// agar insert tidak menahan response. kunciAntrian menjaga agar tidak ada
+// pengirim yang masih memasukkan log ketika penulis mulai menguras antrian.
+var (
+	antrianAktivitas = make(chan models.ActivityLog, 1000)
+	penulisAktif     atomic.Bool
+	kunciAntrian     sync.RWMutex
+)
+
+// CatatAktivitas mengantrikan log aktivitas. Jika penulis belum berjalan atau
+// antrian sedang penuh, log disimpan langsung agar tidak hilang.
+func CatatAktivitas(entri models.ActivityLog) {
+	if kirimAntrianAktivitas(entri) {
+		return
+	}
+	simpanAktivitas([]models.ActivityLog{entri})
+}
+
+// kirimAntrianAktivitas memasukkan log ke antrian selama penulis masih aktif.
+// Pemeriksaan dan pengiriman dilakukan di bawah kunciAntrian sehingga penulis
+// yang sedang berhenti pasti menguras log ini.
+func kirimAntrianAktivitas(entri models.ActivityLog) bool {
+	kunciAntrian.RLock()
+	defer kunciAntrian.RUnlock()
+	if !penulisAktif.Load() {
+		return false
+	}
+	select {
+	case antrianAktivitas <- entri:
+		return true
+	default:
+		return false
+	}
+}
+
+// penulisAktivitas menyimpan log dari antrian secara berkelompok. Setelah
+// berhenti ditutup, sisa antrian dikuras dulu sebelum keluar.
+func penulisAktivitas(berhenti <-chan struct{}) {
+	for {
+		select {
+		case entri := <-antrianAktivitas:
+			simpanAktivitas(ambilAntrianAktivitas([]models.ActivityLog{entri}))
+		case <-berhenti:
+			// Tunggu pengirim yang sedang berjalan selesai sebelum menguras
+			kunciAntrian.Lock()
+			penulisAktif.Store(false)
+			kunciAntrian.Unlock()
+			for {
+				batch := ambilAntrianAktivitas(nil)
+				if len(batch) == 0 {
+					return
+				}
+				simpanAktivitas(batch)
+			}
+		}
+	}
+}
+
+// ambilAntrianAktivitas menambahkan log yang sudah menunggu di antrian ke batch
+// tanpa menunggu log baru
+func ambilAntrianAktivitas(batch []models.ActivityLog) []models.ActivityLog {
+	for len(batch) < maksBatchAktivitas {
+		select {
+		case entri := <-antrianAktivitas:
+			batch = append(batch, entri)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+func simpanAktivitas(batch []models.ActivityLog) {
+	if err := config.DB.CreateInBatches(batch, maksBatchAktivitas).Error; err != nil {
+		log.Printf("[aktivitas] gagal menyimpan %d log aktivitas: %v", len(batch), err)
+	}
+}
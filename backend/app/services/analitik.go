@@ -0,0 +1,367 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"sim-sekolah/config"
+)
+
+// FilterAnalitik membatasi data yang diagregasi. Nilai nol berarti tidak difilter.
+type FilterAnalitik struct {
+	TahunAjaranID   uint       `json:"tahun_ajaran_id,omitempty"`
+	SemesterID      uint       `json:"semester_id,omitempty"`
+	NamaSemester    string     `json:"semester,omitempty"` // "Ganjil" / "Genap", untuk perbandingan antar tahun
+	KelasID         uint       `json:"kelas_id,omitempty"`
+	JurusanID       uint       `json:"jurusan_id,omitempty"`
+	MataPelajaranID uint       `json:"mata_pelajaran_id,omitempty"`
+	GuruID          uint       `json:"guru_id,omitempty"`  // hanya untuk kelengkapan input
+	Komponen        string     `json:"komponen,omitempty"` // harian / uts / uas; kosong = ketiganya (kelengkapan nilai)
+	Dari            *time.Time `json:"dari,omitempty"`
+	Sampai          *time.Time `json:"sampai,omitempty"`
+}
+
+// terapkan memasang filter pada query yang memakai alias sm (semesters) dan
+// k (kelas). kolomMapel adalah kolom mata pelajaran milik tabel utama.
+func (f FilterAnalitik) terapkan(q *gorm.DB, kolomMapel string) *gorm.DB {
+	if f.TahunAjaranID != 0 {
+		q = q.Where("sm.tahun_ajaran_id = ?", f.TahunAjaranID)
+	}
+	if f.SemesterID != 0 {
+		q = q.Where("sm.id = ?", f.SemesterID)
+	}
+	if f.NamaSemester != "" {
+		q = q.Where("LOWER(sm.nama) = LOWER(?)", f.NamaSemester)
+	}
+	if f.KelasID != 0 {
+		q = q.Where("k.id = ?", f.KelasID)
+	}
+	if f.JurusanID != 0 {
+		q = q.Where("k.jurusan_id = ?", f.JurusanID)
+	}
+	if f.MataPelajaranID != 0 {
+		q = q.Where(kolomMapel+" = ?", f.MataPelajaranID)
+	}
+	return q
+}
+
+// queryAbsensiAnalitik: absensis a ⋈ jadwals j ⋈ semesters sm ⋈ kelas k ⋈ jurusans jr
+func queryAbsensiAnalitik(f FilterAnalitik) *gorm.DB {
+	q := config.DB.Table("absensis a").
+		Joins("JOIN jadwals j ON j.id = a.jadwal_id").
+		Joins("JOIN semesters sm ON sm.id = j.semester_id").
+		Joins("JOIN kelas k ON k.id = j.kelas_id").
+		Joins("JOIN jurusans jr ON jr.id = k.jurusan_id")
+	if f.Dari != nil {
+		q = q.Where("a.tanggal >= ?", *f.Dari)
+	}
+	if f.Sampai != nil {
+		q = q.Where("a.tanggal < ?", f.Sampai.AddDate(0, 0, 1))
+	}
+	return f.terapkan(q, "j.mata_pelajaran_id")
+}
+
+// queryNilaiAnalitik: nilais n ⋈ semesters sm ⋈ mata_pelajarans mp, ditambah
+// kelas k yang ditempati siswa pada tahun ajaran semester tersebut (riwayat
+// kelas terakhir, atau kelas saat ini untuk data lama tanpa riwayat).
+func queryNilaiAnalitik(f FilterAnalitik) *gorm.DB {
+	q := config.DB.Table("nilais n").
+		Joins("JOIN semesters sm ON sm.id = n.semester_id").
+		Joins("JOIN mata_pelajarans mp ON mp.id = n.mata_pelajaran_id").
+		Joins("JOIN siswas s ON s.id = n.siswa_id AND s.deleted_at IS NULL").
+		Joins(`LEFT JOIN LATERAL (
+			SELECT rk.kelas_id FROM riwayat_kelas rk
+			WHERE rk.siswa_id = n.siswa_id AND rk.tahun_ajaran_id = sm.tahun_ajaran_id
+			ORDER BY rk.tanggal_mulai DESC LIMIT 1
+		) rk ON true`).
+		Joins("LEFT JOIN kelas k ON k.id = COALESCE(rk.kelas_id, s.kelas_id) AND k.tahun_ajaran_id = sm.tahun_ajaran_id").
+		Joins("LEFT JOIN jurusans jr ON jr.id = k.jurusan_id")
+	return f.terapkan(q, "n.mata_pelajaran_id")
+}
+
+// ── Tren kehadiran ────────────────────────────────────────────
+
+// TitikKehadiran adalah rekap kehadiran satu kelompok pada satu periode
+type TitikKehadiran struct {
+	Periode         time.Time `json:"periode"` // awal minggu (Senin) / awal bulan
+	KelompokID      uint      `json:"kelompok_id"`
+	KelompokNama    string    `json:"kelompok_nama"`
+	Total           int64     `json:"total"`
+	Hadir           int64     `json:"hadir"`
+	Izin            int64     `json:"izin"`
+	Sakit           int64     `json:"sakit"`
+	Alfa            int64     `json:"alfa"`
+	PersentaseHadir float64   `json:"persentase_hadir"`
+}
+
+// kolomKelompok memetakan pengelompokan ke kolom id dan nama
+var kolomKelompok = map[string][2]string{
+	"sekolah": {"0", "'Sekolah'"},
+	"kelas":   {"k.id", "k.nama"},
+	"jurusan": {"jr.id", "jr.nama"},
+	"mapel":   {"mp.id", "mp.nama"},
+}
+
+// TrenKehadiran menghitung persentase kehadiran per minggu/bulan untuk setiap
+// kelompok (sekolah, kelas atau jurusan)
+func TrenKehadiran(f FilterAnalitik, periode, kelompok string) ([]TitikKehadiran, error) {
+	unit := "week"
+	if periode == "bulan" {
+		unit = "month"
+	}
+	kol := kolomKelompok[kelompok]
+
+	var hasil []TitikKehadiran
+	err := queryAbsensiAnalitik(f).
+		Select(`date_trunc('` + unit + `', a.tanggal) AS periode,
+			` + kol[0] + ` AS kelompok_id, ` + kol[1] + ` AS kelompok_nama,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE a.status = 'hadir') AS hadir,
+			COUNT(*) FILTER (WHERE a.status = 'izin') AS izin,
+			COUNT(*) FILTER (WHERE a.status = 'sakit') AS sakit,
+			COUNT(*) FILTER (WHERE a.status = 'alfa') AS alfa,
+			ROUND(100.0 * COUNT(*) FILTER (WHERE a.status = 'hadir') / COUNT(*), 2) AS persentase_hadir`).
+		Group("1, 2, 3").
+		Order("1, 3").
+		Scan(&hasil).Error
+	return hasil, err
+}
+
+// ── Distribusi nilai ──────────────────────────────────────────
+
+// DistribusiMapel adalah sebaran nilai akhir satu mata pelajaran
+type DistribusiMapel struct {
+	MataPelajaranID uint    `json:"mata_pelajaran_id"`
+	Kode            string  `json:"kode"`
+	Nama            string  `json:"nama"`
+	KKM             float64 `json:"kkm"`
+	Jumlah          int64   `json:"jumlah"`
+	RataRata        float64 `json:"rata_rata"`
+	Median          float64 `json:"median"`
+	Minimum         float64 `json:"minimum"`
+	Maksimum        float64 `json:"maksimum"`
+	Rentang0_59     int64   `json:"rentang_0_59" gorm:"column:rentang_0_59"`
+	Rentang60_69    int64   `json:"rentang_60_69" gorm:"column:rentang_60_69"`
+	Rentang70_79    int64   `json:"rentang_70_79" gorm:"column:rentang_70_79"`
+	Rentang80_89    int64   `json:"rentang_80_89" gorm:"column:rentang_80_89"`
+	Rentang90_100   int64   `json:"rentang_90_100" gorm:"column:rentang_90_100"`
+	PredikatA       int64   `json:"predikat_a"`
+	PredikatB       int64   `json:"predikat_b"`
+	PredikatC       int64   `json:"predikat_c"`
+	PredikatD       int64   `json:"predikat_d"`
+	PredikatE       int64   `json:"predikat_e"`
+	DibawahKKM      int64   `json:"dibawah_kkm" gorm:"column:dibawah_kkm"`
+}
+
+// DistribusiNilai menghitung sebaran nilai akhir per mata pelajaran
+func DistribusiNilai(f FilterAnalitik) ([]DistribusiMapel, error) {
+	var hasil []DistribusiMapel
+	err := queryNilaiAnalitik(f).
+		Select(`mp.id AS mata_pelajaran_id, mp.kode, mp.nama, mp.kkm,
+			COUNT(*) AS jumlah,
+			ROUND(AVG(n.nilai_akhir)::numeric, 2) AS rata_rata,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY n.nilai_akhir) AS median,
+			MIN(n.nilai_akhir) AS minimum,
+			MAX(n.nilai_akhir) AS maksimum,
+			COUNT(*) FILTER (WHERE n.nilai_akhir < 60) AS rentang_0_59,
+			COUNT(*) FILTER (WHERE n.nilai_akhir >= 60 AND n.nilai_akhir < 70) AS rentang_60_69,
+			COUNT(*) FILTER (WHERE n.nilai_akhir >= 70 AND n.nilai_akhir < 80) AS rentang_70_79,
+			COUNT(*) FILTER (WHERE n.nilai_akhir >= 80 AND n.nilai_akhir < 90) AS rentang_80_89,
+			COUNT(*) FILTER (WHERE n.nilai_akhir >= 90) AS rentang_90_100,
+			COUNT(*) FILTER (WHERE n.predikat = 'A') AS predikat_a,
+			COUNT(*) FILTER (WHERE n.predikat = 'B') AS predikat_b,
+			COUNT(*) FILTER (WHERE n.predikat = 'C') AS predikat_c,
+			COUNT(*) FILTER (WHERE n.predikat = 'D') AS predikat_d,
+			COUNT(*) FILTER (WHERE n.predikat = 'E') AS predikat_e,
+			COUNT(*) FILTER (WHERE n.nilai_akhir < mp.kkm) AS dibawah_kkm`).
+		Group("mp.id, mp.kode, mp.nama, mp.kkm").
+		Order("mp.nama").
+		Scan(&hasil).Error
+	return hasil, err
+}
+
+// ── Di bawah KKM ──────────────────────────────────────────────
+
+// RekapKKM adalah jumlah nilai dan siswa di bawah KKM untuk satu kelompok
+type RekapKKM struct {
+	KelompokID      uint    `json:"kelompok_id"`
+	KelompokNama    string  `json:"kelompok_nama"`
+	JumlahNilai     int64   `json:"jumlah_nilai"`
+	DibawahKKM      int64   `json:"dibawah_kkm" gorm:"column:dibawah_kkm"`
+	Persentase      float64 `json:"persentase"`
+	JumlahSiswa     int64   `json:"jumlah_siswa"`
+	SiswaDibawahKKM int64   `json:"siswa_dibawah_kkm" gorm:"column:siswa_dibawah_kkm"` // siswa dengan minimal satu nilai di bawah KKM
+}
+
+// RekapDibawahKKM menghitung nilai di bawah KKM per mapel, kelas atau jurusan
+func RekapDibawahKKM(f FilterAnalitik, kelompok string) ([]RekapKKM, error) {
+	kol := kolomKelompok[kelompok]
+	var hasil []RekapKKM
+	err := queryNilaiAnalitik(f).
+		Select(`COALESCE(` + kol[0] + `, 0) AS kelompok_id,
+			COALESCE(` + kol[1] + `, '(tanpa kelas)') AS kelompok_nama,
+			COUNT(*) AS jumlah_nilai,
+			COUNT(*) FILTER (WHERE n.nilai_akhir < mp.kkm) AS dibawah_kkm,
+			ROUND(100.0 * COUNT(*) FILTER (WHERE n.nilai_akhir < mp.kkm) / COUNT(*), 2) AS persentase,
+			COUNT(DISTINCT n.siswa_id) AS jumlah_siswa,
+			COUNT(DISTINCT n.siswa_id) FILTER (WHERE n.nilai_akhir < mp.kkm) AS siswa_dibawah_kkm`).
+		Group("1, 2").
+		Order("dibawah_kkm DESC, 2").
+		Scan(&hasil).Error
+	return hasil, err
+}
+
+// ── Kelengkapan input guru ────────────────────────────────────
+
+// queryPertemuanJadwal menjabarkan setiap jadwal menjadi tanggal pertemuan:
+// tanggal di dalam rentang semester yang harinya sama dengan hari_ke, sampai
+// kemarin (hari ini belum dianggap terlambat). Semester tanpa tanggal mulai dan
+// selesai tidak dapat dijabarkan sehingga dilewati.
+func queryPertemuanJadwal(f FilterAnalitik) *gorm.DB {
+	batasAwal := "sm.tanggal_mulai::date"
+	batasAkhir := "LEAST(sm.tanggal_selesai::date, CURRENT_DATE - 1)"
+	var args []interface{}
+	if f.Dari != nil {
+		batasAwal = "GREATEST(sm.tanggal_mulai::date, ?::date)"
+		args = append(args, f.Dari.Format("2006-01-02"))
+	}
+	if f.Sampai != nil {
+		batasAkhir = "LEAST(sm.tanggal_selesai::date, CURRENT_DATE - 1, ?::date)"
+		args = append(args, f.Sampai.Format("2006-01-02"))
+	}
+
+	q := config.DB.Table("jadwals j").
+		Joins("JOIN semesters sm ON sm.id = j.semester_id").
+		Joins("JOIN kelas k ON k.id = j.kelas_id").
+		Joins("CROSS JOIN LATERAL generate_series("+batasAwal+", "+batasAkhir+", interval '1 day') AS tgl(hari)", args...).
+		Where("sm.tanggal_mulai IS NOT NULL AND sm.tanggal_selesai IS NOT NULL").
+		Where("EXTRACT(ISODOW FROM tgl.hari) = j.hari_ke")
+	return f.terapkan(q, "j.mata_pelajaran_id")
+}
+
+// KelengkapanJadwal adalah rekap pengisian absensi satu jadwal
+type KelengkapanJadwal struct {
+	JadwalID        uint     `json:"jadwal_id"`
+	GuruID          uint     `json:"guru_id"`
+	NamaGuru        string   `json:"nama_guru"`
+	KelasID         uint     `json:"kelas_id"`
+	NamaKelas       string   `json:"nama_kelas"`
+	MataPelajaranID uint     `json:"mata_pelajaran_id"`
+	NamaMapel       string   `json:"nama_mapel"`
+	HariKe          int      `json:"hari_ke"`
+	JamMulai        string   `json:"jam_mulai"`
+	JumlahPertemuan int64    `json:"jumlah_pertemuan"`
+	Terisi          int64    `json:"terisi"`
+	Kosong          int64    `json:"kosong"`
+	Persentase      float64  `json:"persentase"`
+	DaftarKosong    string   `json:"-"`
+	TanggalKosong   []string `json:"tanggal_kosong" gorm:"-"`
+}
+
+// KelengkapanAbsensi mencari pertemuan terjadwal yang sudah lewat tetapi belum
+// memiliki satu pun absensi. Jika hanyaKosong bernilai true, jadwal yang sudah
+// lengkap tidak disertakan.
+func KelengkapanAbsensi(f FilterAnalitik, hanyaKosong bool) ([]KelengkapanJadwal, error) {
+	q := queryPertemuanJadwal(f).
+		Joins("JOIN gurus g ON g.id = j.guru_id").
+		Joins("JOIN mata_pelajarans mp ON mp.id = j.mata_pelajaran_id").
+		Select(`j.id AS jadwal_id, g.id AS guru_id, g.nama AS nama_guru,
+			k.id AS kelas_id, k.nama AS nama_kelas,
+			mp.id AS mata_pelajaran_id, mp.nama AS nama_mapel,
+			j.hari_ke, j.jam_mulai,
+			COUNT(*) AS jumlah_pertemuan,
+			COUNT(*) FILTER (WHERE ada.terisi) AS terisi,
+			COUNT(*) FILTER (WHERE NOT ada.terisi) AS kosong,
+			ROUND(100.0 * COUNT(*) FILTER (WHERE ada.terisi) / COUNT(*), 2) AS persentase,
+			string_agg(to_char(tgl.hari, 'YYYY-MM-DD'), ',' ORDER BY tgl.hari) FILTER (WHERE NOT ada.terisi) AS daftar_kosong`).
+		Joins(`CROSS JOIN LATERAL (SELECT EXISTS (
+			SELECT 1 FROM absensis a WHERE a.jadwal_id = j.id AND a.tanggal::date = tgl.hari::date
+		) AS terisi) ada`).
+		Group("j.id, g.id, g.nama, k.id, k.nama, mp.id, mp.nama, j.hari_ke, j.jam_mulai").
+		Order("persentase ASC, g.nama, k.nama")
+	if f.GuruID != 0 {
+		q = q.Where("j.guru_id = ?", f.GuruID)
+	}
+	if hanyaKosong {
+		q = q.Having("COUNT(*) FILTER (WHERE NOT ada.terisi) > 0")
+	}
+
+	var hasil []KelengkapanJadwal
+	if err := q.Scan(&hasil).Error; err != nil {
+		return nil, err
+	}
+	for i := range hasil {
+		hasil[i].TanggalKosong = []string{}
+		if hasil[i].DaftarKosong != "" {
+			hasil[i].TanggalKosong = strings.Split(hasil[i].DaftarKosong, ",")
+		}
+	}
+	return hasil, nil
+}
+
+// ── Perbandingan antar tahun ajaran ───────────────────────────
+
+// RingkasanTahunan adalah indikator utama satu tahun ajaran beserta selisihnya
+// terhadap tahun ajaran sebelumnya
+type RingkasanTahunan struct {
+	TahunAjaranID        uint     `json:"tahun_ajaran_id"`
+	TahunAjaran          string   `json:"tahun_ajaran"`
+	JumlahAbsensi        int64    `json:"jumlah_absensi"`
+	PersentaseHadir      *float64 `json:"persentase_hadir"`
+	JumlahSiswa          int64    `json:"jumlah_siswa"`
+	JumlahNilai          int64    `json:"jumlah_nilai"`
+	RataRataNilai        *float64 `json:"rata_rata_nilai"`
+	PersentaseDibawahKKM *float64 `json:"persentase_dibawah_kkm" gorm:"column:persentase_dibawah_kkm"`
+
+	SelisihHadir      *float64 `json:"selisih_hadir" gorm:"-"`
+	SelisihRataRata   *float64 `json:"selisih_rata_rata" gorm:"-"`
+	SelisihDibawahKKM *float64 `json:"selisih_dibawah_kkm" gorm:"-"`
+}
+
+// PerbandinganTahunan membandingkan kehadiran dan nilai antar tahun ajaran.
+// Filter tahun ajaran, semester dan kelas diabaikan karena ketiganya terikat
+// pada satu tahun ajaran; gunakan NamaSemester untuk membandingkan semester
+// yang sama (misalnya Ganjil) dari tahun ke tahun.
+func PerbandinganTahunan(f FilterAnalitik) ([]RingkasanTahunan, error) {
+	f.TahunAjaranID, f.SemesterID, f.KelasID = 0, 0, 0
+
+	absensi := queryAbsensiAnalitik(f).
+		Select(`sm.tahun_ajaran_id, COUNT(*) AS total,
+			ROUND(100.0 * COUNT(*) FILTER (WHERE a.status = 'hadir') / COUNT(*), 2) AS persen`).
+		Group("sm.tahun_ajaran_id")
+	nilai := queryNilaiAnalitik(f).
+		Select(`sm.tahun_ajaran_id, COUNT(*) AS total, COUNT(DISTINCT n.siswa_id) AS siswa,
+			ROUND(AVG(n.nilai_akhir)::numeric, 2) AS rata,
+			ROUND(100.0 * COUNT(*) FILTER (WHERE n.nilai_akhir < mp.kkm) / COUNT(*), 2) AS dibawah`).
+		Group("sm.tahun_ajaran_id")
+
+	var hasil []RingkasanTahunan
+	err := config.DB.Table("tahun_ajarans ta").
+		Select(`ta.id AS tahun_ajaran_id, ta.nama AS tahun_ajaran,
+			COALESCE(ab.total, 0) AS jumlah_absensi, ab.persen AS persentase_hadir,
+			COALESCE(nl.siswa, 0) AS jumlah_siswa, COALESCE(nl.total, 0) AS jumlah_nilai,
+			nl.rata AS rata_rata_nilai, nl.dibawah AS persentase_dibawah_kkm`).
+		Joins("LEFT JOIN (?) ab ON ab.tahun_ajaran_id = ta.id", absensi).
+		Joins("LEFT JOIN (?) nl ON nl.tahun_ajaran_id = ta.id", nilai).
+		Where("ab.total IS NOT NULL OR nl.total IS NOT NULL").
+		Order("ta.nama").
+		Scan(&hasil).Error
+	if err != nil {
+		return nil, err
+	}
+
+	selisih := func(kini, lalu *float64) *float64 {
+		if kini == nil || lalu == nil {
+			return nil
+		}
+		d := *kini - *lalu
+		return &d
+	}
+	for i := 1; i < len(hasil); i++ {
+		hasil[i].SelisihHadir = selisih(hasil[i].PersentaseHadir, hasil[i-1].PersentaseHadir)
+		hasil[i].SelisihRataRata = selisih(hasil[i].RataRataNilai, hasil[i-1].RataRataNilai)
+		hasil[i].SelisihDibawahKKM = selisih(hasil[i].PersentaseDibawahKKM, hasil[i-1].PersentaseDibawahKKM)
+	}
+	return hasil, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"strings"
+
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+// gabungFrasa merangkai daftar frasa menjadi "a, b, dan c"
+func gabungFrasa(frasa []string) string {
+	switch len(frasa) {
+	case 0:
+		return ""
+	case 1:
+		return frasa[0]
+	case 2:
+		return frasa[0] + " dan " + frasa[1]
+	}
+	return strings.Join(frasa[:len(frasa)-1], ", ") + ", dan " + frasa[len(frasa)-1]
+}
+
+// SusunDeskripsiCapaian membuat teks capaian kompetensi rapor dari TP yang
+// tercapai dan yang masih perlu bimbingan
+func SusunDeskripsiCapaian(namaSiswa string, tercapai, perluBimbingan []string) string {
+	var kalimat []string
+	if len(tercapai) > 0 {
+		kalimat = append(kalimat, "Ananda "+namaSiswa+" menunjukkan penguasaan yang baik dalam "+gabungFrasa(tercapai)+".")
+	}
+	if len(perluBimbingan) > 0 {
+		kalimat = append(kalimat, "Ananda "+namaSiswa+" perlu bimbingan dalam "+gabungFrasa(perluBimbingan)+".")
+	}
+	return strings.Join(kalimat, " ")
+}
+
+// DeskripsiCapaianSiswa mengembalikan teks capaian kompetensi per mapel
+// (mapel ID → deskripsi) untuk seorang siswa pada satu semester
+func DeskripsiCapaianSiswa(siswa models.Siswa, semesterID uint) map[uint]string {
+	var capaian []models.CapaianTujuanPembelajaran
+	config.DB.Preload("TujuanPembelajaran").
+		Joins("JOIN tujuan_pembelajarans tp ON tp.id = capaian_tujuan_pembelajarans.tujuan_pembelajaran_id").
+		Where("capaian_tujuan_pembelajarans.siswa_id = ? AND capaian_tujuan_pembelajarans.semester_id = ?", siswa.ID, semesterID).
+		Order("tp.mata_pelajaran_id, tp.urutan, tp.id").
+		Find(&capaian)
+
+	tercapai := map[uint][]string{}
+	perlu := map[uint][]string{}
+	for _, c := range capaian {
+		mapelID := c.TujuanPembelajaran.MataPelajaranID
+		if c.Status == models.CapaianTercapai {
+			tercapai[mapelID] = append(tercapai[mapelID], c.TujuanPembelajaran.Deskripsi)
+		} else {
+			perlu[mapelID] = append(perlu[mapelID], c.TujuanPembelajaran.Deskripsi)
+		}
+	}
+
+	hasil := map[uint]string{}
+	for mapelID := range tercapai {
+		hasil[mapelID] = SusunDeskripsiCapaian(siswa.Nama, tercapai[mapelID], perlu[mapelID])
+	}
+	for mapelID := range perlu {
+		if _, ok := hasil[mapelID]; !ok {
+			hasil[mapelID] = SusunDeskripsiCapaian(siswa.Nama, nil, perlu[mapelID])
+		}
+	}
+	return hasil
+}
+
+// ProjekP5Siswa adalah satu projek P5 beserta nilai dimensi seorang siswa
+type ProjekP5Siswa struct {
+	Projek models.ProjekP5  `json:"projek"`
+	Nilai  []models.NilaiP5 `json:"nilai"`
+}
+
+// P5Siswa mengambil projek P5 yang sudah dinilai untuk siswa pada semester tersebut
+func P5Siswa(siswaID, semesterID uint) []ProjekP5Siswa {
+	var nilai []models.NilaiP5
+	config.DB.Preload("ProjekP5").
+		Joins("JOIN projek_p5 ON projek_p5.id = nilai_p5.projek_p5_id").
+		Where("nilai_p5.siswa_id = ? AND projek_p5.semester_id = ?", siswaID, semesterID).
+		Order("nilai_p5.projek_p5_id, nilai_p5.id").
+		Find(&nilai)
+
+	var hasil []ProjekP5Siswa
+	indeks := map[uint]int{}
+	for _, n := range nilai {
+		i, ok := indeks[n.ProjekP5ID]
+		if !ok {
+			hasil = append(hasil, ProjekP5Siswa{Projek: n.ProjekP5})
+			i = len(hasil) - 1
+			indeks[n.ProjekP5ID] = i
+		}
+		hasil[i].Nilai = append(hasil[i].Nilai, n)
+	}
+	return hasil
+}
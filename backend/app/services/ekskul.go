@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+var ErrBukanAnggotaEkskul = errors.New("siswa bukan anggota ekstrakurikuler ini pada semester tersebut")
+
+// KehadiranEkskul adalah rekap presensi seorang anggota ekstrakurikuler
+type KehadiranEkskul struct {
+	SiswaID         uint    `json:"siswa_id"`
+	Hadir           int64   `json:"hadir"`
+	Izin            int64   `json:"izin"`
+	Sakit           int64   `json:"sakit"`
+	Alfa            int64   `json:"alfa"`
+	TotalPertemuan  int64   `json:"total_pertemuan"`
+	PersentaseHadir float64 `json:"persentase_hadir"`
+}
+
+// RekapKehadiranEkskul menghitung kehadiran seluruh anggota ekstrakurikuler
+// pada satu semester dalam satu query. Pertemuan yang belum dipresensi untuk
+// anggota tertentu tidak dihitung sebagai alfa.
+func RekapKehadiranEkskul(ekskulID, semesterID uint) map[uint]KehadiranEkskul {
+	var rows []KehadiranEkskul
+	config.DB.Table("absensi_ekskuls a").
+		Joins("JOIN pertemuan_ekskuls p ON p.id = a.pertemuan_id").
+		Where("p.ekskul_id = ? AND p.semester_id = ?", ekskulID, semesterID).
+		Select(`a.siswa_id,
+			COUNT(*) FILTER (WHERE a.status = 'hadir') AS hadir,
+			COUNT(*) FILTER (WHERE a.status = 'izin') AS izin,
+			COUNT(*) FILTER (WHERE a.status = 'sakit') AS sakit,
+			COUNT(*) FILTER (WHERE a.status = 'alfa') AS alfa,
+			COUNT(*) AS total_pertemuan`).
+		Group("a.siswa_id").
+		Scan(&rows)
+
+	hasil := make(map[uint]KehadiranEkskul, len(rows))
+	for _, r := range rows {
+		if r.TotalPertemuan > 0 {
+			r.PersentaseHadir = float64(r.Hadir) / float64(r.TotalPertemuan) * 100
+		}
+		hasil[r.SiswaID] = r
+	}
+	return hasil
+}
+
+// AbsensiEkskulMasuk adalah status kehadiran satu anggota yang dikirim pembina
+type AbsensiEkskulMasuk struct {
+	SiswaID uint   `json:"siswa_id" binding:"required"`
+	Status  string `json:"status" binding:"required,oneof=hadir izin sakit alfa"`
+}
+
+// SimpanAbsensiEkskul menyimpan (atau memperbarui) presensi anggota pada satu
+// pertemuan. Semua siswa harus terdaftar sebagai anggota pada semester pertemuan.
+func SimpanAbsensiEkskul(pertemuan models.PertemuanEkskul, list []AbsensiEkskulMasuk) error {
+	siswaIDs := make([]uint, 0, len(list))
+	for _, a := range list {
+		siswaIDs = append(siswaIDs, a.SiswaID)
+	}
+	var anggota int64
+	config.DB.Model(&models.AnggotaEkskul{}).
+		Where("ekskul_id = ? AND semester_id = ? AND siswa_id IN ?", pertemuan.EkskulID, pertemuan.SemesterID, siswaIDs).
+		Count(&anggota)
+	if int(anggota) != len(idUnik(siswaIDs)) {
+		return ErrBukanAnggotaEkskul
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, a := range list {
+			absen := models.AbsensiEkskul{PertemuanID: pertemuan.ID, SiswaID: a.SiswaID, Status: a.Status}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "pertemuan_id"}, {Name: "siswa_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"status", "updated_at"}),
+			}).Create(&absen).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func idUnik(ids []uint) []uint {
+	ada := make(map[uint]bool, len(ids))
+	var hasil []uint
+	for _, id := range ids {
+		if !ada[id] {
+			ada[id] = true
+			hasil = append(hasil, id)
+		}
+	}
+	return hasil
+}
+
+// NilaiEkskulSiswa adalah satu baris ekstrakurikuler seorang siswa untuk rapor
+type NilaiEkskulSiswa struct {
+	EkskulID   uint            `json:"ekskul_id"`
+	Nama       string          `json:"nama"`
+	Predikat   string          `json:"predikat"`
+	Keterangan string          `json:"keterangan"`
+	Kehadiran  KehadiranEkskul `json:"kehadiran"`
+}
+
+// EkskulSiswa mengambil ekstrakurikuler yang diikuti siswa pada satu semester
+// beserta predikat dari pembina. Jika pembina tidak menulis keterangan, rapor
+// memakai ringkasan kehadiran.
+func EkskulSiswa(siswaID, semesterID uint) []NilaiEkskulSiswa {
+	var anggota []models.AnggotaEkskul
+	config.DB.Preload("Ekskul").
+		Joins("JOIN ekstrakurikulers e ON e.id = anggota_ekskuls.ekskul_id").
+		Where("anggota_ekskuls.siswa_id = ? AND anggota_ekskuls.semester_id = ?", siswaID, semesterID).
+		Order("e.is_wajib DESC, e.nama ASC").
+		Find(&anggota)
+	if len(anggota) == 0 {
+		return nil
+	}
+
+	var ekskulIDs []uint
+	for _, a := range anggota {
+		ekskulIDs = append(ekskulIDs, a.EkskulID)
+	}
+	type kehadiranPerEkskul struct {
+		EkskulID uint
+		KehadiranEkskul
+	}
+	var perEkskul []kehadiranPerEkskul
+	config.DB.Table("absensi_ekskuls a").
+		Joins("JOIN pertemuan_ekskuls p ON p.id = a.pertemuan_id").
+		Where("a.siswa_id = ? AND p.semester_id = ? AND p.ekskul_id IN ?", siswaID, semesterID, ekskulIDs).
+		Select(`p.ekskul_id, a.siswa_id,
+			COUNT(*) FILTER (WHERE a.status = 'hadir') AS hadir,
+			COUNT(*) FILTER (WHERE a.status = 'izin') AS izin,
+			COUNT(*) FILTER (WHERE a.status = 'sakit') AS sakit,
+			COUNT(*) FILTER (WHERE a.status = 'alfa') AS alfa,
+			COUNT(*) AS total_pertemuan`).
+		Group("p.ekskul_id, a.siswa_id").
+		Scan(&perEkskul)
+
+	kehadiran := make(map[uint]KehadiranEkskul, len(perEkskul))
+	for _, k := range perEkskul {
+		if k.TotalPertemuan > 0 {
+			k.PersentaseHadir = float64(k.Hadir) / float64(k.TotalPertemuan) * 100
+		}
+		kehadiran[k.EkskulID] = k.KehadiranEkskul
+	}
+
+	hasil := make([]NilaiEkskulSiswa, 0, len(anggota))
+	for _, a := range anggota {
+		k := kehadiran[a.EkskulID]
+		k.SiswaID = siswaID
+		keterangan := a.Keterangan
+		if keterangan == "" && k.TotalPertemuan > 0 {
+			keterangan = fmt.Sprintf("Hadir %d dari %d pertemuan", k.Hadir, k.TotalPertemuan)
+		}
+		hasil = append(hasil, NilaiEkskulSiswa{
+			EkskulID:   a.EkskulID,
+			Nama:       a.Ekskul.Nama,
+			Predikat:   a.Predikat,
+			Keterangan: keterangan,
+			Kehadiran:  k,
+		})
+	}
+	return hasil
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+var (
+	ErrAPIKeyPerangkatTidakValid = errors.New("API key perangkat tidak valid atau perangkat nonaktif")
+	ErrEventIDKosong             = errors.New("event_id tidak boleh kosong")
+)
+
+// awalanAPIKeyPerangkat membedakan API key perangkat dari token lain
+const awalanAPIKeyPerangkat = "gtw_"
+
+// KeteranganAbsensiGerbang mengisi keterangan absensi yang dibuat dari tap gerbang
+const KeteranganAbsensiGerbang = "Tap kartu gerbang"
+
+// BuatAPIKeyPerangkat membuat API key baru berbentuk gtw_{prefix}_{rahasia}.
+// Prefix disimpan apa adanya untuk mencari perangkat, rahasianya hanya hash.
+func BuatAPIKeyPerangkat() (key, prefix, hash string, err error) {
+	prefix, err = TokenAcak(4)
+	if err != nil {
+		return
+	}
+	rahasia, err := TokenAcak(24)
+	if err != nil {
+		return
+	}
+	key = awalanAPIKeyPerangkat + prefix + "_" + rahasia
+	return key, prefix, HashToken(key), nil
+}
+
+// PerangkatDariAPIKey mencari perangkat aktif pemilik API key
+func PerangkatDariAPIKey(key string) (models.PerangkatGerbang, error) {
+	var perangkat models.PerangkatGerbang
+	bagian := strings.SplitN(strings.TrimPrefix(key, awalanAPIKeyPerangkat), "_", 2)
+	if !strings.HasPrefix(key, awalanAPIKeyPerangkat) || len(bagian) != 2 {
+		return perangkat, ErrAPIKeyPerangkatTidakValid
+	}
+	if err := config.DB.Where("key_prefix = ?", bagian[0]).First(&perangkat).Error; err != nil {
+		return perangkat, ErrAPIKeyPerangkatTidakValid
+	}
+	if subtle.ConstantTimeCompare([]byte(perangkat.KeyHash), []byte(HashToken(key))) != 1 || !perangkat.IsAktif {
+		return perangkat, ErrAPIKeyPerangkatTidakValid
+	}
+	return perangkat, nil
+}
+
+// NormalisasiUID menyeragamkan UID kartu: huruf besar tanpa pemisah, sehingga
+// "04:a1:b2:c3" dan "04A1B2C3" dianggap kartu yang sama
+func NormalisasiUID(uid string) string {
+	return strings.ToUpper(strings.NewReplacer(":", "", "-", "", " ", "").Replace(strings.TrimSpace(uid)))
+}
+
+// jedaTapUlang: tap berikutnya dalam jeda ini dianggap tap ulang, bukan pulang
+func jedaTapUlang() time.Duration {
+	return time.Duration(config.GetEnvInt("GERBANG_JEDA_TAP_MENIT", 5)) * time.Minute
+}
+
+// TapMasuk adalah satu event tap yang dikirim perangkat
+type TapMasuk struct {
+	UID     string    `json:"uid" binding:"required,max=50"`
+	Waktu   time.Time `json:"waktu"`                              // RFC3339; kosong = waktu server saat diterima
+	EventID string    `json:"event_id" binding:"required,max=64"` // ID unik dari perangkat untuk mencegah tap tercatat dua kali
+}
+
+// HasilTap dikembalikan ke perangkat untuk ditampilkan di layarnya
+type HasilTap struct {
+	EventID        string     `json:"event_id"`
+	UID            string     `json:"uid"`
+	Status         string     `json:"status"`
+	Pesan          string     `json:"pesan,omitempty"`
+	Arah           string     `json:"arah,omitempty"`
+	Pemilik        string     `json:"pemilik,omitempty"`
+	PemilikID      *uint      `json:"pemilik_id,omitempty"`
+	Nama           string     `json:"nama,omitempty"`
+	JamDatang      *time.Time `json:"jam_datang,omitempty"`
+	JamPulang      *time.Time `json:"jam_pulang,omitempty"`
+	Terlambat      bool       `json:"terlambat"`
+	MenitTerlambat int        `json:"menit_terlambat"`
+}
+
+// jadwalBelHari memuat jadwal bel untuk hari tanggal tersebut (1=Senin … 7=Minggu)
+func jadwalBelHari(tx *gorm.DB, t time.Time) (models.JadwalBel, bool) {
+	hariKe := int(t.Weekday())
+	if hariKe == 0 {
+		hariKe = 7
+	}
+	var bel models.JadwalBel
+	if err := tx.Where("hari_ke = ?", hariKe).First(&bel).Error; err != nil {
+		return bel, false
+	}
+	return bel, true
+}
+
+// hitungTerlambat membandingkan jam datang dengan jam masuk pada jadwal bel
+func hitungTerlambat(bel models.JadwalBel, datang time.Time) (bool, int) {
+	jam, err := time.ParseInLocation("15:04", bel.JamMasuk, datang.Location())
+	if err != nil {
+		return false, 0
+	}
+	masuk := time.Date(datang.Year(), datang.Month(), datang.Day(), jam.Hour(), jam.Minute(), 0, 0, datang.Location())
+	menit := int(datang.Sub(masuk).Minutes())
+	if menit <= bel.ToleransiMenit {
+		return false, 0
+	}
+	return true, menit
+}
+
+// ProsesTap mencatat satu tap dan memperbarui jam datang/pulang pemilik kartu.
+// Tap pertama dalam sehari adalah jam datang, tap berikutnya setelah jeda tap
+// ulang menjadi jam pulang. Tap offline yang lebih awal dari jam datang yang
+// tercatat menggeser jam datang, sehingga urutan upload tidak berpengaruh.
+func ProsesTap(perangkat models.PerangkatGerbang, tap TapMasuk, offline bool) (HasilTap, error) {
+	uid := NormalisasiUID(tap.UID)
+	waktu := tap.Waktu
+	if waktu.IsZero() {
+		waktu = time.Now()
+	}
+	waktu = waktu.In(time.Local)
+	eventID := strings.TrimSpace(tap.EventID)
+	hasil := HasilTap{EventID: eventID, UID: uid}
+	if eventID == "" {
+		return hasil, ErrEventIDKosong
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var ada models.TapGerbang
+		if tx.Where("perangkat_id = ? AND event_id = ?", perangkat.ID, eventID).First(&ada).Error == nil {
+			hasil.Status, hasil.Arah = models.TapDuplikat, ada.Arah
+			hasil.Pesan = "Tap ini sudah tercatat sebelumnya"
+			return nil
+		}
+
+		catatan := models.TapGerbang{
+			PerangkatID: perangkat.ID,
+			EventID:     eventID,
+			UID:         uid,
+			Waktu:       waktu,
+			Offline:     offline,
+		}
+
+		var kartu models.KartuAkses
+		if err := tx.Preload("Siswa").Preload("Guru").
+			Where("uid = ? AND is_aktif = ?", uid, true).First(&kartu).Error; err != nil {
+			catatan.Status = models.TapKartuTidakDikenal
+			hasil.Status, hasil.Pesan = models.TapKartuTidakDikenal, "Kartu belum terdaftar"
+			return tx.Create(&catatan).Error
+		}
+		var pemilikID uint
+		switch {
+		case kartu.SiswaID != nil && kartu.Siswa != nil:
+			hasil.Pemilik, pemilikID, hasil.Nama = models.PemilikSiswa, *kartu.SiswaID, kartu.Siswa.Nama
+		case kartu.GuruID != nil && kartu.Guru != nil:
+			hasil.Pemilik, pemilikID, hasil.Nama = models.PemilikGuru, *kartu.GuruID, kartu.Guru.Nama
+		default:
+			catatan.Status = models.TapKartuTidakDikenal
+			hasil.Status, hasil.Pesan = models.TapKartuTidakDikenal, "Pemilik kartu sudah tidak ada"
+			return tx.Create(&catatan).Error
+		}
+		hasil.PemilikID = &pemilikID
+
+		// Baris harian dibuat jika belum ada, lalu dikunci agar tap bersamaan
+		// dari dua perangkat tidak saling menimpa
+		tanggal := time.Date(waktu.Year(), waktu.Month(), waktu.Day(), 0, 0, 0, 0, time.UTC)
+		bel, adaBel := jadwalBelHari(tx, waktu)
+		baru := models.KehadiranGerbang{Tanggal: tanggal, Pemilik: hasil.Pemilik, PemilikID: pemilikID, JamDatang: waktu}
+		if adaBel {
+			baru.Terlambat, baru.MenitTerlambat = hitungTerlambat(bel, waktu)
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&baru)
+		if res.Error != nil {
+			return res.Error
+		}
+		var k models.KehadiranGerbang
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tanggal = ? AND pemilik = ? AND pemilik_id = ?", tanggal.Format("2006-01-02"), hasil.Pemilik, pemilikID).
+			First(&k).Error; err != nil {
+			return err
+		}
+
+		jeda := jedaTapUlang()
+		switch {
+		case res.RowsAffected == 1:
+			hasil.Arah = models.TapDatang
+		case waktu.Before(k.JamDatang):
+			if k.JamPulang == nil && k.JamDatang.Sub(waktu) >= jeda {
+				lama := k.JamDatang
+				k.JamPulang = &lama
+			}
+			k.JamDatang = waktu
+			k.Terlambat, k.MenitTerlambat = false, 0
+			if adaBel {
+				k.Terlambat, k.MenitTerlambat = hitungTerlambat(bel, waktu)
+			}
+			hasil.Arah = models.TapDatang
+		case waktu.Sub(k.JamDatang) < jeda:
+			hasil.Arah = models.TapBerulang
+		case k.JamPulang == nil || waktu.After(*k.JamPulang):
+			k.JamPulang = &waktu
+			hasil.Arah = models.TapPulang
+		default:
+			hasil.Arah = models.TapBerulang
+		}
+		if res.RowsAffected == 0 && hasil.Arah != models.TapBerulang {
+			if err := tx.Save(&k).Error; err != nil {
+				return err
+			}
+		}
+
+		hasil.Status = models.TapDiterima
+		hasil.JamDatang, hasil.JamPulang = &k.JamDatang, k.JamPulang
+		hasil.Terlambat, hasil.MenitTerlambat = k.Terlambat, k.MenitTerlambat
+		catatan.KartuID, catatan.Pemilik, catatan.PemilikID = &kartu.ID, hasil.Pemilik, &pemilikID
+		catatan.Arah, catatan.Status = hasil.Arah, models.TapDiterima
+		return tx.Create(&catatan).Error
+	})
+	return hasil, err
+}
+
+// ProsesBatchTap memproses tap yang disimpan perangkat selama offline,
+// diurutkan berdasarkan waktu tap. Kegagalan satu tap tidak menghentikan yang lain.
+func ProsesBatchTap(perangkat models.PerangkatGerbang, taps []TapMasuk) []HasilTap {
+	sort.SliceStable(taps, func(a, b int) bool { return taps[a].Waktu.Before(taps[b].Waktu) })
+	hasil := make([]HasilTap, 0, len(taps))
+	for _, t := range taps {
+		h, err := ProsesTap(perangkat, t, true)
+		if err != nil {
+			h.Status, h.Pesan = "gagal", "Gagal menyimpan tap"
+		}
+		hasil = append(hasil, h)
+	}
+	return hasil
+}
+
+// ── Rekap harian ──────────────────────────────────────────────
+
+// KehadiranGerbangSiswa adalah baris laporan kehadiran gerbang per orang
+type KehadiranGerbangSiswa struct {
+	Pemilik        string     `json:"pemilik"`
+	PemilikID      uint       `json:"pemilik_id"`
+	Nama           string     `json:"nama"`
+	NomorInduk     string     `json:"nomor_induk"` // NIS siswa / NIP guru
+	NamaKelas      string     `json:"nama_kelas,omitempty"`
+	JamDatang      *time.Time `json:"jam_datang"` // nil = belum tap hari itu
+	JamPulang      *time.Time `json:"jam_pulang"`
+	Terlambat      bool       `json:"terlambat"`
+	MenitTerlambat int        `json:"menit_terlambat"`
+}
+
+// FilterKehadiranGerbang membatasi laporan kehadiran gerbang satu tanggal
+type FilterKehadiranGerbang struct {
+	Tanggal        time.Time
+	Pemilik        string // siswa (default) / guru
+	KelasID        uint
+	HanyaTerlambat bool
+	HanyaBelumTap  bool
+}
+
+// LaporanKehadiranGerbang menampilkan semua siswa aktif (atau guru) beserta jam
+// datang dan pulangnya pada tanggal tersebut, termasuk yang belum tap
+func LaporanKehadiranGerbang(f FilterKehadiranGerbang) ([]KehadiranGerbangSiswa, error) {
+	tanggal := f.Tanggal.Format("2006-01-02")
+	var q *gorm.DB
+	if f.Pemilik == models.PemilikGuru {
+		q = config.DB.Table("gurus p").
+			Select(`'guru' AS pemilik, p.id AS pemilik_id, p.nama, p.nip AS nomor_induk,
+				kg.jam_datang, kg.jam_pulang, COALESCE(kg.terlambat, false) AS terlambat,
+				COALESCE(kg.menit_terlambat, 0) AS menit_terlambat`).
+			Joins("LEFT JOIN kehadiran_gerbangs kg ON kg.pemilik = 'guru' AND kg.pemilik_id = p.id AND kg.tanggal = ?", tanggal).
+			Where("p.deleted_at IS NULL")
+	} else {
+		q = config.DB.Table("siswas s").
+			Select(`'siswa' AS pemilik, s.id AS pemilik_id, s.nama, s.nis AS nomor_induk, k.nama AS nama_kelas,
+				kg.jam_datang, kg.jam_pulang, COALESCE(kg.terlambat, false) AS terlambat,
+				COALESCE(kg.menit_terlambat, 0) AS menit_terlambat`).
+			Joins("LEFT JOIN kelas k ON k.id = s.kelas_id").
+			Joins("LEFT JOIN kehadiran_gerbangs kg ON kg.pemilik = 'siswa' AND kg.pemilik_id = s.id AND kg.tanggal = ?", tanggal).
+			Where("s.deleted_at IS NULL AND s.status = ?", "aktif")
+		if f.KelasID != 0 {
+			q = q.Where("s.kelas_id = ?", f.KelasID)
+		}
+	}
+	if f.HanyaTerlambat {
+		q = q.Where("kg.terlambat = ?", true)
+	}
+	if f.HanyaBelumTap {
+		q = q.Where("kg.id IS NULL")
+	}
+
+	urutan := "nama_kelas, nama"
+	if f.Pemilik == models.PemilikGuru {
+		urutan = "nama"
+	}
+	var hasil []KehadiranGerbangSiswa
+	err := q.Order(urutan).Scan(&hasil).Error
+	return hasil, err
+}
+
+// IsiAbsensiDariGerbang mencatat hadir pada setiap jadwal tanggal tersebut
+// untuk siswa yang menurut tap gerbang berada di sekolah selama jam pelajaran
+// itu. Absensi yang sudah diinput guru tidak diubah dan siswa yang tidak tap
+// tidak dicatat alfa, karena bisa saja izin atau sakit.
+func IsiAbsensiDariGerbang(ctx context.Context, tanggal time.Time) (int64, error) {
+	tgl := tanggal.Format("2006-01-02")
+	now := time.Now()
+	res := config.DB.WithContext(ctx).Exec(`INSERT INTO absensis (siswa_id, jadwal_id, tanggal, status, keterangan, created_at, updated_at)
+		SELECT s.id, j.id, ?::date, 'hadir', ?, ?, ?
+		FROM jadwals j
+		JOIN semesters sm ON sm.id = j.semester_id
+		JOIN kelas k ON k.id = j.kelas_id
+		JOIN siswas s ON `+kondisiSiswaDiKelas+`
+		JOIN kehadiran_gerbangs kg ON kg.pemilik = 'siswa' AND kg.pemilik_id = s.id AND kg.tanggal = ?::date
+		WHERE j.hari_ke = EXTRACT(ISODOW FROM ?::date)
+		AND sm.is_aktif = true
+		AND (sm.tanggal_mulai IS NULL OR sm.tanggal_mulai::date <= ?::date)
+		AND (sm.tanggal_selesai IS NULL OR sm.tanggal_selesai::date >= ?::date)
+		AND kg.jam_datang::time < j.jam_selesai::time
+		AND (kg.jam_pulang IS NULL OR kg.jam_pulang::time > j.jam_mulai::time)
+		AND NOT EXISTS (SELECT 1 FROM absensis a
+			WHERE a.siswa_id = s.id AND a.jadwal_id = j.id AND DATE(a.tanggal) = ?::date)`,
+		tgl, KeteranganAbsensiGerbang, now, now, tgl, tgl, tgl, tgl, tgl)
+	return res.RowsAffected, res.Error
+}
+
+// JobAbsensiGerbang mengisi absensi per jadwal dari tap gerbang
+const JobAbsensiGerbang = "absensi_dari_gerbang"
+
+type payloadAbsensiGerbang struct {
+	Tanggal string `json:"tanggal,omitempty"` // YYYY-MM-DD; kosong = hari ini
+}
+
+// AntrikanAbsensiGerbang mengantrikan pengisian absensi dari tap gerbang
+func AntrikanAbsensiGerbang(tanggal string) (*models.Job, error) {
+	return AntrikanJob(JobAbsensiGerbang, payloadAbsensiGerbang{Tanggal: tanggal})
+}
+
+func isiAbsensiGerbang(ctx context.Context, _ *models.Job, p payloadAbsensiGerbang) error {
+	tanggal := time.Now()
+	if p.Tanggal != "" {
+		t, err := time.Parse("2006-01-02", p.Tanggal)
+		if err != nil {
+			return err
+		}
+		tanggal = t
+	}
+	_, err := IsiAbsensiDariGerbang(ctx, tanggal)
+	return err
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"sim-sekolah/app/models"
+)
+
+func TestNormalisasiUID(t *testing.T) {
+	tests := []struct {
+		masuk, ingin string
+	}{
+		{"04A1B2C3", "04A1B2C3"},
+		{"04:a1:b2:c3", "04A1B2C3"},
+		{"04-A1-B2-C3", "04A1B2C3"},
+		{"04 a1 b2 c3", "04A1B2C3"},
+		{"  04a1b2c3\n", "04A1B2C3"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalisasiUID(tt.masuk); got != tt.ingin {
+			t.Errorf("NormalisasiUID(%q) = %q, ingin %q", tt.masuk, got, tt.ingin)
+		}
+	}
+}
+
+func TestHitungTerlambat(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*3600)
+	bel := models.JadwalBel{HariKe: 1, JamMasuk: "07:00", ToleransiMenit: 5}
+	jam := func(h, m, d int) time.Time { return time.Date(2025, 7, 14, h, m, d, 0, wib) }
+
+	tests := []struct {
+		nama      string
+		bel       models.JadwalBel
+		datang    time.Time
+		terlambat bool
+		menit     int
+	}{
+		{"datang lebih awal", bel, jam(6, 45, 0), false, 0},
+		{"tepat jam masuk", bel, jam(7, 0, 0), false, 0},
+		{"masih dalam toleransi", bel, jam(7, 5, 0), false, 0},
+		{"detik terakhir toleransi", bel, jam(7, 5, 59), false, 0},
+		{"lewat toleransi", bel, jam(7, 6, 0), true, 6},
+		{"terlambat lama", bel, jam(9, 30, 0), true, 150},
+		{"tanpa toleransi", models.JadwalBel{JamMasuk: "07:00"}, jam(7, 1, 0), true, 1},
+		{"jam masuk tidak valid", models.JadwalBel{JamMasuk: "7 pagi"}, jam(9, 0, 0), false, 0},
+	}
+	for _, tt := range tests {
+		terlambat, menit := hitungTerlambat(tt.bel, tt.datang)
+		if terlambat != tt.terlambat || menit != tt.menit {
+			t.Errorf("%s: hitungTerlambat = (%v, %d), ingin (%v, %d)", tt.nama, terlambat, menit, tt.terlambat, tt.menit)
+		}
+	}
+}
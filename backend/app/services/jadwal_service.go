@@ -65,7 +65,7 @@ func ValidasiKonflikJadwal(
 				Keterangan: fmt.Sprintf(
 					"Guru sudah mengajar %s di kelas %s pada %s %s–%s",
 					j.MataPelajaran.Nama, j.Kelas.Nama,
-					namaHari(hariKe), j.JamMulai, j.JamSelesai,
+					NamaHari(hariKe), j.JamMulai, j.JamSelesai,
 				),
 				Jadwal: j,
 			})
@@ -79,7 +79,7 @@ func ValidasiKonflikJadwal(
 				Keterangan: fmt.Sprintf(
 					"Kelas sudah memiliki pelajaran %s diajar %s pada %s %s–%s",
 					j.MataPelajaran.Nama, j.Guru.Nama,
-					namaHari(hariKe), j.JamMulai, j.JamSelesai,
+					NamaHari(hariKe), j.JamMulai, j.JamSelesai,
 				),
 				Jadwal: j,
 			})
@@ -103,7 +103,7 @@ func GetJadwalMingguanGuru(guruID, semesterID uint) map[string][]models.Jadwal {
 
 	hasil := map[string][]models.Jadwal{}
 	for _, j := range jadwalList {
-		hari := namaHari(j.HariKe)
+		hari := NamaHari(j.HariKe)
 		hasil[hari] = append(hasil[hari], j)
 	}
 	return hasil
@@ -122,17 +122,17 @@ func GetJadwalMingguanKelas(kelasID, semesterID uint) map[string][]models.Jadwal
 
 	hasil := map[string][]models.Jadwal{}
 	for _, j := range jadwalList {
-		hari := namaHari(j.HariKe)
+		hari := NamaHari(j.HariKe)
 		hasil[hari] = append(hasil[hari], j)
 	}
 	return hasil
 }
 
-// namaHari mengubah int hari ke nama hari
-func namaHari(hariKe int) string {
+// NamaHari mengubah int hari ke nama hari
+func NamaHari(hariKe int) string {
 	nama := map[int]string{
 		1: "Senin", 2: "Selasa", 3: "Rabu",
-		4: "Kamis", 5: "Jumat", 6: "Sabtu",
+		4: "Kamis", 5: "Jumat", 6: "Sabtu", 7: "Minggu",
 	}
 	if n, ok := nama[hariKe]; ok {
 		return n
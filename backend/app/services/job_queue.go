@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+// handlerJob menjalankan satu job; payload sudah di-decode oleh pembungkus
+// yang dibuat DaftarkanHandlerJob
+type handlerJob func(ctx context.Context, job *models.Job) error
+
+var (
+	handlerJobMu sync.RWMutex
+	handlerJobs  = map[string]handlerJob{}
+)
+
+// errPermanen menandai error yang tidak perlu dicoba ulang
+type errPermanen struct{ err error }
+
+func (e errPermanen) Error() string { return e.err.Error() }
+func (e errPermanen) Unwrap() error { return e.err }
+
+// GagalPermanen membungkus error agar job langsung berstatus gagal tanpa
+// dicoba ulang (misalnya data yang dirujuk payload sudah dihapus)
+func GagalPermanen(err error) error {
+	return errPermanen{err: err}
+}
+
+// DaftarkanHandlerJob mendaftarkan handler untuk satu tipe job. Payload job
+// (JSON) di-decode ke T sebelum handler dipanggil; payload yang tidak bisa
+// di-decode membuat job langsung gagal.
+func DaftarkanHandlerJob[T any](tipe string, fn func(ctx context.Context, job *models.Job, payload T) error) {
+	handlerJobMu.Lock()
+	defer handlerJobMu.Unlock()
+	if _, ada := handlerJobs[tipe]; ada {
+		panic("handler job sudah terdaftar: " + tipe)
+	}
+	handlerJobs[tipe] = func(ctx context.Context, job *models.Job) error {
+		var payload T
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return GagalPermanen(fmt.Errorf("payload tidak valid: %w", err))
+		}
+		return fn(ctx, job, payload)
+	}
+}
+
+func cariHandlerJob(tipe string) (handlerJob, bool) {
+	handlerJobMu.RLock()
+	defer handlerJobMu.RUnlock()
+	h, ok := handlerJobs[tipe]
+	return h, ok
+}
+
+// OpsiJob mengatur kapan job dijalankan dan berapa kali boleh dicoba
+type OpsiJob struct {
+	JalankanPada  time.Time // kosong = secepatnya
+	MaksPercobaan int       // 0 = JOB_MAKS_PERCOBAAN
+}
+
+// AntrikanJob memasukkan job baru ke antrian
+func AntrikanJob(tipe string, payload interface{}, opsi ...OpsiJob) (*models.Job, error) {
+	return AntrikanJobTx(config.DB, tipe, payload, opsi...)
+}
+
+// AntrikanJobTx sama dengan AntrikanJob tetapi memakai transaksi pemanggil,
+// sehingga job hanya masuk antrian jika transaksi berhasil di-commit
+func AntrikanJobTx(tx *gorm.DB, tipe string, payload interface{}, opsi ...OpsiJob) (*models.Job, error) {
+	isi, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	job := models.Job{
+		Tipe:          tipe,
+		Payload:       string(isi),
+		Status:        models.JobAntri,
+		JalankanPada:  time.Now(),
+		MaksPercobaan: config.GetEnvInt("JOB_MAKS_PERCOBAAN", 5),
+	}
+	if len(opsi) > 0 {
+		if !opsi[0].JalankanPada.IsZero() {
+			job.JalankanPada = opsi[0].JalankanPada
+		}
+		if opsi[0].MaksPercobaan > 0 {
+			job.MaksPercobaan = opsi[0].MaksPercobaan
+		}
+	}
+	if err := tx.Create(&job).Error; err != nil {
+		return nil, err
+	}
+	bangunkanWorker()
+	return &job, nil
+}
+
+// ── Pengambilan & eksekusi job ────────────────────────────────
+
+// ambilJob mengunci satu job yang sudah jatuh tempo. FOR UPDATE SKIP LOCKED
+// membuat beberapa worker (juga dari instance server lain) tidak pernah
+// mengambil job yang sama.
+func ambilJob(workerID string) (*models.Job, error) {
+	var job models.Job
+	err := config.DB.Raw(`
+		UPDATE jobs SET
+			status = ?, percobaan = percobaan + 1,
+			dikunci_oleh = ?, dikunci_pada = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = ? AND jalankan_pada <= NOW()
+			ORDER BY jalankan_pada, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING *`,
+		models.JobProses, workerID, models.JobAntri,
+	).Scan(&job).Error
+	if err != nil || job.ID == 0 {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// jedaPercobaan: 30 detik, 1 menit, 2 menit, ... maksimal 1 jam
+func jedaPercobaan(percobaan int) time.Duration {
+	jeda := 30 * time.Second
+	for i := 1; i < percobaan && jeda < time.Hour; i++ {
+		jeda *= 2
+	}
+	if jeda > time.Hour {
+		jeda = time.Hour
+	}
+	return jeda
+}
+
+// jalankanJob mengeksekusi handler lalu mencatat hasilnya. Panic di handler
+// ditangkap dan diperlakukan sebagai error biasa.
+func jalankanJob(ctx context.Context, workerID string, job *models.Job) {
+	mulai := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("[job] panic pada job %d (%s): %v\n%s", job.ID, job.Tipe, r, debug.Stack())
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		handler, ok := cariHandlerJob(job.Tipe)
+		if !ok {
+			return GagalPermanen(fmt.Errorf("tidak ada handler untuk tipe job %q", job.Tipe))
+		}
+		return handler(ctx, job)
+	}()
+
+	now := time.Now()
+	update := map[string]interface{}{
+		"dikunci_oleh": "",
+		"dikunci_pada": nil,
+	}
+	var permanen errPermanen
+	switch {
+	case err == nil:
+		update["status"] = models.JobSelesai
+		update["selesai_pada"] = now
+		update["error_terakhir"] = ""
+	case errors.As(err, &permanen) || job.Percobaan >= job.MaksPercobaan:
+		update["status"] = models.JobGagal
+		update["selesai_pada"] = now
+		update["error_terakhir"] = err.Error()
+		log.Printf("[job] job %d (%s) gagal setelah %d percobaan: %v", job.ID, job.Tipe, job.Percobaan, err)
+	default:
+		update["status"] = models.JobAntri
+		update["jalankan_pada"] = now.Add(jedaPercobaan(job.Percobaan))
+		update["error_terakhir"] = err.Error()
+		log.Printf("[job] job %d (%s) percobaan %d gagal, dicoba lagi: %v", job.ID, job.Tipe, job.Percobaan, err)
+	}
+
+	// Hanya perbarui jika job masih dikunci worker ini (belum diambil alih
+	// oleh pemulihan kunci kadaluarsa)
+	config.DB.Model(&models.Job{}).
+		Where("id = ? AND dikunci_oleh = ?", job.ID, workerID).
+		Updates(update)
+
+	if err == nil {
+		log.Printf("[job] job %d (%s) selesai dalam %s", job.ID, job.Tipe, now.Sub(mulai).Round(time.Millisecond))
+	}
+}
+
+// pulihkanJobMacet mengembalikan job yang terkunci terlalu lama (misalnya
+// server mati saat job berjalan) ke antrian, atau menandainya gagal jika
+// percobaannya sudah habis
+func pulihkanJobMacet() {
+	batas := time.Now().Add(-time.Duration(config.GetEnvInt("JOB_BATAS_KUNCI_MENIT", 60)) * time.Minute)
+	pesan := "job terkunci terlalu lama (worker berhenti di tengah jalan)"
+
+	config.DB.Model(&models.Job{}).
+		Where("status = ? AND dikunci_pada < ? AND percobaan < maks_percobaan", models.JobProses, batas).
+		Updates(map[string]interface{}{
+			"status": models.JobAntri, "jalankan_pada": time.Now(),
+			"dikunci_oleh": "", "dikunci_pada": nil, "error_terakhir": pesan,
+		})
+	config.DB.Model(&models.Job{}).
+		Where("status = ? AND dikunci_pada < ?", models.JobProses, batas).
+		Updates(map[string]interface{}{
+			"status": models.JobGagal, "selesai_pada": time.Now(),
+			"dikunci_oleh": "", "dikunci_pada": nil, "error_terakhir": pesan,
+		})
+}
+
+// ── Runner ────────────────────────────────────────────────────
+
+// sinyal agar worker yang sedang menunggu langsung memeriksa antrian
+var sinyalJobBaru = make(chan struct{}, 1)
+
+func bangunkanWorker() {
+	select {
+	case sinyalJobBaru <- struct{}{}:
+	default:
+	}
+}
+
+// JobRunner menjalankan worker antrian job dan penjadwal job berkala
+type JobRunner struct {
+	id       string
+	berhenti chan struct{}
+	batal    context.CancelFunc
+	ctx      context.Context
+	wg       sync.WaitGroup
+}
+
+// JalankanJobRunner memulai JOB_WORKERS worker, penjadwal cron, pemulihan job
+// macet dan penulis log aktivitas. Panggil Hentikan saat server dimatikan.
+func JalankanJobRunner() *JobRunner {
+	host, _ := os.Hostname()
+	ctx, batal := context.WithCancel(context.Background())
+	r := &JobRunner{
+		id:       fmt.Sprintf("%s-%d", host, os.Getpid()),
+		berhenti: make(chan struct{}),
+		ctx:      ctx,
+		batal:    batal,
+	}
+
+	jumlahWorker := config.GetEnvInt("JOB_WORKERS", 2)
+	if jumlahWorker < 1 {
+		jumlahWorker = 1
+	}
+	interval := time.Duration(config.GetEnvInt("JOB_POLL_DETIK", 5)) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	for i := 1; i <= jumlahWorker; i++ {
+		r.wg.Add(1)
+		go r.worker(fmt.Sprintf("%s-w%d", r.id, i), interval)
+	}
+	r.wg.Add(1)
+	go r.perawatan()
+
+	penulisAktif.Store(true)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		penulisAktivitas(r.berhenti)
+	}()
+
+	log.Printf("[job] runner %s berjalan dengan %d worker", r.id, jumlahWorker)
+	return r
+}
+
+func (r *JobRunner) worker(workerID string, interval time.Duration) {
+	defer r.wg.Done()
+	for {
+		select {
+		case <-r.berhenti:
+			return
+		default:
+		}
+
+		job, err := ambilJob(workerID)
+		if err != nil {
+			log.Printf("[job] gagal mengambil job: %v", err)
+		}
+		if job != nil {
+			jalankanJob(r.ctx, workerID, job)
+			continue // langsung cek job berikutnya
+		}
+
+		select {
+		case <-r.berhenti:
+			return
+		case <-sinyalJobBaru:
+		case <-time.After(interval):
+		}
+	}
+}
+
+// perawatan menjadwalkan job cron yang jatuh tempo dan memulihkan job macet
+func (r *JobRunner) perawatan() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		pulihkanJobMacet()
+		if n, err := antrikanJobTerjadwal(); err != nil {
+			log.Printf("[job] gagal memproses job terjadwal: %v", err)
+		} else if n > 0 {
+			bangunkanWorker()
+		}
+
+		select {
+		case <-r.berhenti:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Hentikan menghentikan pengambilan job baru lalu menunggu job yang sedang
+// berjalan selesai dan antrian log aktivitas tersimpan. Jika ctx habis lebih dulu, context job dibatalkan agar
+// handler berhenti; job tersebut akan dicoba ulang sesuai aturan percobaan.
+func (r *JobRunner) Hentikan(ctx context.Context) error {
+	close(r.berhenti)
+
+	selesai := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(selesai)
+	}()
+
+	select {
+	case <-selesai:
+		r.batal()
+		return nil
+	case <-ctx.Done():
+		r.batal()
+		// beri waktu singkat agar worker sempat mencatat status job
+		select {
+		case <-selesai:
+		case <-time.After(5 * time.Second):
+		}
+		return ctx.Err()
+	}
+}
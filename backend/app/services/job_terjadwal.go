@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+// Tipe job perawatan bawaan
+const (
+	JobBersihkanJob        = "bersihkan_job"
+	JobBersihkanTokenReset = "bersihkan_token_reset"
+	JobBersihkanLoginGagal = "bersihkan_login_gagal"
+)
+
+// DaftarkanJobTerjadwal membuat job berkala berdasarkan nama jika belum ada.
+// Untuk jadwal yang sudah ada hanya tipe dan payload yang diperbarui; cron dan
+// status aktif mengikuti pengaturan admin.
+func DaftarkanJobTerjadwal(nama, tipe, ekspresiCron string, payload interface{}) error {
+	jadwal, err := cron.ParseStandard(ekspresiCron)
+	if err != nil {
+		return err
+	}
+	isi, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	var jt models.JobTerjadwal
+	err = config.DB.Where("nama = ?", nama).First(&jt).Error
+	if err == gorm.ErrRecordNotFound {
+		return config.DB.Create(&models.JobTerjadwal{
+			Nama:           nama,
+			Tipe:           tipe,
+			Payload:        string(isi),
+			Cron:           ekspresiCron,
+			Aktif:          true,
+			BerikutnyaPada: jadwal.Next(time.Now()),
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	return config.DB.Model(&jt).Updates(map[string]interface{}{"tipe": tipe, "payload": string(isi)}).Error
+}
+
+// antrikanJobTerjadwal membuat Job untuk setiap jadwal yang jatuh tempo.
+// Baris jadwal dikunci dengan SKIP LOCKED sehingga satu jadwal hanya
+// diantrikan sekali walaupun ada beberapa instance server.
+func antrikanJobTerjadwal() (int, error) {
+	jumlah := 0
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var list []models.JobTerjadwal
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("aktif = ? AND berikutnya_pada <= ?", true, time.Now()).
+			Find(&list).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, jt := range list {
+			jadwal, err := cron.ParseStandard(jt.Cron)
+			if err != nil {
+				// ekspresi rusak: nonaktifkan daripada gagal terus setiap siklus
+				tx.Model(&jt).Update("aktif", false)
+				continue
+			}
+			id := jt.ID
+			job := models.Job{
+				Tipe:          jt.Tipe,
+				Payload:       jt.Payload,
+				Status:        models.JobAntri,
+				JalankanPada:  now,
+				MaksPercobaan: config.GetEnvInt("JOB_MAKS_PERCOBAAN", 5),
+				JadwalID:      &id,
+			}
+			if err := tx.Create(&job).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&jt).Updates(map[string]interface{}{
+				"berikutnya_pada": jadwal.Next(now),
+				"terakhir_dibuat": now,
+			}).Error; err != nil {
+				return err
+			}
+			jumlah++
+		}
+		return nil
+	})
+	return jumlah, err
+}
+
+// ValidasiCron memastikan ekspresi cron 5 kolom dapat dipakai
+func ValidasiCron(ekspresi string) (time.Time, error) {
+	jadwal, err := cron.ParseStandard(ekspresi)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return jadwal.Next(time.Now()), nil
+}
+
+// ── Job perawatan bawaan ──────────────────────────────────────
+
+type payloadRetensi struct {
+	Hari int `json:"hari"`
+}
+
+// DaftarkanJobBawaan mendaftarkan handler dan jadwal job berkala milik
+// package services
+func DaftarkanJobBawaan() error {
+	DaftarkanHandlerJob(JobBersihkanJob, bersihkanJob)
+	DaftarkanHandlerJob(JobBersihkanTokenReset, bersihkanTokenReset)
+	DaftarkanHandlerJob(JobBersihkanLoginGagal, bersihkanLoginGagal)
+	DaftarkanHandlerJob(JobPengingatKelengkapan, kirimPengingatKelengkapan)
+	DaftarkanHandlerJob(JobAbsensiGerbang, isiAbsensiGerbang)
+	DaftarkanHandlerJob(JobTutupUjian, tutupUjianKedaluwarsa)
+
+	jadwal := []struct {
+		nama, tipe, cron string
+		payload          interface{}
+	}{
+		{"bersihkan-riwayat-job", JobBersihkanJob, "15 2 * * *", payloadRetensi{Hari: config.GetEnvInt("JOB_RETENSI_HARI", 30)}},
+		{"bersihkan-token-reset", JobBersihkanTokenReset, "30 2 * * *", payloadRetensi{Hari: 7}},
+		{"bersihkan-login-gagal", JobBersihkanLoginGagal, "0 * * * *", payloadRetensi{Hari: 1}},
+		{"pengingat-kelengkapan-guru", JobPengingatKelengkapan, "0 6 * * 1-6", payloadPengingatKelengkapan{
+			HariSebelumBatas: config.GetEnvInt("PENGINGAT_NILAI_HARI_SEBELUM_BATAS", 7),
+		}},
+		{"absensi-dari-gerbang", JobAbsensiGerbang, config.GetEnv("GERBANG_CRON_ISI_ABSENSI", "0 17 * * 1-6"), payloadAbsensiGerbang{}},
+		{"tutup-ujian-kedaluwarsa", JobTutupUjian, "*/5 * * * *", payloadTutupUjian{}},
+	}
+	for _, j := range jadwal {
+		if err := DaftarkanJobTerjadwal(j.nama, j.tipe, j.cron, j.payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func batasRetensi(hari int) time.Time {
+	if hari < 1 {
+		hari = 1
+	}
+	return time.Now().AddDate(0, 0, -hari)
+}
+
+// bersihkanJob menghapus riwayat job yang sudah tidak berjalan
+func bersihkanJob(ctx context.Context, _ *models.Job, p payloadRetensi) error {
+	return config.DB.WithContext(ctx).
+		Where("status IN ? AND updated_at < ?",
+			[]string{models.JobSelesai, models.JobGagal, models.JobBatal}, batasRetensi(p.Hari)).
+		Delete(&models.Job{}).Error
+}
+
+// bersihkanTokenReset menghapus token reset password yang kadaluarsa atau terpakai
+func bersihkanTokenReset(ctx context.Context, _ *models.Job, p payloadRetensi) error {
+	batas := batasRetensi(p.Hari)
+	return config.DB.WithContext(ctx).
+		Where("expires_at < ? OR used_at < ?", batas, batas).
+		Delete(&models.PasswordResetToken{}).Error
+}
+
+// bersihkanLoginGagal menghapus catatan login gagal yang sudah di luar jendela
+// hitung dan tidak sedang terkunci
+func bersihkanLoginGagal(ctx context.Context, _ *models.Job, p payloadRetensi) error {
+	return config.DB.WithContext(ctx).
+		Where("(terkunci_sampai IS NULL OR terkunci_sampai < ?) AND updated_at < ?", time.Now(), batasRetensi(p.Hari)).
+		Delete(&models.LoginThrottle{}).Error
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidasiCron(t *testing.T) {
+	tests := []struct {
+		ekspresi string
+		valid    bool
+	}{
+		{"0 2 * * *", true},
+		{"*/15 * * * *", true},
+		{"0 17 * * 1-6", true},
+		{"30 6 1 * *", true},
+		{"@daily", true},
+		{"", false},
+		{"0 2 * *", false},     // kurang satu kolom
+		{"0 0 2 * * *", false}, // 6 kolom (dengan detik) tidak didukung
+		{"60 * * * *", false},  // menit di luar rentang
+		{"0 24 * * *", false},  // jam di luar rentang
+		{"0 2 * * 8", false},   // hari di luar rentang
+		{"setiap hari", false},
+	}
+	for _, tt := range tests {
+		berikut, err := ValidasiCron(tt.ekspresi)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidasiCron(%q) error = %v, ingin valid %v", tt.ekspresi, err, tt.valid)
+			continue
+		}
+		if tt.valid && !berikut.After(time.Now()) {
+			t.Errorf("ValidasiCron(%q) jadwal berikutnya %v tidak di masa depan", tt.ekspresi, berikut)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+var (
+	ErrJenisPelanggaranNonaktif = errors.New("jenis pelanggaran tidak ditemukan atau sudah nonaktif")
+	ErrTanggalDiluarSemester    = errors.New("tanggal pelanggaran berada di luar rentang semester")
+)
+
+// Predikat sikap di rapor berdasarkan akumulasi poin pelanggaran satu semester
+const (
+	PredikatSikapSangatBaik = "Sangat Baik"
+	PredikatSikapBaik       = "Baik"
+	PredikatSikapCukup      = "Cukup"
+	PredikatSikapKurang     = "Kurang"
+)
+
+// TotalPoinTahunAjaran menjumlahkan poin pelanggaran siswa di semua semester
+// pada satu tahun ajaran
+func TotalPoinTahunAjaran(db *gorm.DB, siswaID, tahunAjaranID uint) int {
+	var total int
+	db.Table("pelanggaran_siswas p").
+		Joins("JOIN semesters sm ON sm.id = p.semester_id").
+		Where("p.siswa_id = ? AND sm.tahun_ajaran_id = ?", siswaID, tahunAjaranID).
+		Select("COALESCE(SUM(p.poin), 0)").
+		Scan(&total)
+	return total
+}
+
+// CatatPelanggaran menyimpan kejadian pelanggaran lalu memeriksa ambang poin.
+// Ambang yang baru terlampaui dicatat sebagai sanksi dan diberitahukan ke wali
+// kelas, guru BK, dan (jika diatur) orang tua. Sanksi yang baru dibuat dikembalikan.
+func CatatPelanggaran(p *models.PelanggaranSiswa) ([]models.SanksiSiswa, error) {
+	var jenis models.JenisPelanggaran
+	if err := config.DB.Where("id = ? AND is_aktif = ?", p.JenisPelanggaranID, true).First(&jenis).Error; err != nil {
+		return nil, ErrJenisPelanggaranNonaktif
+	}
+	var semester models.Semester
+	if err := config.DB.First(&semester, p.SemesterID).Error; err != nil {
+		return nil, err
+	}
+	if (semester.TanggalMulai != nil && p.Tanggal.Before(*semester.TanggalMulai)) ||
+		(semester.TanggalSelesai != nil && p.Tanggal.After(*semester.TanggalSelesai)) {
+		return nil, ErrTanggalDiluarSemester
+	}
+	p.Poin = jenis.Poin
+
+	var sanksiBaru []models.SanksiSiswa
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci baris siswa agar dua pencatatan bersamaan tidak sama-sama
+		// melewatkan ambang yang sama
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").First(&models.Siswa{}, p.SiswaID).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(p).Error; err != nil {
+			return err
+		}
+
+		total := TotalPoinTahunAjaran(tx, p.SiswaID, semester.TahunAjaranID)
+		var ambang []models.AmbangPoin
+		tx.Where("poin <= ?", total).
+			Where("id NOT IN (?)", tx.Model(&models.SanksiSiswa{}).Select("ambang_poin_id").
+				Where("siswa_id = ? AND tahun_ajaran_id = ?", p.SiswaID, semester.TahunAjaranID)).
+			Order("poin ASC").
+			Find(&ambang)
+
+		for _, a := range ambang {
+			s := models.SanksiSiswa{
+				SiswaID:       p.SiswaID,
+				AmbangPoinID:  a.ID,
+				TahunAjaranID: semester.TahunAjaranID,
+				TotalPoin:     total,
+				Status:        models.SanksiAktif,
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&s).Error; err != nil {
+				return err
+			}
+			if s.ID == 0 {
+				continue
+			}
+			s.AmbangPoin = a
+			sanksiBaru = append(sanksiBaru, s)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sanksiBaru) > 0 {
+		notifikasiSanksi(p.SiswaID, sanksiBaru)
+	}
+	return sanksiBaru, nil
+}
+
+// notifikasiSanksi memberi tahu pihak terkait bahwa siswa mencapai ambang poin
+func notifikasiSanksi(siswaID uint, sanksi []models.SanksiSiswa) {
+	var siswa models.Siswa
+	if err := config.DB.Preload("Kelas.WaliKelas").First(&siswa, siswaID).Error; err != nil {
+		return
+	}
+
+	var pihakSekolah []uint
+	if siswa.Kelas != nil && siswa.Kelas.WaliKelas != nil {
+		pihakSekolah = append(pihakSekolah, siswa.Kelas.WaliKelas.UserID)
+	}
+	var userBK []uint
+	config.DB.Model(&models.User{}).
+		Where("is_active = ? AND role_id IN (?)", true,
+			config.DB.Model(&models.Role{}).Select("id").Where("nama = ?", models.RoleGuruBK)).
+		Pluck("id", &userBK)
+	pihakSekolah = append(pihakSekolah, userBK...)
+
+	var orangTua []uint
+	config.DB.Table("orang_tua_siswas ots").
+		Joins("JOIN orang_tuas ot ON ot.id = ots.orang_tua_id").
+		Where("ots.siswa_id = ?", siswaID).
+		Pluck("ot.user_id", &orangTua)
+
+	var list []models.Notification
+	for _, s := range sanksi {
+		pesan := fmt.Sprintf("%s mencapai %d poin pelanggaran (ambang %s, %d poin).",
+			siswa.Nama, s.TotalPoin, s.AmbangPoin.Nama, s.AmbangPoin.Poin)
+		if s.AmbangPoin.Sanksi != "" {
+			pesan += " Sanksi: " + s.AmbangPoin.Sanksi
+		}
+		for _, userID := range pihakSekolah {
+			list = append(list, models.Notification{
+				UserID:  userID,
+				Type:    models.NotifPerilaku,
+				Icon:    "⚠️",
+				Title:   "Ambang poin pelanggaran tercapai",
+				Message: pesan,
+				Link:    fmt.Sprintf("/kedisiplinan/sanksi?siswa_id=%d", siswaID),
+			})
+		}
+		if !s.AmbangPoin.KirimOrangTua {
+			continue
+		}
+		for _, userID := range orangTua {
+			list = append(list, models.Notification{
+				UserID:  userID,
+				Type:    models.NotifPerilaku,
+				Icon:    "⚠️",
+				Title:   "Pemberitahuan kedisiplinan",
+				Message: pesan + " Mohon hubungi wali kelas atau guru BK.",
+				Link:    "/kedisiplinan/saya",
+			})
+		}
+	}
+	if len(list) > 0 {
+		config.DB.Create(&list)
+	}
+}
+
+// RingkasanPerilaku adalah rangkuman sikap siswa satu semester untuk rapor
+type RingkasanPerilaku struct {
+	JumlahPelanggaran int    `json:"jumlah_pelanggaran"`
+	TotalPoin         int    `json:"total_poin"`
+	Predikat          string `json:"predikat"`
+	Deskripsi         string `json:"deskripsi"`
+}
+
+// PredikatSikap mengubah total poin pelanggaran satu semester menjadi predikat
+func PredikatSikap(poin int) string {
+	switch {
+	case poin == 0:
+		return PredikatSikapSangatBaik
+	case poin <= 25:
+		return PredikatSikapBaik
+	case poin <= 50:
+		return PredikatSikapCukup
+	default:
+		return PredikatSikapKurang
+	}
+}
+
+// RingkasanPerilakuSiswa menghitung ringkasan perilaku siswa pada satu semester.
+// Catatan konseling tidak ikut dirangkum karena bersifat rahasia.
+func RingkasanPerilakuSiswa(siswaID, semesterID uint) RingkasanPerilaku {
+	var r RingkasanPerilaku
+	config.DB.Model(&models.PelanggaranSiswa{}).
+		Where("siswa_id = ? AND semester_id = ?", siswaID, semesterID).
+		Select("COUNT(*) AS jumlah_pelanggaran, COALESCE(SUM(poin), 0) AS total_poin").
+		Scan(&r)
+
+	r.Predikat = PredikatSikap(r.TotalPoin)
+	switch r.Predikat {
+	case PredikatSikapSangatBaik:
+		r.Deskripsi = "Tidak tercatat pelanggaran tata tertib selama semester ini. Pertahankan sikap yang baik."
+	case PredikatSikapBaik:
+		r.Deskripsi = fmt.Sprintf("Tercatat %d pelanggaran (%d poin). Secara umum menunjukkan sikap yang baik.",
+			r.JumlahPelanggaran, r.TotalPoin)
+	case PredikatSikapCukup:
+		r.Deskripsi = fmt.Sprintf("Tercatat %d pelanggaran (%d poin). Perlu meningkatkan kedisiplinan dan ketaatan pada tata tertib.",
+			r.JumlahPelanggaran, r.TotalPoin)
+	default:
+		r.Deskripsi = fmt.Sprintf("Tercatat %d pelanggaran (%d poin). Perlu pembinaan khusus bersama wali kelas dan guru BK.",
+			r.JumlahPelanggaran, r.TotalPoin)
+	}
+	return r
+}
+
+// UbahStatusSanksi memperbarui status sanksi; waktu selesai diisi saat status selesai
+func UbahStatusSanksi(s *models.SanksiSiswa, status, catatan string) error {
+	s.Status = status
+	if catatan != "" {
+		s.Catatan = catatan
+	}
+	s.SelesaiPada = nil
+	if status == models.SanksiSelesai {
+		now := time.Now()
+		s.SelesaiPada = &now
+	}
+	return config.DB.Model(s).Select("status", "catatan", "selesai_pada").Updates(s).Error
+}
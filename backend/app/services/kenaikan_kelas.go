@@ -0,0 +1,277 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+var (
+	ErrProsesSudahDiterapkan = errors.New("proses kenaikan sudah diterapkan")
+	ErrPreviewBermasalah     = errors.New("masih ada siswa yang belum memiliki kelas tujuan")
+)
+
+// urutanTingkat adalah urutan tingkat kelas; tingkat terakhir lulus
+var urutanTingkat = []string{"X", "XI", "XII"}
+
+// TingkatBerikutnya mengembalikan tingkat setelah t, atau lulus=true jika t tingkat akhir
+func TingkatBerikutnya(t string) (berikut string, lulus bool) {
+	for i, v := range urutanTingkat {
+		if v == t {
+			if i == len(urutanTingkat)-1 {
+				return "", true
+			}
+			return urutanTingkat[i+1], false
+		}
+	}
+	return "", false
+}
+
+// GantiTingkatNama mengganti awalan tingkat pada nama kelas, misalnya "X RPL 1" → "XI RPL 1"
+func GantiTingkatNama(nama, tingkatLama, tingkatBaru string) string {
+	if strings.HasPrefix(nama, tingkatLama+" ") {
+		return tingkatBaru + strings.TrimPrefix(nama, tingkatLama)
+	}
+	return nama
+}
+
+// cariKelasTujuan mencari kelas di tahun ajaran tujuan dengan tingkat dan jurusan
+// yang sama, diutamakan yang namanya persis sama dengan namaUtama
+func cariKelasTujuan(db *gorm.DB, tahunAjaranID uint, tingkat string, jurusanID uint, namaUtama string) *models.Kelas {
+	var kandidat []models.Kelas
+	db.Where("tahun_ajaran_id = ? AND tingkat = ? AND jurusan_id = ?", tahunAjaranID, tingkat, jurusanID).
+		Order("nama ASC").Find(&kandidat)
+	if len(kandidat) == 0 {
+		return nil
+	}
+	for i := range kandidat {
+		if kandidat[i].Nama == namaUtama {
+			return &kandidat[i]
+		}
+	}
+	return &kandidat[0]
+}
+
+// UsulkanPemetaan membuat usulan pemetaan untuk setiap kelas di tahun ajaran asal.
+// Kelas yang tidak menemukan pasangan dibiarkan tanpa kelas tujuan untuk dipetakan manual.
+func UsulkanPemetaan(tx *gorm.DB, proses *models.ProsesKenaikan) error {
+	var kelasAsal []models.Kelas
+	if err := tx.Where("tahun_ajaran_id = ?", proses.TahunAjaranAsalID).Order("tingkat, nama").Find(&kelasAsal).Error; err != nil {
+		return err
+	}
+
+	for _, k := range kelasAsal {
+		pemetaan := models.PemetaanKelasKenaikan{
+			ProsesKenaikanID: proses.ID,
+			KelasAsalID:      k.ID,
+		}
+
+		berikut, lulus := TingkatBerikutnya(k.Tingkat)
+		if lulus {
+			pemetaan.Lulus = true
+		} else if berikut != "" {
+			if tujuan := cariKelasTujuan(tx, proses.TahunAjaranTujuanID, berikut, k.JurusanID,
+				GantiTingkatNama(k.Nama, k.Tingkat, berikut)); tujuan != nil {
+				pemetaan.KelasTujuanID = &tujuan.ID
+			}
+		}
+
+		if err := tx.Create(&pemetaan).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ── Preview ───────────────────────────────────────────────────
+
+// PreviewSiswaKenaikan adalah hasil akhir yang akan diterapkan ke satu siswa
+type PreviewSiswaKenaikan struct {
+	SiswaID         uint   `json:"siswa_id"`
+	Nama            string `json:"nama"`
+	NISN            string `json:"nisn"`
+	Keputusan       string `json:"keputusan"` // naik / tinggal / lulus
+	KelasTujuanID   *uint  `json:"kelas_tujuan_id"`
+	KelasTujuanNama string `json:"kelas_tujuan_nama,omitempty"`
+	Catatan         string `json:"catatan,omitempty"`
+	Masalah         string `json:"masalah,omitempty"`
+}
+
+type PreviewKelasKenaikan struct {
+	PemetaanID uint                   `json:"pemetaan_id"`
+	KelasAsal  models.Kelas           `json:"kelas_asal"`
+	Lulus      bool                   `json:"lulus"`
+	Tujuan     *models.Kelas          `json:"kelas_tujuan"`
+	Siswa      []PreviewSiswaKenaikan `json:"siswa"`
+}
+
+type PreviewKenaikan struct {
+	Kelas         []PreviewKelasKenaikan `json:"kelas"`
+	JumlahNaik    int                    `json:"jumlah_naik"`
+	JumlahTinggal int                    `json:"jumlah_tinggal"`
+	JumlahLulus   int                    `json:"jumlah_lulus"`
+	JumlahMasalah int                    `json:"jumlah_masalah"`
+}
+
+// SusunPreviewKenaikan menghitung hasil kenaikan tanpa mengubah data siswa
+func SusunPreviewKenaikan(db *gorm.DB, proses models.ProsesKenaikan) (PreviewKenaikan, error) {
+	hasil := PreviewKenaikan{}
+
+	var pemetaan []models.PemetaanKelasKenaikan
+	if err := db.Preload("KelasAsal").Preload("KelasTujuan").
+		Where("proses_kenaikan_id = ?", proses.ID).
+		Order("id").Find(&pemetaan).Error; err != nil {
+		return hasil, err
+	}
+
+	var keputusan []models.KeputusanKenaikan
+	db.Where("proses_kenaikan_id = ?", proses.ID).Find(&keputusan)
+	keputusanSiswa := map[uint]models.KeputusanKenaikan{}
+	for _, k := range keputusan {
+		keputusanSiswa[k.SiswaID] = k
+	}
+
+	for _, p := range pemetaan {
+		pk := PreviewKelasKenaikan{
+			PemetaanID: p.ID,
+			KelasAsal:  p.KelasAsal,
+			Lulus:      p.Lulus,
+			Tujuan:     p.KelasTujuan,
+			Siswa:      []PreviewSiswaKenaikan{},
+		}
+
+		var siswaList []models.Siswa
+		db.Where("kelas_id = ? AND status = ?", p.KelasAsalID, models.StatusSiswaAktif).
+			Order("nama ASC").Find(&siswaList)
+
+		for _, s := range siswaList {
+			ps := PreviewSiswaKenaikan{SiswaID: s.ID, Nama: s.Nama, NISN: s.NISN}
+			kep, ada := keputusanSiswa[s.ID]
+			if ada {
+				ps.Catatan = kep.Catatan
+			}
+
+			switch {
+			case ada && kep.Keputusan == models.KeputusanTinggal:
+				ps.Keputusan = models.KeputusanTinggal
+				if kep.KelasTujuanID != nil {
+					ps.KelasTujuanID = kep.KelasTujuanID
+				} else if k := cariKelasTujuan(db, proses.TahunAjaranTujuanID, p.KelasAsal.Tingkat,
+					p.KelasAsal.JurusanID, p.KelasAsal.Nama); k != nil {
+					ps.KelasTujuanID = &k.ID
+				}
+				if ps.KelasTujuanID == nil {
+					ps.Masalah = fmt.Sprintf("Tidak ada kelas tingkat %s di tahun ajaran tujuan", p.KelasAsal.Tingkat)
+				}
+			case p.Lulus:
+				ps.Keputusan = models.KeputusanLulus
+			default:
+				ps.Keputusan = models.KeputusanNaik
+				ps.KelasTujuanID = p.KelasTujuanID
+				if ps.KelasTujuanID == nil {
+					ps.Masalah = "Kelas tujuan belum dipetakan"
+				}
+			}
+
+			switch ps.Keputusan {
+			case models.KeputusanNaik:
+				hasil.JumlahNaik++
+			case models.KeputusanTinggal:
+				hasil.JumlahTinggal++
+			case models.KeputusanLulus:
+				hasil.JumlahLulus++
+			}
+			if ps.Masalah != "" {
+				hasil.JumlahMasalah++
+			}
+			pk.Siswa = append(pk.Siswa, ps)
+		}
+
+		hasil.Kelas = append(hasil.Kelas, pk)
+	}
+
+	// Lengkapi nama kelas tujuan
+	namaKelas := map[uint]string{}
+	var kelasTujuan []models.Kelas
+	db.Where("tahun_ajaran_id = ?", proses.TahunAjaranTujuanID).Find(&kelasTujuan)
+	for _, k := range kelasTujuan {
+		namaKelas[k.ID] = k.Nama
+	}
+	for i := range hasil.Kelas {
+		for j := range hasil.Kelas[i].Siswa {
+			if id := hasil.Kelas[i].Siswa[j].KelasTujuanID; id != nil {
+				hasil.Kelas[i].Siswa[j].KelasTujuanNama = namaKelas[*id]
+			}
+		}
+	}
+
+	return hasil, nil
+}
+
+// ── Terapkan ──────────────────────────────────────────────────
+
+// TerapkanKenaikan menerapkan hasil preview dalam satu transaksi.
+// Siswa yang lulus tidak dihapus, melainkan ditandai alumni dan dilepas dari kelasnya.
+func TerapkanKenaikan(prosesID uint, userID uint) (PreviewKenaikan, error) {
+	var hasil PreviewKenaikan
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var proses models.ProsesKenaikan
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&proses, prosesID).Error; err != nil {
+			return err
+		}
+		if proses.Status != models.ProsesKenaikanDraft {
+			return ErrProsesSudahDiterapkan
+		}
+
+		var err error
+		hasil, err = SusunPreviewKenaikan(tx, proses)
+		if err != nil {
+			return err
+		}
+		if hasil.JumlahMasalah > 0 {
+			return ErrPreviewBermasalah
+		}
+
+		now := time.Now()
+		for _, k := range hasil.Kelas {
+			for _, s := range k.Siswa {
+				updates := map[string]interface{}{}
+				if s.Keputusan == models.KeputusanLulus {
+					updates["kelas_id"] = nil
+					updates["status"] = models.StatusSiswaAlumni
+					updates["tanggal_lulus"] = now
+				} else {
+					updates["kelas_id"] = *s.KelasTujuanID
+				}
+				if err := tx.Model(&models.Siswa{}).Where("id = ?", s.SiswaID).Updates(updates).Error; err != nil {
+					return err
+				}
+
+				sumber := map[string]string{
+					models.KeputusanNaik:    models.RiwayatKenaikan,
+					models.KeputusanTinggal: models.RiwayatTinggalKelas,
+					models.KeputusanLulus:   models.RiwayatLulus,
+				}[s.Keputusan]
+				if err := CatatPerubahanKelas(tx, s.SiswaID, s.KelasTujuanID, sumber,
+					fmt.Sprintf("Proses kenaikan kelas #%d", proses.ID), now); err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Model(&proses).Updates(map[string]interface{}{
+			"status":          models.ProsesKenaikanDiterapkan,
+			"diterapkan_pada": now,
+			"diterapkan_oleh": userID,
+		}).Error
+	})
+
+	return hasil, err
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+var ErrMapelDiluarKurikulum = errors.New("mata pelajaran tidak termasuk kurikulum kelas")
+
+// queryKurikulumKelas memfilter kurikulum yang berlaku untuk tingkat & jurusan kelas:
+// baris khusus jurusan tersebut ditambah baris umum (jurusan_id NULL)
+func queryKurikulumKelas(db *gorm.DB, kelas models.Kelas) *gorm.DB {
+	return db.Model(&models.KurikulumMapel{}).
+		Where("tingkat = ? AND (jurusan_id = ? OR jurusan_id IS NULL)", kelas.Tingkat, kelas.JurusanID)
+}
+
+// KurikulumKelas mengembalikan daftar mapel kurikulum yang berlaku untuk kelas
+func KurikulumKelas(kelas models.Kelas) []models.KurikulumMapel {
+	var list []models.KurikulumMapel
+	queryKurikulumKelas(config.DB, kelas).
+		Preload("MataPelajaran").Preload("Jurusan").
+		Order("kelompok ASC, mata_pelajaran_id ASC").
+		Find(&list)
+	return list
+}
+
+// CekMapelKurikulum memastikan mapel termasuk kurikulum kelas. Validasi hanya
+// berlaku jika kurikulum untuk tingkat/jurusan kelas sudah diatur, sehingga data
+// sekolah yang belum mengisi kurikulum tetap berjalan seperti sebelumnya.
+func CekMapelKurikulum(kelas models.Kelas, mapelID uint) error {
+	var total int64
+	queryKurikulumKelas(config.DB, kelas).Count(&total)
+	if total == 0 {
+		return nil
+	}
+
+	var cocok int64
+	queryKurikulumKelas(config.DB, kelas).Where("mata_pelajaran_id = ?", mapelID).Count(&cocok)
+	if cocok == 0 {
+		return ErrMapelDiluarKurikulum
+	}
+	return nil
+}
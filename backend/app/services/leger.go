@@ -0,0 +1,167 @@
+package services
+
+import (
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+// BarisLeger adalah satu siswa pada leger nilai kelas
+type BarisLeger struct {
+	SiswaID     uint             `json:"siswa_id"`
+	NISN        string           `json:"nisn"`
+	Nama        string           `json:"nama"`
+	Nilai       map[uint]float64 `json:"nilai" gorm:"-"` // mapel ID → nilai akhir
+	JumlahMapel int              `json:"jumlah_mapel"`
+	Jumlah      float64          `json:"jumlah"`
+	RataRata    float64          `json:"rata_rata"`
+	Peringkat   *int             `json:"peringkat"` // nil jika belum punya nilai
+}
+
+// Leger berisi nilai akhir seluruh siswa × mapel di satu kelas dan semester
+type Leger struct {
+	Kelas         models.Kelas           `json:"kelas"`
+	Semester      models.Semester        `json:"semester"`
+	Mapel         []models.MataPelajaran `json:"mapel"` // urutan kolom
+	Baris         []BarisLeger           `json:"baris"` // urut peringkat
+	RataRataMapel map[uint]float64       `json:"rata_rata_mapel"`
+	RataRataKelas float64                `json:"rata_rata_kelas"`
+}
+
+// queryPeringkatKelas menghitung jumlah, rata-rata dan peringkat setiap siswa
+// kelas. Peringkat memakai RANK() atas rata-rata (dibulatkan 2 desimal):
+// siswa dengan rata-rata sama mendapat peringkat sama dan peringkat berikutnya
+// dilewati (1, 1, 3). Siswa tanpa nilai tidak diberi peringkat.
+const queryPeringkatKelas = `
+	WITH per_siswa AS (
+		SELECT s.id AS siswa_id, s.nisn, s.nama,
+			COUNT(n.id) AS jumlah_mapel,
+			COALESCE(SUM(n.nilai_akhir), 0) AS jumlah,
+			COALESCE(ROUND(AVG(n.nilai_akhir)::numeric, 2), 0) AS rata_rata
+		FROM siswas s
+		LEFT JOIN nilais n ON n.siswa_id = s.id AND n.semester_id = @semester
+		WHERE s.id IN (@siswa) AND s.deleted_at IS NULL
+		GROUP BY s.id, s.nisn, s.nama
+	)
+	SELECT siswa_id, nisn, nama, jumlah_mapel, jumlah, rata_rata,
+		CASE WHEN jumlah_mapel > 0 THEN
+			RANK() OVER (PARTITION BY jumlah_mapel > 0 ORDER BY rata_rata DESC)
+		END AS peringkat
+	FROM per_siswa
+	ORDER BY peringkat NULLS LAST, nama`
+
+// HitungLeger menyusun leger nilai kelas pada semester tersebut. Siswa dipilih
+// per semester seperti pada rapor (lihat SiswaIDKelasSemester). Kolom mapel
+// mengikuti kurikulum kelas, ditambah mapel lain yang sudah memiliki nilai.
+func HitungLeger(kelas models.Kelas, semester models.Semester) (Leger, error) {
+	leger := Leger{
+		Kelas:         kelas,
+		Semester:      semester,
+		RataRataMapel: map[uint]float64{},
+	}
+	siswa := SiswaIDKelasSemester(kelas.ID, semester)
+	if len(siswa) == 0 {
+		siswa = []uint{0} // IN () tidak valid di PostgreSQL
+	}
+
+	if err := config.DB.Raw(queryPeringkatKelas, map[string]interface{}{
+		"semester": semester.ID,
+		"siswa":    siswa,
+	}).Scan(&leger.Baris).Error; err != nil {
+		return leger, err
+	}
+
+	var sel []struct {
+		SiswaID         uint
+		MataPelajaranID uint
+		NilaiAkhir      float64
+	}
+	if err := config.DB.Model(&models.Nilai{}).
+		Select("siswa_id, mata_pelajaran_id, nilai_akhir").
+		Where("semester_id = ? AND siswa_id IN (?)", semester.ID, siswa).
+		Scan(&sel).Error; err != nil {
+		return leger, err
+	}
+
+	indeks := make(map[uint]int, len(leger.Baris))
+	for i := range leger.Baris {
+		leger.Baris[i].Nilai = map[uint]float64{}
+		indeks[leger.Baris[i].SiswaID] = i
+	}
+	adaNilai := map[uint]bool{}
+	for _, s := range sel {
+		if i, ok := indeks[s.SiswaID]; ok {
+			leger.Baris[i].Nilai[s.MataPelajaranID] = s.NilaiAkhir
+			adaNilai[s.MataPelajaranID] = true
+		}
+	}
+
+	// Kolom: mapel kurikulum terlebih dahulu, lalu mapel di luar kurikulum
+	kolom := map[uint]bool{}
+	for _, k := range KurikulumKelas(kelas) {
+		leger.Mapel = append(leger.Mapel, k.MataPelajaran)
+		kolom[k.MataPelajaranID] = true
+	}
+	var lain []uint
+	for id := range adaNilai {
+		if !kolom[id] {
+			lain = append(lain, id)
+		}
+	}
+	if len(lain) > 0 {
+		var mapel []models.MataPelajaran
+		config.DB.Where("id IN ?", lain).Order("id").Find(&mapel)
+		leger.Mapel = append(leger.Mapel, mapel...)
+	}
+
+	// Rata-rata per mapel dan rata-rata kelas
+	jumlahKelas, nKelas := 0.0, 0
+	for _, m := range leger.Mapel {
+		total, n := 0.0, 0
+		for _, b := range leger.Baris {
+			if v, ok := b.Nilai[m.ID]; ok {
+				total += v
+				n++
+			}
+		}
+		if n > 0 {
+			leger.RataRataMapel[m.ID] = total / float64(n)
+		}
+	}
+	for _, b := range leger.Baris {
+		if b.JumlahMapel > 0 {
+			jumlahKelas += b.RataRata
+			nKelas++
+		}
+	}
+	if nKelas > 0 {
+		leger.RataRataKelas = jumlahKelas / float64(nKelas)
+	}
+	return leger, nil
+}
+
+// PeringkatSiswa mengembalikan peringkat siswa di kelasnya pada semester
+// tersebut dan jumlah siswa yang diperingkat. ok bernilai false jika siswa
+// belum memiliki nilai.
+func PeringkatSiswa(kelas models.Kelas, semester models.Semester, siswaID uint) (peringkat, dari int, ok bool) {
+	siswa := SiswaIDKelasSemester(kelas.ID, semester)
+	if len(siswa) == 0 {
+		return 0, 0, false
+	}
+	var baris []BarisLeger
+	if err := config.DB.Raw(queryPeringkatKelas, map[string]interface{}{
+		"semester": semester.ID,
+		"siswa":    siswa,
+	}).Scan(&baris).Error; err != nil {
+		return 0, 0, false
+	}
+	for _, b := range baris {
+		if b.Peringkat == nil {
+			continue
+		}
+		dari++
+		if b.SiswaID == siswaID {
+			peringkat, ok = *b.Peringkat, true
+		}
+	}
+	return peringkat, dari, ok
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+// jendelaGagal: percobaan gagal yang lebih lama dari ini tidak dihitung lagi
+const jendelaGagal = 15 * time.Minute
+
+// jedaMaksimal membatasi jeda progresif agar request tidak menggantung terlalu lama
+const jedaMaksimal = 8 * time.Second
+
+// HasilLoginGagal berisi dampak dari satu percobaan login yang gagal
+type HasilLoginGagal struct {
+	Jeda           time.Duration // jeda progresif sebelum response dikirim
+	Terkunci       bool          // true jika percobaan ini memicu lockout baru
+	TipeTerkunci   []string      // "email" dan/atau "ip"
+	TerkunciSampai time.Time
+}
+
+// panjangKunciThrottle mengikuti ukuran kolom login_throttles.kunci
+const panjangKunciThrottle = 100
+
+// batasGagalAkun dan batasGagalIP minimal 1: nilai 0 dari env akan membuat
+// pembagian jeda relatif di CatatLoginGagal gagal
+func batasGagalAkun() int {
+	batas := config.GetEnvInt("LOGIN_MAX_GAGAL_AKUN", 5)
+	if batas < 1 {
+		batas = 1
+	}
+	return batas
+}
+
+func batasGagalIP() int {
+	batas := config.GetEnvInt("LOGIN_MAX_GAGAL_IP", 20)
+	if batas < 1 {
+		batas = 1
+	}
+	return batas
+}
+
+// durasiLockout minimal 1 menit: nilai 0 atau negatif akan membuat
+// TerkunciSampai sudah lewat sehingga lockout tidak pernah berlaku
+func durasiLockout() time.Duration {
+	menit := config.GetEnvInt("LOGIN_LOCKOUT_MENIT", 15)
+	if menit < 1 {
+		menit = 1
+	}
+	return time.Duration(menit) * time.Minute
+}
+
+// NormalisasiEmail menyeragamkan email sebagai kunci pelacakan
+func NormalisasiEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// kunciThrottle menyesuaikan nilai dengan kolom kunci; nilai yang terlalu
+// panjang (misalnya email sembarang saat brute force) diganti dengan hash-nya
+func kunciThrottle(nilai string) string {
+	if len(nilai) <= panjangKunciThrottle {
+		return nilai
+	}
+	h := sha256.Sum256([]byte(nilai))
+	return "sha256:" + hex.EncodeToString(h[:])
+}
+
+// CekLockout mengembalikan sisa waktu lockout untuk email atau IP.
+// Nilai 0 berarti boleh mencoba login.
+func CekLockout(email, ip string) time.Duration {
+	now := time.Now()
+
+	var list []models.LoginThrottle
+	config.DB.
+		Where("(tipe = ? AND kunci = ?) OR (tipe = ? AND kunci = ?)",
+			models.ThrottleEmail, kunciThrottle(NormalisasiEmail(email)), models.ThrottleIP, kunciThrottle(ip)).
+		Where("terkunci_sampai > ?", now).
+		Find(&list)
+
+	var sisa time.Duration
+	for _, t := range list {
+		if d := t.TerkunciSampai.Sub(now); d > sisa {
+			sisa = d
+		}
+	}
+	return sisa
+}
+
+// CatatLoginGagal menaikkan hitungan gagal untuk email dan IP,
+// menentukan jeda progresif, dan mengunci jika batas terlampaui.
+func CatatLoginGagal(email, ip string) HasilLoginGagal {
+	now := time.Now()
+	hasil := HasilLoginGagal{}
+
+	kunci := []struct {
+		tipe  string
+		nilai string
+		batas int
+	}{
+		{models.ThrottleEmail, kunciThrottle(NormalisasiEmail(email)), batasGagalAkun()},
+		{models.ThrottleIP, kunciThrottle(ip), batasGagalIP()},
+	}
+
+	jumlahTerbanyak := 0
+	for _, k := range kunci {
+		if k.nilai == "" {
+			continue
+		}
+		jumlah, terkunci, err := catatGagal(k.tipe, k.nilai, k.batas, now)
+		if err != nil {
+			log.Printf("⚠️  Gagal mencatat login gagal (%s %q): %v", k.tipe, k.nilai, err)
+			continue
+		}
+		// Jeda dihitung relatif terhadap batas masing-masing kunci
+		if rel := jumlah * batasGagalAkun() / k.batas; rel > jumlahTerbanyak {
+			jumlahTerbanyak = rel
+		}
+		if terkunci {
+			hasil.Terkunci = true
+			hasil.TipeTerkunci = append(hasil.TipeTerkunci, k.tipe)
+			hasil.TerkunciSampai = now.Add(durasiLockout())
+		}
+	}
+
+	hasil.Jeda = jedaProgresif(jumlahTerbanyak)
+	return hasil
+}
+
+// ResetLoginGagal menghapus hitungan gagal akun setelah login berhasil.
+// Hitungan per IP sengaja tidak direset agar satu akun valid tidak bisa
+// dipakai untuk "mencuci" percobaan terhadap akun lain dari IP yang sama.
+func ResetLoginGagal(email string) {
+	config.DB.Where("tipe = ? AND kunci = ?", models.ThrottleEmail, kunciThrottle(NormalisasiEmail(email))).
+		Delete(&models.LoginThrottle{})
+}
+
+// catatGagal memperbarui satu baris throttle secara atomik
+func catatGagal(tipe, nilai string, batas int, now time.Time) (int, bool, error) {
+	var jumlah int
+	var terkunci bool
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Pastikan baris ada, lalu kunci untuk update
+		tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.LoginThrottle{Tipe: tipe, Kunci: nilai})
+
+		var t models.LoginThrottle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tipe = ? AND kunci = ?", tipe, nilai).
+			First(&t).Error; err != nil {
+			return err
+		}
+
+		// Percobaan lama di luar jendela tidak dihitung
+		if t.TerakhirGagal == nil || now.Sub(*t.TerakhirGagal) > jendelaGagal {
+			t.JumlahGagal = 0
+		}
+		t.JumlahGagal++
+		t.TerakhirGagal = &now
+
+		if t.JumlahGagal >= batas && !t.IsTerkunci(now) {
+			sampai := now.Add(durasiLockout())
+			t.TerkunciSampai = &sampai
+			terkunci = true
+		}
+		jumlah = t.JumlahGagal
+		return tx.Save(&t).Error
+	})
+	return jumlah, terkunci, err
+}
+
+// jedaProgresif: 2 kali gagal pertama tanpa jeda, selanjutnya 1s, 2s, 4s, … maks 8s
+func jedaProgresif(jumlahGagal int) time.Duration {
+	if jumlahGagal < 3 {
+		return 0
+	}
+	jeda := time.Second << uint(jumlahGagal-3)
+	if jeda > jedaMaksimal || jeda <= 0 {
+		return jedaMaksimal
+	}
+	return jeda
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJedaProgresif(t *testing.T) {
+	tests := []struct {
+		gagal int
+		jeda  time.Duration
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{5, 4 * time.Second},
+		{6, 8 * time.Second},
+		{7, jedaMaksimal},
+		{20, jedaMaksimal},
+		// Geser bit melebihi lebar Duration tidak boleh menghasilkan jeda 0
+		{70, jedaMaksimal},
+		{1000, jedaMaksimal},
+	}
+	for _, tt := range tests {
+		if got := jedaProgresif(tt.gagal); got != tt.jeda {
+			t.Errorf("jedaProgresif(%d) = %v, ingin %v", tt.gagal, got, tt.jeda)
+		}
+	}
+}
+
+func TestKunciThrottle(t *testing.T) {
+	panjang := strings.Repeat("a", panjangKunciThrottle+1) + "@contoh.sch.id"
+	sum := sha256.Sum256([]byte(panjang))
+
+	tests := []struct {
+		nama  string
+		nilai string
+		ingin string
+	}{
+		{"email biasa", "guru@contoh.sch.id", "guru@contoh.sch.id"},
+		{"ip", "203.0.113.7", "203.0.113.7"},
+		{"tepat sepanjang kolom", strings.Repeat("x", panjangKunciThrottle), strings.Repeat("x", panjangKunciThrottle)},
+		{"lebih panjang dari kolom", panjang, "sha256:" + hex.EncodeToString(sum[:])},
+	}
+	for _, tt := range tests {
+		got := kunciThrottle(tt.nilai)
+		if got != tt.ingin {
+			t.Errorf("%s: kunciThrottle = %q, ingin %q", tt.nama, got, tt.ingin)
+		}
+		if len(got) > panjangKunciThrottle {
+			t.Errorf("%s: panjang kunci %d melebihi kolom %d", tt.nama, len(got), panjangKunciThrottle)
+		}
+	}
+
+	// Email panjang yang berbeda harus tetap menghasilkan kunci berbeda
+	lain := strings.Repeat("b", panjangKunciThrottle+1) + "@contoh.sch.id"
+	if kunciThrottle(panjang) == kunciThrottle(lain) {
+		t.Error("dua email panjang berbeda menghasilkan kunci throttle yang sama")
+	}
+}
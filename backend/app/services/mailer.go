@@ -0,0 +1,105 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sim-sekolah/config"
+)
+
+// Mailer adalah abstraksi pengirim email. Implementasi dipilih lewat MAIL_DRIVER.
+type Mailer interface {
+	Kirim(tujuan, subjek, isi string) error
+}
+
+// ── SMTP ──────────────────────────────────────────────────────
+
+// SMTPMailer mengirim email lewat server SMTP (PLAIN auth)
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m SMTPMailer) Kirim(tujuan, subjek, isi string) error {
+	pesan := strings.Join([]string{
+		"From: " + m.From,
+		"To: " + tujuan,
+		"Subject: " + subjek,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		isi,
+	}, "\r\n")
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	return smtp.SendMail(m.Host+":"+m.Port, auth, m.From, []string{tujuan}, []byte(pesan))
+}
+
+// ── File / Log ────────────────────────────────────────────────
+
+// FileMailer tidak mengirim apa pun; email ditulis ke file .eml di Dir dan
+// dicetak ke log. Dipakai untuk development lokal dan pengujian.
+type FileMailer struct {
+	Dir string
+}
+
+func (m FileMailer) Kirim(tujuan, subjek, isi string) error {
+	log.Printf("📧 [mail] ke=%s subjek=%q\n%s", tujuan, subjek, isi)
+	if m.Dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.Dir, 0755); err != nil {
+		return err
+	}
+	nama := fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), strings.NewReplacer("@", "_at_", "/", "_").Replace(tujuan))
+	konten := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", tujuan, subjek, isi)
+	return os.WriteFile(filepath.Join(m.Dir, nama), []byte(konten), 0644)
+}
+
+// ── Registry ──────────────────────────────────────────────────
+
+var (
+	mailer     Mailer
+	mailerOnce sync.Once
+)
+
+// GetMailer mengembalikan mailer aktif sesuai konfigurasi environment
+func GetMailer() Mailer {
+	mailerOnce.Do(func() {
+		if mailer != nil {
+			return
+		}
+		switch config.GetEnv("MAIL_DRIVER", "file") {
+		case "smtp":
+			mailer = SMTPMailer{
+				Host:     os.Getenv("SMTP_HOST"),
+				Port:     config.GetEnv("SMTP_PORT", "587"),
+				Username: os.Getenv("SMTP_USERNAME"),
+				Password: os.Getenv("SMTP_PASSWORD"),
+				From:     config.GetEnv("MAIL_FROM", "noreply@simsekolah.sch.id"),
+			}
+		default:
+			mailer = FileMailer{Dir: config.GetEnv("MAIL_DIR", "./storage/mail")}
+		}
+	})
+	return mailer
+}
+
+// SetMailer mengganti mailer aktif (misalnya dengan FileMailer saat pengujian)
+func SetMailer(m Mailer) {
+	mailerOnce.Do(func() {})
+	mailer = m
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+// KelengkapanPengampu adalah rekap pengisian nilai satu penugasan mengajar
+// (pengampu mapel di satu kelas)
+type KelengkapanPengampu struct {
+	PengampuID      uint    `json:"pengampu_id"`
+	GuruID          uint    `json:"guru_id"`
+	NamaGuru        string  `json:"nama_guru"`
+	KelasID         uint    `json:"kelas_id"`
+	NamaKelas       string  `json:"nama_kelas"`
+	MataPelajaranID uint    `json:"mata_pelajaran_id"`
+	NamaMapel       string  `json:"nama_mapel"`
+	JumlahSiswa     int64   `json:"jumlah_siswa"`
+	Terisi          int64   `json:"terisi"`
+	Kurang          int64   `json:"kurang"`
+	Persentase      float64 `json:"persentase"`
+}
+
+// kolomKomponenNilai memetakan komponen nilai ke kolomnya di tabel nilais
+var kolomKomponenNilai = map[string]string{
+	models.KomponenHarian: "n.nilai_harian",
+	models.KomponenUTS:    "n.nilai_uts",
+	models.KomponenUAS:    "n.nilai_uas",
+}
+
+// kondisiNilaiTerisi adalah kondisi SQL bahwa baris nilai n sudah berisi
+// komponen yang dipantau (kosong = ketiga komponen). Kolom komponen tidak dapat
+// membedakan 0 dengan belum diisi, sehingga nilai 0 dianggap belum diisi; baris
+// hasil sinkron tugas/ujian yang baru mengisi satu komponen tidak dihitung
+// lengkap.
+func kondisiNilaiTerisi(komponen string) string {
+	if kolom, ok := kolomKomponenNilai[komponen]; ok {
+		return kolom + " > 0"
+	}
+	return "n.nilai_harian > 0 AND n.nilai_uts > 0 AND n.nilai_uas > 0"
+}
+
+// KelengkapanNilai membandingkan penugasan pengampu mapel dengan nilai yang
+// sudah diinput untuk setiap siswa yang menempati kelasnya pada semester itu.
+// Jika hanyaKurang bernilai true, penugasan yang nilainya sudah lengkap tidak
+// disertakan.
+func KelengkapanNilai(f FilterAnalitik, hanyaKurang bool) ([]KelengkapanPengampu, error) {
+	q := config.DB.Table("pengampu_mapels p").
+		Joins("JOIN semesters sm ON sm.id = p.semester_id").
+		Joins("JOIN kelas k ON k.id = p.kelas_id").
+		Joins("JOIN gurus g ON g.id = p.guru_id").
+		Joins("JOIN mata_pelajarans mp ON mp.id = p.mata_pelajaran_id").
+		Joins(`CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS jumlah_siswa,
+				COUNT(DISTINCT n.siswa_id) FILTER (WHERE ` + kondisiNilaiTerisi(f.Komponen) + `) AS terisi
+			FROM siswas s
+			LEFT JOIN nilais n ON n.siswa_id = s.id
+				AND n.semester_id = p.semester_id AND n.mata_pelajaran_id = p.mata_pelajaran_id
+			WHERE ` + kondisiSiswaDiKelasSemester + `
+		) hit`).
+		Select(`p.id AS pengampu_id, g.id AS guru_id, g.nama AS nama_guru,
+			k.id AS kelas_id, k.nama AS nama_kelas,
+			mp.id AS mata_pelajaran_id, mp.nama AS nama_mapel,
+			hit.jumlah_siswa, hit.terisi, hit.jumlah_siswa - hit.terisi AS kurang,
+			CASE WHEN hit.jumlah_siswa = 0 THEN 100
+				ELSE ROUND(100.0 * hit.terisi / hit.jumlah_siswa, 2) END AS persentase`).
+		Order("persentase ASC, g.nama, k.nama, mp.nama")
+	q = f.terapkan(q, "p.mata_pelajaran_id")
+	if f.GuruID != 0 {
+		q = q.Where("p.guru_id = ?", f.GuruID)
+	}
+	if hanyaKurang {
+		q = q.Where("hit.jumlah_siswa > hit.terisi")
+	}
+
+	var hasil []KelengkapanPengampu
+	err := q.Scan(&hasil).Error
+	return hasil, err
+}
+
+// KelengkapanGuru merangkum kelengkapan input absensi dan nilai seorang guru
+type KelengkapanGuru struct {
+	GuruID            uint     `json:"guru_id"`
+	UserID            uint     `json:"-"`
+	Nama              string   `json:"nama"`
+	NIP               string   `json:"nip"`
+	JumlahPertemuan   int64    `json:"jumlah_pertemuan"`
+	PertemuanTerisi   int64    `json:"pertemuan_terisi"`
+	PertemuanKosong   int64    `json:"pertemuan_kosong"`
+	PersentaseAbsensi *float64 `json:"persentase_absensi"` // nil jika belum ada pertemuan yang lewat
+	JumlahNilai       int64    `json:"jumlah_nilai"`       // siswa × penugasan mapel
+	NilaiTerisi       int64    `json:"nilai_terisi"`
+	NilaiKurang       int64    `json:"nilai_kurang"`
+	PersentaseNilai   *float64 `json:"persentase_nilai"` // nil jika tidak punya penugasan
+
+	// Rincian, hanya diisi jika diminta
+	SesiKosong    []KelengkapanJadwal   `json:"sesi_kosong,omitempty"`
+	NilaiBelumAda []KelengkapanPengampu `json:"nilai_belum_ada,omitempty"`
+}
+
+func persen(bagian, total int64) *float64 {
+	if total == 0 {
+		return nil
+	}
+	p := float64(bagian) / float64(total) * 100
+	return &p
+}
+
+// MonitorKelengkapan menghitung kelengkapan input setiap guru yang memiliki
+// jadwal atau penugasan pada filter tersebut, diurutkan dari yang paling
+// tidak lengkap. Jika rinci bernilai true, daftar sesi tanpa absensi dan
+// penugasan yang nilainya belum lengkap ikut disertakan.
+func MonitorKelengkapan(f FilterAnalitik, rinci bool) ([]KelengkapanGuru, error) {
+	jadwal, err := KelengkapanAbsensi(f, false)
+	if err != nil {
+		return nil, err
+	}
+	pengampu, err := KelengkapanNilai(f, false)
+	if err != nil {
+		return nil, err
+	}
+
+	perGuru := map[uint]*KelengkapanGuru{}
+	ambil := func(id uint) *KelengkapanGuru {
+		if g, ok := perGuru[id]; ok {
+			return g
+		}
+		g := &KelengkapanGuru{GuruID: id}
+		perGuru[id] = g
+		return g
+	}
+	for _, j := range jadwal {
+		g := ambil(j.GuruID)
+		g.JumlahPertemuan += j.JumlahPertemuan
+		g.PertemuanTerisi += j.Terisi
+		if rinci && j.Kosong > 0 {
+			g.SesiKosong = append(g.SesiKosong, j)
+		}
+	}
+	for _, p := range pengampu {
+		g := ambil(p.GuruID)
+		g.JumlahNilai += p.JumlahSiswa
+		g.NilaiTerisi += p.Terisi
+		if rinci && p.Kurang > 0 {
+			g.NilaiBelumAda = append(g.NilaiBelumAda, p)
+		}
+	}
+
+	ids := make([]uint, 0, len(perGuru))
+	for id := range perGuru {
+		ids = append(ids, id)
+	}
+	var guru []models.Guru
+	if len(ids) > 0 {
+		if err := config.DB.Where("id IN ?", ids).Find(&guru).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	hasil := make([]KelengkapanGuru, 0, len(guru))
+	for _, gr := range guru {
+		g := perGuru[gr.ID]
+		g.UserID, g.Nama, g.NIP = gr.UserID, gr.Nama, gr.NIP
+		g.PertemuanKosong = g.JumlahPertemuan - g.PertemuanTerisi
+		g.NilaiKurang = g.JumlahNilai - g.NilaiTerisi
+		g.PersentaseAbsensi = persen(g.PertemuanTerisi, g.JumlahPertemuan)
+		g.PersentaseNilai = persen(g.NilaiTerisi, g.JumlahNilai)
+		hasil = append(hasil, *g)
+	}
+
+	// Paling tidak lengkap di atas; guru tanpa data dianggap lengkap
+	skor := func(g KelengkapanGuru) float64 {
+		s := 200.0
+		if g.PersentaseAbsensi != nil {
+			s = s - 100 + *g.PersentaseAbsensi
+		}
+		if g.PersentaseNilai != nil {
+			s = s - 100 + *g.PersentaseNilai
+		}
+		return s
+	}
+	sort.SliceStable(hasil, func(a, b int) bool {
+		if sa, sb := skor(hasil[a]), skor(hasil[b]); sa != sb {
+			return sa < sb
+		}
+		return hasil[a].Nama < hasil[b].Nama
+	})
+	return hasil, nil
+}
+
+// ── Pengingat harian ──────────────────────────────────────────
+
+// JobPengingatKelengkapan mengirim notifikasi ke guru yang input absensi atau
+// nilainya belum lengkap pada semester aktif
+const JobPengingatKelengkapan = "pengingat_kelengkapan"
+
+type payloadPengingatKelengkapan struct {
+	// Pengingat nilai mulai dikirim sekian hari sebelum batas input nilai
+	HariSebelumBatas int `json:"hari_sebelum_batas"`
+}
+
+// AntrikanPengingatKelengkapan menjalankan pengingat di luar jadwal hariannya
+func AntrikanPengingatKelengkapan() (*models.Job, error) {
+	return AntrikanJob(JobPengingatKelengkapan, payloadPengingatKelengkapan{
+		HariSebelumBatas: config.GetEnvInt("PENGINGAT_NILAI_HARI_SEBELUM_BATAS", 7),
+	})
+}
+
+func kirimPengingatKelengkapan(ctx context.Context, _ *models.Job, p payloadPengingatKelengkapan) error {
+	var semester models.Semester
+	if err := config.DB.WithContext(ctx).Where("is_aktif = ?", true).First(&semester).Error; err != nil {
+		return nil // belum ada semester aktif, tidak ada yang diingatkan
+	}
+
+	guru, err := MonitorKelengkapan(FilterAnalitik{SemesterID: semester.ID}, false)
+	if err != nil {
+		return err
+	}
+
+	// Nilai baru ditagih menjelang batas input nilai agar guru tidak diingatkan
+	// sejak awal semester
+	ingatkanNilai := semester.BatasInputNilai != nil &&
+		!time.Now().Before(semester.BatasInputNilai.AddDate(0, 0, -p.HariSebelumBatas))
+
+	var list []models.Notification
+	for _, g := range guru {
+		if g.UserID == 0 {
+			continue
+		}
+		if g.PertemuanKosong > 0 {
+			list = append(list, models.Notification{
+				UserID:  g.UserID,
+				Type:    models.NotifAbsensi,
+				Icon:    "⏰",
+				Title:   "Absensi belum diisi",
+				Message: fmt.Sprintf("Ada %d pertemuan semester ini yang belum diisi absensinya.", g.PertemuanKosong),
+				Link:    "/absensi",
+			})
+		}
+		if ingatkanNilai && g.NilaiKurang > 0 {
+			list = append(list, models.Notification{
+				UserID: g.UserID,
+				Type:   models.NotifNilai,
+				Icon:   "⏰",
+				Title:  "Nilai belum lengkap",
+				Message: fmt.Sprintf("Masih ada %d nilai siswa yang belum diinput. Batas input nilai: %s.",
+					g.NilaiKurang, semester.BatasInputNilai.Format("02-01-2006")),
+				Link: "/nilai",
+			})
+		}
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&list, 500).Error
+	})
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+var ErrTokenResetTidakValid = errors.New("token reset tidak valid atau sudah kadaluarsa")
+
+func masaBerlakuTokenReset() time.Duration {
+	return time.Duration(config.GetEnvInt("RESET_PASSWORD_TTL_MENIT", 60)) * time.Minute
+}
+
+// HashToken menghasilkan hash SHA-256 (hex) dari token mentah
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenAcak membuat string hex acak sepanjang 2*n karakter
+func TokenAcak(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// KirimLinkResetPassword membuat token reset baru untuk user, membatalkan
+// token lama yang belum terpakai, lalu mengirim tautan reset lewat email.
+func KirimLinkResetPassword(user models.User, ip string) error {
+	token, err := TokenAcak(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	reset := models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: HashToken(token),
+		ExpiresAt: now.Add(masaBerlakuTokenReset()),
+		IPAddress: ip,
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&reset).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", config.GetEnv("APP_FRONTEND_URL", "http://localhost:5173"), token)
+	isi := fmt.Sprintf(
+		"Halo %s,\n\n"+
+			"Kami menerima permintaan untuk mengatur ulang password akun SIM Sekolah Anda.\n"+
+			"Buka tautan berikut untuk membuat password baru (berlaku %d menit, hanya sekali pakai):\n\n"+
+			"%s\n\n"+
+			"Jika Anda tidak meminta reset password, abaikan email ini.\n",
+		user.Nama, int(masaBerlakuTokenReset().Minutes()), link,
+	)
+	return GetMailer().Kirim(user.Email, "Reset Password SIM Sekolah", isi)
+}
+
+// ResetPasswordDenganToken memvalidasi token lalu mengganti password user.
+// Token ditandai terpakai dalam transaksi yang sama sehingga tidak bisa dipakai ulang.
+func ResetPasswordDenganToken(token, passwordBaru string) (*models.User, error) {
+	var user models.User
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var reset models.PasswordResetToken
+		if err := tx.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", HashToken(token), time.Now()).
+			First(&reset).Error; err != nil {
+			return ErrTokenResetTidakValid
+		}
+
+		// Klaim token secara atomik: hanya satu request yang berhasil
+		res := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", reset.ID).
+			Update("used_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrTokenResetTidakValid
+		}
+
+		if err := tx.Where("is_active = true").First(&user, reset.UserID).Error; err != nil {
+			return ErrTokenResetTidakValid
+		}
+		if err := user.HashPassword(passwordBaru); err != nil {
+			return err
+		}
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"password":             user.Password,
+			"wajib_ganti_password": false,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Reset password yang berhasil juga membuka lockout akun
+	ResetLoginGagal(user.Email)
+	return &user, nil
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+// GuruMengampu mengecek apakah guru ditugaskan mengampu mapel di kelas pada semester tersebut
+func GuruMengampu(guruID, semesterID, kelasID, mapelID uint) bool {
+	var count int64
+	config.DB.Model(&models.PengampuMapel{}).
+		Where("guru_id = ? AND semester_id = ? AND kelas_id = ? AND mata_pelajaran_id = ?",
+			guruID, semesterID, kelasID, mapelID).
+		Count(&count)
+	return count > 0
+}
+
+// ── Beban Mengajar ────────────────────────────────────────────
+
+type BebanMengajarItem struct {
+	KelasID      uint   `json:"kelas_id"`
+	Kelas        string `json:"kelas"`
+	MapelID      uint   `json:"mata_pelajaran_id"`
+	Mapel        string `json:"mata_pelajaran"`
+	JamPerMinggu int    `json:"jam_per_minggu"`
+}
+
+type BebanMengajarGuru struct {
+	GuruID      uint                `json:"guru_id"`
+	Nama        string              `json:"nama"`
+	NIP         string              `json:"nip"`
+	TotalJP     int                 `json:"total_jp"`    // dari kurikulum (JP per minggu)
+	JumlahSesi  int64               `json:"jumlah_sesi"` // sesi terjadwal per minggu
+	JumlahKelas int                 `json:"jumlah_kelas"`
+	Penugasan   []BebanMengajarItem `json:"penugasan"`
+}
+
+// HitungBebanMengajar merangkum beban mengajar per guru pada satu semester.
+// JP diambil dari kurikulum kelas; sesi dihitung dari grid jadwal.
+// guruID 0 berarti semua guru.
+func HitungBebanMengajar(semesterID, guruID uint) []BebanMengajarGuru {
+	query := config.DB.Preload("Guru").Preload("Kelas").Preload("MataPelajaran").
+		Where("semester_id = ?", semesterID)
+	if guruID > 0 {
+		query = query.Where("guru_id = ?", guruID)
+	}
+	var pengampu []models.PengampuMapel
+	query.Order("guru_id, kelas_id, mata_pelajaran_id").Find(&pengampu)
+
+	// Cache JP kurikulum per kelas
+	jpKelas := map[uint]map[uint]int{}
+	jpUntuk := func(kelas models.Kelas, mapelID uint) int {
+		if _, ok := jpKelas[kelas.ID]; !ok {
+			jpKelas[kelas.ID] = map[uint]int{}
+			for _, k := range KurikulumKelas(kelas) {
+				jpKelas[kelas.ID][k.MataPelajaranID] = k.JamPerMinggu
+			}
+		}
+		return jpKelas[kelas.ID][mapelID]
+	}
+
+	var hasil []BebanMengajarGuru
+	indeks := map[uint]int{}
+	kelasGuru := map[uint]map[uint]bool{}
+	for _, p := range pengampu {
+		i, ok := indeks[p.GuruID]
+		if !ok {
+			hasil = append(hasil, BebanMengajarGuru{
+				GuruID:    p.GuruID,
+				Nama:      p.Guru.Nama,
+				NIP:       p.Guru.NIP,
+				Penugasan: []BebanMengajarItem{},
+			})
+			i = len(hasil) - 1
+			indeks[p.GuruID] = i
+			kelasGuru[p.GuruID] = map[uint]bool{}
+		}
+
+		jp := jpUntuk(p.Kelas, p.MataPelajaranID)
+		hasil[i].TotalJP += jp
+		hasil[i].Penugasan = append(hasil[i].Penugasan, BebanMengajarItem{
+			KelasID:      p.KelasID,
+			Kelas:        p.Kelas.Nama,
+			MapelID:      p.MataPelajaranID,
+			Mapel:        p.MataPelajaran.Nama,
+			JamPerMinggu: jp,
+		})
+		kelasGuru[p.GuruID][p.KelasID] = true
+	}
+
+	for i := range hasil {
+		hasil[i].JumlahKelas = len(kelasGuru[hasil[i].GuruID])
+		config.DB.Model(&models.Jadwal{}).
+			Where("semester_id = ? AND guru_id = ?", semesterID, hasil[i].GuruID).
+			Count(&hasil[i].JumlahSesi)
+	}
+	return hasil
+}
+
+// ── Sinkronisasi ──────────────────────────────────────────────
+
+type HasilSinkronPengampu struct {
+	Dibuat  int                    `json:"dibuat"`
+	Konflik []models.PengampuMapel `json:"konflik,omitempty"` // kelas+mapel yang dijadwalkan dengan guru berbeda
+}
+
+// SinkronPengampuDariJadwal membuat penugasan dari kombinasi guru–mapel–kelas yang
+// sudah ada di jadwal semester. Penugasan yang sudah ada tidak diubah.
+func SinkronPengampuDariJadwal(semesterID uint) (HasilSinkronPengampu, error) {
+	hasil := HasilSinkronPengampu{}
+
+	var kombinasi []models.PengampuMapel
+	if err := config.DB.Model(&models.Jadwal{}).
+		Select("DISTINCT semester_id, kelas_id, mata_pelajaran_id, guru_id").
+		Where("semester_id = ?", semesterID).
+		Order("kelas_id, mata_pelajaran_id, guru_id").
+		Scan(&kombinasi).Error; err != nil {
+		return hasil, err
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, k := range kombinasi {
+			p := models.PengampuMapel{
+				SemesterID:      k.SemesterID,
+				KelasID:         k.KelasID,
+				MataPelajaranID: k.MataPelajaranID,
+				GuruID:          k.GuruID,
+			}
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&p)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected > 0 {
+				hasil.Dibuat++
+				continue
+			}
+
+			var ada models.PengampuMapel
+			tx.Where("semester_id = ? AND kelas_id = ? AND mata_pelajaran_id = ?",
+				k.SemesterID, k.KelasID, k.MataPelajaranID).First(&ada)
+			if ada.GuruID != k.GuruID {
+				hasil.Konflik = append(hasil.Konflik, k)
+			}
+		}
+		return nil
+	})
+	return hasil, err
+}
+
+// SalinPengampu menyalin penugasan dari semester asal ke semester tujuan dengan
+// pemetaan kelas lama → kelas baru (dipakai saat rollover tahun ajaran)
+func SalinPengampu(tx *gorm.DB, semesterAsalID, semesterTujuanID uint, petaKelas map[uint]uint) (int, error) {
+	var asal []models.PengampuMapel
+	if err := tx.Where("semester_id = ?", semesterAsalID).Find(&asal).Error; err != nil {
+		return 0, err
+	}
+
+	jumlah := 0
+	for _, p := range asal {
+		kelasBaruID, ok := petaKelas[p.KelasID]
+		if !ok {
+			continue
+		}
+		baru := models.PengampuMapel{
+			SemesterID:      semesterTujuanID,
+			KelasID:         kelasBaruID,
+			MataPelajaranID: p.MataPelajaranID,
+			GuruID:          p.GuruID,
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&baru).Error; err != nil {
+			return jumlah, err
+		}
+		jumlah++
+	}
+	return jumlah, nil
+}
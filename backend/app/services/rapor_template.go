@@ -0,0 +1,127 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+// ProfilSekolah mengembalikan identitas sekolah; kosong jika belum diatur
+func ProfilSekolah() models.ProfilSekolah {
+	var profil models.ProfilSekolah
+	config.DB.Where("id = ?", models.ProfilSekolahID).Limit(1).Find(&profil)
+	profil.ID = models.ProfilSekolahID
+	return profil
+}
+
+// TemplateRaporBawaan adalah tata letak yang dipakai jika belum ada template di DB
+func TemplateRaporBawaan() models.TemplateRapor {
+	return models.TemplateRapor{
+		Nama:               "Bawaan",
+		Format:             "merdeka",
+		UkuranKertas:       models.KertasA4,
+		TampilkanKop:       true,
+		TampilkanLogo:      true,
+		TampilkanP5:        true,
+		TampilkanEkskul:    true,
+		TampilkanKehadiran: true,
+		TampilkanPerilaku:  true,
+		TtdOrangTua:        true,
+		TtdKepalaSekolah:   true,
+	}
+}
+
+// TemplateRaporTahunAjaran memilih template rapor untuk sebuah tahun ajaran:
+// template pilihan tahun ajaran, lalu template default, lalu template bawaan
+func TemplateRaporTahunAjaran(tahunAjaranID uint) models.TemplateRapor {
+	var ta models.TahunAjaran
+	if err := config.DB.First(&ta, tahunAjaranID).Error; err == nil && ta.TemplateRaporID != nil {
+		var t models.TemplateRapor
+		if err := config.DB.First(&t, *ta.TemplateRaporID).Error; err == nil {
+			return t
+		}
+	}
+
+	var t models.TemplateRapor
+	if err := config.DB.Where("is_default = ?", true).First(&t).Error; err == nil {
+		return t
+	}
+	return TemplateRaporBawaan()
+}
+
+// MuatTemplateRaporDisk membaca template rapor dari file JSON di
+// RAPOR_TEMPLATE_DIR (default ./templates/rapor) dan menyimpannya ke DB
+// berdasarkan nama, sehingga tata letak bisa diubah tanpa kompilasi ulang.
+// Field yang tidak diisi memakai nilai template bawaan dan nama kosong diambil
+// dari nama file. Template dari disk menimpa perubahan lewat API dengan nama
+// yang sama setiap kali server dijalankan.
+func MuatTemplateRaporDisk() {
+	dir := os.Getenv("RAPOR_TEMPLATE_DIR")
+	if dir == "" {
+		dir = "./templates/rapor"
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) == 0 {
+		return
+	}
+
+	jumlah := 0
+	for _, f := range files {
+		if err := muatFileTemplateRapor(f); err != nil {
+			log.Printf("⚠️  Template rapor %s dilewati: %v", f, err)
+			continue
+		}
+		jumlah++
+	}
+	log.Printf("✅ Template rapor dari disk: %d dari %d file", jumlah, len(files))
+}
+
+func muatFileTemplateRapor(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	t := TemplateRaporBawaan()
+	t.Nama = ""
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	if t.Nama == "" {
+		t.Nama = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if len(t.Nama) > 100 {
+		return fmt.Errorf("nama template lebih dari 100 karakter")
+	}
+	if t.Format != "merdeka" && t.Format != "klasik" {
+		return fmt.Errorf("format %q tidak dikenal (merdeka/klasik)", t.Format)
+	}
+	switch t.UkuranKertas {
+	case models.KertasA4, models.KertasF4, models.KertasLetter:
+	default:
+		return fmt.Errorf("ukuran kertas %q tidak dikenal (A4/F4/Letter)", t.UkuranKertas)
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var lama models.TemplateRapor
+		t.ID = 0
+		if err := tx.Where("nama = ?", t.Nama).Limit(1).Find(&lama).Error; err != nil {
+			return err
+		}
+		if lama.ID != 0 {
+			t.ID, t.CreatedAt = lama.ID, lama.CreatedAt
+		}
+		if t.IsDefault {
+			if err := tx.Model(&models.TemplateRapor{}).Where("id != ?", t.ID).Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(&t).Error
+	})
+}
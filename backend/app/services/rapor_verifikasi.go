@@ -0,0 +1,95 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+	"sim-sekolah/config"
+)
+
+// alfabet kode verifikasi: tanpa 0/O dan 1/I/L agar tidak salah baca saat diketik
+const alfabetKodeVerifikasi = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// KodeVerifikasiAcak membuat kode verifikasi rapor sepanjang 12 karakter
+func KodeVerifikasiAcak() (string, error) {
+	var sb strings.Builder
+	maks := big.NewInt(int64(len(alfabetKodeVerifikasi)))
+	for i := 0; i < 12; i++ {
+		n, err := rand.Int(rand.Reader, maks)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(alfabetKodeVerifikasi[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// HashKonten menghasilkan SHA-256 (hex) dari isi snapshot rapor. Snapshot
+// disimpan sebagai jsonb yang mengubah urutan key dan spasi, jadi hash dihitung
+// dari bentuk kanonik (di-decode lalu di-encode ulang dengan key terurut).
+func HashKonten(snapshot []byte) (string, error) {
+	var isi interface{}
+	if err := json.Unmarshal(snapshot, &isi); err != nil {
+		return "", err
+	}
+	kanonik, err := json.Marshal(isi)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(kanonik)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// HashFile menghasilkan SHA-256 (hex) dari isi file
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// URLVerifikasiRapor adalah alamat publik yang dikodekan di QR rapor
+func URLVerifikasiRapor(kode string) string {
+	base := config.GetEnv("RAPOR_VERIFIKASI_URL", "http://localhost:8080/api/v1/verify/rapor")
+	return strings.TrimRight(base, "/") + "/" + kode
+}
+
+// QRCodePNG membuat gambar QR (PNG) berukuran sisi x sisi piksel
+func QRCodePNG(isi string, sisi int) ([]byte, error) {
+	kode, err := qr.Encode(isi, qr.M, qr.Auto)
+	if err != nil {
+		return nil, err
+	}
+	kode, err = barcode.Scale(kode, sisi, sisi)
+	if err != nil {
+		return nil, err
+	}
+	// barcode menghasilkan gambar 16-bit yang tidak didukung gofpdf
+	gambar := image.NewGray(kode.Bounds())
+	draw.Draw(gambar, gambar.Bounds(), kode, kode.Bounds().Min, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, gambar); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestHashKontenKanonik(t *testing.T) {
+	// Bentuk kanonik: key terurut, tanpa spasi
+	kanonik := `{"nilai":[{"mapel":"Matematika","nilai_akhir":87.5}],"siswa":{"nama":"Ani","nisn":"0012345678"}}`
+	sum := sha256.Sum256([]byte(kanonik))
+	ingin := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		nama     string
+		snapshot string
+	}{
+		{"sudah kanonik", kanonik},
+		{"urutan key berbeda", `{"siswa":{"nisn":"0012345678","nama":"Ani"},"nilai":[{"nilai_akhir":87.5,"mapel":"Matematika"}]}`},
+		{"spasi dan baris baru seperti keluaran jsonb", "{\n  \"nilai\": [ {\"mapel\": \"Matematika\", \"nilai_akhir\": 87.50} ],\n  \"siswa\": {\"nama\": \"Ani\", \"nisn\": \"0012345678\"}\n}"},
+	}
+	for _, tt := range tests {
+		got, err := HashKonten([]byte(tt.snapshot))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.nama, err)
+		}
+		if got != ingin {
+			t.Errorf("%s: HashKonten = %s, ingin %s", tt.nama, got, ingin)
+		}
+	}
+}
+
+func TestHashKontenBerubah(t *testing.T) {
+	asli, err := HashKonten([]byte(`{"siswa":"Ani","nilai":[87.5,90]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ubahan := []string{
+		`{"siswa":"Ani","nilai":[87.6,90]}`,          // nilai diubah
+		`{"siswa":"Ani","nilai":[90,87.5]}`,          // urutan array bermakna
+		`{"siswa":"ani","nilai":[87.5,90]}`,          // huruf besar/kecil bermakna
+		`{"siswa":"Ani","nilai":[87.5,90],"x":null}`, // key tambahan
+	}
+	for _, s := range ubahan {
+		got, err := HashKonten([]byte(s))
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		if got == asli {
+			t.Errorf("snapshot %s menghasilkan hash yang sama dengan aslinya", s)
+		}
+	}
+
+	if _, err := HashKonten([]byte(`{"siswa":`)); err == nil {
+		t.Error("snapshot yang bukan JSON valid harus ditolak")
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+// FilterRekapAbsensi membatasi absensi yang direkap. Nilai nol berarti tidak difilter.
+type FilterRekapAbsensi struct {
+	SemesterID uint
+	KelasID    uint // kelas pada jadwal, bukan kelas siswa saat ini
+	Dari       *time.Time
+	Sampai     *time.Time // inklusif
+}
+
+// Rentang mengembalikan rentang tanggal [dari, sampai) yang dicakup filter:
+// irisan rentang semester (jika tanggalnya diisi) dengan Dari/Sampai. Waktu nol
+// berarti tidak dibatasi di sisi tersebut.
+func (f FilterRekapAbsensi) Rentang() (dari, sampai time.Time) {
+	if f.SemesterID != 0 {
+		var semester models.Semester
+		if err := config.DB.First(&semester, f.SemesterID).Error; err == nil {
+			dari, sampai, _ = RentangSemester(semester)
+		}
+	}
+	if f.Dari != nil && f.Dari.After(dari) {
+		dari = *f.Dari
+	}
+	if f.Sampai != nil {
+		if s := f.Sampai.AddDate(0, 0, 1); sampai.IsZero() || s.Before(sampai) {
+			sampai = s
+		}
+	}
+	return dari, sampai
+}
+
+// RekapAbsensi adalah jumlah absensi per status seorang siswa
+type RekapAbsensi struct {
+	SiswaID         uint    `json:"siswa_id"`
+	Nama            string  `json:"nama"`
+	TotalPertemuan  int64   `json:"total_pertemuan"`
+	Hadir           int64   `json:"hadir"`
+	Izin            int64   `json:"izin"`
+	Sakit           int64   `json:"sakit"`
+	Alfa            int64   `json:"alfa"`
+	PersentaseHadir float64 `json:"persentase_hadir" gorm:"-"`
+}
+
+func (r *RekapAbsensi) hitungPersentase() {
+	if r.TotalPertemuan > 0 {
+		r.PersentaseHadir = float64(r.Hadir) / float64(r.TotalPertemuan) * 100
+	}
+}
+
+const kolomRekapAbsensi = `COUNT(*) AS total_pertemuan,
+	COUNT(*) FILTER (WHERE a.status = 'hadir') AS hadir,
+	COUNT(*) FILTER (WHERE a.status = 'izin') AS izin,
+	COUNT(*) FILTER (WHERE a.status = 'sakit') AS sakit,
+	COUNT(*) FILTER (WHERE a.status = 'alfa') AS alfa`
+
+// queryRekapAbsensi: absensis a ⋈ jadwals j dengan filter semester, kelas dan tanggal
+func queryRekapAbsensi(f FilterRekapAbsensi) *gorm.DB {
+	q := config.DB.Table("absensis a").Joins("JOIN jadwals j ON j.id = a.jadwal_id")
+	if f.SemesterID != 0 {
+		q = q.Where("j.semester_id = ?", f.SemesterID)
+	}
+	if f.KelasID != 0 {
+		q = q.Where("j.kelas_id = ?", f.KelasID)
+	}
+	if f.Dari != nil {
+		q = q.Where("a.tanggal >= ?", *f.Dari)
+	}
+	if f.Sampai != nil {
+		q = q.Where("a.tanggal < ?", f.Sampai.AddDate(0, 0, 1))
+	}
+	return q
+}
+
+// RekapAbsensiSiswa menghitung jumlah absensi per status seorang siswa dalam
+// satu query
+func RekapAbsensiSiswa(siswaID uint, f FilterRekapAbsensi) (RekapAbsensi, error) {
+	rekap := RekapAbsensi{SiswaID: siswaID}
+	err := queryRekapAbsensi(f).
+		Select(kolomRekapAbsensi).
+		Where("a.siswa_id = ?", siswaID).
+		Scan(&rekap).Error
+	rekap.SiswaID = siswaID
+	rekap.hitungPersentase()
+	return rekap, err
+}
+
+// RekapAbsensiPerSiswa menghitung rekap setiap siswa dalam siswaIDs dengan satu
+// query berkelompok. Siswa tanpa absensi tetap muncul dengan nilai nol; hasil
+// diurutkan berdasarkan nama.
+func RekapAbsensiPerSiswa(siswaIDs []uint, f FilterRekapAbsensi) ([]RekapAbsensi, error) {
+	hasil := []RekapAbsensi{}
+	if len(siswaIDs) == 0 {
+		return hasil, nil
+	}
+	agregat := queryRekapAbsensi(f).
+		Select("a.siswa_id, "+kolomRekapAbsensi).
+		Where("a.siswa_id IN ?", siswaIDs).
+		Group("a.siswa_id")
+
+	err := config.DB.Table("siswas s").
+		Select(`s.id AS siswa_id, s.nama,
+			COALESCE(r.total_pertemuan, 0) AS total_pertemuan,
+			COALESCE(r.hadir, 0) AS hadir, COALESCE(r.izin, 0) AS izin,
+			COALESCE(r.sakit, 0) AS sakit, COALESCE(r.alfa, 0) AS alfa`).
+		Joins("LEFT JOIN (?) r ON r.siswa_id = s.id", agregat).
+		Where("s.id IN ? AND s.deleted_at IS NULL", siswaIDs).
+		Order("s.nama ASC").
+		Scan(&hasil).Error
+	for i := range hasil {
+		hasil[i].hitungPersentase()
+	}
+	return hasil, err
+}
+
+// KunciSesi mengidentifikasi satu pertemuan: jadwal pada tanggal tertentu
+type KunciSesi struct {
+	JadwalID uint
+	Tanggal  string // YYYY-MM-DD
+}
+
+// RekapAbsensiSesi menghitung rekap absensi setiap pertemuan jadwal-jadwal
+// tersebut dalam rentang tanggal, dengan satu query berkelompok
+func RekapAbsensiSesi(jadwalIDs []uint, dari, sampai time.Time) (map[KunciSesi]RekapAbsensi, error) {
+	hasil := map[KunciSesi]RekapAbsensi{}
+	if len(jadwalIDs) == 0 {
+		return hasil, nil
+	}
+	var baris []struct {
+		JadwalID uint
+		Tanggal  string
+		RekapAbsensi
+	}
+	err := queryRekapAbsensi(FilterRekapAbsensi{Dari: &dari, Sampai: &sampai}).
+		Select("a.jadwal_id, to_char(a.tanggal, 'YYYY-MM-DD') AS tanggal, "+kolomRekapAbsensi).
+		Where("a.jadwal_id IN ?", jadwalIDs).
+		Group("1, 2").
+		Scan(&baris).Error
+	for _, b := range baris {
+		b.RekapAbsensi.hitungPersentase()
+		hasil[KunciSesi{b.JadwalID, b.Tanggal}] = b.RekapAbsensi
+	}
+	return hasil, err
+}
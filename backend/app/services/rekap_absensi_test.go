@@ -0,0 +1,237 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+// Ukuran data benchmark: satu kelas dengan jumlahSiswaBench siswa, 10 jadwal
+// per minggu selama 18 minggu (satu semester)
+const (
+	jumlahSiswaBench  = 36
+	jadwalPerMinggu   = 10
+	jumlahMingguBench = 18
+)
+
+// bukaDBBenchmark membuka koneksi ke database PostgreSQL dari variabel DB_*
+// yang sama dengan aplikasi. Benchmark dilewati jika DB_HOST kosong.
+func bukaDBBenchmark(b *testing.B) *gorm.DB {
+	if os.Getenv("DB_HOST") == "" {
+		b.Skip("DB_HOST tidak diisi; benchmark rekap absensi membutuhkan database PostgreSQL yang sudah dimigrasi")
+	}
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=Asia/Jakarta",
+		os.Getenv("DB_HOST"), os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"),
+		os.Getenv("DB_NAME"), os.Getenv("DB_PORT"), os.Getenv("DB_SSLMODE"))
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		b.Fatalf("koneksi database gagal: %v", err)
+	}
+	return db
+}
+
+// dataRekapBench adalah kelas, semester dan siswa hasil seedRekapAbsensi
+type dataRekapBench struct {
+	kelasID    uint
+	semesterID uint
+	siswaIDs   []uint
+}
+
+// seedRekapAbsensi mengisi satu kelas lengkap beserta absensinya selama satu
+// semester. Dipanggil di dalam transaksi yang di-rollback setelah benchmark.
+func seedRekapAbsensi(tx *gorm.DB) (dataRekapBench, error) {
+	var data dataRekapBench
+	kode := fmt.Sprintf("b%d", time.Now().UnixNano()%1e9) // muat di kolom kode/NISN
+
+	role := models.Role{Nama: kode}
+	if err := tx.Create(&role).Error; err != nil {
+		return data, err
+	}
+	ta := models.TahunAjaran{Nama: "bench"}
+	if err := tx.Create(&ta).Error; err != nil {
+		return data, err
+	}
+	mulai := time.Date(2025, 7, 14, 0, 0, 0, 0, time.Local)
+	selesai := mulai.AddDate(0, 0, 7*jumlahMingguBench)
+	semester := models.Semester{TahunAjaranID: ta.ID, Nama: "Ganjil", TanggalMulai: &mulai, TanggalSelesai: &selesai}
+	if err := tx.Create(&semester).Error; err != nil {
+		return data, err
+	}
+	jurusan := models.Jurusan{Kode: kode, Nama: "Bench"}
+	if err := tx.Create(&jurusan).Error; err != nil {
+		return data, err
+	}
+	kelas := models.Kelas{Nama: "X BENCH", Tingkat: "X", JurusanID: jurusan.ID, TahunAjaranID: ta.ID}
+	if err := tx.Create(&kelas).Error; err != nil {
+		return data, err
+	}
+	mapel := models.MataPelajaran{Kode: kode, Nama: "Bench"}
+	if err := tx.Create(&mapel).Error; err != nil {
+		return data, err
+	}
+
+	buatUser := func(nama string) (models.User, error) {
+		u := models.User{RoleID: role.ID, Nama: nama, Email: nama + "@" + kode + ".test", Password: "-"}
+		return u, tx.Create(&u).Error
+	}
+	uGuru, err := buatUser("guru")
+	if err != nil {
+		return data, err
+	}
+	guru := models.Guru{UserID: uGuru.ID, NIP: kode, Nama: "Guru Bench"}
+	if err := tx.Create(&guru).Error; err != nil {
+		return data, err
+	}
+
+	jadwalIDs := make([]uint, jadwalPerMinggu)
+	for i := range jadwalIDs {
+		j := models.Jadwal{KelasID: kelas.ID, GuruID: guru.ID, MataPelajaranID: mapel.ID, SemesterID: semester.ID,
+			HariKe: i%5 + 1, JamMulai: "07:00", JamSelesai: "08:30"}
+		if err := tx.Create(&j).Error; err != nil {
+			return data, err
+		}
+		jadwalIDs[i] = j.ID
+	}
+
+	status := []string{"hadir", "hadir", "hadir", "hadir", "hadir", "hadir", "izin", "sakit", "alfa"}
+	for i := 0; i < jumlahSiswaBench; i++ {
+		u, err := buatUser(fmt.Sprintf("siswa%d", i))
+		if err != nil {
+			return data, err
+		}
+		nomor := fmt.Sprintf("%s%d", kode, i)
+		s := models.Siswa{UserID: u.ID, NISN: nomor, NIS: nomor, Nama: fmt.Sprintf("Siswa %02d", i), KelasID: &kelas.ID}
+		if err := tx.Create(&s).Error; err != nil {
+			return data, err
+		}
+		data.siswaIDs = append(data.siswaIDs, s.ID)
+
+		absensi := make([]models.Absensi, 0, jadwalPerMinggu*jumlahMingguBench)
+		for m := 0; m < jumlahMingguBench; m++ {
+			for k, jadwalID := range jadwalIDs {
+				absensi = append(absensi, models.Absensi{
+					SiswaID:  s.ID,
+					JadwalID: jadwalID,
+					Tanggal:  mulai.AddDate(0, 0, 7*m+k%5),
+					Status:   status[(i+m+k)%len(status)],
+				})
+			}
+		}
+		if err := tx.CreateInBatches(absensi, 500).Error; err != nil {
+			return data, err
+		}
+	}
+	data.kelasID, data.semesterID = kelas.ID, semester.ID
+	return data, nil
+}
+
+// rekapAbsensiKelasLama adalah cara lama GetRekapAbsensiKelas: memuat seluruh
+// baris absensi tiap siswa lalu menghitung statusnya di Go (1 + N query)
+func rekapAbsensiKelasLama(siswaIDs []uint, semesterID, kelasID uint) []RekapAbsensi {
+	var siswaList []models.Siswa
+	config.DB.Where("id IN ?", siswaIDs).Order("nama ASC").Find(&siswaList)
+
+	var rekapList []RekapAbsensi
+	for _, s := range siswaList {
+		var absensiList []models.Absensi
+		config.DB.Joins("JOIN jadwals ON jadwals.id = absensis.jadwal_id").
+			Where("absensis.siswa_id = ? AND jadwals.semester_id = ? AND jadwals.kelas_id = ?", s.ID, semesterID, kelasID).
+			Find(&absensiList)
+
+		counts := map[string]int64{"hadir": 0, "izin": 0, "sakit": 0, "alfa": 0}
+		for _, a := range absensiList {
+			counts[a.Status]++
+		}
+		r := RekapAbsensi{
+			SiswaID:        s.ID,
+			Nama:           s.Nama,
+			TotalPertemuan: int64(len(absensiList)),
+			Hadir:          counts["hadir"],
+			Izin:           counts["izin"],
+			Sakit:          counts["sakit"],
+			Alfa:           counts["alfa"],
+		}
+		r.hitungPersentase()
+		rekapList = append(rekapList, r)
+	}
+	return rekapList
+}
+
+// hitungQuery mendaftarkan callback yang menghitung jumlah query yang dijalankan
+// ke database (penyusunan subquery berjalan dalam mode DryRun dan tidak dihitung)
+func hitungQuery(db *gorm.DB, n *int64) {
+	tambah := func(tx *gorm.DB) {
+		if !tx.DryRun {
+			*n++
+		}
+	}
+	db.Callback().Query().After("gorm:query").Register("bench:hitung_query", tambah)
+	db.Callback().Row().After("gorm:row").Register("bench:hitung_row", tambah)
+}
+
+// BenchmarkRekapAbsensiKelas membandingkan rekap absensi satu kelas per
+// semester cara lama (query per siswa) dengan RekapAbsensiPerSiswa (satu query
+// berkelompok). Data di-seed di dalam transaksi yang selalu di-rollback.
+//
+//	DB_HOST=... DB_USER=... DB_NAME=... go test ./app/services -run '^$' -bench RekapAbsensiKelas -benchmem
+func BenchmarkRekapAbsensiKelas(b *testing.B) {
+	db := bukaDBBenchmark(b)
+	var jumlahQuery int64
+	hitungQuery(db, &jumlahQuery)
+
+	errRollback := errors.New("rollback benchmark")
+	dbLama := config.DB
+	defer func() { config.DB = dbLama }()
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		data, err := seedRekapAbsensi(tx)
+		if err != nil {
+			return err
+		}
+		config.DB = tx
+		filter := FilterRekapAbsensi{SemesterID: data.semesterID, KelasID: data.kelasID}
+
+		lama := rekapAbsensiKelasLama(data.siswaIDs, data.semesterID, data.kelasID)
+		baru, err := RekapAbsensiPerSiswa(data.siswaIDs, filter)
+		if err != nil {
+			return err
+		}
+		if len(lama) != len(baru) {
+			b.Fatalf("jumlah siswa berbeda: lama %d, baru %d", len(lama), len(baru))
+		}
+		for i := range lama {
+			if lama[i] != baru[i] {
+				b.Fatalf("rekap siswa %d berbeda: lama %+v, baru %+v", lama[i].SiswaID, lama[i], baru[i])
+			}
+		}
+
+		b.Run("lama", func(b *testing.B) {
+			jumlahQuery = 0
+			for i := 0; i < b.N; i++ {
+				rekapAbsensiKelasLama(data.siswaIDs, data.semesterID, data.kelasID)
+			}
+			b.ReportMetric(float64(jumlahQuery)/float64(b.N), "query/op")
+		})
+		b.Run("baru", func(b *testing.B) {
+			jumlahQuery = 0
+			for i := 0; i < b.N; i++ {
+				if _, err := RekapAbsensiPerSiswa(data.siswaIDs, filter); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(jumlahQuery)/float64(b.N), "query/op")
+		})
+		return errRollback
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		b.Fatalf("seed data benchmark gagal: %v", err)
+	}
+}
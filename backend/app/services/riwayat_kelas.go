@@ -0,0 +1,207 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+// CatatPerubahanKelas menutup riwayat kelas siswa yang masih berjalan lalu membuka
+// riwayat baru di kelasID. kelasID nil berarti siswa keluar dari kelas (misalnya lulus).
+// Harus dipanggil di dalam transaksi yang sama dengan update siswas.kelas_id.
+func CatatPerubahanKelas(tx *gorm.DB, siswaID uint, kelasID *uint, sumber, alasan string, waktu time.Time) error {
+	var aktif models.RiwayatKelas
+	err := tx.Where("siswa_id = ? AND tanggal_selesai IS NULL", siswaID).
+		Order("tanggal_mulai DESC").First(&aktif).Error
+	adaAktif := err == nil
+
+	// Tidak ada perubahan kelas
+	if adaAktif && kelasID != nil && aktif.KelasID == *kelasID {
+		return nil
+	}
+
+	if adaAktif {
+		if err := tx.Model(&models.RiwayatKelas{}).
+			Where("siswa_id = ? AND tanggal_selesai IS NULL", siswaID).
+			Update("tanggal_selesai", waktu).Error; err != nil {
+			return err
+		}
+	}
+
+	if kelasID == nil {
+		return nil
+	}
+
+	var kelas models.Kelas
+	if err := tx.First(&kelas, *kelasID).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.RiwayatKelas{
+		SiswaID:       siswaID,
+		KelasID:       kelas.ID,
+		TahunAjaranID: kelas.TahunAjaranID,
+		TanggalMulai:  waktu,
+		Sumber:        sumber,
+		Alasan:        alasan,
+	}).Error
+}
+
+// RentangSemester mengembalikan rentang tanggal semester [dari, sampai), dengan
+// sampai = sehari setelah tanggal_selesai. ok bernilai false jika tanggal mulai
+// atau selesai semester belum diisi.
+func RentangSemester(semester models.Semester) (dari, sampai time.Time, ok bool) {
+	if semester.TanggalMulai == nil || semester.TanggalSelesai == nil {
+		return dari, sampai, false
+	}
+	return *semester.TanggalMulai, semester.TanggalSelesai.AddDate(0, 0, 1), true
+}
+
+// KelasSiswaDiSemester mengembalikan kelas yang ditempati siswa pada semester
+// tersebut: riwayat di tahun ajaran semester itu yang masa berlakunya beririsan
+// dengan rentang tanggal semester (jika siswa pindah di tengah semester, dipakai
+// kelas terakhirnya). Jika tanggal semester belum diisi, dipakai riwayat
+// terakhir di tahun ajaran itu. Siswa yang belum punya riwayat sama sekali di
+// tahun ajaran tersebut memakai kelasnya saat ini selama tahun ajarannya sama.
+func KelasSiswaDiSemester(siswaID, semesterID uint) *models.Kelas {
+	var semester models.Semester
+	if err := config.DB.First(&semester, semesterID).Error; err != nil {
+		return nil
+	}
+
+	query := config.DB.Preload("Kelas.Jurusan").Preload("Kelas.WaliKelas").
+		Where("siswa_id = ? AND tahun_ajaran_id = ?", siswaID, semester.TahunAjaranID)
+	if dari, sampai, ok := RentangSemester(semester); ok {
+		query = query.Where("tanggal_mulai < ? AND (tanggal_selesai IS NULL OR tanggal_selesai > ?)", sampai, dari)
+	}
+	var riwayat models.RiwayatKelas
+	if err := query.Order("tanggal_mulai DESC").First(&riwayat).Error; err == nil {
+		return &riwayat.Kelas
+	}
+
+	var adaRiwayat int64
+	config.DB.Model(&models.RiwayatKelas{}).
+		Where("siswa_id = ? AND tahun_ajaran_id = ?", siswaID, semester.TahunAjaranID).
+		Count(&adaRiwayat)
+	if adaRiwayat > 0 {
+		return nil // sudah punya riwayat, tetapi tidak di kelas mana pun selama semester ini
+	}
+
+	var siswa models.Siswa
+	if err := config.DB.Preload("Kelas.Jurusan").Preload("Kelas.WaliKelas").First(&siswa, siswaID).Error; err != nil {
+		return nil
+	}
+	if siswa.Kelas != nil && siswa.Kelas.TahunAjaranID == semester.TahunAjaranID {
+		return siswa.Kelas
+	}
+	return nil
+}
+
+// SiswaIDDiKelas mengembalikan ID siswa yang tercatat di kelas tersebut pada
+// rentang [dari, sampai), termasuk yang pindah atau naik kelas di tengah
+// rentang. Waktu nol berarti rentang tidak dibatasi di sisi tersebut.
+func SiswaIDDiKelas(kelasID uint, dari, sampai time.Time) []uint {
+	query := config.DB.Model(&models.RiwayatKelas{}).Where("kelas_id = ?", kelasID)
+	if !sampai.IsZero() {
+		query = query.Where("tanggal_mulai < ?", sampai)
+	}
+	if !dari.IsZero() {
+		query = query.Where("tanggal_selesai IS NULL OR tanggal_selesai > ?", dari)
+	}
+	var ids []uint
+	query.Distinct().Pluck("siswa_id", &ids)
+
+	// Siswa yang belum memiliki riwayat sama sekali (data lama) tetap disertakan
+	var tanpaRiwayat []uint
+	config.DB.Model(&models.Siswa{}).
+		Where("kelas_id = ?", kelasID).
+		Where("NOT EXISTS (SELECT 1 FROM riwayat_kelas rk WHERE rk.siswa_id = siswas.id)").
+		Pluck("id", &tanpaRiwayat)
+	return append(ids, tanpaRiwayat...)
+}
+
+// SiswaIDKelasSemester mengembalikan ID siswa yang kelasnya pada semester
+// tersebut (menurut KelasSiswaDiSemester) adalah kelas ini, sehingga laporan per
+// kelas memuat siswa yang sama dengan rapornya. Siswa yang pindah kelas di
+// tengah semester hanya dihitung di kelas terakhirnya.
+func SiswaIDKelasSemester(kelasID uint, semester models.Semester) []uint {
+	dari, sampai, _ := RentangSemester(semester)
+	var ids []uint
+	for _, id := range SiswaIDDiKelas(kelasID, dari, sampai) {
+		if k := KelasSiswaDiSemester(id, semester.ID); k != nil && k.ID == kelasID {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// BackfillRiwayatKelas membuat riwayat awal untuk siswa yang sudah punya kelas
+// tetapi belum memiliki riwayat sama sekali (data sebelum fitur riwayat kelas)
+func BackfillRiwayatKelas() {
+	var siswaList []models.Siswa
+	config.DB.Where("kelas_id IS NOT NULL").
+		Where("NOT EXISTS (SELECT 1 FROM riwayat_kelas rk WHERE rk.siswa_id = siswas.id)").
+		Find(&siswaList)
+	if len(siswaList) == 0 {
+		return
+	}
+
+	jumlah := 0
+	for _, s := range siswaList {
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			return CatatPerubahanKelas(tx, s.ID, s.KelasID, models.RiwayatMigrasi, "Riwayat awal dari data kelas siswa", s.CreatedAt)
+		})
+		if err != nil {
+			log.Printf("⚠️  Backfill riwayat kelas siswa %d gagal: %v", s.ID, err)
+			continue
+		}
+		jumlah++
+	}
+	log.Printf("✅ Backfill riwayat kelas: %d siswa", jumlah)
+}
+
+// kondisiSiswaDiKelas adalah kondisi SQL bahwa siswa s menempati kelas k pada
+// tahun ajarannya: riwayat terakhir di tahun ajaran itu, atau kelas saat ini
+// jika riwayatnya belum ada. Berbeda dengan KelasSiswaDiSemester, kondisi ini
+// tidak melihat rentang tanggal semester.
+const kondisiSiswaDiKelas = `s.deleted_at IS NULL AND (
+	(SELECT rk.kelas_id FROM riwayat_kelas rk
+	 WHERE rk.siswa_id = s.id AND rk.tahun_ajaran_id = k.tahun_ajaran_id
+	 ORDER BY rk.tanggal_mulai DESC LIMIT 1) = k.id
+	OR (s.kelas_id = k.id AND NOT EXISTS (
+		SELECT 1 FROM riwayat_kelas rk WHERE rk.siswa_id = s.id AND rk.tahun_ajaran_id = k.tahun_ajaran_id))
+)`
+
+// kondisiSiswaDiKelasSemester adalah versi SQL dari KelasSiswaDiSemester untuk
+// semester sm: siswa s menempati kelas k jika riwayat terakhirnya di tahun
+// ajaran itu yang beririsan dengan tanggal semester adalah k, atau kelasnya
+// saat ini jika belum punya riwayat di tahun ajaran tersebut. Jika tanggal
+// semester belum diisi, dipakai riwayat terakhir di tahun ajaran itu.
+const kondisiSiswaDiKelasSemester = `s.deleted_at IS NULL AND (
+	(SELECT rk.kelas_id FROM riwayat_kelas rk
+	 WHERE rk.siswa_id = s.id AND rk.tahun_ajaran_id = k.tahun_ajaran_id
+	   AND (sm.tanggal_mulai IS NULL OR sm.tanggal_selesai IS NULL OR (
+		rk.tanggal_mulai < sm.tanggal_selesai + INTERVAL '1 day'
+		AND (rk.tanggal_selesai IS NULL OR rk.tanggal_selesai > sm.tanggal_mulai)))
+	 ORDER BY rk.tanggal_mulai DESC LIMIT 1) = k.id
+	OR (s.kelas_id = k.id AND NOT EXISTS (
+		SELECT 1 FROM riwayat_kelas rk WHERE rk.siswa_id = s.id AND rk.tahun_ajaran_id = k.tahun_ajaran_id))
+)`
+
+// QuerySiswaDiKelas menghasilkan subquery ID siswa yang menempati kelas pada
+// tahun ajarannya (lihat kondisiSiswaDiKelas). Dipakai untuk laporan per kelas
+// yang dihitung dalam satu query SQL.
+func QuerySiswaDiKelas(kelas models.Kelas) *gorm.DB {
+	return config.DB.Table("siswas s").Select("s.id").
+		Joins("JOIN kelas k ON k.id = ?", kelas.ID).
+		Where(kondisiSiswaDiKelas)
+}
+
+// SiswaMenempatiKelas memeriksa apakah siswa menempati kelas pada tahun ajarannya
+func SiswaMenempatiKelas(siswaID, kelasID uint) bool {
+	var n int64
+	QuerySiswaDiKelas(models.Kelas{ID: kelasID}).Where("s.id = ?", siswaID).Count(&n)
+	return n > 0
+}
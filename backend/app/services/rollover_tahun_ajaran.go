@@ -0,0 +1,178 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+var (
+	ErrTahunAjaranSudahAda = errors.New("tahun ajaran dengan nama tersebut sudah ada")
+	errRollbackPreview     = errors.New("preview rollover")
+)
+
+// OpsiRollover mengatur apa saja yang disalin dari tahun ajaran asal
+type OpsiRollover struct {
+	NamaBaru       string
+	GantiNamaKelas map[uint]string // kelas asal ID → nama kelas baru
+	SalinWaliKelas bool
+	SalinJadwal    bool
+	Aktifkan       bool
+	// Rentang tanggal semester baru, opsional
+	GanjilMulai, GanjilSelesai *time.Time
+	GenapMulai, GenapSelesai   *time.Time
+}
+
+// PasanganKelas menghubungkan kelas asal dengan hasil salinannya
+type PasanganKelas struct {
+	KelasAsalID uint         `json:"kelas_asal_id"`
+	KelasBaru   models.Kelas `json:"kelas_baru"`
+}
+
+type HasilRollover struct {
+	TahunAjaran    models.TahunAjaran `json:"tahun_ajaran"`
+	Semester       []models.Semester  `json:"semester"`
+	Kelas          []PasanganKelas    `json:"kelas"`
+	JumlahJadwal   int                `json:"jumlah_jadwal"`
+	JumlahPengampu int                `json:"jumlah_pengampu"`
+	Preview        bool               `json:"preview"`
+}
+
+// RolloverTahunAjaran membuat tahun ajaran baru dari tahun ajaran asal: kelas disalin
+// (dengan nama baru opsional), semester Ganjil/Genap dibuat, wali kelas, penugasan
+// guru pengampu dan grid jadwal (opsional) disalin, lalu tahun ajaran baru diaktifkan.
+// Semua dilakukan dalam satu transaksi; dengan preview=true transaksi di-rollback
+// sehingga hasilnya hanya ditampilkan.
+func RolloverTahunAjaran(asalID uint, opsi OpsiRollover, preview bool) (HasilRollover, error) {
+	hasil := HasilRollover{Preview: preview}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var asal models.TahunAjaran
+		if err := tx.First(&asal, asalID).Error; err != nil {
+			return err
+		}
+
+		var existing models.TahunAjaran
+		if err := tx.Where("nama = ?", opsi.NamaBaru).First(&existing).Error; err == nil {
+			return ErrTahunAjaranSudahAda
+		}
+
+		// 1. Tahun ajaran baru
+		baru := models.TahunAjaran{Nama: opsi.NamaBaru, TemplateRaporID: asal.TemplateRaporID}
+		if err := tx.Create(&baru).Error; err != nil {
+			return err
+		}
+
+		// 2. Semester Ganjil & Genap
+		semesterBaru := map[string]*models.Semester{
+			"Ganjil": {TahunAjaranID: baru.ID, Nama: "Ganjil", TanggalMulai: opsi.GanjilMulai, TanggalSelesai: opsi.GanjilSelesai},
+			"Genap":  {TahunAjaranID: baru.ID, Nama: "Genap", TanggalMulai: opsi.GenapMulai, TanggalSelesai: opsi.GenapSelesai},
+		}
+		for _, nama := range []string{"Ganjil", "Genap"} {
+			if err := tx.Create(semesterBaru[nama]).Error; err != nil {
+				return err
+			}
+			hasil.Semester = append(hasil.Semester, *semesterBaru[nama])
+		}
+
+		// 3. Salin kelas
+		var kelasAsal []models.Kelas
+		if err := tx.Where("tahun_ajaran_id = ?", asal.ID).Order("tingkat, nama").Find(&kelasAsal).Error; err != nil {
+			return err
+		}
+		petaKelas := map[uint]uint{}
+		for _, k := range kelasAsal {
+			kb := models.Kelas{
+				Nama:          k.Nama,
+				Tingkat:       k.Tingkat,
+				JurusanID:     k.JurusanID,
+				TahunAjaranID: baru.ID,
+			}
+			if nama, ok := opsi.GantiNamaKelas[k.ID]; ok && nama != "" {
+				kb.Nama = nama
+			}
+			if opsi.SalinWaliKelas {
+				kb.WaliKelasID = k.WaliKelasID
+			}
+			if err := tx.Create(&kb).Error; err != nil {
+				return err
+			}
+			petaKelas[k.ID] = kb.ID
+			hasil.Kelas = append(hasil.Kelas, PasanganKelas{KelasAsalID: k.ID, KelasBaru: kb})
+		}
+
+		// 4. Salin penugasan guru pengampu dan (opsional) jadwal dari semester asal
+		// ke semester baru bernama sama
+		var semesterAsal []models.Semester
+		tx.Where("tahun_ajaran_id = ?", asal.ID).Find(&semesterAsal)
+		for _, sa := range semesterAsal {
+			sb, ok := semesterBaru[sa.Nama]
+			if !ok {
+				continue
+			}
+
+			jumlah, err := SalinPengampu(tx, sa.ID, sb.ID, petaKelas)
+			if err != nil {
+				return err
+			}
+			hasil.JumlahPengampu += jumlah
+
+			if opsi.SalinJadwal {
+				var jadwalAsal []models.Jadwal
+				if err := tx.Where("semester_id = ?", sa.ID).Find(&jadwalAsal).Error; err != nil {
+					return err
+				}
+				for _, j := range jadwalAsal {
+					kelasBaruID, ok := petaKelas[j.KelasID]
+					if !ok {
+						continue
+					}
+					jb := models.Jadwal{
+						KelasID:         kelasBaruID,
+						GuruID:          j.GuruID,
+						MataPelajaranID: j.MataPelajaranID,
+						SemesterID:      sb.ID,
+						HariKe:          j.HariKe,
+						JamMulai:        j.JamMulai,
+						JamSelesai:      j.JamSelesai,
+					}
+					if err := tx.Create(&jb).Error; err != nil {
+						return err
+					}
+					hasil.JumlahJadwal++
+				}
+			}
+		}
+
+		// 5. Aktivasi: hanya satu tahun ajaran & semester aktif
+		if opsi.Aktifkan {
+			if err := tx.Model(&models.TahunAjaran{}).Where("id != ?", baru.ID).Update("is_aktif", false).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&baru).Update("is_aktif", true).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Semester{}).Where("id != ?", semesterBaru["Ganjil"].ID).Update("is_aktif", false).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(semesterBaru["Ganjil"]).Update("is_aktif", true).Error; err != nil {
+				return err
+			}
+			hasil.Semester[0].IsAktif = true
+		}
+		hasil.TahunAjaran = baru
+
+		if preview {
+			return errRollbackPreview
+		}
+		return nil
+	})
+
+	if err == errRollbackPreview {
+		return hasil, nil
+	}
+	return hasil, err
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+var (
+	ErrTokenSesiTidakValid     = errors.New("kode QR tidak valid")
+	ErrTokenSesiKedaluwarsa    = errors.New("kode QR sudah kedaluwarsa, pindai ulang QR yang tampil sekarang")
+	ErrSesiAbsensiDitutup      = errors.New("sesi absensi sudah ditutup")
+	ErrSudahCheckin            = errors.New("absensi Anda untuk pertemuan ini sudah tercatat")
+	ErrSiswaBukanAnggotaKelas  = errors.New("siswa tidak terdaftar di kelas pada jadwal ini")
+	ErrPerangkatWajib          = errors.New("sesi ini mewajibkan check-in dari perangkat terdaftar")
+	ErrPerangkatTidakTerdaftar = errors.New("perangkat ini bukan perangkat yang terdaftar pada akun Anda")
+	ErrPerangkatMilikSiswaLain = errors.New("perangkat ini sudah terdaftar untuk siswa lain")
+	ErrPerangkatSudahDipakai   = errors.New("perangkat ini sudah dipakai check-in siswa lain pada sesi ini")
+)
+
+// KeteranganCheckinQR dan KeteranganTidakCheckin mengisi kolom keterangan
+// absensi yang dibuat otomatis oleh sesi QR
+const (
+	KeteranganCheckinQR    = "Check-in QR"
+	KeteranganTidakCheckin = "Tidak check-in QR sampai sesi ditutup"
+)
+
+// Batas interval QR, sama dengan validasi interval_detik saat membuka sesi
+const (
+	intervalTokenMin  = 10
+	intervalTokenMaks = 300
+)
+
+// IntervalTokenSesi adalah default lama berlakunya satu QR sebelum berganti.
+// Nilai ABSENSI_QR_INTERVAL_DETIK di luar batas dijepit ke 10-300 detik.
+func IntervalTokenSesi() int {
+	interval := config.GetEnvInt("ABSENSI_QR_INTERVAL_DETIK", 30)
+	if interval < intervalTokenMin {
+		interval = intervalTokenMin
+	}
+	if interval > intervalTokenMaks {
+		interval = intervalTokenMaks
+	}
+	return interval
+}
+
+// intervalSesi melindungi perhitungan jendela dari sesi lama yang tersimpan
+// dengan interval 0 atau negatif
+func intervalSesi(sesi models.SesiAbsensi) int64 {
+	if sesi.IntervalDetik < intervalTokenMin {
+		return intervalTokenMin
+	}
+	return int64(sesi.IntervalDetik)
+}
+
+// BuatRahasiaSesi membuat kunci HMAC acak untuk satu sesi absensi
+func BuatRahasiaSesi() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func jendelaSesi(sesi models.SesiAbsensi, t time.Time) int64 {
+	return t.Unix() / intervalSesi(sesi)
+}
+
+func tandaTanganSesi(sesi models.SesiAbsensi, jendela int64) string {
+	mac := hmac.New(sha256.New, []byte(sesi.Rahasia))
+	fmt.Fprintf(mac, "%d.%d", sesi.ID, jendela)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// TokenSesiAbsensi menghasilkan isi QR untuk waktu t dalam bentuk
+// "{sesi_id}.{jendela}.{hmac}" beserta waktu token berganti
+func TokenSesiAbsensi(sesi models.SesiAbsensi, t time.Time) (string, time.Time) {
+	jendela := jendelaSesi(sesi, t)
+	token := fmt.Sprintf("%d.%d.%s", sesi.ID, jendela, tandaTanganSesi(sesi, jendela))
+	berlakuSampai := time.Unix((jendela+1)*intervalSesi(sesi), 0)
+	return token, berlakuSampai
+}
+
+// SesiDariToken mengambil ID sesi dari token tanpa memverifikasinya
+func SesiDariToken(token string) (uint, error) {
+	bagian := strings.Split(strings.TrimSpace(token), ".")
+	if len(bagian) != 3 {
+		return 0, ErrTokenSesiTidakValid
+	}
+	id, err := strconv.ParseUint(bagian[0], 10, 64)
+	if err != nil {
+		return 0, ErrTokenSesiTidakValid
+	}
+	return uint(id), nil
+}
+
+// ValidasiTokenSesi memeriksa tanda tangan token dan bahwa token berasal dari
+// jendela waktu sekarang atau satu jendela sebelumnya (toleransi jeda scan).
+// Token lama yang difoto lalu dibagikan tidak lagi diterima.
+func ValidasiTokenSesi(sesi models.SesiAbsensi, token string, t time.Time) (int64, error) {
+	if sesi.Status != models.SesiAbsensiDibuka {
+		return 0, ErrSesiAbsensiDitutup
+	}
+	bagian := strings.Split(strings.TrimSpace(token), ".")
+	if len(bagian) != 3 || bagian[0] != strconv.FormatUint(uint64(sesi.ID), 10) {
+		return 0, ErrTokenSesiTidakValid
+	}
+	jendela, err := strconv.ParseInt(bagian[1], 10, 64)
+	if err != nil {
+		return 0, ErrTokenSesiTidakValid
+	}
+	if !hmac.Equal([]byte(bagian[2]), []byte(tandaTanganSesi(sesi, jendela))) {
+		return 0, ErrTokenSesiTidakValid
+	}
+	sekarang := jendelaSesi(sesi, t)
+	if jendela > sekarang || jendela < sekarang-1 {
+		return 0, ErrTokenSesiKedaluwarsa
+	}
+	return jendela, nil
+}
+
+// SiswaDiKelasJadwal memeriksa bahwa siswa menempati kelas jadwal tersebut
+func SiswaDiKelasJadwal(siswaID uint, jadwal models.Jadwal) bool {
+	var n int64
+	QuerySiswaDiKelas(models.Kelas{ID: jadwal.KelasID}).Where("s.id = ?", siswaID).Count(&n)
+	return n > 0
+}
+
+// CheckinSesi mencatat siswa hadir melalui QR. Pengikatan perangkat hanya
+// diperiksa jika sesi mewajibkannya; perangkat pertama yang dipakai siswa
+// otomatis didaftarkan ke akunnya.
+func CheckinSesi(sesi models.SesiAbsensi, siswaID uint, jendela int64, perangkatID, ip string) (models.Absensi, error) {
+	var abs models.Absensi
+	perangkatID = strings.TrimSpace(perangkatID)
+	if sesi.WajibPerangkat && perangkatID == "" {
+		return abs, ErrPerangkatWajib
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci baris sesi agar check-in tidak berjalan bersamaan dengan
+		// TutupSesi, lalu pastikan sesi masih dibuka
+		var terkini models.SesiAbsensi
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&terkini, sesi.ID).Error; err != nil {
+			return err
+		}
+		if terkini.Status != models.SesiAbsensiDibuka {
+			return ErrSesiAbsensiDitutup
+		}
+
+		if sesi.WajibPerangkat {
+			if err := cocokkanPerangkat(tx, siswaID, perangkatID); err != nil {
+				return err
+			}
+		}
+		if perangkatID != "" {
+			var n int64
+			tx.Model(&models.CheckinAbsensi{}).
+				Where("sesi_id = ? AND perangkat_id = ? AND siswa_id <> ?", sesi.ID, perangkatID, siswaID).
+				Count(&n)
+			if n > 0 {
+				return ErrPerangkatSudahDipakai
+			}
+		}
+
+		var n int64
+		tx.Model(&models.Absensi{}).
+			Where("jadwal_id = ? AND siswa_id = ? AND DATE(tanggal) = ?", sesi.JadwalID, siswaID, sesi.Tanggal.Format("2006-01-02")).
+			Count(&n)
+		if n > 0 {
+			return ErrSudahCheckin
+		}
+
+		abs = models.Absensi{
+			JadwalID:   sesi.JadwalID,
+			SiswaID:    siswaID,
+			Tanggal:    sesi.Tanggal,
+			Status:     "hadir",
+			Keterangan: KeteranganCheckinQR,
+		}
+		if err := tx.Create(&abs).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.CheckinAbsensi{
+			SesiID:      sesi.ID,
+			SiswaID:     siswaID,
+			AbsensiID:   abs.ID,
+			Jendela:     jendela,
+			PerangkatID: perangkatID,
+			IP:          ip,
+		}).Error
+	})
+	// Check-in ganda yang lolos pemeriksaan di atas ditolak oleh unique index
+	if constraint, ok := pelanggaranUnik(err); ok {
+		switch constraint {
+		case "idx_checkin_sesi_siswa":
+			err = ErrSudahCheckin
+		case "idx_checkin_sesi_perangkat":
+			err = ErrPerangkatSudahDipakai
+		}
+	}
+	return abs, err
+}
+
+// pelanggaranUnik mengembalikan nama constraint jika err adalah pelanggaran
+// unique index PostgreSQL (SQLSTATE 23505)
+func pelanggaranUnik(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return pgErr.ConstraintName, true
+	}
+	return "", false
+}
+
+func cocokkanPerangkat(tx *gorm.DB, siswaID uint, perangkatID string) error {
+	var terdaftar models.PerangkatSiswa
+	if err := tx.Where("siswa_id = ?", siswaID).First(&terdaftar).Error; err == nil {
+		if terdaftar.PerangkatID != perangkatID {
+			return ErrPerangkatTidakTerdaftar
+		}
+		return nil
+	}
+
+	var n int64
+	tx.Model(&models.PerangkatSiswa{}).Where("perangkat_id = ?", perangkatID).Count(&n)
+	if n > 0 {
+		return ErrPerangkatMilikSiswaLain
+	}
+	return tx.Create(&models.PerangkatSiswa{SiswaID: siswaID, PerangkatID: perangkatID}).Error
+}
+
+// TutupSesi menutup sesi dan mencatat alfa untuk setiap siswa kelas yang belum
+// memiliki absensi pada pertemuan itu. Mengembalikan jumlah siswa yang dicatat alfa.
+func TutupSesi(sesi *models.SesiAbsensi, kelasID uint) (int64, error) {
+	var jumlahAlfa int64
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&models.SesiAbsensi{}).
+			Where("id = ? AND status = ?", sesi.ID, models.SesiAbsensiDibuka).
+			Updates(map[string]interface{}{"status": models.SesiAbsensiDitutup, "ditutup_pada": now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrSesiAbsensiDitutup
+		}
+
+		res = tx.Exec(`INSERT INTO absensis (siswa_id, jadwal_id, tanggal, status, keterangan, created_at, updated_at)
+			SELECT s.id, ?, ?, 'alfa', ?, ?, ?
+			FROM siswas s JOIN kelas k ON k.id = ?
+			WHERE `+kondisiSiswaDiKelas+`
+			AND NOT EXISTS (SELECT 1 FROM absensis a
+				WHERE a.siswa_id = s.id AND a.jadwal_id = ? AND DATE(a.tanggal) = ?)`,
+			sesi.JadwalID, sesi.Tanggal, KeteranganTidakCheckin, now, now,
+			kelasID, sesi.JadwalID, sesi.Tanggal.Format("2006-01-02"))
+		if res.Error != nil {
+			return res.Error
+		}
+		jumlahAlfa = res.RowsAffected
+		sesi.Status, sesi.DitutupPada = models.SesiAbsensiDitutup, &now
+		return nil
+	})
+	return jumlahAlfa, err
+}
+
+// KehadiranSesiSiswa adalah status satu siswa kelas pada sesi absensi QR
+type KehadiranSesiSiswa struct {
+	SiswaID      uint       `json:"siswa_id"`
+	NIS          string     `json:"nis"`
+	Nama         string     `json:"nama"`
+	AbsensiID    *uint      `json:"absensi_id"`
+	Status       *string    `json:"status"` // nil jika belum tercatat
+	Keterangan   *string    `json:"keterangan"`
+	WaktuCheckin *time.Time `json:"waktu_checkin"` // nil jika tidak lewat QR
+}
+
+// KehadiranSesi mengambil daftar siswa kelas beserta absensinya pada sesi tersebut
+func KehadiranSesi(sesi models.SesiAbsensi, kelasID uint) ([]KehadiranSesiSiswa, error) {
+	var hasil []KehadiranSesiSiswa
+	err := config.DB.Table("siswas s").
+		Joins("JOIN kelas k ON k.id = ?", kelasID).
+		Joins("LEFT JOIN absensis a ON a.siswa_id = s.id AND a.jadwal_id = ? AND DATE(a.tanggal) = ?",
+			sesi.JadwalID, sesi.Tanggal.Format("2006-01-02")).
+		Joins("LEFT JOIN checkin_absensis ch ON ch.sesi_id = ? AND ch.siswa_id = s.id", sesi.ID).
+		Select(`s.id AS siswa_id, s.nis, s.nama, a.id AS absensi_id, a.status, a.keterangan,
+			ch.created_at AS waktu_checkin`).
+		Where(kondisiSiswaDiKelas).
+		Order("s.nama ASC").
+		Scan(&hasil).Error
+	return hasil, err
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+var (
+	ErrSiswaBukanKelasTugas = errors.New("tugas ini bukan untuk kelas Anda")
+	ErrPengumpulanDitutup   = errors.New("tenggat sudah lewat dan tugas ini tidak menerima pengumpulan terlambat")
+	ErrTugasSudahDinilai    = errors.New("jawaban sudah dinilai dan tidak dapat dikumpulkan ulang")
+	ErrMasihAdaBelumDinilai = errors.New("masih ada pengumpulan tugas yang belum dinilai")
+	ErrTidakAdaTugasDinilai = errors.New("belum ada tugas yang melewati tenggat untuk komponen ini")
+)
+
+// CekPengumpulan memeriksa apakah siswa masih boleh mengumpulkan tugas pada
+// waktu tersebut dan mengembalikan penanda terlambat.
+func CekPengumpulan(tugas models.Tugas, siswaID uint, waktu time.Time) (terlambat bool, err error) {
+	if !SiswaMenempatiKelas(siswaID, tugas.KelasID) {
+		return false, ErrSiswaBukanKelasTugas
+	}
+	var lama models.PengumpulanTugas
+	if err := config.DB.Where("tugas_id = ? AND siswa_id = ?", tugas.ID, siswaID).First(&lama).Error; err == nil && lama.Nilai != nil {
+		return false, ErrTugasSudahDinilai
+	}
+	terlambat = waktu.After(tugas.Tenggat)
+	if terlambat && tugas.TolakTerlambat {
+		return true, ErrPengumpulanDitutup
+	}
+	return terlambat, nil
+}
+
+// RekapNilaiTugas adalah rata-rata nilai tugas per siswa untuk satu komponen
+type RekapNilaiTugas struct {
+	JumlahTugas int              `json:"jumlah_tugas"`
+	RataRata    map[uint]float64 `json:"rata_rata"` // siswa ID → rata-rata 0-100
+}
+
+// HitungRataRataTugas merata-ratakan nilai tugas kelas+mapel+semester yang
+// ditandai untuk komponen nilai tertentu dan sudah melewati tenggat. Tugas yang
+// tidak dikumpulkan dihitung 0; jika masih ada jawaban yang belum dinilai,
+// perhitungan ditolak agar nilai rapor tidak terisi sebagian.
+func HitungRataRataTugas(kelasID, mapelID, semesterID uint, komponen string) (RekapNilaiTugas, error) {
+	rekap := RekapNilaiTugas{RataRata: map[uint]float64{}}
+
+	tugasQuery := config.DB.Model(&models.Tugas{}).Select("id").
+		Where("kelas_id = ? AND mata_pelajaran_id = ? AND semester_id = ? AND komponen_nilai = ? AND tenggat <= ?",
+			kelasID, mapelID, semesterID, komponen, time.Now())
+
+	var jumlah int64
+	tugasQuery.Session(&gorm.Session{}).Count(&jumlah)
+	if jumlah == 0 {
+		return rekap, ErrTidakAdaTugasDinilai
+	}
+	rekap.JumlahTugas = int(jumlah)
+
+	var belumDinilai int64
+	config.DB.Model(&models.PengumpulanTugas{}).
+		Where("tugas_id IN (?) AND nilai IS NULL", tugasQuery).
+		Count(&belumDinilai)
+	if belumDinilai > 0 {
+		return rekap, ErrMasihAdaBelumDinilai
+	}
+
+	var rows []struct {
+		SiswaID uint
+		Total   float64
+	}
+	config.DB.Table("siswas s").
+		Joins("JOIN kelas k ON k.id = ?", kelasID).
+		Joins("LEFT JOIN pengumpulan_tugas pt ON pt.siswa_id = s.id AND pt.tugas_id IN (?)", tugasQuery).
+		Where(kondisiSiswaDiKelas).
+		Group("s.id").
+		Select("s.id AS siswa_id, COALESCE(SUM(pt.nilai), 0) AS total").
+		Scan(&rows)
+
+	for _, r := range rows {
+		rekap.RataRata[r.SiswaID] = math.Round(r.Total/float64(jumlah)*100) / 100
+	}
+	return rekap, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+	"sim-sekolah/utils"
+)
+
+// jumlahRecoveryCode yang dibuat setiap kali 2FA diaktifkan / kode diperbarui
+const jumlahRecoveryCode = 10
+
+// IssuerTOTP adalah nama yang tampil di aplikasi authenticator
+func IssuerTOTP() string {
+	return config.GetEnv("TWO_FA_ISSUER", "SIM Sekolah")
+}
+
+// VerifikasiTOTP memvalidasi kode TOTP milik user dan mencatat langkahnya
+// sehingga kode yang sama tidak bisa dipakai dua kali.
+func VerifikasiTOTP(user *models.User, kode string) bool {
+	if user.TOTPSecret == "" {
+		return false
+	}
+	step, ok := utils.ValidateTOTPSetelah(user.TOTPSecret, kode, time.Now(), user.TOTPStepTerakhir)
+	if !ok {
+		return false
+	}
+
+	// Update bersyarat: hanya berhasil jika step lebih baru dari yang terakhir dipakai
+	res := config.DB.Model(&models.User{}).
+		Where("id = ? AND totp_step_terakhir < ?", user.ID, step).
+		Update("totp_step_terakhir", step)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false
+	}
+	user.TOTPStepTerakhir = step
+	return true
+}
+
+// PakaiRecoveryCode memvalidasi dan menghanguskan satu recovery code
+func PakaiRecoveryCode(userID uint, kode string) bool {
+	res := config.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, HashToken(normalisasiRecoveryCode(kode))).
+		Update("used_at", time.Now())
+	return res.Error == nil && res.RowsAffected > 0
+}
+
+// VerifikasiKode2FA menerima kode TOTP 6 digit atau recovery code
+func VerifikasiKode2FA(user *models.User, kode string) bool {
+	kode = strings.TrimSpace(kode)
+	if len(kode) == 6 {
+		return VerifikasiTOTP(user, kode)
+	}
+	return PakaiRecoveryCode(user.ID, kode)
+}
+
+// BuatRecoveryCodes mengganti seluruh recovery code user dengan yang baru.
+// Kode mentah hanya dikembalikan sekali ini untuk ditampilkan ke user.
+func BuatRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	kodeList := make([]string, 0, jumlahRecoveryCode)
+	for i := 0; i < jumlahRecoveryCode; i++ {
+		acak, err := TokenAcak(5)
+		if err != nil {
+			return nil, err
+		}
+		kode := acak[:5] + "-" + acak[5:]
+		if err := tx.Create(&models.RecoveryCode{
+			UserID:   userID,
+			CodeHash: HashToken(normalisasiRecoveryCode(kode)),
+		}).Error; err != nil {
+			return nil, err
+		}
+		kodeList = append(kodeList, kode)
+	}
+	return kodeList, nil
+}
+
+// SisaRecoveryCode menghitung recovery code yang belum terpakai
+func SisaRecoveryCode(userID uint) int64 {
+	var n int64
+	config.DB.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&n)
+	return n
+}
+
+func normalisasiRecoveryCode(kode string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(kode)))
+}
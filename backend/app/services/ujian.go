@@ -0,0 +1,340 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sim-sekolah/app/models"
+	"sim-sekolah/config"
+)
+
+var (
+	ErrUjianBelumDibuka       = errors.New("sesi ujian belum dibuka")
+	ErrUjianSudahDitutup      = errors.New("sesi ujian sudah ditutup")
+	ErrBukanPesertaUjian      = errors.New("sesi ujian ini bukan untuk kelas Anda")
+	ErrUjianTanpaSoal         = errors.New("ujian belum memiliki soal")
+	ErrWaktuUjianHabis        = errors.New("waktu pengerjaan ujian sudah habis")
+	ErrPercobaanSudahSelesai  = errors.New("ujian sudah diselesaikan")
+	ErrSoalBukanBagianUjian   = errors.New("soal tidak termasuk dalam ujian ini")
+	ErrMasihAdaEsaiBelumNilai = errors.New("masih ada jawaban esai yang belum dinilai")
+	ErrMasihAdaUjianBerjalan  = errors.New("masih ada siswa yang sedang mengerjakan ujian")
+)
+
+// toleransiWaktuUjian memberi kelonggaran untuk autosave terakhir yang
+// terkirim tepat saat waktu habis (latensi jaringan)
+const toleransiWaktuUjian = 30 * time.Second
+
+// UrutanSoalPercobaan mengurai ID soal teracak milik percobaan
+func UrutanSoalPercobaan(p models.PercobaanUjian) []uint {
+	var ids []uint
+	for _, s := range strings.Split(p.UrutanSoal, ",") {
+		if id, err := strconv.ParseUint(s, 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// SisaWaktuUjian menghitung sisa detik pengerjaan menurut jam server
+func SisaWaktuUjian(p models.PercobaanUjian) int {
+	if p.Status != models.PercobaanBerlangsung {
+		return 0
+	}
+	sisa := int(time.Until(p.BatasWaktu).Seconds())
+	if sisa < 0 {
+		return 0
+	}
+	return sisa
+}
+
+// MulaiPercobaan membuat percobaan ujian siswa dengan urutan soal acak, atau
+// mengembalikan percobaan yang sudah ada sehingga siswa dapat melanjutkan
+// setelah koneksi terputus.
+func MulaiPercobaan(sesi models.SesiUjian, siswaID uint) (models.PercobaanUjian, error) {
+	var p models.PercobaanUjian
+	if err := config.DB.Where("sesi_id = ? AND siswa_id = ?", sesi.ID, siswaID).First(&p).Error; err == nil {
+		return p, nil
+	}
+
+	sekarang := time.Now()
+	if sekarang.Before(sesi.MulaiPada) {
+		return p, ErrUjianBelumDibuka
+	}
+	if !sekarang.Before(sesi.SelesaiPada) {
+		return p, ErrUjianSudahDitutup
+	}
+	if !SiswaMenempatiKelas(siswaID, sesi.KelasID) {
+		return p, ErrBukanPesertaUjian
+	}
+
+	var soalIDs []uint
+	config.DB.Model(&models.ButirUjian{}).Where("ujian_id = ?", sesi.UjianID).
+		Order("urutan ASC").Pluck("soal_id", &soalIDs)
+	if len(soalIDs) == 0 {
+		return p, ErrUjianTanpaSoal
+	}
+	rand.Shuffle(len(soalIDs), func(i, j int) { soalIDs[i], soalIDs[j] = soalIDs[j], soalIDs[i] })
+	urutan := make([]string, len(soalIDs))
+	for i, id := range soalIDs {
+		urutan[i] = strconv.FormatUint(uint64(id), 10)
+	}
+
+	var ujian models.Ujian
+	if err := config.DB.First(&ujian, sesi.UjianID).Error; err != nil {
+		return p, err
+	}
+	batas := sekarang.Add(time.Duration(ujian.DurasiMenit) * time.Minute)
+	if batas.After(sesi.SelesaiPada) {
+		batas = sesi.SelesaiPada
+	}
+	p = models.PercobaanUjian{
+		SesiID:     sesi.ID,
+		SiswaID:    siswaID,
+		UrutanSoal: strings.Join(urutan, ","),
+		MulaiPada:  sekarang,
+		BatasWaktu: batas,
+		Status:     models.PercobaanBerlangsung,
+	}
+	// Dua permintaan mulai bersamaan: yang kalah memakai percobaan pemenang
+	if err := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&p).Error; err != nil {
+		return p, err
+	}
+	err := config.DB.Where("sesi_id = ? AND siswa_id = ?", sesi.ID, siswaID).First(&p).Error
+	return p, err
+}
+
+// SimpanJawabanUjian menyimpan (autosave) jawaban satu soal. Jika waktu sudah
+// habis, percobaan langsung diselesaikan dan jawaban ditolak. Baris percobaan
+// dikunci selama menyimpan agar autosave tidak menimpa jawaban yang sedang
+// atau sudah dinilai oleh SelesaikanPercobaan.
+func SimpanJawabanUjian(p *models.PercobaanUjian, soalID uint, jawaban string) error {
+	waktuHabis := false
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(p, p.ID).Error; err != nil {
+			return err
+		}
+		if p.Status != models.PercobaanBerlangsung {
+			return ErrPercobaanSudahSelesai
+		}
+		if time.Now().After(p.BatasWaktu.Add(toleransiWaktuUjian)) {
+			waktuHabis = true
+			return ErrWaktuUjianHabis
+		}
+		termasuk := false
+		for _, id := range UrutanSoalPercobaan(*p) {
+			if id == soalID {
+				termasuk = true
+				break
+			}
+		}
+		if !termasuk {
+			return ErrSoalBukanBagianUjian
+		}
+
+		j := models.JawabanUjian{PercobaanID: p.ID, SoalID: soalID, Jawaban: jawaban}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "percobaan_id"}, {Name: "soal_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"jawaban", "updated_at"}),
+		}).Create(&j).Error
+	})
+	if waktuHabis {
+		if err := SelesaikanPercobaan(p); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// KedaluwarsaPercobaan menutup percobaan yang waktunya sudah habis tetapi
+// belum diselesaikan siswa (tab ditutup, koneksi putus)
+func KedaluwarsaPercobaan(p *models.PercobaanUjian) error {
+	if p.Status == models.PercobaanBerlangsung && time.Now().After(p.BatasWaktu.Add(toleransiWaktuUjian)) {
+		return SelesaikanPercobaan(p)
+	}
+	return nil
+}
+
+// SelesaikanPercobaan mengakhiri percobaan dan menilai otomatis soal pilihan
+// ganda dan isian. Soal yang tidak dijawab bernilai 0; esai yang dijawab
+// menunggu penilaian guru.
+func SelesaikanPercobaan(p *models.PercobaanUjian) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(p, p.ID).Error; err != nil {
+			return err
+		}
+		if p.Status == models.PercobaanSelesai {
+			return nil
+		}
+
+		soalIDs := UrutanSoalPercobaan(*p)
+		var soalList []models.SoalUjian
+		if err := tx.Unscoped().Where("id IN ?", soalIDs).Find(&soalList).Error; err != nil {
+			return err
+		}
+		var jawabanList []models.JawabanUjian
+		tx.Where("percobaan_id = ?", p.ID).Find(&jawabanList)
+		jawabanPerSoal := make(map[uint]models.JawabanUjian, len(jawabanList))
+		for _, j := range jawabanList {
+			jawabanPerSoal[j.SoalID] = j
+		}
+
+		sekarang := time.Now()
+		for _, soal := range soalList {
+			j, ada := jawabanPerSoal[soal.ID]
+			if !ada {
+				j = models.JawabanUjian{PercobaanID: p.ID, SoalID: soal.ID}
+			}
+			skor, dinilai := skorOtomatis(soal, j.Jawaban)
+			if dinilai {
+				j.Skor = &skor
+				j.DinilaiPada = &sekarang
+			}
+			if err := tx.Save(&j).Error; err != nil {
+				return err
+			}
+		}
+
+		p.Status = models.PercobaanSelesai
+		p.SelesaiPada = &sekarang
+		if err := tx.Model(p).Updates(map[string]interface{}{
+			"status":       p.Status,
+			"selesai_pada": sekarang,
+		}).Error; err != nil {
+			return err
+		}
+		return hitungNilaiPercobaan(tx, p)
+	})
+}
+
+// skorOtomatis menilai satu jawaban. dinilai bernilai false untuk esai yang
+// diisi karena harus dinilai guru.
+func skorOtomatis(soal models.SoalUjian, jawaban string) (skor float64, dinilai bool) {
+	jawaban = normalisasiJawaban(jawaban)
+	if jawaban == "" {
+		return 0, true
+	}
+	switch soal.Tipe {
+	case models.SoalPilihanGanda:
+		if jawaban == normalisasiJawaban(soal.KunciJawaban) {
+			return soal.Bobot, true
+		}
+		return 0, true
+	case models.SoalIsian:
+		for _, kunci := range strings.Split(soal.KunciJawaban, "|") {
+			if jawaban == normalisasiJawaban(kunci) {
+				return soal.Bobot, true
+			}
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// normalisasiJawaban mengabaikan huruf besar/kecil dan spasi berlebih
+func normalisasiJawaban(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// hitungNilaiPercobaan menjumlahkan skor butir dan mengisi nilai akhir 0-100
+// jika seluruh butir sudah dinilai
+func hitungNilaiPercobaan(tx *gorm.DB, p *models.PercobaanUjian) error {
+	var r struct {
+		Skor         float64
+		TotalBobot   float64
+		BelumDinilai int64
+	}
+	if err := tx.Table("jawaban_ujians j").
+		Joins("JOIN soal_ujians s ON s.id = j.soal_id").
+		Where("j.percobaan_id = ?", p.ID).
+		Select(`COALESCE(SUM(j.skor), 0) AS skor,
+			COALESCE(SUM(s.bobot), 0) AS total_bobot,
+			COUNT(*) FILTER (WHERE j.skor IS NULL) AS belum_dinilai`).
+		Scan(&r).Error; err != nil {
+		return err
+	}
+
+	p.Skor = r.Skor
+	p.NilaiAkhir = nil
+	if r.BelumDinilai == 0 && r.TotalBobot > 0 {
+		nilai := math.Round(r.Skor/r.TotalBobot*10000) / 100
+		p.NilaiAkhir = &nilai
+	}
+	return tx.Model(p).Updates(map[string]interface{}{
+		"skor":        p.Skor,
+		"nilai_akhir": p.NilaiAkhir,
+	}).Error
+}
+
+// NilaiJawabanEsai menyimpan skor esai dari guru lalu menghitung ulang nilai
+// percobaan
+func NilaiJawabanEsai(j *models.JawabanUjian, skor float64) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		sekarang := time.Now()
+		if err := tx.Model(j).Updates(map[string]interface{}{
+			"skor":         skor,
+			"dinilai_pada": sekarang,
+		}).Error; err != nil {
+			return err
+		}
+		var p models.PercobaanUjian
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&p, j.PercobaanID).Error; err != nil {
+			return err
+		}
+		return hitungNilaiPercobaan(tx, &p)
+	})
+}
+
+// NilaiAkhirUjian mengambil nilai akhir setiap siswa yang sudah mengerjakan
+// ujian. Gagal jika masih ada pengerjaan berlangsung atau esai belum dinilai.
+func NilaiAkhirUjian(ujianID uint) (map[uint]float64, error) {
+	sesiQuery := config.DB.Model(&models.SesiUjian{}).Select("id").Where("ujian_id = ?", ujianID)
+
+	var list []models.PercobaanUjian
+	if err := config.DB.Where("sesi_id IN (?)", sesiQuery).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	hasil := make(map[uint]float64, len(list))
+	for i := range list {
+		if err := KedaluwarsaPercobaan(&list[i]); err != nil {
+			return nil, err
+		}
+		p := list[i]
+		if p.Status == models.PercobaanBerlangsung {
+			return nil, ErrMasihAdaUjianBerjalan
+		}
+		if p.NilaiAkhir == nil {
+			return nil, ErrMasihAdaEsaiBelumNilai
+		}
+		hasil[p.SiswaID] = *p.NilaiAkhir
+	}
+	return hasil, nil
+}
+
+// ── Job ───────────────────────────────────────────────────────
+
+// JobTutupUjian menyelesaikan percobaan ujian yang waktunya sudah habis
+const JobTutupUjian = "tutup_ujian_kedaluwarsa"
+
+type payloadTutupUjian struct{}
+
+func tutupUjianKedaluwarsa(ctx context.Context, _ *models.Job, _ payloadTutupUjian) error {
+	var list []models.PercobaanUjian
+	if err := config.DB.WithContext(ctx).
+		Where("status = ? AND batas_waktu < ?", models.PercobaanBerlangsung, time.Now().Add(-toleransiWaktuUjian)).
+		Find(&list).Error; err != nil {
+		return err
+	}
+	for i := range list {
+		if err := SelesaikanPercobaan(&list[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+
+	"sim-sekolah/app/models"
+)
+
+func TestNormalisasiJawaban(t *testing.T) {
+	tests := []struct {
+		masuk, ingin string
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"A", "a"},
+		{"  Fotosintesis ", "fotosintesis"},
+		{"Proklamasi\tKemerdekaan\n Indonesia", "proklamasi kemerdekaan indonesia"},
+		{"Banda  Aceh", "banda aceh"},
+	}
+	for _, tt := range tests {
+		if got := normalisasiJawaban(tt.masuk); got != tt.ingin {
+			t.Errorf("normalisasiJawaban(%q) = %q, ingin %q", tt.masuk, got, tt.ingin)
+		}
+	}
+}
+
+func TestSkorOtomatis(t *testing.T) {
+	pg := models.SoalUjian{Tipe: models.SoalPilihanGanda, KunciJawaban: "C", Bobot: 2}
+	isian := models.SoalUjian{Tipe: models.SoalIsian, KunciJawaban: "Banda Aceh|Kutaraja", Bobot: 3}
+	esai := models.SoalUjian{Tipe: models.SoalEsai, KunciJawaban: "rubrik", Bobot: 5}
+
+	tests := []struct {
+		nama    string
+		soal    models.SoalUjian
+		jawaban string
+		skor    float64
+		dinilai bool
+	}{
+		{"pilihan ganda benar", pg, "C", 2, true},
+		{"pilihan ganda huruf kecil", pg, " c ", 2, true},
+		{"pilihan ganda salah", pg, "B", 0, true},
+		{"pilihan ganda kosong", pg, "", 0, true},
+		{"isian alternatif pertama", isian, "banda   aceh", 3, true},
+		{"isian alternatif kedua", isian, "KUTARAJA", 3, true},
+		{"isian sebagian kunci", isian, "Banda", 0, true},
+		{"isian berisi pemisah kunci", isian, "Banda Aceh|Kutaraja", 0, true},
+		{"isian kosong", isian, "  ", 0, true},
+		{"esai diisi menunggu guru", esai, "Jawaban panjang", 0, false},
+		{"esai kosong bernilai 0", esai, "", 0, true},
+	}
+	for _, tt := range tests {
+		skor, dinilai := skorOtomatis(tt.soal, tt.jawaban)
+		if skor != tt.skor || dinilai != tt.dinilai {
+			t.Errorf("%s: skorOtomatis = (%v, %v), ingin (%v, %v)", tt.nama, skor, dinilai, tt.skor, tt.dinilai)
+		}
+	}
+}
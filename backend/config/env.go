@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -20,4 +21,22 @@ func LoadEnv() {
 	}
 
 	log.Printf("✅ Environment: %s", env)
-}
\ No newline at end of file
+}
+
+// GetEnv mengambil environment variable, atau nilai default jika kosong
+func GetEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// GetEnvInt mengambil environment variable bertipe angka, atau nilai default
+// jika kosong / tidak valid
+func GetEnvInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
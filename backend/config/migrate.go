@@ -3,14 +3,21 @@ package config
 import (
 	"log"
 	"sim-sekolah/app/models"
+
+	"gorm.io/gorm"
 )
 
 func MigrateDB() {
+	siapkanVersiRapor()
+
 	err := DB.AutoMigrate(
 		// Auth & RBAC
 		&models.Role{},
 		&models.User{},
 		&models.ActivityLog{},
+		&models.LoginThrottle{},
+		&models.PasswordResetToken{},
+		&models.RecoveryCode{},
 
 		// Akademik
 		&models.Guru{},
@@ -22,15 +29,144 @@ func MigrateDB() {
 		&models.Jurusan{},
 		&models.Kelas{},
 		&models.MataPelajaran{},
+		&models.KurikulumMapel{},
+		&models.PengampuMapel{},
+		&models.ProsesKenaikan{},
+		&models.PemetaanKelasKenaikan{},
+		&models.KeputusanKenaikan{},
+		&models.RiwayatKelas{},
 
 		// Jadwal, Absensi, Nilai
 		&models.Jadwal{},
 		&models.Absensi{},
 		&models.Nilai{},
+		&models.TujuanPembelajaran{},
+		&models.CapaianTujuanPembelajaran{},
+		&models.ProjekP5{},
+		&models.NilaiP5{},
 		&models.Rapor{},
+		&models.RaporVersi{},
+		&models.RaporBatch{},
+		&models.ProfilSekolah{},
+		&models.TemplateRapor{},
+		&models.JurnalMengajar{},
+		&models.LampiranJurnal{},
+		&models.SesiAbsensi{},
+		&models.CheckinAbsensi{},
+		&models.PerangkatSiswa{},
+		&models.PerangkatGerbang{},
+		&models.KartuAkses{},
+		&models.TapGerbang{},
+		&models.KehadiranGerbang{},
+		&models.JadwalBel{},
+		&models.JenisPelanggaran{},
+		&models.PelanggaranSiswa{},
+		&models.AmbangPoin{},
+		&models.SanksiSiswa{},
+		&models.CatatanKonseling{},
+		&models.Ekstrakurikuler{},
+		&models.JadwalEkskul{},
+		&models.AnggotaEkskul{},
+		&models.PertemuanEkskul{},
+		&models.AbsensiEkskul{},
+		&models.Tugas{},
+		&models.LampiranTugas{},
+		&models.PengumpulanTugas{},
+		&models.SoalUjian{},
+		&models.OpsiSoal{},
+		&models.Ujian{},
+		&models.ButirUjian{},
+		&models.SesiUjian{},
+		&models.PercobaanUjian{},
+		&models.JawabanUjian{},
+
+		// Antrian job
+		&models.Job{},
+		&models.JobTerjadwal{},
 	)
 	if err != nil {
 		log.Fatal("❌ AutoMigrate gagal:", err)
 	}
+	lengkapiNomorVersiRapor()
 	log.Println("✅ Migrasi database selesai")
-}
\ No newline at end of file
+}
+
+// siapkanVersiRapor mengubah rapor lama — yang bisa berisi beberapa baris per
+// siswa+semester — menjadi satu rapor dengan beberapa versi, sebelum unique
+// index siswa+semester dibuat. Baris terbaru dipertahankan sebagai rapor,
+// seluruh baris (termasuk yang terbaru) menjadi versi berurutan menurut waktu.
+// Baris published terakhir tetap published; baris published sebelumnya
+// menjadi revised karena sudah digantikan, dan draft tetap draft.
+func siapkanVersiRapor() {
+	m := DB.Migrator()
+	if !m.HasTable(&models.Rapor{}) || m.HasTable(&models.RaporVersi{}) {
+		return
+	}
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.AutoMigrate(&models.RaporVersi{}); err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			WITH urut AS (
+				SELECT id, file_path, status, created_at,
+					MAX(id) OVER (PARTITION BY siswa_id, semester_id) AS rapor_id,
+					ROW_NUMBER() OVER (PARTITION BY siswa_id, semester_id ORDER BY created_at, id) AS nomor
+				FROM rapors
+			), terbit AS (
+				SELECT urut.*,
+					MAX(nomor) FILTER (WHERE status = @published) OVER (PARTITION BY rapor_id) AS terbit_terakhir
+				FROM urut
+			)
+			INSERT INTO rapor_versis (rapor_id, nomor, status, file_path, snapshot, alasan, created_at, updated_at)
+			SELECT rapor_id, nomor,
+				CASE
+					WHEN status <> @published THEN @draft
+					WHEN nomor < terbit_terakhir THEN @revised
+					ELSE @published
+				END,
+				file_path, '{}',
+				CASE WHEN nomor > 1 THEN 'Dibuat ulang sebelum fitur versi rapor' ELSE '' END,
+				created_at, created_at
+			FROM terbit`,
+			map[string]interface{}{
+				"published": models.RaporPublished,
+				"revised":   models.RaporRevised,
+				"draft":     models.RaporDraft,
+			},
+		).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			DELETE FROM rapors r
+			WHERE r.id <> (SELECT MAX(id) FROM rapors d WHERE d.siswa_id = r.siswa_id AND d.semester_id = r.semester_id)`,
+		).Error
+	})
+	if err != nil {
+		log.Fatal("❌ Migrasi versi rapor gagal:", err)
+	}
+	log.Println("✅ Rapor ganda dikonversi menjadi versi rapor")
+}
+
+// lengkapiNomorVersiRapor mengisi versi_terakhir/versi_terbit untuk rapor hasil
+// konversi siapkanVersiRapor (kolomnya baru ada setelah AutoMigrate). Rapor
+// yang punya versi terbit diarahkan ke versi tersebut walaupun baris terbarunya
+// masih draft, sama seperti setelah terbitkanVersi.
+func lengkapiNomorVersiRapor() {
+	DB.Exec(`
+		UPDATE rapors r SET
+			versi_terakhir = v.terakhir,
+			versi_terbit = COALESCE(v.terbit, 0),
+			status = CASE WHEN v.terbit IS NULL THEN r.status ELSE @published END,
+			file_path = COALESCE(
+				(SELECT rv.file_path FROM rapor_versis rv WHERE rv.rapor_id = r.id AND rv.nomor = v.terbit),
+				r.file_path)
+		FROM (
+			SELECT rapor_id, MAX(nomor) AS terakhir,
+				MAX(nomor) FILTER (WHERE status = @published) AS terbit
+			FROM rapor_versis GROUP BY rapor_id
+		) v
+		WHERE v.rapor_id = r.id AND r.versi_terakhir = 0`,
+		map[string]interface{}{"published": models.RaporPublished},
+	)
+}
@@ -28,6 +28,7 @@ func seedRoles() {
 		{Nama: models.RoleWaliKelas, Deskripsi: "Wali kelas, akses rekap kelas"},
 		{Nama: models.RoleSiswa, Deskripsi: "Siswa, akses jadwal dan nilai sendiri"},
 		{Nama: models.RoleOrangTua, Deskripsi: "Orang tua, monitoring anak"},
+		{Nama: models.RoleGuruBK, Deskripsi: "Guru BK, akses pelanggaran dan catatan konseling"},
 	}
 
 	for _, role := range roles {
@@ -57,11 +58,12 @@ func seedAdminUser() {
 	}
 
 	admin := models.User{
-		RoleID:   adminRole.ID,
-		Nama:     "Administrator",
-		Email:    "admin@simsekolah.sch.id",
-		Password: "Admin@12345", // akan di-hash oleh BeforeCreate
-		IsActive: true,
+		RoleID:             adminRole.ID,
+		Nama:               "Administrator",
+		Email:              "admin@simsekolah.sch.id",
+		Password:           "Admin@12345", // akan di-hash oleh BeforeCreate
+		IsActive:           true,
+		WajibGantiPassword: true,
 	}
 
 	if err := config.DB.Create(&admin).Error; err != nil {
@@ -72,5 +74,5 @@ func seedAdminUser() {
 	log.Println("   ✔ User admin dibuat:")
 	log.Println("     Email    : admin@simsekolah.sch.id")
 	log.Println("     Password : Admin@12345")
-	log.Println("     ⚠️  Segera ganti password setelah login pertama!")
+	log.Println("     ⚠️  Password wajib diganti saat login pertama!")
 }
\ No newline at end of file
@@ -1,12 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"sim-sekolah/config"
+	"sim-sekolah/app/controllers"
 	"sim-sekolah/app/routes"
+	"sim-sekolah/app/services"
+	"sim-sekolah/config"
 )
 
 func main() {
@@ -14,6 +22,15 @@ func main() {
 	config.LoadEnv()
 	config.ConnectDB()
 	config.MigrateDB()
+	services.BackfillRiwayatKelas()
+	services.MuatTemplateRaporDisk()
+
+	// Antrian job latar belakang
+	controllers.DaftarkanJobHandler()
+	if err := services.DaftarkanJobBawaan(); err != nil {
+		log.Fatal("Gagal mendaftarkan job terjadwal:", err)
+	}
+	runner := services.JalankanJobRunner()
 
 	// Set Gin mode
 	if os.Getenv("APP_ENV") == "production" {
@@ -30,8 +47,29 @@ func main() {
 		port = "8080"
 	}
 
-	log.Printf("🚀 SIM Sekolah berjalan di port %s", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatal("Gagal menjalankan server:", err)
+	srv := &http.Server{Addr: ":" + port, Handler: r}
+	go func() {
+		log.Printf("🚀 SIM Sekolah berjalan di port %s", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Gagal menjalankan server:", err)
+		}
+	}()
+
+	// Tunggu sinyal berhenti, lalu selesaikan request dan job yang sedang berjalan
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+	log.Println("Mematikan server...")
+
+	timeout := time.Duration(config.GetEnvInt("SHUTDOWN_TIMEOUT_DETIK", 30)) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Println("Server tidak berhenti dengan bersih:", err)
+	}
+	if err := runner.Hentikan(ctx); err != nil {
+		log.Println("Sebagian job dihentikan paksa:", err)
 	}
-}
\ No newline at end of file
+	log.Println("Server berhenti")
+}
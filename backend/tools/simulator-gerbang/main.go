@@ -0,0 +1,145 @@
+// Simulator pembaca kartu gerbang untuk menguji API perangkat tanpa hardware.
+//
+// Contoh:
+//
+//	go run ./tools/simulator-gerbang -key gtw_xxx -mode ping
+//	go run ./tools/simulator-gerbang -key gtw_xxx -uid 04A1B2C3,04D5E6F7
+//	go run ./tools/simulator-gerbang -key gtw_xxx -uid 04A1B2C3 -mode batch \
+//		-mulai 2025-02-12T06:50:00+07:00 -jumlah 2 -langkah 7h
+//
+// Mode tap mengirim setiap tap langsung, mode batch menampung tap seperti
+// perangkat yang sedang offline lalu mengirimnya sekaligus. Opsi -ulang
+// mengirim batch yang sama dua kali untuk memastikan tap tidak tercatat ganda.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+type tap struct {
+	UID     string    `json:"uid"`
+	Waktu   time.Time `json:"waktu"`
+	EventID string    `json:"event_id"`
+}
+
+type simulator struct {
+	url    string
+	key    string
+	client *http.Client
+}
+
+func (s simulator) kirim(method, path string, body interface{}) {
+	var isi io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			log.Fatal(err)
+		}
+		isi = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, s.url+path, isi)
+	if err != nil {
+		log.Fatal(err)
+	}
+	req.Header.Set("X-API-Key", s.key)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		log.Printf("❌ %s %s: %v", method, path, err)
+		return
+	}
+	defer res.Body.Close()
+	balasan, _ := io.ReadAll(res.Body)
+
+	var rapi bytes.Buffer
+	if json.Indent(&rapi, balasan, "", "  ") != nil {
+		rapi.Write(balasan)
+	}
+	fmt.Printf("%s %s → %d\n%s\n\n", method, path, res.StatusCode, rapi.String())
+}
+
+func main() {
+	url := flag.String("url", "http://localhost:8080/api/v1/perangkat-gerbang", "Base URL API perangkat gerbang")
+	key := flag.String("key", os.Getenv("GERBANG_API_KEY"), "API key perangkat (default: env GERBANG_API_KEY)")
+	mode := flag.String("mode", "tap", "ping / tap / batch")
+	uids := flag.String("uid", "", "Daftar UID kartu, pisahkan dengan koma")
+	jumlah := flag.Int("jumlah", 1, "Jumlah tap per kartu")
+	mulai := flag.String("mulai", "", "Waktu tap pertama (RFC3339); kosong = sekarang")
+	langkah := flag.Duration("langkah", 0, "Selisih waktu antar tap kartu yang sama, misal 7h untuk simulasi datang lalu pulang")
+	jeda := flag.Duration("jeda", time.Second, "Jeda nyata antar request pada mode tap")
+	ulang := flag.Bool("ulang", false, "Kirim ulang batch yang sama (uji duplikat)")
+	flag.Parse()
+
+	if *key == "" {
+		log.Fatal("API key wajib diisi (-key atau env GERBANG_API_KEY)")
+	}
+	sim := simulator{url: strings.TrimRight(*url, "/"), key: *key, client: &http.Client{Timeout: 15 * time.Second}}
+
+	if *mode == "ping" {
+		sim.kirim(http.MethodGet, "/ping", nil)
+		return
+	}
+
+	var daftarUID []string
+	for _, u := range strings.Split(*uids, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			daftarUID = append(daftarUID, u)
+		}
+	}
+	if len(daftarUID) == 0 {
+		log.Fatal("Isi minimal satu UID kartu dengan -uid")
+	}
+
+	awal := time.Now()
+	if *mulai != "" {
+		t, err := time.Parse(time.RFC3339, *mulai)
+		if err != nil {
+			log.Fatalf("Format -mulai salah: %v", err)
+		}
+		awal = t
+	}
+
+	host, _ := os.Hostname()
+	var taps []tap
+	for i := 0; i < *jumlah; i++ {
+		for j, uid := range daftarUID {
+			// Kartu berbeda ditap berurutan dengan selisih beberapa detik
+			waktu := awal.Add(time.Duration(i) * *langkah).Add(time.Duration(j) * 3 * time.Second)
+			taps = append(taps, tap{
+				UID:     uid,
+				Waktu:   waktu,
+				EventID: fmt.Sprintf("%s-%d-%d-%d", host, awal.Unix(), i, j),
+			})
+		}
+	}
+
+	switch *mode {
+	case "tap":
+		for i, t := range taps {
+			if *mulai == "" {
+				t.Waktu = time.Now()
+			}
+			sim.kirim(http.MethodPost, "/tap", t)
+			if i < len(taps)-1 {
+				time.Sleep(*jeda)
+			}
+		}
+	case "batch":
+		sim.kirim(http.MethodPost, "/tap/batch", map[string]interface{}{"taps": taps})
+		if *ulang {
+			sim.kirim(http.MethodPost, "/tap/batch", map[string]interface{}{"taps": taps})
+		}
+	default:
+		log.Fatalf("Mode tidak dikenal: %s", *mode)
+	}
+}
@@ -2,8 +2,8 @@ package utils
 
 import (
 	"errors"
-	"os"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -15,9 +15,25 @@ type JWTClaims struct {
 	Nama   string `json:"nama"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+	// Akun dibuat admin dan belum mengganti password awal
+	WajibGantiPassword bool `json:"wajib_ganti_password,omitempty"`
+	// Role mewajibkan 2FA tetapi user belum mengaktifkannya
+	Wajib2FA bool `json:"wajib_2fa,omitempty"`
+	// Tahap login; "2fa" = token parsial yang hanya bisa ditukar di /auth/2fa/verify
+	Tahap string `json:"tahap,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// TahapVerifikasi2FA menandai token parsial setelah password benar
+// tetapi sebelum kode 2FA diverifikasi
+const TahapVerifikasi2FA = "2fa"
+
+// TokenFlags berisi pembatasan akses yang ikut disematkan di access token
+type TokenFlags struct {
+	WajibGantiPassword bool
+	Wajib2FA           bool
+}
+
 func getJWTSecret() []byte {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
@@ -27,16 +43,35 @@ func getJWTSecret() []byte {
 }
 
 // GenerateToken membuat JWT access token
-func GenerateToken(userID, roleID uint, nama, email, role string) (string, error) {
+func GenerateToken(userID, roleID uint, nama, email, role string, flags TokenFlags) (string, error) {
 	expHours := 24 // default 24 jam
+	claims := JWTClaims{
+		UserID:             userID,
+		RoleID:             roleID,
+		Nama:               nama,
+		Email:              email,
+		Role:               role,
+		WajibGantiPassword: flags.WajibGantiPassword,
+		Wajib2FA:           flags.Wajib2FA,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expHours) * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "sim-sekolah",
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(getJWTSecret())
+}
+
+// GeneratePartialToken membuat token berumur pendek untuk langkah kedua login (2FA)
+func GeneratePartialToken(userID uint, email string) (string, error) {
 	claims := JWTClaims{
 		UserID: userID,
-		RoleID: roleID,
-		Nama:   nama,
 		Email:  email,
-		Role:   role,
+		Tahap:  TahapVerifikasi2FA,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expHours) * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "sim-sekolah",
 		},
@@ -46,6 +81,18 @@ func GenerateToken(userID, roleID uint, nama, email, role string) (string, error
 	return token.SignedString(getJWTSecret())
 }
 
+// ValidatePartialToken memvalidasi token parsial 2FA
+func ValidatePartialToken(tokenString string) (*JWTClaims, error) {
+	claims, err := ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Tahap != TahapVerifikasi2FA {
+		return nil, errors.New("bukan token verifikasi 2FA")
+	}
+	return claims, nil
+}
+
 // GenerateRefreshToken membuat refresh token (masa berlaku lebih lama)
 func GenerateRefreshToken(userID uint) (string, error) {
 	claims := jwt.RegisteredClaims{
@@ -78,4 +125,4 @@ func ValidateToken(tokenString string) (*JWTClaims, error) {
 	}
 
 	return claims, nil
-}
\ No newline at end of file
+}
@@ -41,6 +41,14 @@ func ResponseCreated(c *gin.Context, message string, data interface{}) {
 	})
 }
 
+func ResponseAccepted(c *gin.Context, message string, data interface{}) {
+	c.JSON(202, APIResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
 func ResponseBadRequest(c *gin.Context, message string, errors interface{}) {
 	c.JSON(400, APIResponse{
 		Success: false,
@@ -93,4 +101,11 @@ func ResponsePaginated(c *gin.Context, message string, data interface{}, page, l
 			TotalPages: totalPages,
 		},
 	})
-}
\ No newline at end of file
+}
+
+func ResponseTooManyRequests(c *gin.Context, message string) {
+	c.JSON(429, APIResponse{
+		Success: false,
+		Message: message,
+	})
+}
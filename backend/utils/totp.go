@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parameter TOTP standar (RFC 6238) yang didukung Google Authenticator dkk.
+const (
+	totpPeriode = 30 // detik
+	totpDigit   = 6
+	totpJendela = 1 // toleransi ±1 periode untuk selisih jam perangkat
+)
+
+var base32NoPad = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret membuat secret acak 160-bit dalam format base32
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32NoPad.EncodeToString(b), nil
+}
+
+// TOTPProvisioningURI membuat URI otpauth:// untuk dijadikan QR code di aplikasi authenticator
+func TOTPProvisioningURI(issuer, akun, secret string) string {
+	label := url.PathEscape(issuer + ":" + akun)
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprintf("%d", totpDigit))
+	q.Set("period", fmt.Sprintf("%d", totpPeriode))
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// GenerateTOTPCode menghitung kode TOTP untuk waktu t
+func GenerateTOTPCode(secret string, t time.Time) (string, error) {
+	return kodeTOTP(secret, t.Unix()/totpPeriode)
+}
+
+// ValidateTOTP memvalidasi kode terhadap waktu t dengan toleransi ±1 periode.
+// Mengembalikan nomor langkah (step) yang cocok agar pemanggil bisa menolak
+// pemakaian ulang kode yang sama (replay).
+func ValidateTOTP(secret, kode string, t time.Time) (int64, bool) {
+	kode = strings.TrimSpace(kode)
+	if len(kode) != totpDigit {
+		return 0, false
+	}
+
+	step := t.Unix() / totpPeriode
+	for d := int64(-totpJendela); d <= totpJendela; d++ {
+		harapan, err := kodeTOTP(secret, step+d)
+		if err != nil {
+			return 0, false
+		}
+		if hmac.Equal([]byte(harapan), []byte(kode)) {
+			return step + d, true
+		}
+	}
+	return 0, false
+}
+
+// ValidateTOTPSetelah sama dengan ValidateTOTP tetapi menolak kode dari langkah
+// yang tidak lebih baru dari stepTerakhir, yaitu kode yang sudah pernah dipakai
+func ValidateTOTPSetelah(secret, kode string, t time.Time, stepTerakhir int64) (int64, bool) {
+	step, ok := ValidateTOTP(secret, kode, t)
+	if !ok || step <= stepTerakhir {
+		return 0, false
+	}
+	return step, true
+}
+
+func kodeTOTP(secret string, step int64) (string, error) {
+	key, err := base32NoPad.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", err
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation (RFC 4226 §5.3)
+	offset := sum[len(sum)-1] & 0x0f
+	bin := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", bin%1000000), nil
+}
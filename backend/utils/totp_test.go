@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// Secret uji RFC 6238 Lampiran B untuk HMAC-SHA1: "12345678901234567890"
+var secretRFC6238 = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerateTOTPCodeRFC6238(t *testing.T) {
+	// Vektor uji RFC 6238 (SHA1, 8 digit); kode 6 digit adalah 6 digit terakhirnya
+	tests := []struct {
+		unix int64
+		kode string
+	}{
+		{59, "287082"},          // 94287082
+		{1111111109, "081804"},  // 07081804
+		{1111111111, "050471"},  // 14050471
+		{1234567890, "005924"},  // 89005924
+		{2000000000, "279037"},  // 69279037
+		{20000000000, "353130"}, // 65353130
+	}
+	for _, tt := range tests {
+		got, err := GenerateTOTPCode(secretRFC6238, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("GenerateTOTPCode(%d): %v", tt.unix, err)
+		}
+		if got != tt.kode {
+			t.Errorf("GenerateTOTPCode(%d) = %s, ingin %s", tt.unix, got, tt.kode)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	t0 := time.Unix(1111111111, 0) // step 37037037
+	step := t0.Unix() / totpPeriode
+
+	tests := []struct {
+		nama   string
+		kode   string
+		waktu  time.Time
+		valid  bool
+		diStep int64
+	}{
+		{"periode yang sama", "050471", t0, true, step},
+		{"spasi di sekitar kode", " 050471 ", t0, true, step},
+		{"satu periode kemudian", "050471", t0.Add(totpPeriode * time.Second), true, step},
+		{"satu periode sebelumnya", "050471", t0.Add(-totpPeriode * time.Second), true, step},
+		{"dua periode kemudian", "050471", t0.Add(2 * totpPeriode * time.Second), false, 0},
+		{"kode salah", "000000", t0, false, 0},
+		{"kurang dari 6 digit", "05047", t0, false, 0},
+		{"lebih dari 6 digit", "14050471", t0, false, 0},
+	}
+	for _, tt := range tests {
+		got, ok := ValidateTOTP(secretRFC6238, tt.kode, tt.waktu)
+		if ok != tt.valid || got != tt.diStep {
+			t.Errorf("%s: ValidateTOTP = (%d, %v), ingin (%d, %v)", tt.nama, got, ok, tt.diStep, tt.valid)
+		}
+	}
+
+	if _, ok := ValidateTOTP("bukan-base32!", "050471", t0); ok {
+		t.Error("secret tidak valid tidak boleh lolos validasi")
+	}
+}
+
+func TestValidateTOTPSetelahMenolakReplay(t *testing.T) {
+	t0 := time.Unix(1111111111, 0)
+	step := t0.Unix() / totpPeriode
+
+	tests := []struct {
+		nama         string
+		stepTerakhir int64
+		valid        bool
+	}{
+		{"belum pernah dipakai", 0, true},
+		{"langkah sebelumnya sudah dipakai", step - 1, true},
+		{"kode yang sama dipakai ulang", step, false},
+		{"langkah yang lebih baru sudah dipakai", step + 1, false},
+	}
+	for _, tt := range tests {
+		got, ok := ValidateTOTPSetelah(secretRFC6238, "050471", t0, tt.stepTerakhir)
+		if ok != tt.valid {
+			t.Errorf("%s: valid = %v, ingin %v", tt.nama, ok, tt.valid)
+		}
+		if ok && got != step {
+			t.Errorf("%s: step = %d, ingin %d", tt.nama, got, step)
+		}
+	}
+
+	// Kode periode sebelumnya yang masih dalam toleransi tetap ditolak setelah
+	// kode periode berjalan dipakai
+	sebelumnya, _ := GenerateTOTPCode(secretRFC6238, t0.Add(-totpPeriode*time.Second))
+	if _, ok := ValidateTOTPSetelah(secretRFC6238, sebelumnya, t0, step); ok {
+		t.Error("kode dari langkah yang lebih lama diterima setelah langkah berjalan dipakai")
+	}
+}
@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AturanUpload membatasi file yang boleh disimpan oleh SimpanUpload
+type AturanUpload struct {
+	Dir      string   // direktori tujuan, misal "storage/jurnal"
+	Prefix   string   // awalan nama file, misal "logo" atau "foto_12"
+	Ekstensi []string // ekstensi yang diizinkan (huruf kecil, dengan titik)
+	MaksByte int64
+	// TipeKonten membatasi tipe MIME hasil deteksi isi file (bukan dari nama
+	// file atau header klien), misal "image/png". Isi file juga harus cocok
+	// dengan ekstensinya (lihat tipeKontenEkstensi). Kosong = tidak diperiksa.
+	TipeKonten []string
+}
+
+// tipeKontenEkstensi adalah tipe MIME hasil deteksi yang sah untuk tiap
+// ekstensi, agar file ZIP yang diganti namanya menjadi .pdf/.jpg ditolak.
+// Dokumen Office terdeteksi sebagai zip sehingga isinya diperiksa lagi oleh
+// strukturOOXMLSesuai.
+var tipeKontenEkstensi = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".webp": "image/webp",
+	".pdf":  "application/pdf",
+	".docx": "application/zip",
+	".pptx": "application/zip",
+	".xlsx": "application/zip",
+}
+
+// folderOOXML adalah folder utama di dalam arsip dokumen Office per ekstensi
+var folderOOXML = map[string]string{
+	".docx": "word/",
+	".pptx": "ppt/",
+	".xlsx": "xl/",
+}
+
+// SimpanUpload memvalidasi ekstensi dan ukuran file lalu menyimpannya dengan
+// nama unik {prefix}_{unix milli}{ext}. Jika gagal, response error sudah
+// dikirim dan ok bernilai false.
+func SimpanUpload(c *gin.Context, header *multipart.FileHeader, aturan AturanUpload) (path string, ok bool) {
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	diizinkan := false
+	for _, e := range aturan.Ekstensi {
+		if e == ext {
+			diizinkan = true
+			break
+		}
+	}
+	if !diizinkan {
+		format := make([]string, len(aturan.Ekstensi))
+		for i, e := range aturan.Ekstensi {
+			format[i] = strings.ToUpper(strings.TrimPrefix(e, "."))
+		}
+		ResponseBadRequest(c, "Format file tidak didukung. Gunakan "+strings.Join(format, ", "), nil)
+		return "", false
+	}
+	if aturan.MaksByte > 0 && header.Size > aturan.MaksByte {
+		ResponseBadRequest(c, "Ukuran file maksimal "+formatUkuran(aturan.MaksByte), nil)
+		return "", false
+	}
+
+	if len(aturan.TipeKonten) > 0 && !tipeKontenDiizinkan(header, ext, aturan.TipeKonten) {
+		ResponseBadRequest(c, "Isi file tidak sesuai dengan formatnya", nil)
+		return "", false
+	}
+
+	if err := os.MkdirAll(aturan.Dir, 0755); err != nil {
+		ResponseInternalError(c, "Gagal membuat direktori upload")
+		return "", false
+	}
+	path = filepath.Join(aturan.Dir, fmt.Sprintf("%s_%d%s", aturan.Prefix, time.Now().UnixMilli(), ext))
+	if err := c.SaveUploadedFile(header, path); err != nil {
+		ResponseInternalError(c, "Gagal menyimpan file")
+		return "", false
+	}
+	return path, true
+}
+
+// KirimFile mengirim file privat (di luar ./uploads) sebagai lampiran. Hak
+// akses harus sudah diperiksa oleh pemanggil.
+func KirimFile(c *gin.Context, path string) {
+	if _, err := os.Stat(path); path == "" || err != nil {
+		ResponseNotFound(c, "File tidak ditemukan di server")
+		return
+	}
+	c.FileAttachment(path, filepath.Base(path))
+}
+
+// tipeKontenDiizinkan mendeteksi tipe MIME dari 512 byte pertama file lalu
+// memastikan tipe tersebut diizinkan dan sesuai dengan ekstensi file. Ekstensi
+// yang tidak terdaftar di tipeKontenEkstensi tidak dapat diverifikasi sehingga
+// ditolak.
+func tipeKontenDiizinkan(header *multipart.FileHeader, ext string, diizinkan []string) bool {
+	f, err := header.Open()
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return false
+	}
+	tipe := strings.TrimSpace(strings.SplitN(http.DetectContentType(buf[:n]), ";", 2)[0])
+	if tipe != tipeKontenEkstensi[ext] {
+		return false
+	}
+	cocok := false
+	for _, t := range diizinkan {
+		if t == tipe {
+			cocok = true
+			break
+		}
+	}
+	if !cocok {
+		return false
+	}
+	if folder, ok := folderOOXML[ext]; ok {
+		return strukturOOXMLSesuai(f, header.Size, folder)
+	}
+	return true
+}
+
+// strukturOOXMLSesuai memastikan arsip zip benar-benar dokumen Office: memiliki
+// [Content_Types].xml dan folder utama sesuai ekstensinya (word/, ppt/, xl/)
+func strukturOOXMLSesuai(f multipart.File, ukuran int64, folder string) bool {
+	arsip, err := zip.NewReader(f, ukuran)
+	if err != nil {
+		return false
+	}
+	adaContentTypes, adaFolder := false, false
+	for _, entri := range arsip.File {
+		if entri.Name == "[Content_Types].xml" {
+			adaContentTypes = true
+		}
+		if strings.HasPrefix(entri.Name, folder) {
+			adaFolder = true
+		}
+	}
+	return adaContentTypes && adaFolder
+}
+
+func formatUkuran(b int64) string {
+	if b >= 1024*1024 && b%(1024*1024) == 0 {
+		return fmt.Sprintf("%dMB", b/(1024*1024))
+	}
+	return fmt.Sprintf("%dKB", b/1024)
+}
@@ -16,6 +16,10 @@ import RaporList    from './pages/Rapor/RaporList';
 // Profile
 import ProfilePage from './pages/Profile/ProfilePage';
 
+// Akun (wajib ganti password / aktifkan 2FA)
+import GantiPasswordWajib from './pages/Akun/GantiPasswordWajib';
+import Aktifkan2FA        from './pages/Akun/Aktifkan2FA';
+
 // Mata Pelajaran
 import MataPelajaranList from './pages/MataPelajaran/MataPelajaranList';
 
@@ -55,6 +59,16 @@ function App() {
         <Routes>
           <Route path="/login" element={<Login />} />
 
+          {/* Di luar MainLayout: endpoint menu lain masih ditolak backend */}
+          <Route
+            path="/akun/ganti-password"
+            element={<PrivateRoute><GantiPasswordWajib /></PrivateRoute>}
+          />
+          <Route
+            path="/akun/aktifkan-2fa"
+            element={<PrivateRoute><Aktifkan2FA /></PrivateRoute>}
+          />
+
           <Route
             path="/"
             element={
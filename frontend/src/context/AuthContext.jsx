@@ -18,6 +18,14 @@ export const extractRole = (user) => {
   return raw.toLowerCase().trim();
 };
 
+// Halaman yang wajib diselesaikan sebelum user boleh memakai aplikasi.
+// Backend menolak endpoint lain selama flag ini masih true.
+export const halamanWajib = (user) => {
+  if (user?.wajib_ganti_password) return '/akun/ganti-password';
+  if (user?.wajib_2fa) return '/akun/aktifkan-2fa';
+  return null;
+};
+
 export const AuthProvider = ({ children }) => {
   const [user, setUser]       = useState(null);
   const [loading, setLoading] = useState(true);
@@ -36,36 +44,79 @@ export const AuthProvider = ({ children }) => {
     setLoading(false);
   }, []);
 
+  const simpanSesi = (token, userData) => {
+    localStorage.setItem('token', token);
+    localStorage.setItem('user', JSON.stringify(userData));
+    setUser(userData);
+  };
+
+  const hapusSesi = () => {
+    localStorage.removeItem('token');
+    localStorage.removeItem('user');
+    setUser(null);
+  };
+
+  // Ambil token & user dari response login / verifikasi 2FA
+  const prosesLoginBerhasil = (body) => {
+    const data     = body.data || body;
+    const token    = data.token;
+    const userData = data.user;
+
+    if (!token) throw new Error('Token tidak ditemukan dalam response');
+
+    simpanSesi(token, userData);
+    return { success: true, user: userData };
+  };
+
+  const gagal = (e, pesanBawaan) => {
+    hapusSesi();
+    return {
+      success: false,
+      message: e.response?.data?.message || e.message || pesanBawaan,
+    };
+  };
+
   const login = async (email, password) => {
     try {
       const res = await api.post('/auth/login', { email, password });
 
-      let token, userData;
-      if (res.data.data) {
-        token    = res.data.data.token;
-        userData = res.data.data.user;
-      } else {
-        token    = res.data.token;
-        userData = res.data.user;
+      // Akun dengan 2FA aktif: belum ada token, lanjut ke langkah verifikasi kode
+      const data = res.data.data || res.data;
+      if (data?.two_fa_required) {
+        return { success: false, twoFaRequired: true, partialToken: data.partial_token };
       }
 
-      if (!token) throw new Error('Token tidak ditemukan dalam response');
+      return prosesLoginBerhasil(res.data);
+    } catch (e) {
+      return gagal(e, 'Login gagal');
+    }
+  };
 
-      localStorage.setItem('token', token);
-      localStorage.setItem('user', JSON.stringify(userData));
-      setUser(userData);
-      return { success: true };
+  /**
+   * Langkah kedua login untuk akun dengan 2FA aktif
+   * @param {string} partialToken - partial_token dari langkah login
+   * @param {string} kode - kode TOTP atau recovery code
+   */
+  const verify2FA = async (partialToken, kode) => {
+    try {
+      const res = await api.post('/auth/2fa/verify', { partial_token: partialToken, kode });
+      return prosesLoginBerhasil(res.data);
     } catch (e) {
-      localStorage.removeItem('token');
-      localStorage.removeItem('user');
-      setUser(null);
-      return {
-        success: false,
-        message: e.response?.data?.message || e.message || 'Login gagal',
-      };
+      return gagal(e, 'Verifikasi 2FA gagal');
     }
   };
 
+  /**
+   * Ganti token setelah backend menerbitkan token baru (ganti password,
+   * aktifkan 2FA) sekaligus memperbarui flag user
+   * @param {string} token - token baru dari backend
+   * @param {Partial<User>} partialUser - field user yang berubah
+   */
+  const perbaruiSesi = (token, partialUser) => {
+    if (token) localStorage.setItem('token', token);
+    updateUser(partialUser);
+  };
+
   /**
    * Update sebagian field user di context + localStorage
    * Dipanggil setelah edit profil atau upload foto
@@ -90,6 +141,8 @@ export const AuthProvider = ({ children }) => {
     <AuthContext.Provider value={{
       user,
       login,
+      verify2FA,
+      perbaruiSesi,
       logout,
       updateUser,
       loading,
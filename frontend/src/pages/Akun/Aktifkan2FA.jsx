@@ -0,0 +1,148 @@
+import { useState } from 'react';
+import { useNavigate } from 'react-router-dom';
+import { useAuth } from '../../context/AuthContext';
+import { authService } from '../../services/authService';
+import Alert from '../../components/Common/Alert';
+import LoadingSpinner from '../../components/Common/LoadingSpinner';
+
+// Halaman untuk akun yang role-nya mewajibkan 2FA tetapi belum mengaktifkannya.
+// Alur: buat secret → masukkan kode dari authenticator → simpan recovery code.
+const Aktifkan2FA = () => {
+  const navigate = useNavigate();
+  const { perbaruiSesi, logout } = useAuth();
+
+  const [setup, setSetup] = useState(null); // { secret, otpauth_uri }
+  const [kode, setKode] = useState('');
+  const [recoveryCodes, setRecoveryCodes] = useState([]);
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState('');
+
+  const handleSetup = async () => {
+    setError('');
+    setLoading(true);
+    try {
+      const res = await authService.setup2FA();
+      setSetup(res.data);
+    } catch (err) {
+      setError(err.response?.data?.message || 'Gagal menyiapkan 2FA');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleAktifkan = async (e) => {
+    e.preventDefault();
+    setError('');
+    setLoading(true);
+    try {
+      const res = await authService.aktifkan2FA(kode.trim());
+      perbaruiSesi(res.data?.token, { wajib_2fa: false });
+      setRecoveryCodes(res.data?.recovery_codes || []);
+    } catch (err) {
+      setError(err.response?.data?.message || 'Gagal mengaktifkan 2FA');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  // Secret ditampilkan per 4 karakter agar mudah diketik manual
+  const secretTerformat = setup?.secret?.match(/.{1,4}/g)?.join(' ');
+
+  return (
+    <div className="min-h-screen bg-gradient-to-br from-primary to-primary-light flex items-center justify-center p-4">
+      <div className="bg-white rounded-lg shadow-2xl w-full max-w-md p-8">
+        <div className="text-center mb-8">
+          <h1 className="text-2xl font-bold text-primary mb-2">Aktifkan Autentikasi Dua Faktor</h1>
+          <p className="text-text-light text-sm">
+            Role Anda mewajibkan 2FA. Siapkan aplikasi authenticator (Google Authenticator, Aegis, dsb.) di ponsel Anda.
+          </p>
+        </div>
+
+        {error && <Alert type="error" message={error} onClose={() => setError('')} />}
+
+        {recoveryCodes.length > 0 ? (
+          <div className="space-y-5">
+            <Alert
+              type="warning"
+              message="Simpan recovery code berikut di tempat yang aman. Setiap kode hanya bisa dipakai sekali dan tidak akan ditampilkan lagi."
+            />
+            <div className="grid grid-cols-2 gap-2 font-mono text-sm bg-gray-50 border rounded-lg p-4">
+              {recoveryCodes.map(k => <span key={k}>{k}</span>)}
+            </div>
+            <button
+              type="button"
+              onClick={() => navigate('/dashboard', { replace: true })}
+              className="w-full btn-primary py-3"
+            >
+              Saya Sudah Menyimpan, Lanjutkan
+            </button>
+          </div>
+        ) : setup ? (
+          <form onSubmit={handleAktifkan} className="space-y-5">
+            <div>
+              <p className="text-sm text-text mb-2">
+                1. Tambahkan akun baru di aplikasi authenticator dengan kunci berikut:
+              </p>
+              <div className="font-mono text-center text-lg tracking-wider bg-gray-50 border rounded-lg p-3 break-all">
+                {secretTerformat}
+              </div>
+              <a
+                href={setup.otpauth_uri}
+                className="block text-center text-xs text-primary hover:underline mt-2"
+              >
+                Buka langsung di aplikasi authenticator (dari ponsel)
+              </a>
+            </div>
+
+            <div>
+              <label className="block text-sm font-medium text-text mb-2">
+                2. Masukkan kode 6 digit dari aplikasi
+              </label>
+              <input
+                type="text"
+                inputMode="numeric"
+                value={kode}
+                onChange={(e) => setKode(e.target.value.replace(/\D/g, '').slice(0, 6))}
+                className="input-field text-center tracking-widest"
+                placeholder="000000"
+                autoComplete="one-time-code"
+                required
+                disabled={loading}
+              />
+            </div>
+
+            <button
+              type="submit"
+              className="w-full btn-primary py-3"
+              disabled={loading || kode.length !== 6}
+            >
+              {loading ? <LoadingSpinner size="sm" /> : 'Aktifkan 2FA'}
+            </button>
+          </form>
+        ) : (
+          <button
+            type="button"
+            onClick={handleSetup}
+            className="w-full btn-primary py-3"
+            disabled={loading}
+          >
+            {loading ? <LoadingSpinner size="sm" /> : 'Mulai Pengaturan 2FA'}
+          </button>
+        )}
+
+        {recoveryCodes.length === 0 && (
+          <button
+            type="button"
+            onClick={logout}
+            className="w-full text-sm text-text-light hover:text-primary mt-4"
+            disabled={loading}
+          >
+            Keluar
+          </button>
+        )}
+      </div>
+    </div>
+  );
+};
+
+export default Aktifkan2FA;
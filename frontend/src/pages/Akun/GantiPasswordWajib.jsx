@@ -0,0 +1,150 @@
+import { useState } from 'react';
+import { useNavigate } from 'react-router-dom';
+import { useAuth, halamanWajib } from '../../context/AuthContext';
+import { authService } from '../../services/authService';
+import Alert from '../../components/Common/Alert';
+import LoadingSpinner from '../../components/Common/LoadingSpinner';
+
+// Halaman untuk akun yang wajib mengganti password (misal password awal dari
+// admin atau password yang sudah kadaluarsa). Endpoint lain ditolak backend
+// sampai password diganti.
+const GantiPasswordWajib = () => {
+  const navigate = useNavigate();
+  const { user, perbaruiSesi, logout } = useAuth();
+
+  const [form, setForm] = useState({
+    password_lama: '',
+    password_baru: '',
+    konfirmasi_password: '',
+  });
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState('');
+
+  const handleChange = (e) => {
+    setForm({ ...form, [e.target.name]: e.target.value });
+  };
+
+  const handleSubmit = async (e) => {
+    e.preventDefault();
+    setError('');
+
+    if (form.password_baru.length < 8) {
+      setError('Password baru minimal 8 karakter');
+      return;
+    }
+    if (form.password_baru !== form.konfirmasi_password) {
+      setError('Konfirmasi password tidak cocok');
+      return;
+    }
+    if (form.password_baru === form.password_lama) {
+      setError('Password baru harus berbeda dari password lama');
+      return;
+    }
+
+    setLoading(true);
+    try {
+      const res = await authService.changePassword({
+        password_lama: form.password_lama,
+        password_baru: form.password_baru,
+      });
+
+      const perubahan = {
+        wajib_ganti_password: false,
+        wajib_2fa: !!res.data?.wajib_2fa,
+      };
+      perbaruiSesi(res.data?.token, perubahan);
+      navigate(halamanWajib({ ...user, ...perubahan }) || '/dashboard', { replace: true });
+    } catch (err) {
+      setError(err.response?.data?.message || 'Gagal mengubah password');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="min-h-screen bg-gradient-to-br from-primary to-primary-light flex items-center justify-center p-4">
+      <div className="bg-white rounded-lg shadow-2xl w-full max-w-md p-8">
+        <div className="text-center mb-8">
+          <h1 className="text-2xl font-bold text-primary mb-2">Ganti Password</h1>
+          <p className="text-text-light text-sm">
+            Demi keamanan akun, Anda wajib mengganti password sebelum melanjutkan.
+          </p>
+        </div>
+
+        {error && <Alert type="error" message={error} onClose={() => setError('')} />}
+
+        <form onSubmit={handleSubmit} className="space-y-5">
+          <div>
+            <label className="block text-sm font-medium text-text mb-2">
+              Password Lama
+            </label>
+            <input
+              type="password"
+              name="password_lama"
+              value={form.password_lama}
+              onChange={handleChange}
+              className="input-field"
+              placeholder="Masukkan password lama"
+              autoComplete="current-password"
+              required
+              disabled={loading}
+            />
+          </div>
+
+          <div>
+            <label className="block text-sm font-medium text-text mb-2">
+              Password Baru
+            </label>
+            <input
+              type="password"
+              name="password_baru"
+              value={form.password_baru}
+              onChange={handleChange}
+              className="input-field"
+              placeholder="Minimal 8 karakter"
+              autoComplete="new-password"
+              required
+              disabled={loading}
+            />
+          </div>
+
+          <div>
+            <label className="block text-sm font-medium text-text mb-2">
+              Konfirmasi Password Baru
+            </label>
+            <input
+              type="password"
+              name="konfirmasi_password"
+              value={form.konfirmasi_password}
+              onChange={handleChange}
+              className="input-field"
+              placeholder="Ulangi password baru"
+              autoComplete="new-password"
+              required
+              disabled={loading}
+            />
+          </div>
+
+          <button
+            type="submit"
+            className="w-full btn-primary py-3"
+            disabled={loading}
+          >
+            {loading ? <LoadingSpinner size="sm" /> : 'Simpan Password'}
+          </button>
+
+          <button
+            type="button"
+            onClick={logout}
+            className="w-full text-sm text-text-light hover:text-primary"
+            disabled={loading}
+          >
+            Keluar
+          </button>
+        </form>
+      </div>
+    </div>
+  );
+};
+
+export default GantiPasswordWajib;
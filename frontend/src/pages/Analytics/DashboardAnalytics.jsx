@@ -3,8 +3,7 @@ import { Chart as ChartJS, ArcElement, CategoryScale, LinearScale, BarElement, T
 import { Pie, Bar, Line } from 'react-chartjs-2';
 import { kelasService } from '../../services/kelasService';
 import { siswaService } from '../../services/siswaService';
-import { absensiService } from '../../services/absensiService';
-import { nilaiService } from '../../services/nilaiService';
+import { analyticsService } from '../../services/analyticsService';
 import { semesterService } from '../../services/semesterService';
 import LoadingSpinner from '../../components/Common/LoadingSpinner';
 
@@ -68,15 +67,17 @@ export default function DashboardAnalytics() {
   async function fetchKehadiranData(kelasId, semesterId) {
     if (!semesterId) return;
     try {
-      const res = await absensiService.getRekapKelas(kelasId, { semester_id: semesterId });
-      const rekap = Array.isArray(res.data) ? res.data : (res.data?.data || []);
+      const res = await analyticsService.getTrenKehadiran({
+        semester_id: semesterId, kelas_id: kelasId, periode: 'bulan',
+      });
+      const tren = res.data?.data || [];
 
-      // Hitung total
-      const totals = rekap.reduce((acc, r) => ({
-        hadir: acc.hadir + (r.hadir || 0),
-        izin: acc.izin + (r.izin || 0),
-        sakit: acc.sakit + (r.sakit || 0),
-        alfa: acc.alfa + (r.alfa || 0),
+      // Total seluruh periode (sudah diagregasi di server)
+      const totals = tren.reduce((acc, t) => ({
+        hadir: acc.hadir + t.hadir,
+        izin: acc.izin + t.izin,
+        sakit: acc.sakit + t.sakit,
+        alfa: acc.alfa + t.alfa,
       }), { hadir: 0, izin: 0, sakit: 0, alfa: 0 });
 
       setKehadiranData({
@@ -97,30 +98,24 @@ export default function DashboardAnalytics() {
   async function fetchNilaiData(kelasId, semesterId) {
     if (!semesterId) return;
     try {
-      // Fetch siswa di kelas
-      const siswaRes = await kelasService.getSiswaByKelas(kelasId);
-      const rawSiswa = siswaRes.data;
-      const siswa = Array.isArray(rawSiswa) ? rawSiswa : (rawSiswa?.siswa || []);
-
-      // Fetch nilai semua siswa
-      const nilaiPromises = siswa.map(s => 
-        nilaiService.getNilaiSiswa(s.id, { semester_id: semesterId }).catch(() => ({ data: null }))
-      );
-      const nilaiResults = await Promise.all(nilaiPromises);
-
-      // Hitung distribusi predikat
-      const distribusi = { A: 0, B: 0, C: 0, D: 0, E: 0 };
-      nilaiResults.forEach(res => {
-        const predikat = res.data?.predikat_umum;
-        if (predikat && distribusi.hasOwnProperty(predikat)) {
-          distribusi[predikat]++;
-        }
+      const res = await analyticsService.getDistribusiNilai({
+        semester_id: semesterId, kelas_id: kelasId,
       });
+      const mapel = res.data?.data || [];
+
+      // Jumlahkan predikat seluruh mata pelajaran
+      const distribusi = mapel.reduce((acc, m) => ({
+        A: acc.A + m.predikat_a,
+        B: acc.B + m.predikat_b,
+        C: acc.C + m.predikat_c,
+        D: acc.D + m.predikat_d,
+        E: acc.E + m.predikat_e,
+      }), { A: 0, B: 0, C: 0, D: 0, E: 0 });
 
       setNilaiDistribusi({
         labels: ['A', 'B', 'C', 'D', 'E'],
         datasets: [{
-          label: 'Jumlah Siswa',
+          label: 'Jumlah Nilai',
           data: [distribusi.A, distribusi.B, distribusi.C, distribusi.D, distribusi.E],
           backgroundColor: ['#22C55E', '#3B82F6', '#FACC15', '#F59E0B', '#EF4444'],
           borderColor: ['#16A34A', '#2563EB', '#EAB308', '#D97706', '#DC2626'],
@@ -265,7 +260,7 @@ export default function DashboardAnalytics() {
         <ul className="text-sm text-blue-800 space-y-1">
           <li>• Data ditampilkan untuk semester aktif: <strong>{semesterAktif?.nama || '-'}</strong></li>
           <li>• Pilih kelas berbeda untuk melihat statistik per kelas</li>
-          <li>• Kehadiran dan nilai dihitung real-time dari database</li>
+          <li>• Kehadiran dan nilai diagregasi di server dari endpoint /analytics</li>
         </ul>
       </div>
     </div>
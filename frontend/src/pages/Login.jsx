@@ -1,12 +1,12 @@
 import { useState } from 'react';
 import { useNavigate } from 'react-router-dom';
-import { useAuth } from '../context/AuthContext';
+import { useAuth, halamanWajib } from '../context/AuthContext';
 import Alert from '../components/Common/Alert';
 import LoadingSpinner from '../components/Common/LoadingSpinner';
 
 const Login = () => {
   const navigate = useNavigate();
-  const { login } = useAuth();
+  const { login, verify2FA } = useAuth();
   
   const [formData, setFormData] = useState({
     email: '',
@@ -14,6 +14,9 @@ const Login = () => {
   });
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState('');
+  // Terisi jika akun memakai 2FA: login dilanjutkan dengan kode authenticator
+  const [partialToken, setPartialToken] = useState('');
+  const [kode, setKode] = useState('');
 
   const handleChange = (e) => {
     setFormData({
@@ -35,7 +38,9 @@ const Login = () => {
       
       if (result.success) {
         console.log('Login successful, navigating to dashboard...'); // Debug log
-        navigate('/dashboard');
+        navigate(halamanWajib(result.user) || '/dashboard');
+      } else if (result.twoFaRequired) {
+        setPartialToken(result.partialToken);
       } else {
         setError(result.message);
       }
@@ -47,6 +52,32 @@ const Login = () => {
     }
   };
 
+  const handleVerify = async (e) => {
+    e.preventDefault();
+    setError('');
+    setLoading(true);
+
+    try {
+      const result = await verify2FA(partialToken, kode.trim());
+      if (result.success) {
+        navigate(halamanWajib(result.user) || '/dashboard');
+      } else {
+        setError(result.message);
+      }
+    } catch (err) {
+      console.error('Verify 2FA exception:', err); // Debug log
+      setError('Terjadi kesalahan. Silakan coba lagi.');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const kembaliKeLogin = () => {
+    setPartialToken('');
+    setKode('');
+    setError('');
+  };
+
   return (
     <div className="min-h-screen bg-gradient-to-br from-primary to-primary-light flex items-center justify-center p-4">
       <div className="bg-white rounded-lg shadow-2xl w-full max-w-md p-8">
@@ -57,47 +88,89 @@ const Login = () => {
 
         {error && <Alert type="error" message={error} onClose={() => setError('')} />}
 
-        <form onSubmit={handleSubmit} className="space-y-6">
-          <div>
-            <label className="block text-sm font-medium text-text mb-2">
-              Email
-            </label>
-            <input
-              type="email"
-              name="email"
-              value={formData.email}
-              onChange={handleChange}
-              className="input-field"
-              placeholder="Masukkan email"
-              required
+        {partialToken ? (
+          <form onSubmit={handleVerify} className="space-y-6">
+            <div>
+              <label className="block text-sm font-medium text-text mb-2">
+                Kode Autentikasi
+              </label>
+              <input
+                type="text"
+                name="kode"
+                value={kode}
+                onChange={(e) => setKode(e.target.value)}
+                className="input-field tracking-widest"
+                placeholder="6 digit kode atau recovery code"
+                autoComplete="one-time-code"
+                autoFocus
+                required
+                disabled={loading}
+              />
+              <p className="text-xs text-text-light mt-2">
+                Buka aplikasi authenticator, atau gunakan salah satu recovery code jika perangkat tidak tersedia.
+              </p>
+            </div>
+
+            <button
+              type="submit"
+              className="w-full btn-primary py-3"
+              disabled={loading}
+            >
+              {loading ? <LoadingSpinner size="sm" /> : 'Verifikasi'}
+            </button>
+
+            <button
+              type="button"
+              onClick={kembaliKeLogin}
+              className="w-full text-sm text-text-light hover:text-primary"
               disabled={loading}
-            />
-          </div>
-
-          <div>
-            <label className="block text-sm font-medium text-text mb-2">
-              Password
-            </label>
-            <input
-              type="password"
-              name="password"
-              value={formData.password}
-              onChange={handleChange}
-              className="input-field"
-              placeholder="Masukkan password"
-              required
+            >
+              ← Kembali ke login
+            </button>
+          </form>
+        ) : (
+          <form onSubmit={handleSubmit} className="space-y-6">
+            <div>
+              <label className="block text-sm font-medium text-text mb-2">
+                Email
+              </label>
+              <input
+                type="email"
+                name="email"
+                value={formData.email}
+                onChange={handleChange}
+                className="input-field"
+                placeholder="Masukkan email"
+                required
+                disabled={loading}
+              />
+            </div>
+
+            <div>
+              <label className="block text-sm font-medium text-text mb-2">
+                Password
+              </label>
+              <input
+                type="password"
+                name="password"
+                value={formData.password}
+                onChange={handleChange}
+                className="input-field"
+                placeholder="Masukkan password"
+                required
+                disabled={loading}
+              />
+            </div>
+
+            <button
+              type="submit"
+              className="w-full btn-primary py-3"
               disabled={loading}
-            />
-          </div>
-
-          <button
-            type="submit"
-            className="w-full btn-primary py-3"
-            disabled={loading}
-          >
-            {loading ? <LoadingSpinner size="sm" /> : 'Login'}
-          </button>
-        </form>
+            >
+              {loading ? <LoadingSpinner size="sm" /> : 'Login'}
+            </button>
+          </form>
+        )}
 
         <div className="mt-6 text-center text-sm text-text-light">
           <p>© 2025 SIM Sekolah. All rights reserved.</p>
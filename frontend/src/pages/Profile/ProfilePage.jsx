@@ -16,7 +16,7 @@ const ROLE_LABEL = {
 };
 
 export default function ProfilePage() {
-  const { user, updateUser } = useAuth();
+  const { user, updateUser, perbaruiSesi } = useAuth();
   const location  = useLocation();
   const fileInputRef = useRef(null);
 
@@ -133,6 +133,12 @@ export default function ProfilePage() {
       const data = await res.json();
       if (!res.ok) throw new Error(data.message || 'Gagal mengubah password');
 
+      // Backend mengirim token baru (tanpa flag wajib ganti password)
+      perbaruiSesi(data.data?.token, {
+        wajib_ganti_password: false,
+        wajib_2fa: !!data.data?.wajib_2fa,
+      });
+
       setPasswordForm({ password_lama: '', password_baru: '', konfirmasi_password: '' });
       showMessage('success', 'Password berhasil diubah!');
     } catch (err) {
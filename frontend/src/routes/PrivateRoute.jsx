@@ -1,13 +1,20 @@
-import { Navigate } from 'react-router-dom';
-import { useAuth } from '../context/AuthContext';
+import { Navigate, useLocation } from 'react-router-dom';
+import { useAuth, halamanWajib } from '../context/AuthContext';
 
 const PrivateRoute = ({ children }) => {
-  const { isAuthenticated } = useAuth();
+  const { isAuthenticated, user } = useAuth();
+  const location = useLocation();
 
   if (!isAuthenticated) {
     return <Navigate to="/login" replace />;
   }
 
+  // Akun yang wajib ganti password / aktifkan 2FA diarahkan ke halaman itu dulu
+  const wajib = halamanWajib(user);
+  if (wajib && location.pathname !== wajib) {
+    return <Navigate to={wajib} replace />;
+  }
+
   return children;
 };
 
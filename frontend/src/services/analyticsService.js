@@ -0,0 +1,33 @@
+import api from '../utils/api';
+
+export const analyticsService = {
+  // GET /analytics/kehadiran/tren - params: periode, kelompok, semester_id, kelas_id, ...
+  getTrenKehadiran: async (params) => {
+    const response = await api.get('/analytics/kehadiran/tren', { params });
+    return response.data;
+  },
+
+  // GET /analytics/nilai/distribusi
+  getDistribusiNilai: async (params) => {
+    const response = await api.get('/analytics/nilai/distribusi', { params });
+    return response.data;
+  },
+
+  // GET /analytics/nilai/dibawah-kkm - params: kelompok (mapel/kelas/jurusan)
+  getDibawahKKM: async (params) => {
+    const response = await api.get('/analytics/nilai/dibawah-kkm', { params });
+    return response.data;
+  },
+
+  // GET /analytics/kelengkapan-input
+  getKelengkapanInput: async (params) => {
+    const response = await api.get('/analytics/kelengkapan-input', { params });
+    return response.data;
+  },
+
+  // GET /analytics/perbandingan-tahunan - params: semester (Ganjil/Genap), jurusan_id
+  getPerbandinganTahunan: async (params) => {
+    const response = await api.get('/analytics/perbandingan-tahunan', { params });
+    return response.data;
+  },
+};
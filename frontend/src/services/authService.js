@@ -14,5 +14,41 @@ export const authService = {
   getProfile: async () => {
     const response = await api.get('/auth/profile');
     return response.data;
+  },
+
+  /**
+   * Langkah kedua login untuk akun dengan 2FA aktif
+   * @param {string} partialToken - partial_token dari response login
+   * @param {string} kode - kode TOTP 6 digit atau recovery code
+   */
+  verify2FA: async (partialToken, kode) => {
+    const response = await api.post('/auth/2fa/verify', { partial_token: partialToken, kode });
+    return response.data;
+  },
+
+  /**
+   * Ganti password user yang sedang login
+   * @param {{ password_lama: string, password_baru: string }} data
+   */
+  changePassword: async (data) => {
+    const response = await api.put('/auth/change-password', data);
+    return response.data;
+  },
+
+  /**
+   * Buat secret TOTP baru (belum aktif sampai dikonfirmasi)
+   */
+  setup2FA: async () => {
+    const response = await api.post('/auth/2fa/setup');
+    return response.data;
+  },
+
+  /**
+   * Aktifkan 2FA dengan kode dari aplikasi authenticator
+   * @param {string} kode - kode TOTP 6 digit
+   */
+  aktifkan2FA: async (kode) => {
+    const response = await api.post('/auth/2fa/aktifkan', { kode });
+    return response.data;
   }
 };
\ No newline at end of file